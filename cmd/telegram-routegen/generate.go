@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strings"
+	"text/template"
+)
+
+const directive = "//telegram:route"
+
+// route is an annotated payload type.
+type route struct {
+	Type    string // Payload type name
+	Name    string // Callback route
+	Command string // Optional command bound to the same handler
+}
+
+func parseDirective(line string) (route, error) {
+	var r route
+	for _, field := range strings.Fields(strings.TrimPrefix(line, directive)) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return r, fmt.Errorf("invalid directive argument %q", field)
+		}
+		switch key {
+		case "name":
+			r.Name = value
+		case "command":
+			r.Command = strings.TrimPrefix(value, "/")
+		default:
+			return r, fmt.Errorf("unknown directive argument %q", key)
+		}
+	}
+	if r.Name == "" {
+		return r, fmt.Errorf("directive requires name")
+	}
+	if strings.Contains(r.Name, ":") {
+		return r, fmt.Errorf("route name %q must not contain \":\"", r.Name)
+	}
+	return r, nil
+}
+
+// parseRoutes returns the annotated types of the source file.
+func parseRoutes(filename string, src []byte) (string, []route, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), filename, src, parser.ParseComments)
+	if err != nil {
+		return "", nil, err
+	}
+	var routes []route
+	seen := map[string]string{}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			doc := ts.Doc
+			if doc == nil && len(gen.Specs) == 1 {
+				doc = gen.Doc
+			}
+			if doc == nil {
+				continue
+			}
+			for _, c := range doc.List {
+				if !strings.HasPrefix(c.Text, directive) {
+					continue
+				}
+				r, e := parseDirective(c.Text)
+				if e != nil {
+					return "", nil, fmt.Errorf("%s: %w", ts.Name.Name, e)
+				}
+				if other, dup := seen[r.Name]; dup {
+					return "", nil, fmt.Errorf("route %q is used by %s and %s", r.Name, other, ts.Name.Name)
+				}
+				seen[r.Name] = ts.Name.Name
+				r.Type = ts.Name.Name
+				routes = append(routes, r)
+			}
+		}
+	}
+	return file.Name.Name, routes, nil
+}
+
+var tmpl = template.Must(template.New("routes").Parse(`// Code generated by telegram-routegen. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+	"context"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+const (
+{{- range .Routes }}
+	Route{{ .Type }} = "{{ .Name }}"
+{{- if .Command }}
+	Command{{ .Type }} = "{{ .Command }}"
+{{- end }}
+{{- end }}
+)
+{{ range .Routes }}
+// New{{ .Type }}Button creates a button routed to Route{{ .Type }}.
+func New{{ .Type }}Button(text string, data {{ .Type }}, options ...telegram.DataOption) telegram.Button {
+	return telegram.NewButton(text, Route{{ .Type }}, data, options...)
+}
+
+// Bind{{ .Type }} registers the handler for callbacks routed to Route{{ .Type }}
+{{- if .Command }} and the Command{{ .Type }} command, for which data is nil{{ end }}.
+func Bind{{ .Type }}(b *telegram.Bot, handler func(ctx context.Context, update *telegram.Update, data *{{ .Type }}) error, middlewares ...telegram.MiddlewareFunc) {
+	fn := func(ctx context.Context, update *telegram.Update) error {
+		var data *{{ .Type }}
+		if update.CallbackQuery != nil {
+			_, decoded, err := telegram.UnmarshalData[{{ .Type }}](update.CallbackQuery.Data, b.DataOptions()...)
+			if err != nil {
+				return err
+			}
+			data = decoded
+		}
+		return handler(ctx, update, data)
+	}
+	b.BindCallback(Route{{ .Type }}, fn, middlewares...)
+{{- if .Command }}
+	b.BindCommand(Command{{ .Type }}, fn, middlewares...)
+{{- end }}
+}
+{{ end }}`))
+
+// generate returns the formatted source of the route helpers for the annotated types.
+func generate(filename string, src []byte) ([]byte, error) {
+	pkg, routes, err := parseRoutes(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("no %s directive found in %s", directive, filename)
+	}
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, map[string]any{
+		"Package": pkg,
+		"Routes":  routes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const testSource = `package menu
+
+//telegram:route name=menu command=start
+type MenuPayload struct {
+	Page int ` + "`json:\"page\"`" + `
+}
+
+type (
+	//telegram:route name=item
+	ItemPayload struct {
+		ID int64
+	}
+	ignored struct{}
+)
+`
+
+func TestGenerate(t *testing.T) {
+	code, err := generate("menu.go", []byte(testSource))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(code)
+	for _, want := range []string{
+		"package menu",
+		`RouteMenuPayload   = "menu"`,
+		`CommandMenuPayload = "start"`,
+		`RouteItemPayload   = "item"`,
+		"func NewItemPayloadButton(text string, data ItemPayload, options ...telegram.DataOption) telegram.Button",
+		"b.BindCommand(CommandMenuPayload, fn, middlewares...)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "ignored") || strings.Contains(out, "CommandItemPayload") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestGenerate_DuplicateRoute(t *testing.T) {
+	src := "package p\n//telegram:route name=a\ntype A struct{}\n//telegram:route name=a\ntype B struct{}\n"
+	if _, err := generate("p.go", []byte(src)); err == nil {
+		t.Error("expected duplicate route error")
+	}
+}
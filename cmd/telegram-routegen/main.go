@@ -0,0 +1,48 @@
+// Command telegram-routegen generates typed route constants, button constructors and
+// Bind helpers for callback payload structs, so buttons and handlers can not drift apart.
+//
+// Annotate payload structs with a directive:
+//
+//	//telegram:route name=menu command=start
+//	type MenuPayload struct {
+//		Page int `json:"page"`
+//	}
+//
+// and add to the file:
+//
+//	//go:generate go run github.com/go-sphere/telegram-bot/cmd/telegram-routegen
+//
+// For every annotated type T the generator emits the RouteT (and CommandT) constants,
+// a NewTButton constructor and a BindT function registering a typed handler.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	input := flag.String("file", os.Getenv("GOFILE"), "Go source file containing the annotated payload types")
+	output := flag.String("output", "", "output file, defaults to <file>_routes.go")
+	flag.Parse()
+	if *input == "" {
+		log.Fatal("telegram-routegen: -file is required outside of go generate")
+	}
+	src, err := os.ReadFile(*input)
+	if err != nil {
+		log.Fatalf("telegram-routegen: %v", err)
+	}
+	code, err := generate(filepath.Base(*input), src)
+	if err != nil {
+		log.Fatalf("telegram-routegen: %v", err)
+	}
+	if *output == "" {
+		*output = strings.TrimSuffix(*input, ".go") + "_routes.go"
+	}
+	if err = os.WriteFile(*output, code, 0o644); err != nil {
+		log.Fatalf("telegram-routegen: %v", err)
+	}
+}
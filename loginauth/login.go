@@ -0,0 +1,166 @@
+package loginauth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrHashMissing     = errors.New("hash is missing")
+	ErrHashInvalid     = errors.New("hash is invalid")
+	ErrAuthDateMissing = errors.New("auth_date is missing")
+	ErrExpired         = errors.New("login data is expired")
+)
+
+// LoginData holds the user data sent by the Telegram Login Widget.
+type LoginData struct {
+	ID        int64  `json:"id"`
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+	Username  string `json:"username,omitempty"`
+	PhotoURL  string `json:"photo_url,omitempty"`
+	AuthDate  int64  `json:"auth_date"`
+	Hash      string `json:"hash"`
+}
+
+// ToAuthMap returns the login data in the same shape produced by telegram.DefaultAuthExtractor.
+func (d *LoginData) ToAuthMap() map[string]any {
+	return map[string]any{
+		"uid":     d.ID,
+		"subject": d.Username,
+	}
+}
+
+func (d *LoginData) values() map[string]string {
+	values := map[string]string{
+		"id":        strconv.FormatInt(d.ID, 10),
+		"auth_date": strconv.FormatInt(d.AuthDate, 10),
+	}
+	if d.FirstName != "" {
+		values["first_name"] = d.FirstName
+	}
+	if d.LastName != "" {
+		values["last_name"] = d.LastName
+	}
+	if d.Username != "" {
+		values["username"] = d.Username
+	}
+	if d.PhotoURL != "" {
+		values["photo_url"] = d.PhotoURL
+	}
+	return values
+}
+
+// LoginAuth validates Telegram Login Widget payloads.
+// See https://core.telegram.org/widgets/login#checking-authorization
+type LoginAuth struct {
+	token string
+	expIn time.Duration
+}
+
+func NewLoginAuth(token string, expIn time.Duration) *LoginAuth {
+	return &LoginAuth{
+		token: token,
+		expIn: expIn,
+	}
+}
+
+// ParseToken validates a login callback query string and returns the parsed login data.
+func (a *LoginAuth) ParseToken(ctx context.Context, query string) (LoginData, error) {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return LoginData{}, err
+	}
+	return a.ParseValues(ctx, values)
+}
+
+// ParseValues validates login callback query values and returns the parsed login data.
+func (a *LoginAuth) ParseValues(ctx context.Context, values url.Values) (LoginData, error) {
+	data := LoginData{
+		FirstName: values.Get("first_name"),
+		LastName:  values.Get("last_name"),
+		Username:  values.Get("username"),
+		PhotoURL:  values.Get("photo_url"),
+		Hash:      values.Get("hash"),
+	}
+	var err error
+	if raw := values.Get("id"); raw != "" {
+		if data.ID, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			return LoginData{}, err
+		}
+	}
+	if raw := values.Get("auth_date"); raw != "" {
+		if data.AuthDate, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			return LoginData{}, err
+		}
+	}
+	fields := make(map[string]string, len(values))
+	for k := range values {
+		if k != "hash" {
+			fields[k] = values.Get(k)
+		}
+	}
+	if err = a.validate(fields, data.AuthDate, data.Hash); err != nil {
+		return LoginData{}, err
+	}
+	return data, nil
+}
+
+// Verify validates login data received as JSON, e.g. from the widget's data-onauth callback.
+func (a *LoginAuth) Verify(ctx context.Context, data *LoginData) error {
+	if data == nil {
+		return ErrHashMissing
+	}
+	return a.validate(data.values(), data.AuthDate, data.Hash)
+}
+
+// GenerateToken signs the login data and returns it encoded as a callback query string.
+func (a *LoginAuth) GenerateToken(ctx context.Context, data *LoginData) (string, error) {
+	if data == nil {
+		return "", errors.New("login data must not be nil")
+	}
+	fields := data.values()
+	values := url.Values{}
+	for k, v := range fields {
+		values.Set(k, v)
+	}
+	values.Set("hash", a.sign(fields))
+	return values.Encode(), nil
+}
+
+func (a *LoginAuth) validate(fields map[string]string, authDate int64, hash string) error {
+	if hash == "" {
+		return ErrHashMissing
+	}
+	if authDate == 0 {
+		return ErrAuthDateMissing
+	}
+	if a.expIn > 0 && time.Unix(authDate, 0).Add(a.expIn).Before(time.Now()) {
+		return ErrExpired
+	}
+	expected := a.sign(fields)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(hash))) {
+		return ErrHashInvalid
+	}
+	return nil
+}
+
+func (a *LoginAuth) sign(fields map[string]string) string {
+	pairs := make([]string, 0, len(fields))
+	for k, v := range fields {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	secret := sha256.Sum256([]byte(a.token))
+	mac := hmac.New(sha256.New, secret[:])
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,41 @@
+package loginauth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLoginAuth_ParseToken(t *testing.T) {
+	ctx := context.Background()
+	auth := NewLoginAuth("test", time.Hour)
+	data := LoginData{
+		ID:        1,
+		FirstName: "first_name",
+		Username:  "username",
+		AuthDate:  time.Now().Unix(),
+	}
+	token, err := auth.GenerateToken(ctx, &data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := auth.ParseToken(ctx, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.ID != data.ID || parsed.Username != data.Username {
+		t.Errorf("unexpected login data: %+v", parsed)
+	}
+	if err = auth.Verify(ctx, &parsed); err != nil {
+		t.Errorf("verify failed: %v", err)
+	}
+	parsed.Username = "other"
+	if err = auth.Verify(ctx, &parsed); !errors.Is(err, ErrHashInvalid) {
+		t.Errorf("expected %v, got %v", ErrHashInvalid, err)
+	}
+	_, err = NewLoginAuth("other", time.Hour).ParseToken(ctx, token)
+	if !errors.Is(err, ErrHashInvalid) {
+		t.Errorf("expected %v, got %v", ErrHashInvalid, err)
+	}
+}
@@ -0,0 +1,191 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	_ telegram.MetricsRecorder = (*Registry)(nil)
+	_ prometheus.Collector     = (*Registry)(nil)
+)
+
+// DefaultBuckets are the default histogram buckets in seconds.
+var DefaultBuckets = prometheus.DefBuckets
+
+// options holds configuration for the Registry.
+type options struct {
+	namespace string                   // Prefix of every metric name
+	buckets   []float64                // Histogram buckets in seconds
+	stats     *telegram.StatsCollector // Optional statistics exported along with the bot metrics
+}
+
+// Option defines a function type for configuring the Registry.
+type Option func(*options)
+
+// WithNamespace sets the prefix of every metric name. Defaults to "telegram".
+func WithNamespace(namespace string) Option {
+	return func(o *options) {
+		o.namespace = namespace
+	}
+}
+
+// WithBuckets sets the histogram buckets, in seconds, of the latency metrics.
+func WithBuckets(buckets ...float64) Option {
+	return func(o *options) {
+		o.buckets = buckets
+	}
+}
+
+// WithStatsCollector exports the command, chat type, active user and latency statistics
+// of the collector along with the bot metrics. Update and error totals are reported by
+// the Registry itself, so pass it to telegram.WithMetrics as well.
+func WithStatsCollector(stats *telegram.StatsCollector) Option {
+	return func(o *options) {
+		o.stats = stats
+	}
+}
+
+// Registry collects bot metrics. It is a prometheus.Collector, so it can be registered
+// with any prometheus.Registerer, and serves its own metrics through Handler.
+// Pass it to telegram.WithMetrics to instrument a bot.
+type Registry struct {
+	updates         *prometheus.CounterVec
+	updateErrors    *prometheus.CounterVec
+	updateDuration  *prometheus.HistogramVec
+	apiCalls        *prometheus.CounterVec
+	apiErrors       *prometheus.CounterVec
+	apiDuration     *prometheus.HistogramVec
+	rateLimitWait   prometheus.Histogram
+	broadcastSent   *prometheus.GaugeVec
+	broadcastFailed *prometheus.GaugeVec
+	broadcastTotal  *prometheus.GaugeVec
+	collectors      []prometheus.Collector
+	handler         http.Handler
+}
+
+// NewRegistry creates a Registry with all bot metrics.
+func NewRegistry(opts ...Option) *Registry {
+	o := &options{
+		namespace: "telegram",
+		buckets:   DefaultBuckets,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	r := &Registry{
+		updates: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: o.namespace, Name: "updates_total",
+			Help: "Total number of handled updates.",
+		}, []string{"type"}),
+		updateErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: o.namespace, Name: "update_errors_total",
+			Help: "Total number of updates whose handler returned an error.",
+		}, []string{"type"}),
+		updateDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: o.namespace, Name: "update_duration_seconds",
+			Help: "Time spent handling updates.", Buckets: o.buckets,
+		}, []string{"type"}),
+		apiCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: o.namespace, Name: "api_requests_total",
+			Help: "Total number of Bot API requests.",
+		}, []string{"method"}),
+		apiErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: o.namespace, Name: "api_request_errors_total",
+			Help: "Total number of failed Bot API requests.",
+		}, []string{"method"}),
+		apiDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: o.namespace, Name: "api_request_duration_seconds",
+			Help: "Duration of Bot API requests.", Buckets: o.buckets,
+		}, []string{"method"}),
+		rateLimitWait: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: o.namespace, Name: "rate_limit_wait_seconds",
+			Help: "Time spent waiting for rate limiters.", Buckets: o.buckets,
+		}),
+		broadcastSent: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: o.namespace, Name: "broadcast_sent",
+			Help: "Number of messages sent by the broadcast.",
+		}, []string{"name"}),
+		broadcastFailed: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: o.namespace, Name: "broadcast_failed",
+			Help: "Number of messages the broadcast failed to send.",
+		}, []string{"name"}),
+		broadcastTotal: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: o.namespace, Name: "broadcast_recipients",
+			Help: "Number of recipients of the broadcast.",
+		}, []string{"name"}),
+	}
+	r.collectors = []prometheus.Collector{
+		r.updates, r.updateErrors, r.updateDuration,
+		r.apiCalls, r.apiErrors, r.apiDuration,
+		r.rateLimitWait,
+		r.broadcastSent, r.broadcastFailed, r.broadcastTotal,
+	}
+	if o.stats != nil {
+		r.collectors = append(r.collectors, newStatsCollector(o.namespace, o.stats))
+	}
+	gatherer := prometheus.NewRegistry()
+	gatherer.MustRegister(r)
+	r.handler = promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})
+	return r
+}
+
+// Describe implements prometheus.Collector.
+func (r *Registry) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range r.collectors {
+		c.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (r *Registry) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range r.collectors {
+		c.Collect(ch)
+	}
+}
+
+// ObserveUpdate implements telegram.MetricsRecorder.
+func (r *Registry) ObserveUpdate(updateType string, duration time.Duration, err error) {
+	r.updates.WithLabelValues(updateType).Inc()
+	if err != nil {
+		r.updateErrors.WithLabelValues(updateType).Inc()
+	}
+	r.updateDuration.WithLabelValues(updateType).Observe(duration.Seconds())
+}
+
+// ObserveAPICall implements telegram.MetricsRecorder.
+func (r *Registry) ObserveAPICall(method string, duration time.Duration, err error) {
+	r.apiCalls.WithLabelValues(method).Inc()
+	if err != nil {
+		r.apiErrors.WithLabelValues(method).Inc()
+	}
+	r.apiDuration.WithLabelValues(method).Observe(duration.Seconds())
+}
+
+// ObserveRateLimitWait implements telegram.MetricsRecorder.
+func (r *Registry) ObserveRateLimitWait(duration time.Duration) {
+	r.rateLimitWait.Observe(duration.Seconds())
+}
+
+// ObserveBroadcast implements telegram.MetricsRecorder.
+func (r *Registry) ObserveBroadcast(name string, sent, failed, total int) {
+	r.broadcastSent.WithLabelValues(name).Set(float64(sent))
+	r.broadcastFailed.WithLabelValues(name).Set(float64(failed))
+	r.broadcastTotal.WithLabelValues(name).Set(float64(total))
+}
+
+// ServeHTTP serves the metrics of the Registry, see Handler.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.handler.ServeHTTP(w, req)
+}
+
+// Handler returns a promhttp handler serving only the metrics of the Registry, to be mounted
+// at e.g. "/metrics". To serve them along with other metrics, register the Registry with
+// a prometheus.Registerer instead.
+func (r *Registry) Handler() http.Handler {
+	return r.handler
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry(WithBuckets(0.1, 1))
+	r.ObserveUpdate("message", 50*time.Millisecond, nil)
+	r.ObserveUpdate("message", 500*time.Millisecond, errors.New("fail"))
+	r.ObserveAPICall("sendMessage", 20*time.Millisecond, nil)
+	r.ObserveBroadcast("news", 8, 2, 10)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`telegram_updates_total{type="message"} 2`,
+		`telegram_update_errors_total{type="message"} 1`,
+		`telegram_update_duration_seconds_bucket{type="message",le="0.1"} 1`,
+		`telegram_update_duration_seconds_bucket{type="message",le="+Inf"} 2`,
+		`telegram_api_requests_total{method="sendMessage"} 1`,
+		`telegram_broadcast_sent{name="news"} 8`,
+		"# TYPE telegram_update_duration_seconds histogram",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q in:\n%s", want, body)
+		}
+	}
+	if !strings.Contains(body, `telegram_broadcast_failed{name="news"} 2`) {
+		t.Error("broadcast progress must be written")
+	}
+}
+
+func TestRegistryCollector(t *testing.T) {
+	r := NewRegistry(WithNamespace("bot"))
+	r.ObserveRateLimitWait(time.Second)
+
+	reg := prometheus.NewRegistry()
+	if err := reg.Register(r); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	for _, f := range families {
+		if f.GetName() == "bot_rate_limit_wait_seconds" {
+			if got := f.GetMetric()[0].GetHistogram().GetSampleCount(); got != 1 {
+				t.Errorf("expected 1 observation, got %d", got)
+			}
+			return
+		}
+	}
+	t.Errorf("bot_rate_limit_wait_seconds not gathered")
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// statsCollector exports a snapshot of a telegram.StatsCollector on every scrape.
+type statsCollector struct {
+	stats       *telegram.StatsCollector
+	commands    *prometheus.Desc
+	chatTypes   *prometheus.Desc
+	activeUsers *prometheus.Desc
+	latency     *prometheus.Desc
+}
+
+func newStatsCollector(namespace string, stats *telegram.StatsCollector) *statsCollector {
+	return &statsCollector{
+		stats: stats,
+		commands: prometheus.NewDesc(prometheus.BuildFQName(namespace, "stats", "commands_total"),
+			"Number of updates per command.", []string{"command"}, nil),
+		chatTypes: prometheus.NewDesc(prometheus.BuildFQName(namespace, "stats", "chat_type_updates_total"),
+			"Number of updates per chat type.", []string{"chat_type"}, nil),
+		activeUsers: prometheus.NewDesc(prometheus.BuildFQName(namespace, "stats", "active_users"),
+			"Unique users seen during the window.", []string{"window"}, nil),
+		latency: prometheus.NewDesc(prometheus.BuildFQName(namespace, "stats", "handler_latency_seconds"),
+			"Handler latency percentile over the most recent updates.", []string{"percentile"}, nil),
+	}
+}
+
+func (c *statsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.commands
+	ch <- c.chatTypes
+	ch <- c.activeUsers
+	ch <- c.latency
+}
+
+func (c *statsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.stats.Stats()
+	for command, n := range stats.Commands {
+		ch <- prometheus.MustNewConstMetric(c.commands, prometheus.CounterValue, float64(n), command)
+	}
+	for chatType, n := range stats.ChatTypes {
+		ch <- prometheus.MustNewConstMetric(c.chatTypes, prometheus.CounterValue, float64(n), chatType)
+	}
+	ch <- prometheus.MustNewConstMetric(c.activeUsers, prometheus.GaugeValue, float64(stats.DailyUsers), "1d")
+	ch <- prometheus.MustNewConstMetric(c.activeUsers, prometheus.GaugeValue, float64(stats.WeeklyUsers), "7d")
+	if stats.Latency.Count == 0 {
+		return
+	}
+	for _, p := range []struct {
+		label string
+		value time.Duration
+	}{
+		{"50", stats.Latency.P50},
+		{"90", stats.Latency.P90},
+		{"99", stats.Latency.P99},
+		{"100", stats.Latency.Max},
+	} {
+		ch <- prometheus.MustNewConstMetric(c.latency, prometheus.GaugeValue, p.value.Seconds(), p.label)
+	}
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+	"github.com/go-telegram/bot/models"
+)
+
+func TestRegistryStatsCollector(t *testing.T) {
+	stats := telegram.NewStatsCollector()
+	handler := stats.Middleware()(func(ctx context.Context, update *telegram.Update) error {
+		return nil
+	})
+	for _, userID := range []int64{1, 2} {
+		_ = handler(context.Background(), &telegram.Update{Message: &models.Message{
+			Text: "/start",
+			From: &models.User{ID: userID},
+			Chat: models.Chat{ID: userID, Type: models.ChatTypePrivate},
+		}})
+	}
+
+	r := NewRegistry(WithStatsCollector(stats))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`telegram_stats_commands_total{command="/start"} 2`,
+		`telegram_stats_chat_type_updates_total{chat_type="private"} 2`,
+		`telegram_stats_active_users{window="1d"} 2`,
+		`telegram_stats_handler_latency_seconds{percentile="50"}`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q in:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package redisstore
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+var (
+	_ telegram.Locker      = (*Store)(nil)
+	_ telegram.UpdateStore = (*Store)(nil)
+)
+
+// releaseScript deletes the lock only if it is still held by the caller.
+const releaseScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) else return 0 end`
+
+// TryLock implements telegram.Locker with SET NX PX and a token checked on release.
+func (s *Store) TryLock(ctx context.Context, key string, ttl time.Duration) (func(context.Context) error, bool, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, false, err
+	}
+	token := hex.EncodeToString(buf)
+	lockKey := s.key("lock", key)
+	ok, err := s.client.SetNX(ctx, lockKey, token, ttl)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return func(ctx context.Context) error {
+		_, err := s.client.Eval(ctx, releaseScript, []string{lockKey}, token)
+		return err
+	}, true, nil
+}
+
+// MarkProcessed implements telegram.UpdateStore, so NewIdempotencyMiddleware deduplicates
+// updates across replicas. Update IDs are remembered for the configured update TTL.
+func (s *Store) MarkProcessed(ctx context.Context, updateID int64) (bool, error) {
+	return s.client.SetNX(ctx, s.key("update", strconv.FormatInt(updateID, 10)), "1", s.updateTTL)
+}
@@ -0,0 +1,30 @@
+package redisstore
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStore_TryLock(t *testing.T) {
+	ctx := context.Background()
+	store := New(newMemoryClient())
+	release, ok, err := store.TryLock(ctx, "chat:1", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("lock is not acquired: %v", err)
+	}
+	if _, ok, _ = store.TryLock(ctx, "chat:1", time.Minute); ok {
+		t.Error("lock acquired twice")
+	}
+	if err = release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ = store.TryLock(ctx, "chat:1", time.Minute); !ok {
+		t.Error("lock is not released")
+	}
+	first, _ := store.MarkProcessed(ctx, 1)
+	second, _ := store.MarkProcessed(ctx, 1)
+	if !first || second {
+		t.Errorf("unexpected dedup result: %v %v", first, second)
+	}
+}
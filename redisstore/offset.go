@@ -0,0 +1,28 @@
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+var _ telegram.OffsetStore = (*Store)(nil)
+
+// LoadOffset implements telegram.OffsetStore.
+func (s *Store) LoadOffset(ctx context.Context, botID int64) (int64, error) {
+	raw, err := s.client.Get(ctx, s.key("offset", strconv.FormatInt(botID, 10)))
+	if errors.Is(err, ErrNil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(raw, 10, 64)
+}
+
+// SaveOffset implements telegram.OffsetStore.
+func (s *Store) SaveOffset(ctx context.Context, botID int64, offset int64) error {
+	return s.client.Set(ctx, s.key("offset", strconv.FormatInt(botID, 10)), strconv.FormatInt(offset, 10))
+}
@@ -0,0 +1,23 @@
+package redisstore
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStore_Offset(t *testing.T) {
+	ctx := context.Background()
+	store := New(newMemoryClient())
+	if offset, err := store.LoadOffset(ctx, 1); offset != 0 || err != nil {
+		t.Fatalf("expected no offset, got %d, %v", offset, err)
+	}
+	if err := store.SaveOffset(ctx, 1, 42); err != nil {
+		t.Fatal(err)
+	}
+	if offset, err := store.LoadOffset(ctx, 1); offset != 42 || err != nil {
+		t.Errorf("expected offset 42, got %d, %v", offset, err)
+	}
+	if offset, _ := store.LoadOffset(ctx, 2); offset != 0 {
+		t.Errorf("offsets must be kept per bot, got %d", offset)
+	}
+}
@@ -0,0 +1,74 @@
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNil is returned by a Client when the requested key does not exist.
+var ErrNil = errors.New("redis: nil")
+
+// Client is the subset of Redis commands used by the store. It is intentionally small so that
+// any Redis library can be adapted with a thin wrapper.
+type Client interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key, value string) error
+	Del(ctx context.Context, keys ...string) error
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	HSet(ctx context.Context, key string, values map[string]string) error
+	HSetNX(ctx context.Context, key, field, value string) error
+	SAdd(ctx context.Context, key string, members ...string) error
+	SMembers(ctx context.Context, key string) ([]string, error)
+	// SetNX sets the key with an expiry if it does not exist and reports whether it was set.
+	SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error)
+	Eval(ctx context.Context, script string, keys []string, args ...any) (any, error)
+}
+
+// Store implements the telegram storage interfaces on top of Redis.
+type Store struct {
+	client    Client
+	prefix    string
+	updateTTL time.Duration
+}
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithKeyPrefix sets the prefix of every key written by the store. Defaults to "telegram:".
+func WithKeyPrefix(prefix string) Option {
+	return func(s *Store) {
+		s.prefix = prefix
+	}
+}
+
+// WithUpdateTTL sets how long processed update IDs are remembered. Defaults to 24 hours.
+func WithUpdateTTL(ttl time.Duration) Option {
+	return func(s *Store) {
+		s.updateTTL = ttl
+	}
+}
+
+// New creates a Store using the given client.
+func New(client Client, opts ...Option) *Store {
+	s := &Store{
+		client:    client,
+		prefix:    "telegram:",
+		updateTTL: 24 * time.Hour,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *Store) key(parts ...string) string {
+	key := s.prefix
+	for i, part := range parts {
+		if i > 0 {
+			key += ":"
+		}
+		key += part
+	}
+	return key
+}
@@ -0,0 +1,128 @@
+package redisstore
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryClient is an in-memory Client used by tests.
+type memoryClient struct {
+	mu      sync.Mutex
+	strings map[string]string
+	hashes  map[string]map[string]string
+	sets    map[string]map[string]struct{}
+}
+
+func newMemoryClient() *memoryClient {
+	return &memoryClient{
+		strings: map[string]string{},
+		hashes:  map[string]map[string]string{},
+		sets:    map[string]map[string]struct{}{},
+	}
+}
+
+func (c *memoryClient) Get(ctx context.Context, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.strings[key]
+	if !ok {
+		return "", ErrNil
+	}
+	return v, nil
+}
+
+func (c *memoryClient) Set(ctx context.Context, key, value string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.strings[key] = value
+	return nil
+}
+
+func (c *memoryClient) Del(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		delete(c.strings, key)
+		delete(c.hashes, key)
+		delete(c.sets, key)
+	}
+	return nil
+}
+
+func (c *memoryClient) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	values := map[string]string{}
+	for k, v := range c.hashes[key] {
+		values[k] = v
+	}
+	return values, nil
+}
+
+func (c *memoryClient) HSet(ctx context.Context, key string, values map[string]string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.hashes[key] == nil {
+		c.hashes[key] = map[string]string{}
+	}
+	for k, v := range values {
+		c.hashes[key][k] = v
+	}
+	return nil
+}
+
+func (c *memoryClient) HSetNX(ctx context.Context, key, field, value string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.hashes[key] == nil {
+		c.hashes[key] = map[string]string{}
+	}
+	if _, ok := c.hashes[key][field]; !ok {
+		c.hashes[key][field] = value
+	}
+	return nil
+}
+
+func (c *memoryClient) SAdd(ctx context.Context, key string, members ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sets[key] == nil {
+		c.sets[key] = map[string]struct{}{}
+	}
+	for _, m := range members {
+		c.sets[key][m] = struct{}{}
+	}
+	return nil
+}
+
+func (c *memoryClient) SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.strings[key]; ok {
+		return false, nil
+	}
+	c.strings[key] = value
+	return true, nil
+}
+
+// Eval only supports the lock release script.
+func (c *memoryClient) Eval(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.strings[keys[0]] == args[0] {
+		delete(c.strings, keys[0])
+		return int64(1), nil
+	}
+	return int64(0), nil
+}
+
+func (c *memoryClient) SMembers(ctx context.Context, key string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	members := make([]string, 0, len(c.sets[key]))
+	for m := range c.sets[key] {
+		members = append(members, m)
+	}
+	return members, nil
+}
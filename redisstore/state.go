@@ -0,0 +1,46 @@
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+var _ telegram.StateStore = (*Store)(nil)
+
+func (s *Store) stateKey(key telegram.StateKey) string {
+	return s.key("state", strconv.FormatInt(key.ChatID, 10), strconv.FormatInt(key.UserID, 10))
+}
+
+// GetState implements telegram.StateStore.
+func (s *Store) GetState(ctx context.Context, key telegram.StateKey) (*telegram.ConversationState, error) {
+	raw, err := s.client.Get(ctx, s.stateKey(key))
+	if errors.Is(err, ErrNil) {
+		return nil, telegram.ErrStateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state telegram.ConversationState
+	if err = json.Unmarshal([]byte(raw), &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// SetState implements telegram.StateStore.
+func (s *Store) SetState(ctx context.Context, state *telegram.ConversationState) error {
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.stateKey(state.Key), string(raw))
+}
+
+// DeleteState implements telegram.StateStore.
+func (s *Store) DeleteState(ctx context.Context, key telegram.StateKey) error {
+	return s.client.Del(ctx, s.stateKey(key))
+}
@@ -0,0 +1,37 @@
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+func TestStore_State(t *testing.T) {
+	ctx := context.Background()
+	store := New(newMemoryClient())
+	key := telegram.StateKey{ChatID: 1, UserID: 2}
+	if _, err := store.GetState(ctx, key); !errors.Is(err, telegram.ErrStateNotFound) {
+		t.Fatalf("expected ErrStateNotFound, got %v", err)
+	}
+	type form struct {
+		Name string `json:"name"`
+	}
+	if err := telegram.SaveState(ctx, store, key, "age", form{Name: "bob"}); err != nil {
+		t.Fatal(err)
+	}
+	step, data, err := telegram.LoadState[form](ctx, store, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if step != "age" || data.Name != "bob" {
+		t.Errorf("unexpected state: %s %+v", step, data)
+	}
+	if err = store.DeleteState(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = store.GetState(ctx, key); !errors.Is(err, telegram.ErrStateNotFound) {
+		t.Errorf("expected ErrStateNotFound after delete, got %v", err)
+	}
+}
@@ -0,0 +1,17 @@
+package redisstore
+
+import (
+	"context"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+var _ telegram.UserDataStore = (*Store)(nil)
+
+// DeleteUserData implements telegram.UserDataStore, deleting the user record and the state of
+// the private chat with the user. Client has no key scan, so states of the user in group chats
+// are left to be deleted with DeleteState. The bare user ID stays in the user index, ListUsers
+// skips it once the record is gone.
+func (s *Store) DeleteUserData(ctx context.Context, userID int64) error {
+	return s.client.Del(ctx, s.userKey(userID), s.stateKey(telegram.StateKey{ChatID: userID, UserID: userID}))
+}
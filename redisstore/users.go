@@ -0,0 +1,106 @@
+package redisstore
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+var _ telegram.UserStore = (*Store)(nil)
+
+func (s *Store) userKey(id int64) string {
+	return s.key("user", strconv.FormatInt(id, 10))
+}
+
+// UpsertUser implements telegram.UserStore.
+func (s *Store) UpsertUser(ctx context.Context, user *telegram.UserRecord) error {
+	key := s.userKey(user.ID)
+	err := s.client.HSetNX(ctx, key, "first_seen", strconv.FormatInt(user.FirstSeen.Unix(), 10))
+	if err != nil {
+		return err
+	}
+	err = s.client.HSet(ctx, key, map[string]string{
+		"id":            strconv.FormatInt(user.ID, 10),
+		"username":      user.Username,
+		"first_name":    user.FirstName,
+		"last_name":     user.LastName,
+		"language_code": user.LanguageCode,
+		"last_seen":     strconv.FormatInt(user.LastSeen.Unix(), 10),
+		"blocked":       strconv.FormatBool(user.Blocked),
+	})
+	if err != nil {
+		return err
+	}
+	return s.client.SAdd(ctx, s.key("users"), strconv.FormatInt(user.ID, 10))
+}
+
+// GetUser implements telegram.UserStore.
+func (s *Store) GetUser(ctx context.Context, id int64) (*telegram.UserRecord, error) {
+	values, err := s.client.HGetAll(ctx, s.userKey(id))
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, telegram.ErrUserNotFound
+	}
+	return parseUser(values), nil
+}
+
+// SetBlocked implements telegram.UserStore.
+func (s *Store) SetBlocked(ctx context.Context, id int64, blocked bool) error {
+	key := s.userKey(id)
+	values, err := s.client.HGetAll(ctx, key)
+	if err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return telegram.ErrUserNotFound
+	}
+	return s.client.HSet(ctx, key, map[string]string{"blocked": strconv.FormatBool(blocked)})
+}
+
+// ListUsers implements telegram.UserStore.
+func (s *Store) ListUsers(ctx context.Context, filter telegram.UserFilter) ([]*telegram.UserRecord, error) {
+	members, err := s.client.SMembers(ctx, s.key("users"))
+	if err != nil {
+		return nil, err
+	}
+	users := make([]*telegram.UserRecord, 0, len(members))
+	for _, member := range members {
+		id, e := strconv.ParseInt(member, 10, 64)
+		if e != nil {
+			continue
+		}
+		user, e := s.GetUser(ctx, id)
+		if e != nil {
+			continue
+		}
+		if filter.Match(user) {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID < users[j].ID
+	})
+	return users, nil
+}
+
+func parseUser(values map[string]string) *telegram.UserRecord {
+	id, _ := strconv.ParseInt(values["id"], 10, 64)
+	firstSeen, _ := strconv.ParseInt(values["first_seen"], 10, 64)
+	lastSeen, _ := strconv.ParseInt(values["last_seen"], 10, 64)
+	blocked, _ := strconv.ParseBool(values["blocked"])
+	return &telegram.UserRecord{
+		ID:           id,
+		Username:     values["username"],
+		FirstName:    values["first_name"],
+		LastName:     values["last_name"],
+		LanguageCode: values["language_code"],
+		FirstSeen:    time.Unix(firstSeen, 0),
+		LastSeen:     time.Unix(lastSeen, 0),
+		Blocked:      blocked,
+	}
+}
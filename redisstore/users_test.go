@@ -0,0 +1,40 @@
+package redisstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+func TestStore_Users(t *testing.T) {
+	ctx := context.Background()
+	store := New(newMemoryClient())
+	first := time.Unix(1000, 0)
+	if err := store.UpsertUser(ctx, &telegram.UserRecord{ID: 1, Username: "a", FirstSeen: first, LastSeen: first}); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Unix(2000, 0)
+	if err := store.UpsertUser(ctx, &telegram.UserRecord{ID: 1, Username: "b", FirstSeen: later, LastSeen: later}); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.UpsertUser(ctx, &telegram.UserRecord{ID: 2, FirstSeen: later, LastSeen: later})
+	user, err := store.GetUser(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != "b" || !user.FirstSeen.Equal(first) || !user.LastSeen.Equal(later) {
+		t.Errorf("unexpected user: %+v", user)
+	}
+	if err = store.SetBlocked(ctx, 2, true); err != nil {
+		t.Fatal(err)
+	}
+	ids, err := telegram.RecipientIDs(ctx, store, telegram.UserFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("unexpected recipients: %v", ids)
+	}
+}
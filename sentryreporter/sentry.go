@@ -0,0 +1,329 @@
+package sentryreporter
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+var _ telegram.ErrorReporter = (*Reporter)(nil)
+
+// Reporter forwards telegram error reports to Sentry using the envelope endpoint.
+// It only depends on net/http, so no Sentry SDK is required.
+//
+// Events are delivered in the background from a bounded queue, so reporting never blocks
+// the error path; events reported while the queue is full are dropped. Callers must call
+// Close on shutdown, which delivers the queued events and stops the background worker.
+type Reporter struct {
+	endpoint    string
+	auth        string
+	client      *http.Client
+	environment string
+	release     string
+	serverName  string
+	queueSize   int
+	queue       chan queued
+	stop        chan struct{}
+	closeOnce   sync.Once
+}
+
+// queued is an event waiting for delivery, or a Flush marker when flushed is set.
+type queued struct {
+	ctx     context.Context
+	event   *event
+	flushed chan struct{}
+}
+
+// Option configures a Reporter.
+type Option func(*Reporter)
+
+// WithHTTPClient sets the HTTP client used to deliver events.
+func WithHTTPClient(client *http.Client) Option {
+	return func(r *Reporter) {
+		r.client = client
+	}
+}
+
+// WithQueueSize sets how many events may wait for delivery. Defaults to 100.
+func WithQueueSize(size int) Option {
+	return func(r *Reporter) {
+		if size > 0 {
+			r.queueSize = size
+		}
+	}
+}
+
+// WithEnvironment sets the environment attached to every event.
+func WithEnvironment(environment string) Option {
+	return func(r *Reporter) {
+		r.environment = environment
+	}
+}
+
+// WithRelease sets the release attached to every event.
+func WithRelease(release string) Option {
+	return func(r *Reporter) {
+		r.release = release
+	}
+}
+
+// WithServerName sets the server name attached to every event.
+func WithServerName(serverName string) Option {
+	return func(r *Reporter) {
+		r.serverName = serverName
+	}
+}
+
+// New creates a Reporter from a Sentry DSN such as "https://<key>@o0.ingest.sentry.io/<project>".
+func New(dsn string, opts ...Option) (*Reporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, err
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, errors.New("sentry dsn has no public key")
+	}
+	path := strings.Trim(u.Path, "/")
+	idx := strings.LastIndex(path, "/")
+	projectID := path[idx+1:]
+	if projectID == "" {
+		return nil, errors.New("sentry dsn has no project id")
+	}
+	prefix := ""
+	if idx >= 0 {
+		prefix = "/" + path[:idx]
+	}
+	r := &Reporter{
+		endpoint:  fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, prefix, projectID),
+		auth:      "Sentry sentry_version=7, sentry_client=go-sphere-telegram-bot/1.0, sentry_key=" + u.User.Username(),
+		client:    &http.Client{Timeout: 5 * time.Second},
+		queueSize: 100,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.queue = make(chan queued, r.queueSize)
+	r.stop = make(chan struct{})
+	go r.run()
+	return r, nil
+}
+
+type exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Stacktrace *stacktrace `json:"stacktrace,omitempty"`
+}
+
+type stacktrace struct {
+	Frames []frame `json:"frames"`
+}
+
+type frame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	AbsPath  string `json:"abs_path,omitempty"`
+	Lineno   int    `json:"lineno,omitempty"`
+}
+
+type event struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Platform    string            `json:"platform"`
+	Level       string            `json:"level"`
+	Logger      string            `json:"logger"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Exception   map[string]any    `json:"exception"`
+	Tags        map[string]string `json:"tags"`
+	Extra       map[string]any    `json:"extra"`
+}
+
+// Report implements telegram.ErrorReporter by queueing the report as a Sentry event.
+// Delivery failures are logged and never propagated to the bot.
+func (r *Reporter) Report(ctx context.Context, report *telegram.ErrorReport) {
+	if report == nil || report.Err == nil {
+		return
+	}
+	select {
+	case <-r.stop:
+		return
+	default:
+	}
+	select {
+	case r.queue <- queued{ctx: context.WithoutCancel(ctx), event: r.newEvent(report)}:
+	default:
+		slog.Warn("sentry queue is full, event dropped", slog.String("error", report.Err.Error()))
+	}
+}
+
+// Flush waits until the events reported so far are delivered, or the context is done.
+func (r *Reporter) Flush(ctx context.Context) error {
+	flushed := make(chan struct{})
+	select {
+	case r.queue <- queued{flushed: flushed}:
+	case <-r.stop:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-flushed:
+		return nil
+	case <-r.stop:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close delivers the queued events like Flush and stops the background worker, also when
+// the context is done first. Events reported afterwards are discarded.
+func (r *Reporter) Close(ctx context.Context) error {
+	err := r.Flush(ctx)
+	r.closeOnce.Do(func() {
+		close(r.stop)
+	})
+	return err
+}
+
+func (r *Reporter) run() {
+	for {
+		select {
+		case <-r.stop:
+			return
+		case item := <-r.queue:
+			if item.flushed != nil {
+				close(item.flushed)
+				continue
+			}
+			if err := r.send(item.ctx, item.event); err != nil {
+				slog.Error("send sentry event error", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+func (r *Reporter) newEvent(report *telegram.ErrorReport) *event {
+	level := "error"
+	if report.IsPanic() {
+		level = "fatal"
+	}
+	ev := &event{
+		EventID:     newEventID(),
+		Timestamp:   report.Time.UTC().Format(time.RFC3339),
+		Platform:    "go",
+		Level:       level,
+		Logger:      "telegram-bot",
+		Environment: r.environment,
+		Release:     r.release,
+		ServerName:  r.serverName,
+		Exception: map[string]any{
+			"values": []exception{{
+				Type:       fmt.Sprintf("%T", report.Err),
+				Value:      report.Err.Error(),
+				Stacktrace: parseStack(report.Stack),
+			}},
+		},
+		Tags: map[string]string{
+			"update_type": report.UpdateType,
+			"panic":       strconv.FormatBool(report.IsPanic()),
+		},
+		Extra: map[string]any{
+			"update_id": report.UpdateID,
+			"chat_id":   report.ChatID,
+			"user_id":   report.UserID,
+		},
+	}
+	return ev
+}
+
+// parseStack converts a stack trace formatted by runtime/debug.Stack to Sentry frames,
+// ordered from the outermost call to the panicking one as Sentry expects.
+func parseStack(stack []byte) *stacktrace {
+	lines := strings.Split(strings.TrimSpace(string(stack)), "\n")
+	var frames []frame
+	for i := 1; i+1 < len(lines); i += 2 {
+		function := strings.TrimPrefix(lines[i], "created by ")
+		function, _, _ = strings.Cut(function, " in goroutine ")
+		if idx := strings.LastIndex(function, "("); idx > 0 && strings.HasSuffix(function, ")") {
+			function = function[:idx]
+		}
+		location, _, _ := strings.Cut(strings.TrimSpace(lines[i+1]), " ")
+		path, lineno := location, 0
+		if idx := strings.LastIndex(location, ":"); idx > 0 {
+			path = location[:idx]
+			lineno, _ = strconv.Atoi(location[idx+1:])
+		}
+		module, name := splitFunction(function)
+		frames = append(frames, frame{Function: name, Module: module, AbsPath: path, Lineno: lineno})
+	}
+	if len(frames) == 0 {
+		return nil
+	}
+	slices.Reverse(frames)
+	return &stacktrace{Frames: frames}
+}
+
+// splitFunction splits a qualified function name like "github.com/a/b.(*T).M" into the
+// package path and the function name.
+func splitFunction(function string) (string, string) {
+	slash := strings.LastIndex(function, "/")
+	dot := strings.Index(function[slash+1:], ".")
+	if dot < 0 {
+		return "", function
+	}
+	dot += slash + 1
+	return function[:dot], function[dot+1:]
+}
+
+func (r *Reporter) send(ctx context.Context, ev *event) error {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	var body bytes.Buffer
+	header, _ := json.Marshal(map[string]string{"event_id": ev.EventID, "sent_at": time.Now().UTC().Format(time.RFC3339)})
+	item, _ := json.Marshal(map[string]any{"type": "event", "length": len(payload)})
+	body.Write(header)
+	body.WriteByte('\n')
+	body.Write(item)
+	body.WriteByte('\n')
+	body.Write(payload)
+	body.WriteByte('\n')
+	req, err := http.NewRequestWithContext(context.WithoutCancel(ctx), http.MethodPost, r.endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", r.auth)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("sentry responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func newEventID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,149 @@
+package sentryreporter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+func TestReporter(t *testing.T) {
+	var got event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/42/envelope/" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if !strings.Contains(r.Header.Get("X-Sentry-Auth"), "sentry_key=public") {
+			t.Errorf("unexpected auth header: %s", r.Header.Get("X-Sentry-Auth"))
+		}
+		scanner := bufio.NewScanner(r.Body)
+		for i := 0; scanner.Scan(); i++ {
+			if i == 2 {
+				_ = json.Unmarshal(scanner.Bytes(), &got)
+			}
+		}
+	}))
+	defer server.Close()
+
+	reporter, err := New(strings.Replace(server.URL, "http://", "http://public@", 1)+"/42", WithEnvironment("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = reporter.Close(context.Background()) }()
+	reporter.Report(context.Background(), telegram.NewErrorReport(&telegram.Update{ID: 1}, errors.New("failed")))
+	if err = reporter.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got.Environment != "test" || got.Level != "error" || got.Extra["update_id"] != float64(1) {
+		t.Errorf("unexpected event: %+v", got)
+	}
+}
+
+func TestReporterQueue(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+	reporter, err := New(strings.Replace(server.URL, "http://", "http://public@", 1)+"/42", WithQueueSize(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// the first event blocks the delivery, the third one overflows the queue
+		for range 3 {
+			reporter.Report(context.Background(), telegram.NewErrorReport(nil, errors.New("failed")))
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Report blocked on a slow server")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err = reporter.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the flush to time out, got %v", err)
+	}
+	// the worker stops even when the queue could not be drained in time
+	if err = reporter.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the close to time out, got %v", err)
+	}
+}
+
+func TestReporterClose(t *testing.T) {
+	var events atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		events.Add(1)
+	}))
+	defer server.Close()
+	reporter, err := New(strings.Replace(server.URL, "http://", "http://public@", 1) + "/42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		reporter.Report(context.Background(), telegram.NewErrorReport(nil, errors.New("failed")))
+	}
+	if err = reporter.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := events.Load(); n != 3 {
+		t.Errorf("expected the queued events to be delivered on close, got %d", n)
+	}
+
+	// a closed reporter discards events and returns right away
+	reporter.Report(context.Background(), telegram.NewErrorReport(nil, errors.New("late")))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err = reporter.Flush(ctx); err != nil {
+		t.Errorf("flush after close: %v", err)
+	}
+	if err = reporter.Close(ctx); err != nil {
+		t.Errorf("second close: %v", err)
+	}
+	if n := events.Load(); n != 3 {
+		t.Errorf("event reported after close was delivered, got %d events", n)
+	}
+}
+
+func TestParseStack(t *testing.T) {
+	stack := []byte(`goroutine 7 [running]:
+runtime/debug.Stack()
+	/usr/local/go/src/runtime/debug/stack.go:26 +0x5e
+github.com/go-sphere/telegram-bot/telegram.(*Bot).recover(0xc000010000)
+	/src/telegram/bot.go:120 +0x4a
+main.handler({0x1, 0x2})
+	/src/main.go:15 +0x1d
+created by net/http.(*Server).Serve in goroutine 1
+	/usr/local/go/src/net/http/server.go:3285 +0x4b4
+`)
+	trace := parseStack(stack)
+	if trace == nil || len(trace.Frames) != 4 {
+		t.Fatalf("unexpected stacktrace: %+v", trace)
+	}
+	want := []frame{
+		{Function: "(*Server).Serve", Module: "net/http", AbsPath: "/usr/local/go/src/net/http/server.go", Lineno: 3285},
+		{Function: "handler", Module: "main", AbsPath: "/src/main.go", Lineno: 15},
+		{Function: "(*Bot).recover", Module: "github.com/go-sphere/telegram-bot/telegram", AbsPath: "/src/telegram/bot.go", Lineno: 120},
+		{Function: "Stack", Module: "runtime/debug", AbsPath: "/usr/local/go/src/runtime/debug/stack.go", Lineno: 26},
+	}
+	for i := range want {
+		if trace.Frames[i] != want[i] {
+			t.Errorf("frame %d: got %+v, want %+v", i, trace.Frames[i], want[i])
+		}
+	}
+	if parseStack(nil) != nil {
+		t.Error("an empty stack must not produce a stacktrace")
+	}
+}
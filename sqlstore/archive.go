@@ -0,0 +1,78 @@
+package sqlstore
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+var _ telegram.ArchiveStore = (*Store)(nil)
+
+// AppendArchive implements telegram.ArchiveStore.
+func (s *Store) AppendArchive(ctx context.Context, record *telegram.ArchiveRecord) error {
+	_, err := s.exec(ctx, `INSERT INTO {prefix}archive (chat_id, message_id, user_id, direction, created_at, content)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		record.ChatID, record.MessageID, record.UserID, string(record.Direction), record.Time.UnixMilli(), record.Content,
+	)
+	return err
+}
+
+// QueryArchive implements telegram.ArchiveStore.
+func (s *Store) QueryArchive(ctx context.Context, query telegram.ArchiveQuery) ([]*telegram.ArchiveRecord, error) {
+	var (
+		where []string
+		args  []any
+	)
+	if query.ChatID != 0 {
+		where, args = append(where, "chat_id = ?"), append(args, query.ChatID)
+	}
+	if query.UserID != 0 {
+		where, args = append(where, "user_id = ?"), append(args, query.UserID)
+	}
+	if !query.Since.IsZero() {
+		where, args = append(where, "created_at >= ?"), append(args, query.Since.UnixMilli())
+	}
+	if !query.Until.IsZero() {
+		where, args = append(where, "created_at < ?"), append(args, query.Until.UnixMilli())
+	}
+	stmt := `SELECT chat_id, message_id, user_id, direction, created_at, content FROM {prefix}archive`
+	if len(where) > 0 {
+		stmt += " WHERE " + strings.Join(where, " AND ")
+	}
+	stmt += " ORDER BY created_at, message_id"
+	if query.Limit > 0 {
+		stmt, args = stmt+" LIMIT ?", append(args, query.Limit)
+	}
+	rows, err := s.query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var records []*telegram.ArchiveRecord
+	for rows.Next() {
+		var (
+			record    telegram.ArchiveRecord
+			direction string
+			createdAt int64
+		)
+		if err = rows.Scan(&record.ChatID, &record.MessageID, &record.UserID, &direction, &createdAt, &record.Content); err != nil {
+			return nil, err
+		}
+		record.Direction = telegram.ArchiveDirection(direction)
+		record.Time = time.UnixMilli(createdAt)
+		records = append(records, &record)
+	}
+	return records, rows.Err()
+}
+
+// PruneArchive implements telegram.ArchiveStore.
+func (s *Store) PruneArchive(ctx context.Context, before time.Time) (int, error) {
+	res, err := s.exec(ctx, `DELETE FROM {prefix}archive WHERE created_at < ?`, before.UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
@@ -0,0 +1,60 @@
+package sqlstore
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+func TestArchive(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.UnixMilli(time.Now().UnixMilli())
+	records := []*telegram.ArchiveRecord{
+		{ChatID: 7, MessageID: 1, UserID: 7, Direction: telegram.ArchiveIncoming, Time: now.Add(-2 * time.Hour), Content: "old"},
+		{ChatID: 7, MessageID: 2, UserID: 99, Direction: telegram.ArchiveOutgoing, Time: now.Add(-time.Minute), Content: "reply"},
+		{ChatID: 7, MessageID: 3, UserID: 7, Direction: telegram.ArchiveIncoming, Time: now, Content: "new"},
+		{ChatID: 8, MessageID: 1, UserID: 8, Direction: telegram.ArchiveIncoming, Time: now, Content: "other"},
+	}
+	for _, record := range records {
+		if err := s.AppendArchive(ctx, record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query telegram.ArchiveQuery
+		want  []string
+	}{
+		{"chat", telegram.ArchiveQuery{ChatID: 7}, []string{"old", "reply", "new"}},
+		{"user", telegram.ArchiveQuery{ChatID: 7, UserID: 7}, []string{"old", "new"}},
+		{"range", telegram.ArchiveQuery{Since: now.Add(-time.Hour), Until: now}, []string{"reply"}},
+		{"limit", telegram.ArchiveQuery{ChatID: 7, Limit: 1}, []string{"old"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := s.QueryArchive(ctx, tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, record := range list {
+				got = append(got, record.Content)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if n, err := s.PruneArchive(ctx, now.Add(-time.Hour)); err != nil || n != 1 {
+		t.Errorf("pruned %d records: %v", n, err)
+	}
+	if list, _ := s.QueryArchive(ctx, telegram.ArchiveQuery{}); len(list) != 3 || list[0].Direction != telegram.ArchiveOutgoing || !list[0].Time.Equal(records[1].Time) {
+		t.Errorf("unexpected records after pruning %+v", list)
+	}
+}
@@ -0,0 +1,48 @@
+package sqlstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+var _ telegram.DeletionStore = (*Store)(nil)
+
+// AddDeletion implements telegram.DeletionStore.
+func (s *Store) AddDeletion(ctx context.Context, deletion telegram.PendingDeletion) error {
+	_, err := s.exec(ctx, `INSERT INTO {prefix}deletions (chat_id, message_id, delete_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (chat_id, message_id) DO UPDATE SET delete_at = excluded.delete_at`,
+		deletion.ChatID, deletion.MessageID, deletion.DeleteAt.UnixMilli(),
+	)
+	return err
+}
+
+// RemoveDeletion implements telegram.DeletionStore.
+func (s *Store) RemoveDeletion(ctx context.Context, chatID int64, messageID int) error {
+	_, err := s.exec(ctx, `DELETE FROM {prefix}deletions WHERE chat_id = ? AND message_id = ?`, chatID, messageID)
+	return err
+}
+
+// PendingDeletions implements telegram.DeletionStore.
+func (s *Store) PendingDeletions(ctx context.Context) ([]telegram.PendingDeletion, error) {
+	rows, err := s.query(ctx, `SELECT chat_id, message_id, delete_at FROM {prefix}deletions ORDER BY delete_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var pending []telegram.PendingDeletion
+	for rows.Next() {
+		var (
+			deletion telegram.PendingDeletion
+			deleteAt int64
+		)
+		if err = rows.Scan(&deletion.ChatID, &deletion.MessageID, &deleteAt); err != nil {
+			return nil, err
+		}
+		deletion.DeleteAt = time.UnixMilli(deleteAt)
+		pending = append(pending, deletion)
+	}
+	return pending, rows.Err()
+}
@@ -0,0 +1,69 @@
+package sqlstore
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+	"github.com/go-telegram/bot/models"
+)
+
+func TestDeletions(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.UnixMilli(time.Now().UnixMilli())
+	deletions := []telegram.PendingDeletion{
+		{ChatID: 1, MessageID: 10, DeleteAt: now.Add(time.Hour)},
+		{ChatID: 1, MessageID: 11, DeleteAt: now.Add(time.Minute)},
+		{ChatID: 1, MessageID: 10, DeleteAt: now.Add(time.Second)}, // replaces the first one
+		{ChatID: 2, MessageID: 10, DeleteAt: now.Add(2 * time.Hour)},
+	}
+	for _, deletion := range deletions {
+		if err := s.AddDeletion(ctx, deletion); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.RemoveDeletion(ctx, 2, 10); err != nil {
+		t.Fatal(err)
+	}
+	pending, err := s.PendingDeletions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []telegram.PendingDeletion{deletions[2], deletions[1]}
+	if len(pending) != len(want) {
+		t.Fatalf("got %+v, want %+v", pending, want)
+	}
+	for i := range want {
+		if pending[i].ChatID != want[i].ChatID || pending[i].MessageID != want[i].MessageID || !pending[i].DeleteAt.Equal(want[i].DeleteAt) {
+			t.Errorf("got %+v, want %+v", pending[i], want[i])
+		}
+	}
+}
+
+func TestDeletionsFromSend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":7,"date":1,"chat":{"id":3,"type":"private"}}}`))
+	}))
+	defer server.Close()
+	s := newTestStore(t)
+	ctx := context.Background()
+	app, err := telegram.NewApp(telegram.Config{Token: "token"}, telegram.WithAPIServer(server.URL), telegram.WithDeletionStore(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	update := &telegram.Update{Message: &models.Message{ID: 1, Chat: models.Chat{ID: 3, Type: models.ChatTypePrivate}}}
+	if err = app.SendMessage(ctx, update, &telegram.Message{Text: "code: 1234", DeleteAfter: time.Minute}); err != nil {
+		t.Fatal(err)
+	}
+	pending, err := s.PendingDeletions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].ChatID != 3 || pending[0].MessageID != 7 {
+		t.Errorf("unexpected pending deletions: %+v", pending)
+	}
+}
@@ -0,0 +1,75 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+	"github.com/go-telegram/bot/models"
+)
+
+var _ telegram.MembershipStore = (*Store)(nil)
+
+// SetMembership implements telegram.MembershipStore.
+func (s *Store) SetMembership(ctx context.Context, m *telegram.ChatMembership) error {
+	_, err := s.exec(ctx, `INSERT INTO {prefix}memberships (chat_id, chat_type, title, status, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (chat_id) DO UPDATE SET
+			chat_type = excluded.chat_type,
+			title = excluded.title,
+			status = excluded.status,
+			updated_at = excluded.updated_at`,
+		m.ChatID, string(m.ChatType), m.Title, string(m.Status), m.UpdatedAt.Unix(),
+	)
+	return err
+}
+
+// GetMembership implements telegram.MembershipStore.
+func (s *Store) GetMembership(ctx context.Context, chatID int64) (*telegram.ChatMembership, error) {
+	row := s.queryRow(ctx, `SELECT chat_id, chat_type, title, status, updated_at
+		FROM {prefix}memberships WHERE chat_id = ?`, chatID)
+	m, err := scanMembership(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, telegram.ErrMembershipNotFound
+	}
+	return m, err
+}
+
+// ListMemberships implements telegram.MembershipStore. The filter is applied after loading
+// the rows, the table holds one row per chat the bot was ever added to.
+func (s *Store) ListMemberships(ctx context.Context, filter telegram.MembershipFilter) ([]*telegram.ChatMembership, error) {
+	rows, err := s.query(ctx, `SELECT chat_id, chat_type, title, status, updated_at
+		FROM {prefix}memberships ORDER BY chat_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var memberships []*telegram.ChatMembership
+	for rows.Next() {
+		m, err := scanMembership(rows)
+		if err != nil {
+			return nil, err
+		}
+		if filter.Match(m) {
+			memberships = append(memberships, m)
+		}
+	}
+	return memberships, rows.Err()
+}
+
+func scanMembership(row scanner) (*telegram.ChatMembership, error) {
+	var (
+		m                telegram.ChatMembership
+		chatType, status string
+		updatedAt        int64
+	)
+	if err := row.Scan(&m.ChatID, &chatType, &m.Title, &status, &updatedAt); err != nil {
+		return nil, err
+	}
+	m.ChatType = models.ChatType(chatType)
+	m.Status = models.ChatMemberType(status)
+	m.UpdatedAt = time.Unix(updatedAt, 0)
+	return &m, nil
+}
@@ -0,0 +1,61 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+	"github.com/go-telegram/bot/models"
+)
+
+func TestMemberships(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now()
+	memberships := []*telegram.ChatMembership{
+		{ChatID: -1, ChatType: models.ChatTypeGroup, Title: "group", Status: models.ChatMemberTypeMember, UpdatedAt: now},
+		{ChatID: -2, ChatType: models.ChatTypeChannel, Title: "channel", Status: models.ChatMemberTypeAdministrator, UpdatedAt: now},
+		{ChatID: -3, ChatType: models.ChatTypeSupergroup, Title: "left", Status: models.ChatMemberTypeLeft, UpdatedAt: now},
+		{ChatID: -1, ChatType: models.ChatTypeSupergroup, Title: "migrated", Status: models.ChatMemberTypeMember, UpdatedAt: now},
+	}
+	for _, m := range memberships {
+		if err := s.SetMembership(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.GetMembership(ctx, -4); !errors.Is(err, telegram.ErrMembershipNotFound) {
+		t.Errorf("unknown membership returned %v", err)
+	}
+	if m, err := s.GetMembership(ctx, -1); err != nil || m.Title != "migrated" || m.ChatType != models.ChatTypeSupergroup {
+		t.Errorf("unexpected membership %+v: %v", m, err)
+	}
+
+	tests := []struct {
+		name   string
+		filter telegram.MembershipFilter
+		want   []int64
+	}{
+		{"active", telegram.MembershipFilter{}, []int64{-2, -1}},
+		{"inactive", telegram.MembershipFilter{IncludeInactive: true}, []int64{-3, -2, -1}},
+		{"admin", telegram.MembershipFilter{AdminOnly: true}, []int64{-2}},
+		{"types", telegram.MembershipFilter{ChatTypes: []models.ChatType{models.ChatTypeSupergroup}}, []int64{-1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := s.ListMemberships(ctx, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []int64
+			for _, m := range list {
+				ids = append(ids, m.ChatID)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("got %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
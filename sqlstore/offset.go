@@ -0,0 +1,30 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+var _ telegram.OffsetStore = (*Store)(nil)
+
+// LoadOffset implements telegram.OffsetStore.
+func (s *Store) LoadOffset(ctx context.Context, botID int64) (int64, error) {
+	var offset int64
+	err := s.queryRow(ctx, `SELECT update_offset FROM {prefix}offsets WHERE bot_id = ?`, botID).Scan(&offset)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return offset, err
+}
+
+// SaveOffset implements telegram.OffsetStore.
+func (s *Store) SaveOffset(ctx context.Context, botID int64, offset int64) error {
+	_, err := s.exec(ctx, `INSERT INTO {prefix}offsets (bot_id, update_offset) VALUES (?, ?)
+		ON CONFLICT (bot_id) DO UPDATE SET update_offset = excluded.update_offset`,
+		botID, offset,
+	)
+	return err
+}
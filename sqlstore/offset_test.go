@@ -0,0 +1,25 @@
+package sqlstore
+
+import (
+	"context"
+	"testing"
+)
+
+func TestOffsets(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	if offset, err := s.LoadOffset(ctx, 1); err != nil || offset != 0 {
+		t.Fatalf("unknown bot has offset %d: %v", offset, err)
+	}
+	for _, offset := range []int64{8, 12} {
+		if err := s.SaveOffset(ctx, 1, offset); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if offset, _ := s.LoadOffset(ctx, 1); offset != 12 {
+		t.Errorf("got offset %d, want 12", offset)
+	}
+	if offset, _ := s.LoadOffset(ctx, 2); offset != 0 {
+		t.Errorf("offset of another bot %d", offset)
+	}
+}
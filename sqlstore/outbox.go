@@ -0,0 +1,103 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+var _ telegram.OutboxStore = (*Store)(nil)
+
+// Enqueue implements telegram.OutboxStore.
+func (s *Store) Enqueue(ctx context.Context, m *telegram.OutboxMessage) error {
+	return s.enqueue(ctx, s.db, m)
+}
+
+// EnqueueTx adds the message to the outbox within the caller's transaction,
+// so it is sent if and only if the transaction commits.
+func (s *Store) EnqueueTx(ctx context.Context, tx *sql.Tx, m *telegram.OutboxMessage) error {
+	return s.enqueue(ctx, tx, m)
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func (s *Store) enqueue(ctx context.Context, db execer, m *telegram.OutboxMessage) error {
+	payload, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx, s.rebind(`INSERT INTO {prefix}outbox (id, payload, attempts, retry_at, last_error, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`),
+		m.ID, string(payload), m.Attempts, m.RetryAt.UnixMilli(), m.LastError, m.CreatedAt.UnixMilli(),
+	)
+	return err
+}
+
+// Claim implements telegram.OutboxStore. The due messages are selected and claimed in a
+// single statement, on Postgres rows locked by a concurrent claim are skipped.
+func (s *Store) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*telegram.OutboxMessage, error) {
+	lock := ""
+	if s.dialect == DialectPostgres {
+		lock = " FOR UPDATE SKIP LOCKED"
+	}
+	rows, err := s.query(ctx, `UPDATE {prefix}outbox SET claimed_until = ?
+		WHERE id IN (SELECT id FROM {prefix}outbox
+			WHERE retry_at > 0 AND retry_at <= ? AND claimed_until <= ?
+			ORDER BY created_at LIMIT ?`+lock+`)
+		RETURNING payload, attempts, retry_at, last_error`,
+		now.Add(lease).UnixMilli(), now.UnixMilli(), now.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var due []*telegram.OutboxMessage
+	for rows.Next() {
+		var (
+			m        telegram.OutboxMessage
+			payload  string
+			attempts int
+			retryAt  int64
+			lastErr  string
+		)
+		if err = rows.Scan(&payload, &attempts, &retryAt, &lastErr); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal([]byte(payload), &m); err != nil {
+			return nil, err
+		}
+		m.Attempts = attempts
+		m.RetryAt = time.UnixMilli(retryAt)
+		m.LastError = lastErr
+		due = append(due, &m)
+	}
+	// RETURNING does not keep the order of the subquery
+	slices.SortStableFunc(due, func(a, b *telegram.OutboxMessage) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return due, rows.Err()
+}
+
+// MarkSent implements telegram.OutboxStore.
+func (s *Store) MarkSent(ctx context.Context, id string) error {
+	_, err := s.exec(ctx, `DELETE FROM {prefix}outbox WHERE id = ?`, id)
+	return err
+}
+
+// MarkFailed implements telegram.OutboxStore. Abandoned messages are kept with a zero retry_at
+// for inspection.
+func (s *Store) MarkFailed(ctx context.Context, id string, lastErr string, retryAt time.Time) error {
+	var next int64
+	if !retryAt.IsZero() {
+		next = retryAt.UnixMilli()
+	}
+	_, err := s.exec(ctx, `UPDATE {prefix}outbox SET attempts = attempts + 1, last_error = ?, retry_at = ?, claimed_until = 0
+		WHERE id = ?`,
+		lastErr, next, id)
+	return err
+}
@@ -0,0 +1,108 @@
+package sqlstore
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+func TestOutbox(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now()
+	first := telegram.NewOutboxMessage(7, &telegram.Message{Text: "first"})
+	first.RetryAt, first.CreatedAt = now, now.Add(-time.Minute)
+	later := telegram.NewOutboxMessage(7, &telegram.Message{Text: "later"})
+	later.RetryAt, later.CreatedAt = now.Add(time.Hour), now
+	if err := s.Enqueue(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.EnqueueTx(ctx, tx, later); err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	due, err := s.Claim(ctx, now, time.Minute, 10)
+	if err != nil || len(due) != 1 || due[0].ID != first.ID || due[0].Text != "first" {
+		t.Fatalf("unexpected due messages %+v: %v", due, err)
+	}
+	if err = s.MarkFailed(ctx, first.ID, "flood", now.Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	due, _ = s.Claim(ctx, now.Add(3*time.Hour), time.Minute, 10)
+	if len(due) != 2 || due[0].Attempts != 1 || due[0].LastError != "flood" {
+		t.Fatalf("unexpected retried messages %+v", due)
+	}
+	// abandoned messages are kept, but never due again
+	if err = s.MarkFailed(ctx, first.ID, "blocked", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.MarkSent(ctx, later.ID); err != nil {
+		t.Fatal(err)
+	}
+	if due, _ = s.Claim(ctx, now.Add(5*time.Hour), time.Minute, 10); len(due) != 0 {
+		t.Errorf("unexpected due messages %+v", due)
+	}
+}
+
+func TestOutboxClaim(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now()
+	for i := range 10 {
+		m := telegram.NewOutboxMessage(7, &telegram.Message{Text: "hi"})
+		m.RetryAt, m.CreatedAt = now, now.Add(time.Duration(i)*time.Second)
+		if err := s.Enqueue(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// concurrent senders claim every message exactly once
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		claimed = map[string]int{}
+	)
+	for range 5 {
+		wg.Go(func() {
+			due, err := s.Claim(ctx, now, time.Minute, 3)
+			if err != nil {
+				t.Error(err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for i, m := range due {
+				claimed[m.ID]++
+				if i > 0 && m.CreatedAt.Before(due[i-1].CreatedAt) {
+					t.Error("claimed messages are not ordered")
+				}
+			}
+		})
+	}
+	wg.Wait()
+	if len(claimed) != 10 {
+		t.Errorf("claimed %d of 10 messages", len(claimed))
+	}
+	for id, n := range claimed {
+		if n != 1 {
+			t.Errorf("message %s claimed %d times", id, n)
+		}
+	}
+
+	// the messages of a crashed sender are claimed again after the lease
+	if due, _ := s.Claim(ctx, now.Add(30*time.Second), time.Minute, 100); len(due) != 0 {
+		t.Errorf("claimed %d messages during the lease", len(due))
+	}
+	if due, _ := s.Claim(ctx, now.Add(2*time.Minute), time.Minute, 100); len(due) != 10 {
+		t.Errorf("claimed %d messages after the lease, want 10", len(due))
+	}
+}
@@ -0,0 +1,99 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+var _ telegram.QuotaStore = (*Store)(nil)
+
+// ConsumeQuota implements telegram.QuotaStore. The limit is checked within the upsert, so
+// concurrent consumers can not exceed it. Expired windows of the user are deleted on every call.
+func (s *Store) ConsumeQuota(ctx context.Context, key telegram.QuotaKey, n, limit int64, expiresAt time.Time) (int64, bool, error) {
+	_, err := s.exec(ctx, `DELETE FROM {prefix}quota_usage WHERE user_id = ? AND expires_at <= ?`,
+		key.UserID, time.Now().Unix())
+	if err != nil {
+		return 0, false, err
+	}
+	if n > limit {
+		used, err := s.QuotaUsage(ctx, key)
+		return used, false, err
+	}
+	var res sql.Result
+	if n > 0 {
+		res, err = s.exec(ctx, `INSERT INTO {prefix}quota_usage (user_id, route, window_name, used, expires_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (user_id, route, window_name) DO UPDATE SET
+				used = {prefix}quota_usage.used + excluded.used,
+				expires_at = excluded.expires_at
+			WHERE {prefix}quota_usage.used + excluded.used <= ?`,
+			key.UserID, key.Route, key.Window, n, expiresAt.Unix(), limit,
+		)
+	} else {
+		res, err = s.exec(ctx, `UPDATE {prefix}quota_usage
+			SET used = CASE WHEN used + ? < 0 THEN 0 ELSE used + ? END
+			WHERE user_id = ? AND route = ? AND window_name = ?`,
+			n, n, key.UserID, key.Route, key.Window,
+		)
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, false, err
+	}
+	used, err := s.QuotaUsage(ctx, key)
+	return used, affected > 0 || n <= 0, err
+}
+
+// QuotaUsage implements telegram.QuotaStore.
+func (s *Store) QuotaUsage(ctx context.Context, key telegram.QuotaKey) (int64, error) {
+	var used int64
+	err := s.queryRow(ctx, `SELECT used FROM {prefix}quota_usage
+		WHERE user_id = ? AND route = ? AND window_name = ? AND expires_at > ?`,
+		key.UserID, key.Route, key.Window, time.Now().Unix(),
+	).Scan(&used)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return used, err
+}
+
+// AddCredits implements telegram.QuotaStore.
+func (s *Store) AddCredits(ctx context.Context, userID int64, n int64) (int64, error) {
+	_, err := s.exec(ctx, `INSERT INTO {prefix}credits (user_id, balance) VALUES (?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			balance = CASE WHEN {prefix}credits.balance + ? < 0 THEN 0 ELSE {prefix}credits.balance + ? END`,
+		userID, max(n, 0), n, n,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return s.Credits(ctx, userID)
+}
+
+// UseCredits implements telegram.QuotaStore.
+func (s *Store) UseCredits(ctx context.Context, userID int64, n int64) (bool, error) {
+	res, err := s.exec(ctx, `UPDATE {prefix}credits SET balance = balance - ? WHERE user_id = ? AND balance >= ?`,
+		n, userID, n)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+// Credits implements telegram.QuotaStore.
+func (s *Store) Credits(ctx context.Context, userID int64) (int64, error) {
+	var balance int64
+	err := s.queryRow(ctx, `SELECT balance FROM {prefix}credits WHERE user_id = ?`, userID).Scan(&balance)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return balance, err
+}
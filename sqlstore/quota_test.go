@@ -0,0 +1,83 @@
+package sqlstore
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+func TestConsumeQuota(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	key := telegram.QuotaKey{UserID: 7, Route: "ask", Window: "day:1"}
+	expiresAt := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		n, limit int64
+		used     int64
+		ok       bool
+	}{
+		{n: 4, limit: 3, used: 0, ok: false}, // a single request above the limit
+		{n: 2, limit: 3, used: 2, ok: true},
+		{n: 2, limit: 3, used: 2, ok: false},
+		{n: 1, limit: 3, used: 3, ok: true},
+		{n: -2, limit: 0, used: 1, ok: true}, // give back
+		{n: -5, limit: 0, used: 0, ok: true},
+	}
+	for i, tt := range tests {
+		used, ok, err := s.ConsumeQuota(ctx, key, tt.n, tt.limit, expiresAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if used != tt.used || ok != tt.ok {
+			t.Errorf("%d: consume %d got used %d ok %t, want %d %t", i, tt.n, used, ok, tt.used, tt.ok)
+		}
+	}
+
+	// concurrent consumers never exceed the limit
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	granted := 0
+	for range 20 {
+		wg.Go(func() {
+			if _, ok, err := s.ConsumeQuota(ctx, key, 1, 5, expiresAt); err == nil && ok {
+				mu.Lock()
+				granted++
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	if used, _ := s.QuotaUsage(ctx, key); granted != 5 || used != 5 {
+		t.Errorf("granted %d with usage %d, want 5", granted, used)
+	}
+
+	// expired windows are forgotten
+	expired := telegram.QuotaKey{UserID: 7, Route: "ask", Window: "day:0"}
+	if _, ok, _ := s.ConsumeQuota(ctx, expired, 1, 1, time.Now().Add(-time.Second)); !ok {
+		t.Fatal("consume failed")
+	}
+	if used, _ := s.QuotaUsage(ctx, expired); used != 0 {
+		t.Errorf("expired usage %d", used)
+	}
+}
+
+func TestCredits(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	if balance, _ := s.AddCredits(ctx, 7, 5); balance != 5 {
+		t.Fatalf("balance %d", balance)
+	}
+	if ok, _ := s.UseCredits(ctx, 7, 6); ok {
+		t.Error("used more credits than available")
+	}
+	if ok, _ := s.UseCredits(ctx, 7, 3); !ok {
+		t.Error("credits were not used")
+	}
+	if balance, _ := s.AddCredits(ctx, 7, -10); balance != 0 {
+		t.Errorf("balance %d after revoking", balance)
+	}
+}
@@ -0,0 +1,85 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+var _ telegram.ReferralStore = (*Store)(nil)
+
+// AddReferral implements telegram.ReferralStore.
+func (s *Store) AddReferral(ctx context.Context, referral *telegram.Referral) (bool, error) {
+	res, err := s.exec(ctx, `INSERT INTO {prefix}referrals (user_id, referrer_id, created_at) VALUES (?, ?, ?)
+		ON CONFLICT (user_id) DO NOTHING`,
+		referral.UserID, referral.ReferrerID, referral.CreatedAt.Unix(),
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// GetReferral implements telegram.ReferralStore.
+func (s *Store) GetReferral(ctx context.Context, userID int64) (*telegram.Referral, error) {
+	row := s.queryRow(ctx, `SELECT referrer_id, user_id, created_at FROM {prefix}referrals WHERE user_id = ?`, userID)
+	referral, err := scanReferral(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, telegram.ErrReferralNotFound
+	}
+	return referral, err
+}
+
+// ListReferrals implements telegram.ReferralStore.
+func (s *Store) ListReferrals(ctx context.Context, referrerID int64) ([]*telegram.Referral, error) {
+	rows, err := s.query(ctx, `SELECT referrer_id, user_id, created_at FROM {prefix}referrals
+		WHERE referrer_id = ? ORDER BY created_at, user_id`, referrerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var referrals []*telegram.Referral
+	for rows.Next() {
+		referral, err := scanReferral(rows)
+		if err != nil {
+			return nil, err
+		}
+		referrals = append(referrals, referral)
+	}
+	return referrals, rows.Err()
+}
+
+// TopReferrers implements telegram.ReferralStore.
+func (s *Store) TopReferrers(ctx context.Context, limit int) ([]telegram.ReferrerCount, error) {
+	rows, err := s.query(ctx, `SELECT referrer_id, COUNT(*) AS referrals FROM {prefix}referrals
+		GROUP BY referrer_id ORDER BY referrals DESC, referrer_id LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var top []telegram.ReferrerCount
+	for rows.Next() {
+		var count telegram.ReferrerCount
+		if err = rows.Scan(&count.ReferrerID, &count.Count); err != nil {
+			return nil, err
+		}
+		top = append(top, count)
+	}
+	return top, rows.Err()
+}
+
+func scanReferral(row scanner) (*telegram.Referral, error) {
+	var (
+		referral  telegram.Referral
+		createdAt int64
+	)
+	if err := row.Scan(&referral.ReferrerID, &referral.UserID, &createdAt); err != nil {
+		return nil, err
+	}
+	referral.CreatedAt = time.Unix(createdAt, 0)
+	return &referral, nil
+}
@@ -0,0 +1,44 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+func TestReferrals(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now()
+	tests := []struct {
+		referral telegram.Referral
+		added    bool
+	}{
+		{telegram.Referral{ReferrerID: 1, UserID: 10, CreatedAt: now}, true},
+		{telegram.Referral{ReferrerID: 1, UserID: 11, CreatedAt: now.Add(time.Second)}, true},
+		{telegram.Referral{ReferrerID: 2, UserID: 12, CreatedAt: now}, true},
+		{telegram.Referral{ReferrerID: 2, UserID: 10, CreatedAt: now}, false}, // first referrer wins
+	}
+	for _, tt := range tests {
+		added, err := s.AddReferral(ctx, &tt.referral)
+		if err != nil || added != tt.added {
+			t.Errorf("add %+v got %t, want %t: %v", tt.referral, added, tt.added, err)
+		}
+	}
+	if referral, err := s.GetReferral(ctx, 10); err != nil || referral.ReferrerID != 1 {
+		t.Errorf("unexpected referral %+v: %v", referral, err)
+	}
+	if _, err := s.GetReferral(ctx, 1); !errors.Is(err, telegram.ErrReferralNotFound) {
+		t.Errorf("unknown referral returned %v", err)
+	}
+	if list, _ := s.ListReferrals(ctx, 1); len(list) != 2 || list[0].UserID != 10 || list[1].UserID != 11 {
+		t.Errorf("unexpected referrals %+v", list)
+	}
+	top, err := s.TopReferrers(ctx, 1)
+	if err != nil || len(top) != 1 || top[0] != (telegram.ReferrerCount{ReferrerID: 1, Count: 2}) {
+		t.Errorf("unexpected top referrers %+v: %v", top, err)
+	}
+}
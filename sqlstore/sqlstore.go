@@ -0,0 +1,180 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+// Dialect describes the SQL flavor of the database.
+type Dialect int
+
+const (
+	// DialectSQLite uses "?" placeholders.
+	DialectSQLite Dialect = iota
+	// DialectPostgres uses "$n" placeholders.
+	DialectPostgres
+)
+
+// Store implements the telegram storage interfaces on top of database/sql, supporting
+// SQLite and Postgres. The caller is responsible for importing the database driver
+// and for calling Migrate once to create the tables.
+type Store struct {
+	db      *sql.DB
+	dialect Dialect
+	prefix  string
+}
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithDialect sets the SQL dialect. Defaults to DialectSQLite.
+func WithDialect(dialect Dialect) Option {
+	return func(s *Store) {
+		s.dialect = dialect
+	}
+}
+
+// WithTablePrefix sets the prefix of every table created by the store. Defaults to "telegram_".
+func WithTablePrefix(prefix string) Option {
+	return func(s *Store) {
+		s.prefix = prefix
+	}
+}
+
+// New creates a Store using the given database handle.
+func New(db *sql.DB, opts ...Option) *Store {
+	s := &Store{
+		db:      db,
+		dialect: DialectSQLite,
+		prefix:  "telegram_",
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// rebind rewrites "?" placeholders and "{prefix}" table prefixes for the configured dialect.
+func (s *Store) rebind(query string) string {
+	query = strings.ReplaceAll(query, "{prefix}", s.prefix)
+	if s.dialect != DialectPostgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (s *Store) exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return s.db.ExecContext(ctx, s.rebind(query), args...)
+}
+
+func (s *Store) query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return s.db.QueryContext(ctx, s.rebind(query), args...)
+}
+
+func (s *Store) queryRow(ctx context.Context, query string, args ...any) *sql.Row {
+	return s.db.QueryRowContext(ctx, s.rebind(query), args...)
+}
+
+// Migrate creates the tables used by the store if they do not exist.
+func (s *Store) Migrate(ctx context.Context) error {
+	for _, stmt := range migrations {
+		if _, err := s.exec(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var migrations = []string{
+	`CREATE TABLE IF NOT EXISTS {prefix}users (
+		id BIGINT PRIMARY KEY,
+		username TEXT NOT NULL DEFAULT '',
+		first_name TEXT NOT NULL DEFAULT '',
+		last_name TEXT NOT NULL DEFAULT '',
+		language_code TEXT NOT NULL DEFAULT '',
+		first_seen BIGINT NOT NULL,
+		last_seen BIGINT NOT NULL,
+		blocked BOOLEAN NOT NULL DEFAULT FALSE
+	)`,
+	`CREATE TABLE IF NOT EXISTS {prefix}states (
+		chat_id BIGINT NOT NULL,
+		user_id BIGINT NOT NULL,
+		step TEXT NOT NULL DEFAULT '',
+		data TEXT NOT NULL DEFAULT '',
+		updated_at BIGINT NOT NULL,
+		PRIMARY KEY (chat_id, user_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS {prefix}outbox (
+		id TEXT PRIMARY KEY,
+		payload TEXT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		retry_at BIGINT NOT NULL,
+		last_error TEXT NOT NULL DEFAULT '',
+		created_at BIGINT NOT NULL,
+		claimed_until BIGINT NOT NULL DEFAULT 0
+	)`,
+	`CREATE INDEX IF NOT EXISTS {prefix}outbox_retry_at ON {prefix}outbox (retry_at)`,
+	`CREATE TABLE IF NOT EXISTS {prefix}deletions (
+		chat_id BIGINT NOT NULL,
+		message_id BIGINT NOT NULL,
+		delete_at BIGINT NOT NULL,
+		PRIMARY KEY (chat_id, message_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS {prefix}offsets (
+		bot_id BIGINT PRIMARY KEY,
+		update_offset BIGINT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS {prefix}memberships (
+		chat_id BIGINT PRIMARY KEY,
+		chat_type TEXT NOT NULL DEFAULT '',
+		title TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL,
+		updated_at BIGINT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS {prefix}quota_usage (
+		user_id BIGINT NOT NULL,
+		route TEXT NOT NULL,
+		window_name TEXT NOT NULL,
+		used BIGINT NOT NULL,
+		expires_at BIGINT NOT NULL,
+		PRIMARY KEY (user_id, route, window_name)
+	)`,
+	`CREATE TABLE IF NOT EXISTS {prefix}credits (
+		user_id BIGINT PRIMARY KEY,
+		balance BIGINT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS {prefix}subscriptions (
+		user_id BIGINT PRIMARY KEY,
+		plan_id TEXT NOT NULL,
+		expires_at BIGINT NOT NULL,
+		charge_id TEXT NOT NULL DEFAULT ''
+	)`,
+	`CREATE TABLE IF NOT EXISTS {prefix}referrals (
+		user_id BIGINT PRIMARY KEY,
+		referrer_id BIGINT NOT NULL,
+		created_at BIGINT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS {prefix}referrals_referrer_id ON {prefix}referrals (referrer_id)`,
+	`CREATE TABLE IF NOT EXISTS {prefix}archive (
+		chat_id BIGINT NOT NULL,
+		message_id BIGINT NOT NULL,
+		user_id BIGINT NOT NULL,
+		direction TEXT NOT NULL,
+		created_at BIGINT NOT NULL,
+		content TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS {prefix}archive_chat_id ON {prefix}archive (chat_id, created_at)`,
+	`CREATE INDEX IF NOT EXISTS {prefix}archive_created_at ON {prefix}archive (created_at)`,
+}
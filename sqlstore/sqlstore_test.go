@@ -0,0 +1,52 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+// newTestStore creates a migrated store on a fresh SQLite database.
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a single connection avoids SQLITE_BUSY between concurrent writers
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+	s := New(db)
+	if err = s.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		prefix  string
+		want    string
+	}{
+		{DialectSQLite, "telegram_", `SELECT used FROM telegram_quota WHERE user_id = ? AND route = ?`},
+		{DialectPostgres, "telegram_", `SELECT used FROM telegram_quota WHERE user_id = $1 AND route = $2`},
+		{DialectPostgres, "bot_", `SELECT used FROM bot_quota WHERE user_id = $1 AND route = $2`},
+	}
+	for _, tt := range tests {
+		s := New(nil, WithDialect(tt.dialect), WithTablePrefix(tt.prefix))
+		if got := s.rebind(`SELECT used FROM {prefix}quota WHERE user_id = ? AND route = ?`); got != tt.want {
+			t.Errorf("rebind got %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestMigrateTwice(t *testing.T) {
+	s := newTestStore(t)
+	if err := s.Migrate(context.Background()); err != nil {
+		t.Fatalf("migrations are not idempotent: %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+var _ telegram.StateStore = (*Store)(nil)
+
+// GetState implements telegram.StateStore.
+func (s *Store) GetState(ctx context.Context, key telegram.StateKey) (*telegram.ConversationState, error) {
+	var (
+		state     = telegram.ConversationState{Key: key}
+		data      string
+		updatedAt int64
+	)
+	err := s.queryRow(ctx, `SELECT step, data, updated_at FROM {prefix}states WHERE chat_id = ? AND user_id = ?`,
+		key.ChatID, key.UserID,
+	).Scan(&state.Step, &data, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, telegram.ErrStateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	state.Data = []byte(data)
+	state.UpdatedAt = time.Unix(updatedAt, 0)
+	return &state, nil
+}
+
+// SetState implements telegram.StateStore.
+func (s *Store) SetState(ctx context.Context, state *telegram.ConversationState) error {
+	_, err := s.exec(ctx, `INSERT INTO {prefix}states (chat_id, user_id, step, data, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (chat_id, user_id) DO UPDATE SET
+			step = excluded.step,
+			data = excluded.data,
+			updated_at = excluded.updated_at`,
+		state.Key.ChatID, state.Key.UserID, state.Step, string(state.Data), state.UpdatedAt.Unix(),
+	)
+	return err
+}
+
+// DeleteState implements telegram.StateStore.
+func (s *Store) DeleteState(ctx context.Context, key telegram.StateKey) error {
+	_, err := s.exec(ctx, `DELETE FROM {prefix}states WHERE chat_id = ? AND user_id = ?`, key.ChatID, key.UserID)
+	return err
+}
@@ -0,0 +1,35 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+func TestStates(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	key := telegram.StateKey{ChatID: -100, UserID: 7}
+	if _, err := s.GetState(ctx, key); !errors.Is(err, telegram.ErrStateNotFound) {
+		t.Errorf("unknown state returned %v", err)
+	}
+	for _, step := range []string{"name", "age"} {
+		state := &telegram.ConversationState{Key: key, Step: step, Data: []byte(`{"name":"A"}`), UpdatedAt: time.Now()}
+		if err := s.SetState(ctx, state); err != nil {
+			t.Fatal(err)
+		}
+	}
+	state, err := s.GetState(ctx, key)
+	if err != nil || state.Step != "age" || string(state.Data) != `{"name":"A"}` {
+		t.Fatalf("unexpected state %+v: %v", state, err)
+	}
+	if err = s.DeleteState(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.GetState(ctx, key); !errors.Is(err, telegram.ErrStateNotFound) {
+		t.Errorf("deleted state returned %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+var _ telegram.SubscriptionStore = (*Store)(nil)
+
+// SetSubscription implements telegram.SubscriptionStore.
+func (s *Store) SetSubscription(ctx context.Context, sub *telegram.Subscription) error {
+	_, err := s.exec(ctx, `INSERT INTO {prefix}subscriptions (user_id, plan_id, expires_at, charge_id)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			plan_id = excluded.plan_id,
+			expires_at = excluded.expires_at,
+			charge_id = excluded.charge_id`,
+		sub.UserID, sub.PlanID, sub.ExpiresAt.Unix(), sub.ChargeID,
+	)
+	return err
+}
+
+// GetSubscription implements telegram.SubscriptionStore.
+func (s *Store) GetSubscription(ctx context.Context, userID int64) (*telegram.Subscription, error) {
+	row := s.queryRow(ctx, `SELECT user_id, plan_id, expires_at, charge_id FROM {prefix}subscriptions WHERE user_id = ?`, userID)
+	sub, err := scanSubscription(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, telegram.ErrSubscriptionNotFound
+	}
+	return sub, err
+}
+
+// ListSubscriptions implements telegram.SubscriptionStore.
+func (s *Store) ListSubscriptions(ctx context.Context, activeAt time.Time) ([]*telegram.Subscription, error) {
+	rows, err := s.query(ctx, `SELECT user_id, plan_id, expires_at, charge_id FROM {prefix}subscriptions
+		WHERE expires_at > ? ORDER BY user_id`, activeAt.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var subs []*telegram.Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+func scanSubscription(row scanner) (*telegram.Subscription, error) {
+	var (
+		sub       telegram.Subscription
+		expiresAt int64
+	)
+	if err := row.Scan(&sub.UserID, &sub.PlanID, &expiresAt, &sub.ChargeID); err != nil {
+		return nil, err
+	}
+	sub.ExpiresAt = time.Unix(expiresAt, 0)
+	return &sub, nil
+}
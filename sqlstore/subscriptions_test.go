@@ -0,0 +1,35 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+func TestSubscriptions(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now()
+	subs := []*telegram.Subscription{
+		{UserID: 1, PlanID: "pro", ExpiresAt: now.Add(time.Hour), ChargeID: "c1"},
+		{UserID: 2, PlanID: "pro", ExpiresAt: now.Add(-time.Hour), ChargeID: "c2"},
+		{UserID: 1, PlanID: "team", ExpiresAt: now.Add(2 * time.Hour), ChargeID: "c3"}, // renewal
+	}
+	for _, sub := range subs {
+		if err := s.SetSubscription(ctx, sub); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if sub, err := s.GetSubscription(ctx, 1); err != nil || sub.PlanID != "team" || sub.ChargeID != "c3" {
+		t.Errorf("unexpected subscription %+v: %v", sub, err)
+	}
+	if _, err := s.GetSubscription(ctx, 3); !errors.Is(err, telegram.ErrSubscriptionNotFound) {
+		t.Errorf("unknown subscription returned %v", err)
+	}
+	if active, _ := s.ListSubscriptions(ctx, now); len(active) != 1 || active[0].UserID != 1 {
+		t.Errorf("unexpected active subscriptions %+v", active)
+	}
+}
@@ -0,0 +1,43 @@
+package sqlstore
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+var _ telegram.UserDataStore = (*Store)(nil)
+
+// userDataDeletes delete the data of a user from every table of the store. Tables keyed by
+// chat also drop the private chat with the user, whose ID equals the user ID.
+var userDataDeletes = []string{
+	`DELETE FROM {prefix}users WHERE id = ?`,
+	`DELETE FROM {prefix}states WHERE user_id = ? OR chat_id = ?`,
+	`DELETE FROM {prefix}deletions WHERE chat_id = ?`,
+	`DELETE FROM {prefix}quota_usage WHERE user_id = ?`,
+	`DELETE FROM {prefix}credits WHERE user_id = ?`,
+	`DELETE FROM {prefix}subscriptions WHERE user_id = ?`,
+	`DELETE FROM {prefix}referrals WHERE user_id = ? OR referrer_id = ?`,
+	`DELETE FROM {prefix}archive WHERE user_id = ? OR chat_id = ?`,
+}
+
+// DeleteUserData implements telegram.UserDataStore. The deletes run in one transaction, so
+// either all or none of the data of the user is deleted.
+func (s *Store) DeleteUserData(ctx context.Context, userID int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	for _, stmt := range userDataDeletes {
+		args := []any{userID}
+		if strings.Count(stmt, "?") == 2 {
+			args = append(args, userID)
+		}
+		if _, err = tx.ExecContext(ctx, s.rebind(stmt), args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
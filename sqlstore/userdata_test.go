@@ -0,0 +1,63 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+func TestDeleteUserData(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now()
+	for _, id := range []int64{7, 8} {
+		steps := []error{
+			s.UpsertUser(ctx, &telegram.UserRecord{ID: id, FirstSeen: now, LastSeen: now}),
+			s.SetState(ctx, &telegram.ConversationState{Key: telegram.StateKey{ChatID: id, UserID: id}, UpdatedAt: now}),
+			s.AddDeletion(ctx, telegram.PendingDeletion{ChatID: id, MessageID: 1, DeleteAt: now}),
+			s.SetSubscription(ctx, &telegram.Subscription{UserID: id, PlanID: "pro", ExpiresAt: now.Add(time.Hour)}),
+			s.AppendArchive(ctx, &telegram.ArchiveRecord{ChatID: id, MessageID: 1, UserID: id, Direction: telegram.ArchiveIncoming, Time: now}),
+		}
+		if err := errors.Join(steps...); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.AddCredits(ctx, id, 5); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.AddReferral(ctx, &telegram.Referral{ReferrerID: 8, UserID: 7, CreatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.DeleteUserData(ctx, 7); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetUser(ctx, 7); !errors.Is(err, telegram.ErrUserNotFound) {
+		t.Errorf("user was not deleted: %v", err)
+	}
+	if _, err := s.GetState(ctx, telegram.StateKey{ChatID: 7, UserID: 7}); !errors.Is(err, telegram.ErrStateNotFound) {
+		t.Errorf("state was not deleted: %v", err)
+	}
+	if _, err := s.GetSubscription(ctx, 7); !errors.Is(err, telegram.ErrSubscriptionNotFound) {
+		t.Errorf("subscription was not deleted: %v", err)
+	}
+	if _, err := s.GetReferral(ctx, 7); !errors.Is(err, telegram.ErrReferralNotFound) {
+		t.Errorf("referral was not deleted: %v", err)
+	}
+	if credits, _ := s.Credits(ctx, 7); credits != 0 {
+		t.Errorf("credits were not deleted: %d", credits)
+	}
+	if pending, _ := s.PendingDeletions(ctx); len(pending) != 1 || pending[0].ChatID != 8 {
+		t.Errorf("unexpected pending deletions %+v", pending)
+	}
+	if records, _ := s.QueryArchive(ctx, telegram.ArchiveQuery{}); len(records) != 1 || records[0].UserID != 8 {
+		t.Errorf("unexpected archive %+v", records)
+	}
+	// the data of other users is kept
+	if _, err := s.GetUser(ctx, 8); err != nil {
+		t.Errorf("other user was deleted: %v", err)
+	}
+}
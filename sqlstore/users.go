@@ -0,0 +1,104 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+var _ telegram.UserStore = (*Store)(nil)
+
+// UpsertUser implements telegram.UserStore.
+func (s *Store) UpsertUser(ctx context.Context, user *telegram.UserRecord) error {
+	_, err := s.exec(ctx, `INSERT INTO {prefix}users (id, username, first_name, last_name, language_code, first_seen, last_seen, blocked)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			username = excluded.username,
+			first_name = excluded.first_name,
+			last_name = excluded.last_name,
+			language_code = excluded.language_code,
+			last_seen = excluded.last_seen,
+			blocked = excluded.blocked`,
+		user.ID, user.Username, user.FirstName, user.LastName, user.LanguageCode,
+		user.FirstSeen.Unix(), user.LastSeen.Unix(), user.Blocked,
+	)
+	return err
+}
+
+// GetUser implements telegram.UserStore.
+func (s *Store) GetUser(ctx context.Context, id int64) (*telegram.UserRecord, error) {
+	row := s.queryRow(ctx, `SELECT id, username, first_name, last_name, language_code, first_seen, last_seen, blocked
+		FROM {prefix}users WHERE id = ?`, id)
+	user, err := scanUser(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, telegram.ErrUserNotFound
+	}
+	return user, err
+}
+
+// SetBlocked implements telegram.UserStore.
+func (s *Store) SetBlocked(ctx context.Context, id int64, blocked bool) error {
+	res, err := s.exec(ctx, `UPDATE {prefix}users SET blocked = ? WHERE id = ?`, blocked, id)
+	if err != nil {
+		return err
+	}
+	if n, e := res.RowsAffected(); e == nil && n == 0 {
+		return telegram.ErrUserNotFound
+	}
+	return nil
+}
+
+// ListUsers implements telegram.UserStore.
+func (s *Store) ListUsers(ctx context.Context, filter telegram.UserFilter) ([]*telegram.UserRecord, error) {
+	query := `SELECT id, username, first_name, last_name, language_code, first_seen, last_seen, blocked
+		FROM {prefix}users WHERE 1 = 1`
+	var args []any
+	if !filter.IncludeBlocked {
+		query += ` AND blocked = ?`
+		args = append(args, false)
+	}
+	if !filter.ActiveSince.IsZero() {
+		query += ` AND last_seen >= ?`
+		args = append(args, filter.ActiveSince.Unix())
+	}
+	if filter.LanguageCode != "" {
+		query += ` AND language_code = ?`
+		args = append(args, filter.LanguageCode)
+	}
+	query += ` ORDER BY id`
+	rows, err := s.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var users []*telegram.UserRecord
+	for rows.Next() {
+		user, e := scanUser(rows)
+		if e != nil {
+			return nil, e
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUser(row scanner) (*telegram.UserRecord, error) {
+	var (
+		user                telegram.UserRecord
+		firstSeen, lastSeen int64
+	)
+	err := row.Scan(&user.ID, &user.Username, &user.FirstName, &user.LastName, &user.LanguageCode, &firstSeen, &lastSeen, &user.Blocked)
+	if err != nil {
+		return nil, err
+	}
+	user.FirstSeen = time.Unix(firstSeen, 0)
+	user.LastSeen = time.Unix(lastSeen, 0)
+	return &user, nil
+}
@@ -0,0 +1,65 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+func TestUsers(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Unix(time.Now().Unix(), 0)
+	users := []*telegram.UserRecord{
+		{ID: 1, Username: "alice", LanguageCode: "en", FirstSeen: now.Add(-time.Hour), LastSeen: now},
+		{ID: 2, Username: "bob", LanguageCode: "de", FirstSeen: now.Add(-time.Hour), LastSeen: now.Add(-time.Hour)},
+		{ID: 3, Username: "carol", LanguageCode: "en", FirstSeen: now, LastSeen: now},
+	}
+	for _, user := range users {
+		if err := s.UpsertUser(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.SetBlocked(ctx, 3, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetBlocked(ctx, 4, true); !errors.Is(err, telegram.ErrUserNotFound) {
+		t.Errorf("blocking an unknown user returned %v", err)
+	}
+	if _, err := s.GetUser(ctx, 4); !errors.Is(err, telegram.ErrUserNotFound) {
+		t.Errorf("unknown user returned %v", err)
+	}
+	if user, err := s.GetUser(ctx, 1); err != nil || user.Username != "alice" || !user.LastSeen.Equal(now) {
+		t.Errorf("unexpected user %+v: %v", user, err)
+	}
+
+	tests := []struct {
+		name   string
+		filter telegram.UserFilter
+		want   []int64
+	}{
+		{"default", telegram.UserFilter{}, []int64{1, 2}},
+		{"blocked", telegram.UserFilter{IncludeBlocked: true}, []int64{1, 2, 3}},
+		{"active", telegram.UserFilter{ActiveSince: now.Add(-time.Minute)}, []int64{1}},
+		{"language", telegram.UserFilter{LanguageCode: "en", IncludeBlocked: true}, []int64{1, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := s.ListUsers(ctx, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []int64
+			for _, user := range list {
+				ids = append(ids, user.ID)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("got %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
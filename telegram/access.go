@@ -0,0 +1,321 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrAccessNotFound is returned by an AccessStore when the ID has no entry.
+var ErrAccessNotFound = errors.New("access entry not found")
+
+// AccessRule is the rule of an access list entry.
+type AccessRule string
+
+// Access list rules.
+const (
+	AccessAllow AccessRule = "allow"
+	AccessDeny  AccessRule = "deny"
+)
+
+// AccessEntry allows or denies a user or chat. User IDs and private chat IDs are positive,
+// group and channel chat IDs negative, so both share one ID space.
+type AccessEntry struct {
+	ID        int64      `json:"id"`
+	Rule      AccessRule `json:"rule"`
+	ExpiresAt time.Time  `json:"expires_at"` // Time the entry stops applying, zero for never
+}
+
+// Active reports whether the entry applies at the given time.
+func (e *AccessEntry) Active(now time.Time) bool {
+	return e.ExpiresAt.IsZero() || now.Before(e.ExpiresAt)
+}
+
+// AccessStore persists access list entries.
+type AccessStore interface {
+	// SetAccess inserts or replaces the entry of the ID.
+	SetAccess(ctx context.Context, entry *AccessEntry) error
+	// GetAccess returns the entry of the ID or ErrAccessNotFound.
+	GetAccess(ctx context.Context, id int64) (*AccessEntry, error)
+	// RemoveAccess deletes the entry of the ID, removing an unknown ID is not an error.
+	RemoveAccess(ctx context.Context, id int64) error
+	// ListAccess returns all entries ordered by ID.
+	ListAccess(ctx context.Context) ([]*AccessEntry, error)
+}
+
+// MemoryAccessStore is an in-memory AccessStore, suitable for tests and single instance bots.
+type MemoryAccessStore struct {
+	mu      sync.RWMutex
+	entries map[int64]AccessEntry
+}
+
+// NewMemoryAccessStore creates an empty in-memory access store.
+func NewMemoryAccessStore() *MemoryAccessStore {
+	return &MemoryAccessStore{entries: map[int64]AccessEntry{}}
+}
+
+// SetAccess implements AccessStore.
+func (s *MemoryAccessStore) SetAccess(ctx context.Context, entry *AccessEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[entry.ID] = *entry
+	return nil
+}
+
+// GetAccess implements AccessStore.
+func (s *MemoryAccessStore) GetAccess(ctx context.Context, id int64) (*AccessEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entry, ok := s.entries[id]
+	if !ok {
+		return nil, ErrAccessNotFound
+	}
+	return &entry, nil
+}
+
+// RemoveAccess implements AccessStore.
+func (s *MemoryAccessStore) RemoveAccess(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, id)
+	return nil
+}
+
+// ListAccess implements AccessStore.
+func (s *MemoryAccessStore) ListAccess(ctx context.Context) ([]*AccessEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entries := make([]*AccessEntry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		entries = append(entries, &entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ID < entries[j].ID
+	})
+	return entries, nil
+}
+
+// accessOptions holds configuration for the AccessList.
+type accessOptions struct {
+	allowlist bool // Whether only allowed users and chats pass
+}
+
+// AccessOption defines a function type for configuring the AccessList.
+type AccessOption func(*accessOptions)
+
+// WithAllowlistOnly only lets updates pass whose user or chat is allowed. By default every
+// update passes unless its user or chat is denied.
+func WithAllowlistOnly(allowlist bool) AccessOption {
+	return func(o *accessOptions) {
+		o.allowlist = allowlist
+	}
+}
+
+// AccessList decides which users and chats may use the bot, backed by an AccessStore.
+type AccessList struct {
+	store AccessStore
+	opts  accessOptions
+}
+
+// NewAccessList creates an access list backed by the store.
+func NewAccessList(store AccessStore, options ...AccessOption) *AccessList {
+	l := &AccessList{store: store}
+	for _, opt := range options {
+		opt(&l.opts)
+	}
+	return l
+}
+
+// Ban denies the user or chat, for the duration or forever if it is zero.
+func (l *AccessList) Ban(ctx context.Context, id int64, duration time.Duration) error {
+	return l.set(ctx, id, AccessDeny, duration)
+}
+
+// Allow allows the user or chat, for the duration or forever if it is zero.
+// It also lifts a ban of the ID.
+func (l *AccessList) Allow(ctx context.Context, id int64, duration time.Duration) error {
+	return l.set(ctx, id, AccessAllow, duration)
+}
+
+// Remove deletes the entry of the user or chat.
+func (l *AccessList) Remove(ctx context.Context, id int64) error {
+	return l.store.RemoveAccess(ctx, id)
+}
+
+func (l *AccessList) set(ctx context.Context, id int64, rule AccessRule, duration time.Duration) error {
+	entry := &AccessEntry{ID: id, Rule: rule}
+	if duration > 0 {
+		entry.ExpiresAt = time.Now().Add(duration)
+	}
+	return l.store.SetAccess(ctx, entry)
+}
+
+// rule returns the active rule of the ID, or an empty rule if there is none.
+func (l *AccessList) rule(ctx context.Context, id int64, now time.Time) (AccessRule, error) {
+	entry, err := l.store.GetAccess(ctx, id)
+	if errors.Is(err, ErrAccessNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if !entry.Active(now) {
+		return "", nil
+	}
+	return entry.Rule, nil
+}
+
+// Check reports whether the user and chat may use the bot; zero IDs are ignored.
+// A denied user or chat is rejected, with WithAllowlistOnly the user or chat must be allowed.
+func (l *AccessList) Check(ctx context.Context, userID, chatID int64) (bool, error) {
+	now := time.Now()
+	allowed := false
+	for _, id := range []int64{userID, chatID} {
+		if id == 0 {
+			continue
+		}
+		rule, err := l.rule(ctx, id, now)
+		if err != nil {
+			return false, err
+		}
+		switch rule {
+		case AccessDeny:
+			return false, nil
+		case AccessAllow:
+			allowed = true
+		}
+	}
+	return allowed || !l.opts.allowlist, nil
+}
+
+// Middleware returns a middleware dropping the updates of users and chats without access.
+// Dropped updates are not reported to the error handler, so banned users can not flood it.
+func (l *AccessList) Middleware() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, update *Update) error {
+			var userID, chatID int64
+			if user := UpdateUser(update); user != nil {
+				userID = user.ID
+			}
+			if ref, err := UpdateMessageRef(update); err == nil {
+				chatID = ref.ChatID
+			}
+			ok, err := l.Check(ctx, userID, chatID)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return nil
+			}
+			return next(ctx, update)
+		}
+	}
+}
+
+// NewAccessListMiddleware creates a middleware enforcing the allowlists and denylists of the store.
+func NewAccessListMiddleware(store AccessStore, options ...AccessOption) MiddlewareFunc {
+	return NewAccessList(store, options...).Middleware()
+}
+
+// BindAccessCommands registers admin commands managing the access list:
+//   - "/ban <id> [duration]" denies a user or chat, e.g. "/ban 42 24h"
+//   - "/allow <id> [duration]" allows a user or chat
+//   - "/unban <id>" removes the entry of a user or chat
+//   - "/access" lists the entries
+//
+// Without an ID, "/ban", "/allow" and "/unban" apply to the author of the replied-to message.
+// Only the given admins receive a reply, updates from other users are ignored silently.
+func (b *Bot) BindAccessCommands(list *AccessList, admins []int64, middlewares ...MiddlewareFunc) {
+	middlewares = append([]MiddlewareFunc{newAdminOnlyMiddleware(admins)}, middlewares...)
+	reply := func(ctx context.Context, update *Update, format string, args ...any) error {
+		return b.SendMessage(ctx, update, &Message{Text: fmt.Sprintf(format, args...)})
+	}
+	b.BindCommand("ban", func(ctx context.Context, update *Update) error {
+		id, duration, err := parseAccessArgs(update)
+		if err != nil {
+			return reply(ctx, update, "usage: /ban <id> [duration]: %v", err)
+		}
+		if err = list.Ban(ctx, id, duration); err != nil {
+			return err
+		}
+		return reply(ctx, update, "banned %d", id)
+	}, middlewares...)
+	b.BindCommand("allow", func(ctx context.Context, update *Update) error {
+		id, duration, err := parseAccessArgs(update)
+		if err != nil {
+			return reply(ctx, update, "usage: /allow <id> [duration]: %v", err)
+		}
+		if err = list.Allow(ctx, id, duration); err != nil {
+			return err
+		}
+		return reply(ctx, update, "allowed %d", id)
+	}, middlewares...)
+	b.BindCommand("unban", func(ctx context.Context, update *Update) error {
+		id, _, err := parseAccessArgs(update)
+		if err != nil {
+			return reply(ctx, update, "usage: /unban <id>: %v", err)
+		}
+		if err = list.Remove(ctx, id); err != nil {
+			return err
+		}
+		return reply(ctx, update, "removed %d", id)
+	}, middlewares...)
+	b.BindCommand("access", func(ctx context.Context, update *Update) error {
+		entries, err := list.store.ListAccess(ctx)
+		if err != nil {
+			return err
+		}
+		return reply(ctx, update, "%s", formatAccessEntries(entries, time.Now()))
+	}, middlewares...)
+}
+
+// parseAccessArgs parses "<command> [id] [duration]", falling back to the author of the
+// replied-to message when no ID is given.
+func parseAccessArgs(update *Update) (int64, time.Duration, error) {
+	args := strings.Fields(update.Message.Text)[1:]
+	var id int64
+	if len(args) > 0 {
+		if parsed, err := strconv.ParseInt(args[0], 10, 64); err == nil {
+			id = parsed
+			args = args[1:]
+		}
+	}
+	if id == 0 {
+		reply := update.Message.ReplyToMessage
+		if reply == nil || reply.From == nil {
+			return 0, 0, errors.New("missing id")
+		}
+		id = reply.From.ID
+	}
+	var duration time.Duration
+	if len(args) > 0 {
+		var err error
+		if duration, err = time.ParseDuration(args[0]); err != nil {
+			return 0, 0, err
+		}
+	}
+	return id, duration, nil
+}
+
+func formatAccessEntries(entries []*AccessEntry, now time.Time) string {
+	var s strings.Builder
+	for _, entry := range entries {
+		if !entry.Active(now) {
+			continue
+		}
+		fmt.Fprintf(&s, "%d %s", entry.ID, entry.Rule)
+		if !entry.ExpiresAt.IsZero() {
+			fmt.Fprintf(&s, " until %s", entry.ExpiresAt.Format(time.DateTime))
+		}
+		s.WriteString("\n")
+	}
+	if s.Len() == 0 {
+		return "access list is empty"
+	}
+	return s.String()
+}
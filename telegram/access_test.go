@@ -0,0 +1,54 @@
+package telegram
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestAccessList(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryAccessStore()
+	list := NewAccessList(store)
+
+	check := func(l *AccessList, userID, chatID int64, want bool) {
+		t.Helper()
+		if ok, err := l.Check(ctx, userID, chatID); err != nil || ok != want {
+			t.Errorf("Check(%d, %d) = %v, %v, want %v", userID, chatID, ok, err, want)
+		}
+	}
+	check(list, 1, -100, true)
+	_ = list.Ban(ctx, 1, 0)
+	_ = list.Ban(ctx, -200, 0)
+	check(list, 1, -100, false)
+	check(list, 2, -200, false)
+	check(list, 2, -100, true)
+
+	_ = store.SetAccess(ctx, &AccessEntry{ID: 3, Rule: AccessDeny, ExpiresAt: time.Now().Add(-time.Second)})
+	check(list, 3, 0, true)
+
+	allowlist := NewAccessList(store, WithAllowlistOnly(true))
+	_ = allowlist.Allow(ctx, -100, 0)
+	check(allowlist, 2, -100, true)
+	check(allowlist, 2, -300, false)
+	check(allowlist, 1, -100, false)
+
+	_ = list.Remove(ctx, 1)
+	check(list, 1, -100, true)
+}
+
+func TestParseAccessArgs(t *testing.T) {
+	update := &Update{Message: &models.Message{Text: "/ban 42 1h"}}
+	if id, d, err := parseAccessArgs(update); err != nil || id != 42 || d != time.Hour {
+		t.Errorf("unexpected args: %d, %s, %v", id, d, err)
+	}
+	update = &Update{Message: &models.Message{Text: "/ban 30m", ReplyToMessage: &models.Message{From: &models.User{ID: 7}}}}
+	if id, d, err := parseAccessArgs(update); err != nil || id != 7 || d != 30*time.Minute {
+		t.Errorf("unexpected reply args: %d, %s, %v", id, d, err)
+	}
+	if _, _, err := parseAccessArgs(&Update{Message: &models.Message{Text: "/ban"}}); err == nil {
+		t.Error("expected a missing id error")
+	}
+}
@@ -0,0 +1,167 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+)
+
+// adminOptions holds configuration for the admin notifications.
+type adminOptions struct {
+	batchInterval  time.Duration // Interval queued notifications are sent in one message
+	errorThreshold int           // Handler errors within a batch interval that are reported
+}
+
+// AdminOption defines a function type for configuring the admin notifications.
+type AdminOption func(*adminOptions)
+
+// WithAdminBatchInterval sets the interval queued notifications are batched into a single
+// message. Defaults to 10 seconds.
+func WithAdminBatchInterval(interval time.Duration) AdminOption {
+	return func(o *adminOptions) {
+		o.batchInterval = interval
+	}
+}
+
+// WithAdminErrorThreshold sets how many handler errors within a batch interval trigger a
+// notification. Panics and rate limits are always reported. Defaults to 5.
+func WithAdminErrorThreshold(threshold int) AdminOption {
+	return func(o *adminOptions) {
+		o.errorThreshold = threshold
+	}
+}
+
+// adminNotifier batches operational events into messages sent to an admin chat.
+type adminNotifier struct {
+	chatID int64
+	opts   adminOptions
+	send   func(ctx context.Context, text string) error
+
+	mu      sync.Mutex
+	lines   []string
+	errors  int
+	lastErr error
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+func newAdminNotifier(chatID int64, send func(ctx context.Context, text string) error, opts ...AdminOption) *adminNotifier {
+	defaults := adminOptions{
+		batchInterval:  10 * time.Second,
+		errorThreshold: 5,
+	}
+	for _, opt := range opts {
+		opt(&defaults)
+	}
+	return &adminNotifier{
+		chatID: chatID,
+		opts:   defaults,
+		send:   send,
+	}
+}
+
+// notify queues a line for the next batch.
+func (n *adminNotifier) notify(format string, args ...any) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.lines = append(n.lines, fmt.Sprintf(format, args...))
+}
+
+// report classifies a handler error into a notification.
+func (n *adminNotifier) report(update *Update, err error) {
+	report := NewErrorReport(update, err)
+	var tooManyRequestsError *bot.TooManyRequestsError
+	switch {
+	case report.IsPanic():
+		n.notify("panic in %s update %d (chat %d, user %d): %v", report.UpdateType, report.UpdateID, report.ChatID, report.UserID, err)
+	case errors.As(err, &tooManyRequestsError):
+		n.notify("rate limited by Telegram, retry after %ds", tooManyRequestsError.RetryAfter)
+	default:
+		n.mu.Lock()
+		n.errors++
+		n.lastErr = err
+		n.mu.Unlock()
+	}
+}
+
+// flush sends the queued notifications as a single message.
+func (n *adminNotifier) flush(ctx context.Context) error {
+	n.mu.Lock()
+	lines := n.lines
+	if n.errors >= n.opts.errorThreshold && n.errors > 0 {
+		lines = append(lines, fmt.Sprintf("%d handler errors, last: %v", n.errors, n.lastErr))
+	}
+	n.lines, n.errors, n.lastErr = nil, 0, nil
+	n.mu.Unlock()
+	if len(lines) == 0 {
+		return nil
+	}
+	text := strings.Join(lines, "\n")
+	if runes := []rune(text); len(runes) > MaxMessageLength {
+		text = string(runes[:MaxMessageLength-1]) + "…"
+	}
+	return n.send(ctx, text)
+}
+
+// start announces the start and sends batches until stop is called.
+func (n *adminNotifier) start(ctx context.Context) error {
+	n.notify("bot started")
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	done := make(chan struct{})
+	n.mu.Lock()
+	n.cancel, n.done = cancel, done
+	n.mu.Unlock()
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(n.opts.batchInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := n.flush(ctx); err != nil {
+					slog.Error("send admin notification", slog.Any("error", err))
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// stop ends the batching and sends the remaining notifications with the shutdown notice.
+func (n *adminNotifier) stop(ctx context.Context) error {
+	n.mu.Lock()
+	cancel, done := n.cancel, n.done
+	n.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+	n.notify("bot stopped")
+	return n.flush(ctx)
+}
+
+// withAdminNotifier wraps an error handler so every error is reported to the admin chat.
+func withAdminNotifier(notifier *adminNotifier, next ErrorHandlerFunc) ErrorHandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *Update, err error) {
+		notifier.report(update, err)
+		if next != nil {
+			next(ctx, b, update, err)
+		}
+	}
+}
+
+// NotifyAdmin queues a custom notification for the admin chat configured with WithAdminChat.
+// It does nothing when no admin chat is configured.
+func (b *Bot) NotifyAdmin(format string, args ...any) {
+	if b.admin != nil {
+		b.admin.notify(format, args...)
+	}
+}
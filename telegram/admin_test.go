@@ -0,0 +1,44 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+func TestAdminNotifierBatching(t *testing.T) {
+	var sent []string
+	n := newAdminNotifier(1, func(ctx context.Context, text string) error {
+		sent = append(sent, text)
+		return nil
+	}, WithAdminErrorThreshold(2))
+	update := &Update{ID: 7, Message: &models.Message{Chat: models.Chat{ID: 3}}}
+
+	n.report(update, errors.New("first"))
+	if err := n.flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 0 {
+		t.Fatalf("errors below the threshold must not be sent: %v", sent)
+	}
+
+	n.report(update, &PanicError{Value: "boom"})
+	n.report(update, &bot.TooManyRequestsError{RetryAfter: 3})
+	n.report(update, errors.New("second"))
+	n.report(update, errors.New("third"))
+	if err := n.flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 {
+		t.Fatalf("expected a single batched message, got %d", len(sent))
+	}
+	for _, want := range []string{"panic in message update 7", "retry after 3s", "2 handler errors, last: third"} {
+		if !strings.Contains(sent[0], want) {
+			t.Errorf("notification %q does not contain %q", sent[0], want)
+		}
+	}
+}
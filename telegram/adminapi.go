@@ -0,0 +1,364 @@
+package telegram
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/time/rate"
+)
+
+// maxAdminAPIBody bounds the size of admin API request bodies.
+const maxAdminAPIBody = 1 << 20
+
+// maxAdminBroadcasts is the number of broadcasts whose status is kept, the oldest finished
+// broadcasts are forgotten first.
+const maxAdminBroadcasts = 100
+
+// AdminSendRequest is the body of the admin API send and broadcast endpoints.
+type AdminSendRequest struct {
+	ChatID    int64            `json:"chat_id,omitempty"`  // Recipient of "POST /send"
+	ChatIDs   []int64          `json:"chat_ids,omitempty"` // Recipients of "POST /broadcasts"
+	Text      string           `json:"text"`
+	ParseMode models.ParseMode `json:"parse_mode,omitempty"`
+	Button    [][]Button       `json:"button,omitempty"` // Inline keyboard, e.g. URL buttons
+}
+
+func (r *AdminSendRequest) message() *Message {
+	return &Message{Text: r.Text, ParseMode: r.ParseMode, Button: r.Button}
+}
+
+// AdminBroadcastStatus is the progress of a broadcast started through the admin API.
+type AdminBroadcastStatus struct {
+	ID         string            `json:"id"`
+	Recipients int               `json:"recipients"`
+	Done       bool              `json:"done"`
+	Error      string            `json:"error,omitempty"` // Error ending the broadcast early
+	Results    []BroadcastResult `json:"results"`
+}
+
+// adminAPIOptions holds configuration for the admin API.
+type adminAPIOptions struct {
+	limiter *rate.Limiter // Rate limit of broadcasts
+	quotas  *Quotas       // Quotas whose credits are managed through the API
+	archive *Archiver     // Archiver whose history is exported through the API
+}
+
+// AdminAPIOption defines a function type for configuring the admin API.
+type AdminAPIOption func(*adminAPIOptions)
+
+// WithAdminAPIRateLimiter sets the rate limit of broadcasts. Defaults to Config.RateLimit,
+// or 25 messages per second without one.
+func WithAdminAPIRateLimiter(limiter *rate.Limiter) AdminAPIOption {
+	return func(o *adminAPIOptions) {
+		o.limiter = limiter
+	}
+}
+
+// WithAdminAPIQuotas enables the credit endpoints for the quotas:
+//   - "GET /users/{id}/credits" returns the credit balance of a user.
+//   - "POST /users/{id}/credits" grants the credits of an AdminCreditRequest and returns the balance.
+func WithAdminAPIQuotas(quotas *Quotas) AdminAPIOption {
+	return func(o *adminAPIOptions) {
+		o.quotas = quotas
+	}
+}
+
+// WithAdminAPIArchive enables the history endpoint of the archiver:
+//   - "GET /chats/{id}/history" exports the archived messages of a chat as JSON, or as CSV with
+//     "format=csv". The "since" and "until" RFC 3339 times and "limit" parameters narrow it.
+func WithAdminAPIArchive(archiver *Archiver) AdminAPIOption {
+	return func(o *adminAPIOptions) {
+		o.archive = archiver
+	}
+}
+
+// AdminCreditRequest is the body of the admin API credit endpoint.
+type AdminCreditRequest struct {
+	Credits int64 `json:"credits"` // Credits to grant, negative to revoke
+}
+
+type adminAPI struct {
+	app  *Bot
+	keys [][]byte
+	opts *adminAPIOptions
+
+	mu         sync.Mutex
+	broadcasts map[string]*adminBroadcast
+	order      []string // IDs of the broadcasts, oldest first
+}
+
+type adminBroadcast struct {
+	recipients int
+	report     BroadcastReport
+	mu         sync.Mutex
+	done       bool
+	err        error
+}
+
+// AdminAPIHandler returns an http.Handler letting external systems drive the bot without
+// linking Go code. Requests must carry one of the API keys in the "Authorization: Bearer"
+// or "X-API-Key" header, others are rejected with 401 Unauthorized. Endpoints:
+//
+//   - "POST /send" sends an AdminSendRequest to its chat and returns the message ID.
+//   - "POST /broadcasts" starts a broadcast of an AdminSendRequest to its chats in the
+//     background and returns 202 Accepted with its AdminBroadcastStatus.
+//   - "GET /broadcasts/{id}" returns the AdminBroadcastStatus of a broadcast.
+//   - "POST /updates" processes the body as an incoming update with HandleUpdate, e.g. to
+//     inject synthetic updates in tests, and returns 500 with the handler error if any.
+//   - The credit endpoints of WithAdminAPIQuotas.
+//   - The history endpoint of WithAdminAPIArchive.
+//
+// Serve it on a private address or behind TLS, the API keys grant full control of the bot.
+func (b *Bot) AdminAPIHandler(apiKeys []string, options ...AdminAPIOption) http.Handler {
+	opts := &adminAPIOptions{}
+	if b.currentConfig().RateLimit.PerSecond > 0 {
+		opts.limiter = b.currentConfig().RateLimit.NewLimiter()
+	} else {
+		opts.limiter = rate.NewLimiter(25, 1)
+	}
+	for _, opt := range options {
+		opt(opts)
+	}
+	api := &adminAPI{app: b, opts: opts, broadcasts: map[string]*adminBroadcast{}}
+	for _, key := range apiKeys {
+		if key != "" {
+			api.keys = append(api.keys, []byte(key))
+		}
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /send", api.send)
+	mux.HandleFunc("POST /broadcasts", api.broadcast)
+	mux.HandleFunc("GET /broadcasts/{id}", api.broadcastStatus)
+	mux.HandleFunc("POST /updates", api.update)
+	if opts.quotas != nil {
+		mux.HandleFunc("GET /users/{id}/credits", api.credits)
+		mux.HandleFunc("POST /users/{id}/credits", api.credits)
+	}
+	if opts.archive != nil {
+		mux.HandleFunc("GET /chats/{id}/history", api.history)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !api.authorized(r) {
+			writeAdminAPIError(w, http.StatusUnauthorized, errors.New("invalid api key"))
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxAdminAPIBody)
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func (a *adminAPI) authorized(r *http.Request) bool {
+	key := r.Header.Get("X-API-Key")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		key = bearer
+	}
+	if key == "" {
+		return false
+	}
+	for _, k := range a.keys {
+		if subtle.ConstantTimeCompare([]byte(key), k) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *adminAPI) send(w http.ResponseWriter, r *http.Request) {
+	var req AdminSendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ChatID == 0 || req.Text == "" {
+		writeAdminAPIError(w, http.StatusBadRequest, errors.New("chat_id and text are required"))
+		return
+	}
+	ref, err := a.app.SendTo(r.Context(), req.ChatID, req.message())
+	if err != nil {
+		writeAdminAPIError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeAdminAPI(w, http.StatusOK, map[string]int{"message_id": ref.MessageID})
+}
+
+func (a *adminAPI) broadcast(w http.ResponseWriter, r *http.Request) {
+	var req AdminSendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.ChatIDs) == 0 || req.Text == "" {
+		writeAdminAPIError(w, http.StatusBadRequest, errors.New("chat_ids and text are required"))
+		return
+	}
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	id := hex.EncodeToString(buf)
+	run := &adminBroadcast{recipients: len(req.ChatIDs)}
+	a.add(id, run)
+
+	ctx := contextWithSendHooks(context.WithoutCancel(r.Context()), a.app.sendHooks)
+	go func() {
+		err := BroadcastMessage(ctx, a.app.API(), req.ChatIDs, a.opts.limiter, func(ctx context.Context, b *bot.Bot, chatID int64) error {
+			// a message per recipient, since the send hooks may modify it
+			_, err := SendTo(ctx, b, chatID, req.message())
+			return err
+		}, WithBroadcastReport(&run.report))
+		if err != nil {
+			slog.ErrorContext(ctx, "admin api broadcast", slog.String("id", id), slog.Any("error", err))
+		}
+		run.mu.Lock()
+		run.done, run.err = true, err
+		run.mu.Unlock()
+	}()
+	writeAdminAPI(w, http.StatusAccepted, run.status(id))
+}
+
+// add keeps the broadcast, forgetting the oldest finished ones beyond maxAdminBroadcasts.
+func (a *adminAPI) add(id string, run *adminBroadcast) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.broadcasts[id] = run
+	a.order = append(a.order, id)
+	for i := 0; len(a.order) > maxAdminBroadcasts && i < len(a.order); {
+		old := a.broadcasts[a.order[i]]
+		old.mu.Lock()
+		done := old.done
+		old.mu.Unlock()
+		if !done {
+			i++
+			continue
+		}
+		delete(a.broadcasts, a.order[i])
+		a.order = append(a.order[:i], a.order[i+1:]...)
+	}
+}
+
+func (a *adminAPI) broadcastStatus(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	a.mu.Lock()
+	run, ok := a.broadcasts[id]
+	a.mu.Unlock()
+	if !ok {
+		writeAdminAPIError(w, http.StatusNotFound, errors.New("broadcast not found"))
+		return
+	}
+	writeAdminAPI(w, http.StatusOK, run.status(id))
+}
+
+func (r *adminBroadcast) status(id string) AdminBroadcastStatus {
+	status := AdminBroadcastStatus{ID: id, Recipients: r.recipients, Results: r.report.Results()}
+	if status.Results == nil {
+		status.Results = []BroadcastResult{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	status.Done = r.done
+	if r.err != nil {
+		status.Error = r.err.Error()
+	}
+	return status
+}
+
+func (a *adminAPI) update(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeAdminAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	var update Update
+	if err = json.Unmarshal(body, &update); err != nil {
+		writeAdminAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err = a.app.HandleUpdate(r.Context(), &update); err != nil {
+		writeAdminAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeAdminAPI(w, http.StatusOK, map[string]bool{"ok": true})
+}
+
+func (a *adminAPI) credits(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeAdminAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	var balance int64
+	if r.Method == http.MethodPost {
+		var req AdminCreditRequest
+		if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeAdminAPIError(w, http.StatusBadRequest, err)
+			return
+		}
+		balance, err = a.opts.quotas.GrantCredits(r.Context(), userID, req.Credits)
+	} else {
+		balance, err = a.opts.quotas.Credits(r.Context(), userID)
+	}
+	if err != nil {
+		writeAdminAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeAdminAPI(w, http.StatusOK, map[string]int64{"user_id": userID, "credits": balance})
+}
+
+func (a *adminAPI) history(w http.ResponseWriter, r *http.Request) {
+	query, err := parseArchiveQuery(r)
+	if err != nil {
+		writeAdminAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	messages, err := a.opts.archive.Query(r.Context(), query)
+	if err != nil {
+		writeAdminAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if r.URL.Query().Get("format") != "csv" {
+		writeAdminAPI(w, http.StatusOK, messages)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv")
+	_ = writeArchiveCSV(w, messages)
+}
+
+func parseArchiveQuery(r *http.Request) (ArchiveQuery, error) {
+	var (
+		query ArchiveQuery
+		err   error
+	)
+	if query.ChatID, err = strconv.ParseInt(r.PathValue("id"), 10, 64); err != nil {
+		return query, err
+	}
+	values := r.URL.Query()
+	if v := values.Get("since"); v != "" {
+		if query.Since, err = time.Parse(time.RFC3339, v); err != nil {
+			return query, err
+		}
+	}
+	if v := values.Get("until"); v != "" {
+		if query.Until, err = time.Parse(time.RFC3339, v); err != nil {
+			return query, err
+		}
+	}
+	if v := values.Get("limit"); v != "" {
+		if query.Limit, err = strconv.Atoi(v); err != nil {
+			return query, err
+		}
+	}
+	return query, nil
+}
+
+func writeAdminAPI(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeAdminAPIError(w http.ResponseWriter, status int, err error) {
+	writeAdminAPI(w, status, map[string]string{"error": err.Error()})
+}
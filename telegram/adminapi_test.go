@@ -0,0 +1,68 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAdminAPIHandler(t *testing.T) {
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":5,"chat":{"id":1}}}`))
+	})
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var injected string
+	app.BindCommand("/ping", func(ctx context.Context, update *Update) error {
+		injected = update.Message.Text
+		return nil
+	})
+	handler := app.AdminAPIHandler([]string{"key"})
+	do := func(method, path, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do(http.MethodPost, "/send", "wrong", `{"chat_id":1,"text":"hi"}`); rec.Code != http.StatusUnauthorized {
+		t.Errorf("wrong key got status %d", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/send", "key", `{"chat_id":1}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("missing text got status %d", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/send", "key", `{"chat_id":1,"text":"hi"}`); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"message_id":5`) {
+		t.Errorf("send got %d %s", rec.Code, rec.Body)
+	}
+
+	rec := do(http.MethodPost, "/broadcasts", "key", `{"chat_ids":[1,2,3],"text":"news"}`)
+	var status AdminBroadcastStatus
+	if err = json.Unmarshal(rec.Body.Bytes(), &status); rec.Code != http.StatusAccepted || err != nil {
+		t.Fatalf("broadcast got %d %s", rec.Code, rec.Body)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for !status.Done && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		_ = json.Unmarshal(do(http.MethodGet, "/broadcasts/"+status.ID, "key", "").Body.Bytes(), &status)
+	}
+	if !status.Done || len(status.Results) != 3 {
+		t.Errorf("unexpected broadcast status %+v", status)
+	}
+	if rec = do(http.MethodGet, "/broadcasts/unknown", "key", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown broadcast got status %d", rec.Code)
+	}
+
+	rec = do(http.MethodPost, "/updates", "key", `{"update_id":1,"message":{"message_id":1,"chat":{"id":1},"text":"/ping"}}`)
+	if rec.Code != http.StatusOK || injected != "/ping" {
+		t.Errorf("injected update got %d, handled %q", rec.Code, injected)
+	}
+}
@@ -0,0 +1,491 @@
+package telegram
+
+import (
+	"cmp"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// ArchiveDirection tells whether an archived message was received or sent by the bot.
+type ArchiveDirection string
+
+const (
+	ArchiveIncoming ArchiveDirection = "in"
+	ArchiveOutgoing ArchiveDirection = "out"
+)
+
+// ArchiveField selects optional message content kept by the Archiver.
+type ArchiveField int
+
+const (
+	ArchiveText     ArchiveField = 1 << iota // Text or caption of the message
+	ArchiveUsername                          // Username of the sender
+	ArchiveFiles                             // File IDs of attached media
+)
+
+// ArchivedMessage is a message of the chat history kept by the Archiver.
+type ArchivedMessage struct {
+	ChatID    int64            `json:"chat_id"`
+	MessageID int              `json:"message_id"`
+	UserID    int64            `json:"user_id,omitempty"` // Sender of the message, the bot for outgoing messages
+	Direction ArchiveDirection `json:"direction"`
+	Time      time.Time        `json:"time"`
+	Username  string           `json:"username,omitempty"`
+	Text      string           `json:"text,omitempty"`
+	FileIDs   []string         `json:"file_ids,omitempty"`
+}
+
+// archivedContent is the part of an ArchivedMessage encrypted at rest.
+type archivedContent struct {
+	Username string   `json:"username,omitempty"`
+	Text     string   `json:"text,omitempty"`
+	FileIDs  []string `json:"file_ids,omitempty"`
+}
+
+// ArchiveRecord is an ArchivedMessage as persisted by an ArchiveStore. Content holds the
+// optional fields, encrypted when the Archiver has an ArchiveCipher.
+type ArchiveRecord struct {
+	ChatID    int64
+	MessageID int
+	UserID    int64
+	Direction ArchiveDirection
+	Time      time.Time
+	Content   string
+}
+
+// ArchiveQuery selects archived messages. Zero fields do not filter.
+type ArchiveQuery struct {
+	ChatID int64     `json:"chat_id,omitempty"`
+	UserID int64     `json:"user_id,omitempty"`
+	Since  time.Time `json:"since,omitzero"` // Inclusive lower bound of the message time
+	Until  time.Time `json:"until,omitzero"` // Exclusive upper bound of the message time
+	Limit  int       `json:"limit,omitempty"`
+}
+
+// Match reports whether the record is selected by the query, ignoring the limit.
+func (q ArchiveQuery) Match(record *ArchiveRecord) bool {
+	return (q.ChatID == 0 || record.ChatID == q.ChatID) &&
+		(q.UserID == 0 || record.UserID == q.UserID) &&
+		(q.Since.IsZero() || !record.Time.Before(q.Since)) &&
+		(q.Until.IsZero() || record.Time.Before(q.Until))
+}
+
+// ArchiveStore persists archived messages.
+type ArchiveStore interface {
+	// AppendArchive stores the record.
+	AppendArchive(ctx context.Context, record *ArchiveRecord) error
+	// QueryArchive returns the records selected by the query, oldest first.
+	QueryArchive(ctx context.Context, query ArchiveQuery) ([]*ArchiveRecord, error)
+	// PruneArchive deletes the records older than before and returns their number.
+	PruneArchive(ctx context.Context, before time.Time) (int, error)
+}
+
+// MemoryArchiveStore is an in-memory ArchiveStore, suitable for tests and single instance bots.
+type MemoryArchiveStore struct {
+	mu      sync.RWMutex
+	records []*ArchiveRecord
+}
+
+// NewMemoryArchiveStore creates an empty in-memory archive store.
+func NewMemoryArchiveStore() *MemoryArchiveStore {
+	return &MemoryArchiveStore{}
+}
+
+// AppendArchive implements ArchiveStore.
+func (s *MemoryArchiveStore) AppendArchive(ctx context.Context, record *ArchiveRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *record
+	// keep the records ordered by time, messages are mostly archived in order
+	i := sort.Search(len(s.records), func(i int) bool {
+		return s.records[i].Time.After(stored.Time)
+	})
+	s.records = append(s.records, nil)
+	copy(s.records[i+1:], s.records[i:])
+	s.records[i] = &stored
+	return nil
+}
+
+// QueryArchive implements ArchiveStore.
+func (s *MemoryArchiveStore) QueryArchive(ctx context.Context, query ArchiveQuery) ([]*ArchiveRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var records []*ArchiveRecord
+	for _, record := range s.records {
+		if !query.Match(record) {
+			continue
+		}
+		stored := *record
+		records = append(records, &stored)
+		if query.Limit > 0 && len(records) == query.Limit {
+			break
+		}
+	}
+	return records, nil
+}
+
+// PruneArchive implements ArchiveStore.
+func (s *MemoryArchiveStore) PruneArchive(ctx context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := sort.Search(len(s.records), func(i int) bool {
+		return !s.records[i].Time.Before(before)
+	})
+	s.records = append(s.records[:0], s.records[i:]...)
+	return i, nil
+}
+
+// DeleteUserData implements UserDataStore, deleting the messages sent by the user and the
+// history of the private chat with the user.
+func (s *MemoryArchiveStore) DeleteUserData(ctx context.Context, userID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = slices.DeleteFunc(s.records, func(record *ArchiveRecord) bool {
+		return record.UserID == userID || record.ChatID == userID
+	})
+	return nil
+}
+
+// ArchiveCipher encrypts the archived content at rest.
+type ArchiveCipher interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+type aesGCMCipher struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCMCipher creates an ArchiveCipher using AES-GCM with a random nonce per message.
+// The key must be 16, 24 or 32 bytes long.
+func NewAESGCMCipher(key []byte) (ArchiveCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &aesGCMCipher{aead: aead}, nil
+}
+
+func (c *aesGCMCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return c.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (c *aesGCMCipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	size := c.aead.NonceSize()
+	if len(ciphertext) < size {
+		return nil, errors.New("archive ciphertext too short")
+	}
+	return c.aead.Open(nil, ciphertext[:size], ciphertext[size:], nil)
+}
+
+// archiveOptions holds configuration for the Archiver.
+type archiveOptions struct {
+	fields    ArchiveField  // Optional content kept for every message
+	retention time.Duration // Age after which messages are deleted, 0 keeps them forever
+	cipher    ArchiveCipher // Encryption of the content at rest, nil for none
+}
+
+// ArchiveOption defines a function type for configuring the Archiver.
+type ArchiveOption func(*archiveOptions)
+
+// WithArchiveFields sets the optional content kept for every message. Defaults to ArchiveText,
+// without fields only the metadata of messages is archived.
+func WithArchiveFields(fields ...ArchiveField) ArchiveOption {
+	return func(o *archiveOptions) {
+		o.fields = 0
+		for _, field := range fields {
+			o.fields |= field
+		}
+	}
+}
+
+// WithArchiveRetention deletes archived messages older than the retention. Expired messages are
+// pruned at most once an hour while messages are archived, or explicitly with Prune.
+func WithArchiveRetention(retention time.Duration) ArchiveOption {
+	return func(o *archiveOptions) {
+		o.retention = retention
+	}
+}
+
+// WithArchiveCipher encrypts the text, username and file IDs of archived messages at rest.
+func WithArchiveCipher(c ArchiveCipher) ArchiveOption {
+	return func(o *archiveOptions) {
+		o.cipher = c
+	}
+}
+
+// archivePruneInterval is the minimal interval between two automatic prunes.
+const archivePruneInterval = time.Hour
+
+// Archiver keeps the history of the conversations of the bot, e.g. for support bots that must
+// retain it. Incoming messages are archived by Middleware and outgoing ones by AfterSend.
+type Archiver struct {
+	store ArchiveStore
+	opts  archiveOptions
+
+	mu     sync.Mutex
+	pruned time.Time
+}
+
+// NewArchiver creates an archiver backed by the store.
+func NewArchiver(store ArchiveStore, options ...ArchiveOption) *Archiver {
+	a := &Archiver{store: store, opts: archiveOptions{fields: ArchiveText}}
+	for _, opt := range options {
+		opt(&a.opts)
+	}
+	return a
+}
+
+// Middleware returns a middleware archiving incoming and edited messages before they are
+// handled. Edits are archived as another entry with the same message ID.
+func (a *Archiver) Middleware() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, update *Update) error {
+			if msg := archivedUpdateMessage(update); msg != nil {
+				a.record(ctx, ArchiveIncoming, msg)
+			}
+			return next(ctx, update)
+		}
+	}
+}
+
+// AfterSend is an AfterSendHook archiving the messages sent or edited by the bot, install it
+// with WithSendHooks(nil, archiver.AfterSend). Sends bypassing the hooks, e.g. broadcasts,
+// can be archived with Record.
+func (a *Archiver) AfterSend(ctx context.Context, m *Message, sent *models.Message, err error) {
+	if err == nil && sent != nil {
+		a.record(ctx, ArchiveOutgoing, sent)
+	}
+}
+
+// Record archives the message at its edit or send date, now without one.
+func (a *Archiver) Record(ctx context.Context, direction ArchiveDirection, msg *models.Message) error {
+	record, err := a.encode(direction, msg)
+	if err != nil {
+		return err
+	}
+	if err = a.store.AppendArchive(ctx, record); err != nil {
+		return err
+	}
+	if a.opts.retention > 0 && a.prunable(time.Now()) {
+		_, err = a.Prune(ctx)
+	}
+	return err
+}
+
+// record archives the message, logging failures so archiving can not break update processing.
+func (a *Archiver) record(ctx context.Context, direction ArchiveDirection, msg *models.Message) {
+	if err := a.Record(ctx, direction, msg); err != nil {
+		slog.Error("archive message error", slog.Int64("chat_id", msg.Chat.ID), slog.String("error", err.Error()))
+	}
+}
+
+func (a *Archiver) prunable(now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if now.Sub(a.pruned) < archivePruneInterval {
+		return false
+	}
+	a.pruned = now
+	return true
+}
+
+// Prune deletes the messages older than the retention and returns their number.
+func (a *Archiver) Prune(ctx context.Context) (int, error) {
+	if a.opts.retention <= 0 {
+		return 0, nil
+	}
+	return a.store.PruneArchive(ctx, time.Now().Add(-a.opts.retention))
+}
+
+// Query returns the archived messages selected by the query, oldest first.
+func (a *Archiver) Query(ctx context.Context, query ArchiveQuery) ([]ArchivedMessage, error) {
+	records, err := a.store.QueryArchive(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]ArchivedMessage, 0, len(records))
+	for _, record := range records {
+		msg, err := a.decode(record)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+// ExportJSON writes the messages selected by the query as a JSON array.
+func (a *Archiver) ExportJSON(ctx context.Context, w io.Writer, query ArchiveQuery) error {
+	messages, err := a.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(messages)
+}
+
+// ExportCSV writes the messages selected by the query as CSV with a header row. Times are
+// formatted as RFC 3339 and file IDs are separated by spaces.
+func (a *Archiver) ExportCSV(ctx context.Context, w io.Writer, query ArchiveQuery) error {
+	messages, err := a.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	return writeArchiveCSV(w, messages)
+}
+
+func (a *Archiver) encode(direction ArchiveDirection, msg *models.Message) (*ArchiveRecord, error) {
+	record := &ArchiveRecord{
+		ChatID:    msg.Chat.ID,
+		MessageID: msg.ID,
+		Direction: direction,
+		Time:      time.Now(),
+	}
+	if msg.EditDate > 0 {
+		record.Time = time.Unix(int64(msg.EditDate), 0)
+	} else if msg.Date > 0 {
+		record.Time = time.Unix(int64(msg.Date), 0)
+	}
+	if msg.From != nil {
+		record.UserID = msg.From.ID
+	}
+	var content archivedContent
+	if a.opts.fields&ArchiveText != 0 {
+		content.Text = cmp.Or(msg.Text, msg.Caption)
+	}
+	if a.opts.fields&ArchiveUsername != 0 && msg.From != nil {
+		content.Username = msg.From.Username
+	}
+	if a.opts.fields&ArchiveFiles != 0 {
+		content.FileIDs = messageFileIDs(msg)
+	}
+	data, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	if a.opts.cipher == nil {
+		record.Content = string(data)
+		return record, nil
+	}
+	if data, err = a.opts.cipher.Encrypt(data); err != nil {
+		return nil, err
+	}
+	record.Content = base64.StdEncoding.EncodeToString(data)
+	return record, nil
+}
+
+func (a *Archiver) decode(record *ArchiveRecord) (ArchivedMessage, error) {
+	msg := ArchivedMessage{
+		ChatID:    record.ChatID,
+		MessageID: record.MessageID,
+		UserID:    record.UserID,
+		Direction: record.Direction,
+		Time:      record.Time,
+	}
+	data := []byte(record.Content)
+	if a.opts.cipher != nil {
+		sealed, err := base64.StdEncoding.DecodeString(record.Content)
+		if err != nil {
+			return msg, err
+		}
+		if data, err = a.opts.cipher.Decrypt(sealed); err != nil {
+			return msg, err
+		}
+	}
+	var content archivedContent
+	if err := json.Unmarshal(data, &content); err != nil {
+		return msg, err
+	}
+	msg.Username, msg.Text, msg.FileIDs = content.Username, content.Text, content.FileIDs
+	return msg, nil
+}
+
+func writeArchiveCSV(w io.Writer, messages []ArchivedMessage) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"chat_id", "message_id", "user_id", "direction", "time", "username", "text", "file_ids"})
+	for _, msg := range messages {
+		_ = cw.Write([]string{
+			strconv.FormatInt(msg.ChatID, 10),
+			strconv.Itoa(msg.MessageID),
+			strconv.FormatInt(msg.UserID, 10),
+			string(msg.Direction),
+			msg.Time.Format(time.RFC3339),
+			msg.Username,
+			msg.Text,
+			strings.Join(msg.FileIDs, " "),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// archivedUpdateMessage returns the user message of the update, nil for other updates.
+func archivedUpdateMessage(update *Update) *models.Message {
+	switch {
+	case update.Message != nil:
+		return update.Message
+	case update.EditedMessage != nil:
+		return update.EditedMessage
+	case update.BusinessMessage != nil:
+		return update.BusinessMessage
+	case update.EditedBusinessMessage != nil:
+		return update.EditedBusinessMessage
+	default:
+		return nil
+	}
+}
+
+// messageFileIDs returns the file IDs of the media attached to the message. Only the largest
+// size of photos is kept.
+func messageFileIDs(msg *models.Message) []string {
+	var ids []string
+	if n := len(msg.Photo); n > 0 {
+		ids = append(ids, msg.Photo[n-1].FileID)
+	}
+	if msg.Document != nil {
+		ids = append(ids, msg.Document.FileID)
+	}
+	if msg.Audio != nil {
+		ids = append(ids, msg.Audio.FileID)
+	}
+	if msg.Video != nil {
+		ids = append(ids, msg.Video.FileID)
+	}
+	if msg.Voice != nil {
+		ids = append(ids, msg.Voice.FileID)
+	}
+	if msg.VideoNote != nil {
+		ids = append(ids, msg.VideoNote.FileID)
+	}
+	if msg.Animation != nil {
+		ids = append(ids, msg.Animation.FileID)
+	}
+	if msg.Sticker != nil {
+		ids = append(ids, msg.Sticker.FileID)
+	}
+	return ids
+}
@@ -0,0 +1,83 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestArchiver(t *testing.T) {
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":2,"from":{"id":1,"is_bot":true},"chat":{"id":7},"text":"pong"}}`))
+	})
+	key := bytes.Repeat([]byte{1}, 32)
+	c, err := NewAESGCMCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewMemoryArchiveStore()
+	archiver := NewArchiver(store, WithArchiveFields(ArchiveText, ArchiveUsername), WithArchiveCipher(c))
+	app, err := NewApp(Config{Token: "token"},
+		WithAPIServer(server.URL),
+		AppendUpdateMiddlewares(archiver.Middleware()),
+		WithSendHooks(nil, archiver.AfterSend),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.BindCommand("ping", func(ctx context.Context, update *Update) error {
+		return app.SendMessage(ctx, update, &Message{Text: "pong"})
+	})
+
+	ctx := context.Background()
+	body := `{"update_id":1,"message":{"message_id":1,"date":1700000000,"from":{"id":7,"username":"alice"},"chat":{"id":7},"text":"/ping"}}`
+	if err = app.HandleUpdateJSON(ctx, []byte(body)); err != nil {
+		t.Fatal(err)
+	}
+
+	records, _ := store.QueryArchive(ctx, ArchiveQuery{})
+	if len(records) != 2 || strings.Contains(records[0].Content, "ping") {
+		t.Fatalf("content is not encrypted at rest: %+v", records)
+	}
+	messages, err := archiver.Query(ctx, ArchiveQuery{ChatID: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 2 ||
+		messages[0].Direction != ArchiveIncoming || messages[0].Text != "/ping" || messages[0].Username != "alice" ||
+		messages[1].Direction != ArchiveOutgoing || messages[1].Text != "pong" {
+		t.Fatalf("unexpected history: %+v", messages)
+	}
+
+	var buf bytes.Buffer
+	if err = archiver.ExportCSV(ctx, &buf, ArchiveQuery{ChatID: 7, Limit: 1}); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil || len(rows) != 2 || rows[1][3] != "in" || rows[1][6] != "/ping" {
+		t.Errorf("unexpected CSV export: %v, %v", rows, err)
+	}
+}
+
+func TestArchiverRetention(t *testing.T) {
+	store := NewMemoryArchiveStore()
+	archiver := NewArchiver(store, WithArchiveFields(), WithArchiveRetention(24*time.Hour))
+	ctx := context.Background()
+	_ = store.AppendArchive(ctx, &ArchiveRecord{ChatID: 7, MessageID: 1, Time: time.Now().Add(-48 * time.Hour), Content: "{}"})
+	_ = archiver.Record(ctx, ArchiveIncoming, &models.Message{ID: 2, Chat: models.Chat{ID: 7}, Text: "new"})
+
+	messages, err := archiver.Query(ctx, ArchiveQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// recording prunes expired messages, without fields only metadata is kept
+	if len(messages) != 1 || messages[0].MessageID != 2 || messages[0].Text != "" {
+		t.Fatalf("unexpected history: %+v", messages)
+	}
+}
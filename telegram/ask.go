@@ -0,0 +1,179 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+var (
+	// ErrAskTimeout is returned by Ask when no answer arrives in time.
+	ErrAskTimeout = errors.New("ask: no answer before timeout")
+	// ErrAskPending is returned by Ask when a prompt is already waiting for the same chat and user.
+	ErrAskPending = errors.New("ask: another prompt is pending")
+)
+
+// Answer is the reply to a prompt sent with Ask.
+type Answer struct {
+	Update       *Update // The update carrying the answer
+	Text         string  // Text of the answering message, empty for button presses
+	CallbackData string  // Data of the pressed button, empty for messages
+}
+
+// askOptions holds configuration for a prompt.
+type askOptions struct {
+	userID int64 // User expected to answer, 0 for anyone in the chat
+}
+
+// AskOption defines a function type for configuring a prompt.
+type AskOption func(*askOptions)
+
+// WithAskUser only accepts answers from the given user, which is needed in group chats.
+func WithAskUser(userID int64) AskOption {
+	return func(o *askOptions) {
+		o.userID = userID
+	}
+}
+
+type promptKey struct {
+	chatID int64
+	userID int64
+}
+
+type prompt struct {
+	messageID int
+	answer    chan *Update
+}
+
+// prompts tracks the prompts waiting for an answer.
+type prompts struct {
+	mu      sync.Mutex
+	pending map[promptKey]*prompt
+}
+
+func newPrompts() *prompts {
+	return &prompts{pending: map[promptKey]*prompt{}}
+}
+
+func (p *prompts) add(key promptKey) (*prompt, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.pending[key]; ok {
+		return nil, ErrAskPending
+	}
+	pr := &prompt{answer: make(chan *Update, 1)}
+	p.pending[key] = pr
+	return pr, nil
+}
+
+func (p *prompts) remove(key promptKey, pr *prompt) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pending[key] == pr {
+		delete(p.pending, key)
+	}
+}
+
+// deliver hands the update to the matching prompt and reports whether it was consumed.
+func (p *prompts) deliver(update *Update) bool {
+	var (
+		chatID, userID int64
+		messageID      int
+	)
+	switch {
+	case update.Message != nil && update.Message.From != nil:
+		chatID, userID = update.Message.Chat.ID, update.Message.From.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.Message.Message != nil:
+		origin := update.CallbackQuery.Message.Message
+		chatID, userID, messageID = origin.Chat.ID, update.CallbackQuery.From.ID, origin.ID
+	default:
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.pending) == 0 {
+		return false
+	}
+	for _, key := range []promptKey{{chatID, userID}, {chatID, 0}} {
+		pr, ok := p.pending[key]
+		if !ok || (messageID != 0 && pr.messageID != messageID) {
+			continue
+		}
+		delete(p.pending, key)
+		pr.answer <- update
+		return true
+	}
+	return false
+}
+
+// middleware consumes the updates answering a pending prompt before they reach the handlers.
+func (p *prompts) middleware() bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			if !p.deliver(update) {
+				next(ctx, b, update)
+				return
+			}
+			if update.CallbackQuery != nil {
+				_, _ = b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+					CallbackQueryID: update.CallbackQuery.ID,
+				})
+			}
+		}
+	}
+}
+
+// Ask sends the question to the chat and waits for the answer: the next message in the chat,
+// or a press of one of the question's inline buttons. The answering update is consumed and
+// does not reach the regular handlers. Only one prompt may wait per chat and user at a time,
+// further calls fail with ErrAskPending. Ask returns ErrAskTimeout when the timeout elapses.
+func (b *Bot) Ask(ctx context.Context, chatID int64, question *Message, timeout time.Duration, options ...AskOption) (*Answer, error) {
+	opts := &askOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+	key := promptKey{chatID: chatID, userID: opts.userID}
+	// register before sending, so fast answers are not routed to the handlers
+	pr, err := b.prompts.add(key)
+	if err != nil {
+		return nil, err
+	}
+	defer b.prompts.remove(key, pr)
+
+	var sent *models.Message
+	err = runSendHooks(contextWithSendHooks(ctx, b.sendHooks), question, func() (*models.Message, error) {
+		// an empty business connection sends a regular message
+		sent, err = sendBusinessMessage(ctx, b.API(), "", chatID, question)
+		return sent, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if sent != nil {
+		b.prompts.mu.Lock()
+		pr.messageID = sent.ID
+		b.prompts.mu.Unlock()
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case update := <-pr.answer:
+		answer := &Answer{Update: update}
+		if update.Message != nil {
+			answer.Text = update.Message.Text
+		}
+		if update.CallbackQuery != nil {
+			answer.CallbackData = update.CallbackQuery.Data
+		}
+		return answer, nil
+	case <-timer.C:
+		return nil, ErrAskTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,36 @@
+package telegram
+
+import (
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestPromptsDeliver(t *testing.T) {
+	p := newPrompts()
+	key := promptKey{chatID: 1, userID: 2}
+	pr, err := p.add(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr.messageID = 10
+	if _, err = p.add(key); err != ErrAskPending {
+		t.Errorf("expected ErrAskPending, got %v", err)
+	}
+	if p.deliver(newCallbackUpdate(2, 11, "menu:a")) {
+		t.Error("button of another message must not answer the prompt")
+	}
+	other := &Update{Message: &models.Message{Chat: models.Chat{ID: 1}, From: &models.User{ID: 3}}}
+	if p.deliver(other) {
+		t.Error("message of another user must not answer the prompt")
+	}
+	if !p.deliver(newCallbackUpdate(2, 10, "menu:a")) {
+		t.Fatal("answer is not delivered")
+	}
+	if got := <-pr.answer; got.CallbackQuery.Data != "menu:a" {
+		t.Errorf("unexpected answer: %+v", got)
+	}
+	if len(p.pending) != 0 {
+		t.Error("answered prompt is still pending")
+	}
+}
@@ -0,0 +1,213 @@
+package telegram
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Audit event kinds recorded by the Auditor.
+const (
+	AuditKindCommand   = "command"
+	AuditKindCallback  = "callback"
+	AuditKindMessage   = "message"
+	AuditKindAdmin     = "admin"
+	AuditKindBroadcast = "broadcast"
+)
+
+// AuditEvent is a structured record of an auditable action.
+type AuditEvent struct {
+	Time     time.Time      // Time the action happened
+	Kind     string         // Event kind, see the AuditKind constants
+	Action   string         // Command, callback route or custom action name
+	UserID   int64          // User who performed the action, 0 for system actions
+	Username string         // Username of the user, if any
+	ChatID   int64          // Chat the action happened in, 0 if not applicable
+	UpdateID int64          // Update that triggered the action, 0 if not applicable
+	Payload  string         // Message text or callback data, after redaction
+	Error    string         // Error returned by the handler, if any
+	Attrs    map[string]any // Additional structured attributes
+}
+
+// AuditSink persists audit events.
+type AuditSink interface {
+	Write(ctx context.Context, event *AuditEvent) error
+}
+
+// AuditSinkFunc is a function type that implements the AuditSink interface.
+type AuditSinkFunc func(ctx context.Context, event *AuditEvent) error
+
+// Write implements the AuditSink interface by calling the function.
+func (f AuditSinkFunc) Write(ctx context.Context, event *AuditEvent) error {
+	return f(ctx, event)
+}
+
+// NewSlogAuditSink creates an AuditSink that writes events as structured log records.
+func NewSlogAuditSink(logger *slog.Logger) AuditSink {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return AuditSinkFunc(func(ctx context.Context, event *AuditEvent) error {
+		attrs := []any{
+			slog.String("kind", event.Kind),
+			slog.String("action", event.Action),
+			slog.Int64("user_id", event.UserID),
+			slog.String("username", event.Username),
+			slog.Int64("chat_id", event.ChatID),
+			slog.Int64("update_id", event.UpdateID),
+			slog.String("payload", event.Payload),
+		}
+		if event.Error != "" {
+			attrs = append(attrs, slog.String("error", event.Error))
+		}
+		if len(event.Attrs) > 0 {
+			attrs = append(attrs, slog.Any("attrs", event.Attrs))
+		}
+		logger.InfoContext(ctx, "audit", attrs...)
+		return nil
+	})
+}
+
+// AuditRedactor rewrites an event before it is written, e.g. to remove personal data.
+type AuditRedactor = func(event *AuditEvent)
+
+// RedactPayloadPatterns creates a redactor replacing every match of the patterns
+// in the event payload with "[REDACTED]".
+func RedactPayloadPatterns(patterns ...*regexp.Regexp) AuditRedactor {
+	return func(event *AuditEvent) {
+		for _, pattern := range patterns {
+			event.Payload = pattern.ReplaceAllString(event.Payload, "[REDACTED]")
+		}
+	}
+}
+
+// RedactAttrs creates a redactor replacing the values of the given attribute keys with "[REDACTED]".
+func RedactAttrs(keys ...string) AuditRedactor {
+	return func(event *AuditEvent) {
+		for _, key := range keys {
+			if _, ok := event.Attrs[key]; ok {
+				event.Attrs[key] = "[REDACTED]"
+			}
+		}
+	}
+}
+
+// auditOptions holds configuration for the Auditor.
+type auditOptions struct {
+	redactors []AuditRedactor // Redaction rules applied to every event
+	messages  bool            // Whether plain messages are audited in addition to commands
+}
+
+// AuditOption defines a function type for configuring the Auditor.
+type AuditOption func(*auditOptions)
+
+// WithAuditRedactor adds a redaction rule applied to every event before it is written.
+func WithAuditRedactor(redactors ...AuditRedactor) AuditOption {
+	return func(o *auditOptions) {
+		o.redactors = append(o.redactors, redactors...)
+	}
+}
+
+// WithAuditMessages records plain (non command) messages in addition to commands and callbacks.
+func WithAuditMessages(enabled bool) AuditOption {
+	return func(o *auditOptions) {
+		o.messages = enabled
+	}
+}
+
+// Auditor records audit events to an AuditSink applying redaction rules.
+type Auditor struct {
+	sink AuditSink
+	opts *auditOptions
+}
+
+// NewAuditor creates an Auditor writing to the given sink.
+func NewAuditor(sink AuditSink, options ...AuditOption) *Auditor {
+	opts := &auditOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return &Auditor{sink: sink, opts: opts}
+}
+
+// Record redacts and writes the event. Write failures are logged and never returned,
+// so auditing can not break update processing.
+func (a *Auditor) Record(ctx context.Context, event *AuditEvent) {
+	if a == nil || event == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	for _, redact := range a.opts.redactors {
+		redact(event)
+	}
+	if err := a.sink.Write(ctx, event); err != nil {
+		slog.Error("write audit event error", slog.String("error", err.Error()))
+	}
+}
+
+// RecordAdminAction records an administrative action performed by the user of the update.
+func (a *Auditor) RecordAdminAction(ctx context.Context, update *Update, action string, attrs map[string]any) {
+	event := newAuditEvent(update)
+	event.Kind = AuditKindAdmin
+	event.Action = action
+	event.Attrs = attrs
+	a.Record(ctx, event)
+}
+
+// Middleware returns a middleware recording who ran which command or pressed which button,
+// together with the handler's error.
+func (a *Auditor) Middleware() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, update *Update) error {
+			err := next(ctx, update)
+			event := newAuditEvent(update)
+			switch {
+			case update.CallbackQuery != nil:
+				event.Kind = AuditKindCallback
+				event.Action, _, _ = strings.Cut(update.CallbackQuery.Data, ":")
+				event.Payload = update.CallbackQuery.Data
+			case update.Message != nil && strings.HasPrefix(update.Message.Text, "/"):
+				event.Kind = AuditKindCommand
+				event.Action = updateCommand(update)
+				event.Payload = update.Message.Text
+			case update.Message != nil && a.opts.messages:
+				event.Kind = AuditKindMessage
+				event.Payload = update.Message.Text
+			default:
+				return err
+			}
+			if err != nil {
+				event.Error = err.Error()
+			}
+			a.Record(ctx, event)
+			return err
+		}
+	}
+}
+
+func newAuditEvent(update *Update) *AuditEvent {
+	event := &AuditEvent{Time: time.Now()}
+	if update == nil {
+		return event
+	}
+	event.UpdateID = update.ID
+	switch {
+	case update.Message != nil:
+		event.ChatID = update.Message.Chat.ID
+		if update.Message.From != nil {
+			event.UserID = update.Message.From.ID
+			event.Username = update.Message.From.Username
+		}
+	case update.CallbackQuery != nil:
+		event.UserID = update.CallbackQuery.From.ID
+		event.Username = update.CallbackQuery.From.Username
+		if origin := update.CallbackQuery.Message.Message; origin != nil {
+			event.ChatID = origin.Chat.ID
+		}
+	}
+	return event
+}
@@ -0,0 +1,115 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/time/rate"
+)
+
+func TestAuditorMiddleware(t *testing.T) {
+	var events []*AuditEvent
+	sink := AuditSinkFunc(func(ctx context.Context, event *AuditEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	auditor := NewAuditor(sink, WithAuditRedactor(RedactPayloadPatterns(regexp.MustCompile(`\d{4}-\d{4}`))))
+	app, err := NewApp(Config{Token: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.BindCommand("pay", func(ctx context.Context, update *Update) error {
+		return errors.New("declined")
+	}, auditor.Middleware())
+	app.BindCallback("vote", func(ctx context.Context, update *Update) error {
+		return nil
+	}, auditor.Middleware())
+	app.BindNoRoute(func(ctx context.Context, update *Update) error {
+		return nil
+	}, auditor.Middleware())
+
+	updates := []string{
+		`{"update_id":1,"message":{"message_id":1,"date":1,"chat":{"id":7,"type":"private"},"from":{"id":7,"is_bot":false,"first_name":"A","username":"alice"},"text":"/pay 1234-5678","entities":[{"type":"bot_command","offset":0,"length":4}]}}`,
+		`{"update_id":2,"callback_query":{"id":"q","from":{"id":8,"is_bot":false,"first_name":"B"},"chat_instance":"c","data":"vote:yes","message":{"message_id":2,"date":1,"chat":{"id":-1,"type":"group"}}}}`,
+		`{"update_id":3,"message":{"message_id":3,"date":1,"chat":{"id":7,"type":"private"},"from":{"id":7,"is_bot":false,"first_name":"A"},"text":"hello"}}`,
+	}
+	for _, body := range updates {
+		_ = app.HandleUpdateJSON(context.Background(), []byte(body))
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events without WithAuditMessages, got %d", len(events))
+	}
+	command := events[0]
+	if command.Kind != AuditKindCommand || command.Action != "/pay" || command.UserID != 7 || command.Username != "alice" ||
+		command.ChatID != 7 || command.UpdateID != 1 || command.Error != "declined" || command.Payload != "/pay [REDACTED]" {
+		t.Errorf("unexpected command event: %+v", command)
+	}
+	callback := events[1]
+	if callback.Kind != AuditKindCallback || callback.Action != "vote" || callback.Payload != "vote:yes" ||
+		callback.UserID != 8 || callback.ChatID != -1 || callback.Error != "" {
+		t.Errorf("unexpected callback event: %+v", callback)
+	}
+
+	events = nil
+	messages := NewAuditor(sink, WithAuditMessages(true))
+	handler := messages.Middleware()(func(ctx context.Context, update *Update) error { return nil })
+	update := &Update{ID: 4}
+	update.Message = &models.Message{Chat: models.Chat{ID: 7, Type: models.ChatTypePrivate}, Text: "hello"}
+	if err = handler(context.Background(), update); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Kind != AuditKindMessage || events[0].Payload != "hello" {
+		t.Errorf("unexpected message events: %+v", events)
+	}
+}
+
+func TestAuditorRecord(t *testing.T) {
+	var events []*AuditEvent
+	auditor := NewAuditor(AuditSinkFunc(func(ctx context.Context, event *AuditEvent) error {
+		events = append(events, event)
+		return errors.New("sink unavailable")
+	}), WithAuditRedactor(RedactAttrs("phone")))
+	auditor.RecordAdminAction(context.Background(), &Update{ID: 1, Message: &models.Message{Chat: models.Chat{ID: 5, Type: models.ChatTypeGroup}, Text: "/ban"}}, "ban",
+		map[string]any{"phone": "+100", "target": 9})
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	event := events[0]
+	if event.Kind != AuditKindAdmin || event.Action != "ban" || event.ChatID != 5 || event.Time.IsZero() ||
+		event.Attrs["phone"] != "[REDACTED]" || event.Attrs["target"] != 9 {
+		t.Errorf("unexpected admin event: %+v", event)
+	}
+	var nilAuditor *Auditor
+	nilAuditor.Record(context.Background(), &AuditEvent{})
+}
+
+func TestBroadcastAuditor(t *testing.T) {
+	var events []*AuditEvent
+	auditor := NewAuditor(AuditSinkFunc(func(ctx context.Context, event *AuditEvent) error {
+		events = append(events, event)
+		return nil
+	}))
+	err := BroadcastMessage(context.Background(), nil, []int64{1, 2, 3}, rate.NewLimiter(rate.Inf, 1), func(ctx context.Context, b *bot.Bot, id int64) error {
+		if id == 2 {
+			return errors.New("blocked")
+		}
+		return nil
+	}, WithBroadcastAuditor(auditor, "news"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected start and finish events, got %d", len(events))
+	}
+	start, finish := events[0], events[1]
+	if start.Kind != AuditKindBroadcast || start.Action != "news" || start.Attrs["stage"] != "start" || start.Attrs["total"] != 3 {
+		t.Errorf("unexpected start event: %+v", start)
+	}
+	if finish.Attrs["stage"] != "finish" || finish.Attrs["total"] != 3 || finish.Attrs["errors"] != 1 {
+		t.Errorf("unexpected finish event: %+v", finish)
+	}
+}
@@ -2,6 +2,7 @@ package telegram
 
 import (
 	"context"
+	"reflect"
 
 	"github.com/go-telegram/bot/models"
 )
@@ -21,25 +22,73 @@ func (f AuthExtractorFunc) ExtractorAuth(ctx context.Context, update *Update) (m
 	return f(ctx, update)
 }
 
+// AuthKey is the key of the data extracted by the auth middleware, see AuthInfo.
+var AuthKey = NewKey[map[string]any]("auth")
+
+// AuthInfo returns the data extracted by the auth middleware, e.g. "uid" and "subject" with
+// DefaultAuthExtractor, or nil if there is none.
+func AuthInfo(ctx context.Context) map[string]any {
+	info, _ := Value(ctx, AuthKey)
+	return info
+}
+
 // NewAuthMiddleware creates a middleware that extracts authentication information from updates.
 // It uses the provided AuthExtractor to get user data and injects it into the request context
-// using metadata. The extracted data becomes available to downstream handlers.
+// under AuthKey. The extracted data becomes available to downstream handlers through AuthInfo.
+// A nil extractor makes the middleware a no-op.
 func NewAuthMiddleware(auth AuthExtractor) MiddlewareFunc {
+	if fn, ok := auth.(AuthExtractorFunc); auth == nil || ok && fn == nil {
+		return skipAuth
+	}
 	return func(next HandlerFunc) HandlerFunc {
 		return func(ctx context.Context, update *Update) error {
 			info, err := auth.ExtractorAuth(ctx, update)
 			if err != nil {
 				return err
 			}
-			return next(contextWithValues(ctx, info), update)
+			if len(info) == 0 {
+				return next(ctx, update)
+			}
+			return next(WithValue(ctx, AuthKey, info), update)
 		}
 	}
 }
 
+// SkipAuth returns a middleware that, passed to a Bind method, excludes the route from the
+// auth middleware enabled with WithAuth, e.g. for public commands like "/start".
+func SkipAuth() MiddlewareFunc {
+	return skipAuth
+}
+
+func skipAuth(next HandlerFunc) HandlerFunc {
+	return next
+}
+
+// isSkipAuth reports whether the middleware is the marker returned by SkipAuth.
+func isSkipAuth(middleware MiddlewareFunc) bool {
+	return reflect.ValueOf(middleware).Pointer() == reflect.ValueOf(skipAuth).Pointer()
+}
+
 // DefaultAuthExtractor is the default implementation for extracting authentication data from updates.
-// It extracts user ID and username from either message or callback query updates.
+// It extracts user ID and username from message, callback query or business message updates.
 // Returns a map containing "uid" (user ID) and "subject" (username) if a user is found.
 func DefaultAuthExtractor(ctx context.Context, update *Update) (map[string]any, error) {
+	user := UpdateUser(update)
+	if user == nil {
+		return nil, nil
+	}
+	return map[string]any{
+		"uid":     user.ID,
+		"subject": user.Username,
+	}, nil
+}
+
+// UpdateUser returns the user who triggered the update from message, callback query
+// or business message updates, or nil if the update carries no user.
+func UpdateUser(update *Update) *models.User {
+	if update == nil {
+		return nil
+	}
 	var user *models.User
 	if update.Message != nil {
 		user = update.Message.From
@@ -47,11 +96,8 @@ func DefaultAuthExtractor(ctx context.Context, update *Update) (map[string]any,
 	if update.CallbackQuery != nil {
 		user = &update.CallbackQuery.From
 	}
-	if user == nil {
-		return nil, nil
+	if update.BusinessMessage != nil {
+		user = update.BusinessMessage.From
 	}
-	return map[string]any{
-		"uid":     user.ID,
-		"subject": user.Username,
-	}, nil
+	return user
 }
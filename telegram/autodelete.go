@@ -0,0 +1,154 @@
+package telegram
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// PendingDeletion is a message scheduled for deletion, see AutoDelete.
+type PendingDeletion struct {
+	ChatID    int64     `json:"chat_id"`
+	MessageID int       `json:"message_id"`
+	DeleteAt  time.Time `json:"delete_at"`
+}
+
+// DeletionStore persists pending deletions, so they survive restarts.
+type DeletionStore interface {
+	// AddDeletion inserts or replaces the pending deletion of the message.
+	AddDeletion(ctx context.Context, deletion PendingDeletion) error
+	// RemoveDeletion removes the pending deletion of the message.
+	RemoveDeletion(ctx context.Context, chatID int64, messageID int) error
+	// PendingDeletions returns all pending deletions.
+	PendingDeletions(ctx context.Context) ([]PendingDeletion, error)
+}
+
+// MemoryDeletionStore is an in-memory DeletionStore, suitable for tests. Pending deletions
+// are lost on restart.
+type MemoryDeletionStore struct {
+	mu        sync.Mutex
+	deletions map[MessageRef]PendingDeletion
+}
+
+// NewMemoryDeletionStore creates an empty in-memory deletion store.
+func NewMemoryDeletionStore() *MemoryDeletionStore {
+	return &MemoryDeletionStore{deletions: map[MessageRef]PendingDeletion{}}
+}
+
+// AddDeletion implements DeletionStore.
+func (s *MemoryDeletionStore) AddDeletion(ctx context.Context, deletion PendingDeletion) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deletions[MessageRef{ChatID: deletion.ChatID, MessageID: deletion.MessageID}] = deletion
+	return nil
+}
+
+// RemoveDeletion implements DeletionStore.
+func (s *MemoryDeletionStore) RemoveDeletion(ctx context.Context, chatID int64, messageID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.deletions, MessageRef{ChatID: chatID, MessageID: messageID})
+	return nil
+}
+
+// PendingDeletions implements DeletionStore.
+func (s *MemoryDeletionStore) PendingDeletions(ctx context.Context) ([]PendingDeletion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	deletions := make([]PendingDeletion, 0, len(s.deletions))
+	for _, deletion := range s.deletions {
+		deletions = append(deletions, deletion)
+	}
+	return deletions, nil
+}
+
+// autoDeleter deletes messages once their PendingDeletion is due. Deletions are saved in the
+// store and scheduled on a Scheduler run between the start and stop of the bot.
+type autoDeleter struct {
+	app       *Bot
+	store     DeletionStore
+	scheduler *Scheduler
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newAutoDeleter(app *Bot, store DeletionStore) *autoDeleter {
+	return &autoDeleter{app: app, store: store, scheduler: NewScheduler()}
+}
+
+// schedule saves the deletion and schedules it.
+func (d *autoDeleter) schedule(ctx context.Context, deletion PendingDeletion) error {
+	if err := d.store.AddDeletion(ctx, deletion); err != nil {
+		return err
+	}
+	d.enqueue(deletion)
+	return nil
+}
+
+func (d *autoDeleter) enqueue(deletion PendingDeletion) {
+	d.scheduler.ScheduleAt(deletion.ChatID, deletion.DeleteAt, func(ctx context.Context, chatID int64) error {
+		// the message may be gone already, the deletion is done either way
+		if err := DeleteTo(ctx, d.app.API(), chatID, deletion.MessageID); err != nil {
+			slog.WarnContext(ctx, "auto delete message", slog.Int64("chat_id", chatID), slog.Any("error", err))
+		}
+		return d.store.RemoveDeletion(ctx, chatID, deletion.MessageID)
+	})
+}
+
+// start schedules the saved deletions and runs the scheduler.
+func (d *autoDeleter) start(ctx context.Context) error {
+	pending, err := d.store.PendingDeletions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, deletion := range pending {
+		d.enqueue(deletion)
+	}
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	done := make(chan struct{})
+	d.mu.Lock()
+	d.cancel, d.done = cancel, done
+	d.mu.Unlock()
+	go func() {
+		defer close(done)
+		_ = d.scheduler.Run(ctx)
+	}()
+	return nil
+}
+
+// stop stops the scheduler, pending deletions stay in the store for the next start.
+func (d *autoDeleter) stop(context.Context) error {
+	d.mu.Lock()
+	cancel, done := d.cancel, d.done
+	d.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+	return nil
+}
+
+// afterSend schedules the deletion of sent messages with a DeleteAfter delay.
+func (d *autoDeleter) afterSend(ctx context.Context, m *Message, sent *models.Message, err error) {
+	if err != nil || sent == nil || m.DeleteAfter <= 0 {
+		return
+	}
+	deletion := PendingDeletion{ChatID: sent.Chat.ID, MessageID: sent.ID, DeleteAt: time.Now().Add(m.DeleteAfter)}
+	if err = d.schedule(ctx, deletion); err != nil {
+		slog.ErrorContext(ctx, "schedule message deletion", slog.Int64("chat_id", sent.Chat.ID), slog.Any("error", err))
+	}
+}
+
+// AutoDelete schedules the deletion of the message after d, e.g. for one-time passwords and
+// temporary notices. Pending deletions are kept in the store set with WithDeletionStore and
+// resumed on the next start, messages due while the bot was stopped are deleted right away.
+// Messages sent through the bot with Message.DeleteAfter are scheduled automatically.
+// sqlstore.Store implements a persistent DeletionStore.
+func AutoDelete(ctx context.Context, b *Bot, msg MessageRef, d time.Duration) error {
+	return b.autoDelete.schedule(ctx, PendingDeletion{ChatID: msg.ChatID, MessageID: msg.MessageID, DeleteAt: time.Now().Add(d)})
+}
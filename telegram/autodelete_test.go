@@ -0,0 +1,56 @@
+package telegram
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestAutoDelete(t *testing.T) {
+	deleted := make(chan string, 2)
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/deleteMessage") {
+			_ = r.ParseMultipartForm(1 << 20)
+			deleted <- r.FormValue("message_id")
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+	ctx := context.Background()
+	store := NewMemoryDeletionStore()
+	// due while the bot was stopped
+	_ = store.AddDeletion(ctx, PendingDeletion{ChatID: 1, MessageID: 10, DeleteAt: time.Now().Add(-time.Minute)})
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL), WithDeletionStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = app.autoDelete.start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = app.autoDelete.stop(ctx) }()
+
+	app.autoDelete.afterSend(ctx, &Message{DeleteAfter: 10 * time.Millisecond}, &models.Message{ID: 11, Chat: models.Chat{ID: 1}}, nil)
+	app.autoDelete.afterSend(ctx, &Message{}, &models.Message{ID: 12, Chat: models.Chat{ID: 1}}, nil)
+	if err = AutoDelete(ctx, app, MessageRef{ChatID: 1, MessageID: 13}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"10", "11"} {
+		select {
+		case id := <-deleted:
+			if id != want {
+				t.Errorf("deleted message %s, want %s", id, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("message %s was not deleted", want)
+		}
+	}
+	time.Sleep(10 * time.Millisecond) // the deletion is removed from the store after the call
+	pending, _ := store.PendingDeletions(ctx)
+	if len(pending) != 1 || pending[0].MessageID != 13 {
+		t.Errorf("unexpected pending deletions: %+v", pending)
+	}
+}
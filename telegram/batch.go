@@ -0,0 +1,135 @@
+package telegram
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/time/rate"
+)
+
+// SendResult is the outcome of a single message sent by SendMessages.
+type SendResult struct {
+	Message *models.Message // The sent message, nil if sending failed
+	Err     error           // Error of the send call
+}
+
+// batchOptions holds configuration for SendMessages.
+type batchOptions struct {
+	delay           time.Duration // Pause between two messages
+	rateLimiter     *rate.Limiter // Optional limiter waited on before every message
+	continueOnError bool          // Whether the remaining messages are sent after a failure
+}
+
+// BatchOption defines a function type for configuring SendMessages.
+type BatchOption func(*batchOptions)
+
+// WithBatchDelay sets the pause between two messages, e.g. to let a multi-part reply read
+// naturally. Defaults to no delay.
+func WithBatchDelay(delay time.Duration) BatchOption {
+	return func(o *batchOptions) {
+		o.delay = delay
+	}
+}
+
+// WithBatchRateLimiter waits on the limiter before every message.
+func WithBatchRateLimiter(limiter *rate.Limiter) BatchOption {
+	return func(o *batchOptions) {
+		o.rateLimiter = limiter
+	}
+}
+
+// WithBatchContinueOnError keeps sending the remaining messages after a failure instead of
+// stopping, which keeps the order of a multi-part reply intact.
+func WithBatchContinueOnError(continueOnError bool) BatchOption {
+	return func(o *batchOptions) {
+		o.continueOnError = continueOnError
+	}
+}
+
+// SendMessages sends the messages to the chat in order and returns the result of every
+// attempted message. Plain text messages longer than MaxMessageLength are split at line
+// breaks into several messages, the keyboard is attached to the last one; messages with a
+// ParseMode or Entities are sent as is, see RenderDocument for long formatted texts.
+// It stops at the first failure unless WithBatchContinueOnError is set and returns that
+// error, or the context error if the context is canceled.
+// Send hooks run for every message when the context carries them, see Bot.SendMessages.
+func SendMessages(ctx context.Context, b *bot.Bot, chatID int64, messages []*Message, options ...BatchOption) ([]SendResult, error) {
+	opts := &batchOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+	var chunks []*Message
+	for _, m := range messages {
+		chunks = append(chunks, chunkMessage(m)...)
+	}
+	results := make([]SendResult, 0, len(chunks))
+	var firstErr error
+	for i, m := range chunks {
+		if i > 0 && opts.delay > 0 {
+			timer := time.NewTimer(opts.delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return results, ctx.Err()
+			case <-timer.C:
+			}
+		}
+		if opts.rateLimiter != nil {
+			if err := opts.rateLimiter.Wait(ctx); err != nil {
+				return results, err
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		var msg *models.Message
+		err := runSendHooks(ctx, m, func() (*models.Message, error) {
+			sent, err := sendBusinessMessage(ctx, b, "", chatID, m)
+			msg = sent
+			return sent, err
+		})
+		results = append(results, SendResult{Message: msg, Err: err})
+		if err != nil {
+			if !opts.continueOnError {
+				return results, err
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return results, firstErr
+}
+
+// chunkMessage splits a plain text message exceeding MaxMessageLength at line breaks.
+func chunkMessage(m *Message) []*Message {
+	if m.Media != nil || m.ParseMode != "" || len(m.Entities) > 0 || utf16Len(m.Text) <= MaxMessageLength {
+		return []*Message{m}
+	}
+	var pieces []string
+	current := ""
+	for _, line := range strings.SplitAfter(m.Text, "\n") {
+		// a rune is at most two UTF-16 code units, so halved chunks always fit
+		for _, part := range splitText(line, MaxMessageLength/2) {
+			if current != "" && utf16Len(current)+utf16Len(part) > MaxMessageLength {
+				pieces = append(pieces, current)
+				current = ""
+			}
+			current += part
+		}
+	}
+	if current != "" {
+		pieces = append(pieces, current)
+	}
+	chunks := make([]*Message, len(pieces))
+	for i, piece := range pieces {
+		chunks[i] = &Message{Text: piece}
+	}
+	last := *m
+	last.Text = pieces[len(pieces)-1]
+	chunks[len(chunks)-1] = &last
+	return chunks
+}
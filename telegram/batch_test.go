@@ -0,0 +1,45 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestChunkMessage(t *testing.T) {
+	short := &Message{Text: "hello"}
+	if chunks := chunkMessage(short); len(chunks) != 1 || chunks[0] != short {
+		t.Fatalf("short message must not be split: %v", chunks)
+	}
+
+	line := strings.Repeat("a", 1000) + "\n"
+	m := &Message{
+		Text:   strings.Repeat(line, 10),
+		Button: [][]models.InlineKeyboardButton{{{Text: "ok", CallbackData: "ok"}}},
+	}
+	chunks := chunkMessage(m)
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d", len(chunks))
+	}
+	var joined strings.Builder
+	for i, chunk := range chunks {
+		if utf16Len(chunk.Text) > MaxMessageLength {
+			t.Errorf("chunk %d is too long: %d", i, utf16Len(chunk.Text))
+		}
+		if (len(chunk.Button) > 0) != (i == len(chunks)-1) {
+			t.Errorf("keyboard must only be attached to the last chunk, chunk %d", i)
+		}
+		joined.WriteString(chunk.Text)
+	}
+	if joined.String() != m.Text {
+		t.Error("chunks do not add up to the original text")
+	}
+
+	long := &Message{Text: strings.Repeat("😀", MaxMessageLength)}
+	for _, chunk := range chunkMessage(long) {
+		if utf16Len(chunk.Text) > MaxMessageLength {
+			t.Errorf("chunk without line breaks is too long: %d", utf16Len(chunk.Text))
+		}
+	}
+}
@@ -1,34 +1,66 @@
 package telegram
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 )
 
-// Config defines the configuration parameters for the Telegram bot.
-type Config struct {
-	Token string `json:"token" yaml:"token"`
-}
-
 // Bot represents a Telegram bot application with routing and middleware support.
 // It wraps the underlying bot client and provides additional functionality for
 // handling messages, authentication, and error processing.
 type Bot struct {
-	config Config
-	bot    *bot.Bot
+	mu         sync.RWMutex
+	config     Config
+	bot        *bot.Bot
+	botOptions []bot.Option       // Options used to (re)build the client
+	httpClient bot.HttpClient     // Client of the Bot API requests, shared with the polling loop
+	routes     []func(*bot.Bot)   // Handler registrations replayed on a rebuilt client
+	restart    context.CancelFunc // Stops the running update loop so it resumes with a new client
 
 	middlewares    []MiddlewareFunc
 	noRouteHandler bot.HandlerFunc
 	errorHandler   ErrorHandlerFunc
 	authExtractor  AuthExtractorFunc
+	sendHooks      *sendHooks
+	status         *botStatus
+	deadLetters    DeadLetterStore
+	events         *eventBus
+	prompts        *prompts
+	dataOptions    []DataOption
+	roleResolver   RoleResolver
+	commands       commandMenu
+	self           *ttlCache[struct{}, *models.User]
+
+	keepWebhook        bool
+	dropPendingOnStart *bool
+	lifecycle          lifecycle
+	admin              *adminNotifier
+	autoDelete         *autoDeleter
+	poller             *poller
+	webhook            webhookOptions
+	captures           captures
+	maintenance        maintenance
+	userData           userDataStores
 }
 
+// validateTokenTimeout bounds the getMe call validating the token at startup.
+const validateTokenTimeout = 10 * time.Second
+
 // NewApp creates a new Telegram bot application with the provided configuration and options.
 // It initializes the bot client, applies middleware, and sets up default handlers.
 // Returns an error if the bot token is invalid or client initialization fails.
+// The token is only checked against the Bot API with WithValidateToken.
 func NewApp(config Config, opts ...Option) (*Bot, error) {
 	opt := newOptions(opts...)
 	app := &Bot{
@@ -37,7 +69,112 @@ func NewApp(config Config, opts ...Option) (*Bot, error) {
 		noRouteHandler: opt.noRouteHandler,
 		errorHandler:   opt.errorHandler,
 		authExtractor:  opt.authExtractor,
+		sendHooks:      &opt.sendHooks,
+		status:         newBotStatus(),
+		deadLetters:    opt.deadLetters,
+		events:         newEventBus(),
+		prompts:        newPrompts(),
+		roleResolver:   opt.roleResolver,
+		self:           newTTLCache[struct{}, *models.User](opt.selfTTL),
+		maintenance:    maintenance{admins: opt.maintenanceAdmins},
+
+		keepWebhook:        opt.keepWebhook,
+		webhook:            opt.webhook,
+		dropPendingOnStart: opt.dropPendingOnStart,
+	}
+	if opt.callbackCodec != nil {
+		app.dataOptions = append(app.dataOptions, WithCodec(opt.callbackCodec))
+	}
+	if opt.signingKey != nil {
+		app.dataOptions = append(app.dataOptions, signWith(opt.signingKey))
+	}
+	if len(opt.errorChain) > 0 {
+		app.errorHandler = WithErrorMiddlewares(app.errorHandler, opt.errorChain...)
+	}
+	if opt.errorReporter != nil {
+		app.errorHandler = withErrorReporter(opt.errorReporter, app.errorHandler)
+	}
+	if opt.deadLetters != nil {
+		app.errorHandler = withDeadLetters(opt.deadLetters, app.errorHandler)
+	}
+	if opt.adminChatID != 0 {
+		app.admin = newAdminNotifier(opt.adminChatID, func(ctx context.Context, text string) error {
+			_, err := app.API().SendMessage(ctx, &bot.SendMessageParams{ChatID: opt.adminChatID, Text: text})
+			return err
+		}, opt.adminOptions...)
+		app.errorHandler = withAdminNotifier(app.admin, app.errorHandler)
+		app.OnStart(app.admin.start)
+		app.OnStop(app.admin.stop)
+	}
+	app.userData.add(opt.userDataStores...)
+	app.autoDelete = newAutoDeleter(app, opt.deletionStore)
+	app.sendHooks.after = append(app.sendHooks.after, app.autoDelete.afterSend)
+	app.OnStart(app.autoDelete.start)
+	app.OnStop(app.autoDelete.stop)
+	if opt.privacy != nil {
+		app.errorHandler = withPrivacyPolicy(opt.privacy, app.errorHandler)
 	}
+	app.errorHandler = withUpdateResult(app.errorHandler)
+	app.errorHandler = app.withTokenRedaction(app.errorHandler)
+	handleError := func(ctx context.Context, bot *bot.Bot, update *Update, err error) {
+		if app.errorHandler != nil {
+			app.errorHandler(ctx, bot, update, err)
+		}
+	}
+	// recovery must be the outermost middleware, after the one signaling HandleUpdate, so it
+	// also covers middlewares added by AppendBotOptions
+	recovery := bot.WithMiddlewares(NewRecoveryMiddleware(
+		WithRecoveryReporter(opt.panicReporter),
+		WithRecoveryErrorHandler(handleError),
+	))
+	updateContext := bot.WithMiddlewares(newUpdateContextMiddleware(opt.baseContext, opt.updateTimeout))
+	hooks := bot.WithMiddlewares(newSendHooksMiddleware(app.sendHooks))
+	status := bot.WithMiddlewares(app.status.middleware())
+	events := bot.WithMiddlewares(app.events.middleware())
+	prompts := bot.WithMiddlewares(app.prompts.middleware())
+	updateHooks := bot.WithMiddlewares(app.lifecycle.middleware(func() ErrorHandlerFunc {
+		return app.errorHandler
+	}))
+	capture := bot.WithMiddlewares(app.captures.middleware())
+	result := bot.WithMiddlewares(newUpdateResultMiddleware())
+	internal := []bot.Option{result, recovery, status, capture, updateContext, hooks, updateHooks}
+	if opt.privacy != nil {
+		internal = append(internal, bot.WithMiddlewares(newPrivacyMiddleware(opt.privacy)))
+	}
+	if opt.signingKey != nil {
+		// forged callbacks must neither reach subscribers nor answer prompts
+		internal = append(internal, bot.WithMiddlewares(newCallbackSigningMiddleware(opt.signingKey)))
+	}
+	internal = append(internal, bot.WithMiddlewares(app.maintenance.middleware()))
+	if opt.contentFilter != nil {
+		internal = append(internal, bot.WithMiddlewares(NewBotMiddleware(newContentFilterMiddleware(app, opt.contentFilter), handleError)))
+	}
+	for _, middleware := range opt.updateMiddlewares {
+		internal = append(internal, bot.WithMiddlewares(NewBotMiddleware(middleware, handleError)))
+	}
+	internal = append(internal, events, prompts)
+	opt.botOptions = append(internal, opt.botOptions...)
+	if opt.allowedUpdates != nil {
+		app.config.AllowedUpdates = opt.allowedUpdates
+	}
+	if opt.dropPendingUpdates != nil {
+		app.config.DropPendingUpdates = *opt.dropPendingUpdates
+	}
+	opt.botOptions = append(app.config.botOptions(), opt.botOptions...)
+	if endpoint := cmp.Or(opt.apiServer, config.APIEndpoint); endpoint != "" {
+		opt.botOptions = append([]bot.Option{bot.WithServerURL(strings.TrimSuffix(endpoint, "/"))}, opt.botOptions...)
+	}
+	if opt.metrics != nil {
+		app.middlewares = append([]MiddlewareFunc{newMetricsMiddleware(opt.metrics)}, app.middlewares...)
+	}
+	httpClient, pollTimeout, err := newHTTPClient(&opt.transport, config.Proxy, opt.metrics)
+	if err != nil {
+		return nil, err
+	}
+	app.httpClient = httpClient
+	opt.botOptions = append([]bot.Option{bot.WithHTTPClient(pollTimeout, httpClient)}, opt.botOptions...)
+	server := strings.TrimSuffix(cmp.Or(opt.apiServer, config.APIEndpoint, defaultAPIServer), "/")
+	app.poller = newPoller(opt.polling, server, httpClient, pollTimeout)
 	opt.botOptions = append(opt.botOptions,
 		bot.WithDefaultHandler(
 			func(ctx context.Context, bot *bot.Bot, update *models.Update) {
@@ -45,44 +182,226 @@ func NewApp(config Config, opts ...Option) (*Bot, error) {
 			},
 		),
 	)
-	app.middlewares = append(app.middlewares,
-		NewAuthMiddleware(
-			AuthExtractorFunc(func(ctx context.Context, update *Update) (map[string]any, error) {
-				return app.authExtractor(ctx, update)
-			}),
-		),
-	)
+	if opt.noRoute != nil {
+		app.BindNoRoute(opt.noRoute, opt.noRouteMiddlewares...)
+	}
 	client, err := bot.New(config.Token, opt.botOptions...)
 	if err != nil {
 		return nil, err
 	}
 	app.bot = client
+	app.botOptions = opt.botOptions
+	if opt.validateToken {
+		ctx, cancel := context.WithTimeout(context.Background(), validateTokenTimeout)
+		defer cancel()
+		me, err := client.GetMe(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("validate bot token: %w", err)
+		}
+		app.self.set(struct{}{}, me)
+	}
 	return app, nil
 }
 
 // Update applies additional configuration options to the underlying bot client.
+// The options are kept and applied again when the client is rebuilt by SetToken.
 func (b *Bot) Update(options ...bot.Option) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.botOptions = append(b.botOptions, options...)
 	for _, opt := range options {
 		opt(b.bot)
 	}
 }
 
 // API returns the underlying Telegram bot client for direct API access.
+// The client changes when the token is rotated with SetToken, so it should not be cached.
 func (b *Bot) API() *bot.Bot {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 	return b.bot
 }
 
+// SetToken replaces the bot token at runtime. The new token is verified with getMe, then
+// the underlying client is rebuilt with the same options and handlers, and a running
+// polling or webhook loop resumes with it, so credentials can be rotated without a restart.
+// Components constructed with the previous client from API() keep using the old token.
+func (b *Bot) SetToken(ctx context.Context, token string) error {
+	b.mu.RLock()
+	options := b.botOptions
+	b.mu.RUnlock()
+	client, err := bot.New(token, options...)
+	if err != nil {
+		return err
+	}
+	me, err := client.GetMe(ctx)
+	if err != nil {
+		return err
+	}
+	b.self.set(struct{}{}, me)
+	b.mu.Lock()
+	for _, route := range b.routes {
+		route(client)
+	}
+	b.bot = client
+	b.config.Token = token
+	restart := b.restart
+	b.mu.Unlock()
+	if restart != nil {
+		restart()
+	}
+	return nil
+}
+
+// Self returns the bot's own user, calling getMe once per WithSelfTTL and sharing the
+// result with the group message filter and deep link helpers.
+func (b *Bot) Self(ctx context.Context) (*models.User, error) {
+	return b.self.get(struct{}{}, "getMe", func() (*models.User, error) {
+		client := b.API()
+		if client == nil {
+			return nil, errBotClosed
+		}
+		return client.GetMe(ctx)
+	})
+}
+
+func (b *Bot) currentConfig() Config {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.config
+}
+
+// Run starts the bot in the mode selected by Config.Mode.
+// In webhook mode WebhookHandler must be served by the caller, unless Config.Webhook.CertFile
+// is set, then it is served over TLS with StartWebhookTLS.
+func (b *Bot) Run(ctx context.Context) error {
+	config := b.currentConfig()
+	if config.Mode == ModeWebhook {
+		if config.Webhook.CertFile != "" {
+			return b.StartWebhookTLS(ctx, config.Webhook.CertFile, config.Webhook.KeyFile)
+		}
+		return b.StartWebhook(ctx)
+	}
+	return b.Start(ctx)
+}
+
+// SetWebhook registers Config.Webhook with Telegram, together with the allowed updates
+// and the drop pending updates setting. With Config.Webhook.SelfSigned the certificate
+// Config.Webhook.CertFile is uploaded, so Telegram trusts it.
+func (b *Bot) SetWebhook(ctx context.Context) error {
+	return b.setWebhook(ctx, b.currentConfig().Webhook.CertFile)
+}
+
+func (b *Bot) setWebhook(ctx context.Context, certFile string) error {
+	config := b.currentConfig()
+	params := &bot.SetWebhookParams{
+		URL:                config.Webhook.URL,
+		MaxConnections:     config.Webhook.MaxConnections,
+		AllowedUpdates:     config.AllowedUpdates,
+		DropPendingUpdates: config.DropPendingUpdates,
+		SecretToken:        config.Webhook.SecretToken,
+	}
+	if config.Webhook.SelfSigned && certFile != "" {
+		cert, err := os.ReadFile(certFile)
+		if err != nil {
+			return fmt.Errorf("read webhook certificate: %w", err)
+		}
+		params.Certificate = NewBytesInputFile(filepath.Base(certFile), cert)
+	}
+	_, err := b.API().SetWebhook(ctx, params)
+	return err
+}
+
+// ErrWebhookActive is returned by Start with WithKeepWebhook when a webhook is registered,
+// since Telegram does not deliver updates by polling while a webhook is set.
+var ErrWebhookActive = errors.New("webhook is active")
+
 // Start begins the bot's update polling and message processing.
-// It removes any existing webhook and starts listening for updates using long polling.
+// It removes any existing webhook, dropping pending updates if configured with
+// WithDropPendingUpdates or WithDropPendingOnStart, and starts listening for updates using
+// long polling. With WithKeepWebhook an existing webhook is left in place and Start fails
+// with ErrWebhookActive instead. Errors of the webhook calls are returned.
+// Failed getUpdates calls are retried with exponential backoff, see WithPollingBackoff and
+// WithPollingHealthHook, only a rejected token ends polling with an error.
 func (b *Bot) Start(ctx context.Context) error {
-	_, _ = b.bot.DeleteWebhook(context.Background(), &bot.DeleteWebhookParams{})
-	b.bot.Start(ctx)
-	return nil
+	drop := b.currentConfig().DropPendingUpdates
+	if b.dropPendingOnStart != nil {
+		drop = *b.dropPendingOnStart
+	}
+	return b.run(ctx, ModePolling, func(ctx context.Context, client *bot.Bot) error {
+		if b.keepWebhook {
+			info, err := client.GetWebhookInfo(ctx)
+			if err != nil {
+				return fmt.Errorf("get webhook info: %w", err)
+			}
+			if info.URL != "" {
+				return fmt.Errorf("%w: %s", ErrWebhookActive, info.URL)
+			}
+		} else if _, err := client.DeleteWebhook(ctx, &bot.DeleteWebhookParams{DropPendingUpdates: drop}); err != nil {
+			return fmt.Errorf("delete webhook: %w", err)
+		}
+		return b.poller.run(ctx, client, b.currentConfig().AllowedUpdates)
+	})
+}
+
+// StartWebhook begins processing updates delivered to WebhookHandler, served by the caller,
+// see StartWebhookTLS to serve it directly.
+// When Config.Webhook.URL is set the webhook is registered first with the allowed updates
+// and drop pending updates settings, otherwise it must be registered beforehand.
+// The webhook is registered again for the new client when the token is rotated.
+func (b *Bot) StartWebhook(ctx context.Context) error {
+	return b.startWebhook(ctx, b.currentConfig().Webhook.CertFile)
+}
+
+func (b *Bot) startWebhook(ctx context.Context, certFile string) error {
+	return b.run(ctx, ModeWebhook, func(ctx context.Context, client *bot.Bot) error {
+		if b.currentConfig().Webhook.URL != "" {
+			if err := b.setWebhook(ctx, certFile); err != nil {
+				return err
+			}
+		}
+		client.StartWebhook(ctx)
+		return nil
+	})
+}
+
+// run executes the update loop until ctx is done, restarting it with the new client
+// whenever the token is rotated. Errors returned by start end the loop. The lifecycle hooks
+// run before and after the loop.
+func (b *Bot) run(ctx context.Context, mode string, start func(context.Context, *bot.Bot) error) error {
+	if err := b.lifecycle.runStart(ctx); err != nil {
+		return err
+	}
+	b.status.mode.Store(mode)
+	defer b.status.mode.Store("")
+	err := b.loop(ctx, start)
+	return errors.Join(err, b.lifecycle.runStop(context.WithoutCancel(ctx)))
+}
+
+func (b *Bot) loop(ctx context.Context, start func(context.Context, *bot.Bot) error) error {
+	for {
+		runCtx, cancel := context.WithCancel(ctx)
+		b.mu.Lock()
+		client := b.bot
+		b.restart = cancel
+		b.mu.Unlock()
+		err := start(runCtx, client)
+		restarted := runCtx.Err() != nil && ctx.Err() == nil
+		cancel()
+		if err != nil && !restarted {
+			return err
+		}
+		if !restarted {
+			return nil
+		}
+	}
 }
 
 // Close gracefully shuts down the bot and releases resources.
 // It stops the update polling and closes the underlying bot client connection.
 func (b *Bot) Close(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 	_, err := b.bot.Close(ctx)
 	b.bot = nil
 	return err
@@ -90,12 +409,42 @@ func (b *Bot) Close(ctx context.Context) error {
 
 // SendMessage sends a message in response to an update using the bot's client.
 func (b *Bot) SendMessage(ctx context.Context, update *Update, m *Message) error {
-	return SendMessage(ctx, b.bot, update, m)
+	return SendMessage(contextWithSendHooks(ctx, b.sendHooks), b.API(), update, m)
+}
+
+// SendTo sends the message to the chat using the bot's client, running the send hooks
+// also outside of update processing, e.g. from a Scheduler job.
+func (b *Bot) SendTo(ctx context.Context, chatID int64, m *Message) (MessageRef, error) {
+	return SendTo(contextWithSendHooks(ctx, b.sendHooks), b.API(), chatID, m)
+}
+
+// EditTo edits the message using the bot's client, running the send hooks also outside
+// of update processing.
+func (b *Bot) EditTo(ctx context.Context, chatID int64, messageID int, m *Message) error {
+	return EditTo(contextWithSendHooks(ctx, b.sendHooks), b.API(), chatID, messageID, m)
+}
+
+// SendMessages sends the messages to the chat using the bot's client, running the send hooks
+// also outside of update processing.
+func (b *Bot) SendMessages(ctx context.Context, chatID int64, messages []*Message, options ...BatchOption) ([]SendResult, error) {
+	return SendMessages(contextWithSendHooks(ctx, b.sendHooks), b.API(), chatID, messages, options...)
+}
+
+// register applies the handler registration to the client and records it,
+// so it can be replayed when the client is rebuilt.
+func (b *Bot) register(route func(client *bot.Bot)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.routes = append(b.routes, route)
+	route(b.bot)
 }
 
 func (b *Bot) appendMiddlewares(middlewares ...MiddlewareFunc) []MiddlewareFunc {
-	mid := make([]MiddlewareFunc, 0, len(middlewares)+len(b.middlewares))
+	mid := make([]MiddlewareFunc, 0, len(middlewares)+len(b.middlewares)+1)
 	mid = append(mid, b.middlewares...)
+	if b.authExtractor != nil && !slices.ContainsFunc(middlewares, isSkipAuth) {
+		mid = append(mid, NewAuthMiddleware(b.authExtractor))
+	}
 	mid = append(mid, middlewares...)
 	return mid
 }
@@ -111,14 +460,27 @@ func (b *Bot) BindNoRoute(handlerFunc HandlerFunc, middlewares ...MiddlewareFunc
 func (b *Bot) BindCommand(command string, handlerFunc HandlerFunc, middlewares ...MiddlewareFunc) {
 	fn := WithMiddleware(handlerFunc, b.errorHandler, b.appendMiddlewares(middlewares...)...)
 	command = "/" + strings.TrimPrefix(command, "/")
-	b.bot.RegisterHandler(bot.HandlerTypeMessageText, command, bot.MatchTypePrefix, fn)
+	b.register(func(client *bot.Bot) {
+		client.RegisterHandler(bot.HandlerTypeMessageText, command, bot.MatchTypePrefix, fn)
+	})
 }
 
 // BindCallback registers a handler for callback query data with a specific route prefix.
 // The route is used as a prefix for matching callback query data (e.g., "menu:" matches "menu:item1").
 func (b *Bot) BindCallback(route string, handlerFunc HandlerFunc, middlewares ...MiddlewareFunc) {
 	fn := WithMiddleware(handlerFunc, b.errorHandler, b.appendMiddlewares(middlewares...)...)
-	b.bot.RegisterHandler(bot.HandlerTypeCallbackQueryData, route+":", bot.MatchTypePrefix, fn)
+	b.register(func(client *bot.Bot) {
+		client.RegisterHandler(bot.HandlerTypeCallbackQueryData, route+":", bot.MatchTypePrefix, fn)
+	})
+}
+
+// BindMatch registers a handler for updates accepted by the match function.
+// It is the building block for routes that can not be expressed as a text or callback prefix.
+func (b *Bot) BindMatch(match func(update *Update) bool, handlerFunc HandlerFunc, middlewares ...MiddlewareFunc) {
+	fn := WithMiddleware(handlerFunc, b.errorHandler, b.appendMiddlewares(middlewares...)...)
+	b.register(func(client *bot.Bot) {
+		client.RegisterHandlerMatchFunc(match, fn)
+	})
 }
 
 // MessageSender defines a function that sends messages in response to updates.
@@ -131,15 +493,75 @@ type RouteMap = map[string]func(ctx context.Context, request *Update) error
 type RouteMapBuilder[S any, D any] = func(srv S, codec D, sender MessageSender) RouteMap
 
 // BindRoute registers multiple handlers based on a route map and method metadata.
-// It automatically binds commands and callback queries based on the provided operation metadata.
-func (b *Bot) BindRoute(route RouteMap, extra func(string) *MethodExtraData, operations []string, middlewares ...MiddlewareFunc) {
-	for _, operation := range operations {
+// It automatically binds commands, callback queries and inline queries based on the provided
+// operation metadata, and records described commands for SyncCommands.
+// Operations declaring roles are guarded by NewRoleMiddleware with the resolver set by
+// WithRoleResolver. Operation middlewares run after the given middlewares, and operations
+// scoped to chat types only match updates from those chats, other updates fall through to
+// the remaining handlers.
+// Nothing is registered if an operation has no handler, no metadata or nothing to bind to,
+// the returned error lists those operations instead.
+func (b *Bot) BindRoute(route RouteMap, extra func(string) *MethodExtraData, operations []string, middlewares ...MiddlewareFunc) error {
+	var unbound []string
+	var errs []error
+	infos := make([]*MethodExtraData, len(operations))
+	for i, operation := range operations {
 		info := extra(operation)
+		infos[i] = info
+		if route[operation] == nil || info == nil || (info.Command == "" && info.CallbackQuery == "" && info.InlineQuery == "") {
+			unbound = append(unbound, operation)
+			continue
+		}
+		if len(info.Roles) > 0 && b.roleResolver == nil {
+			errs = append(errs, fmt.Errorf("operation %s requires roles but no role resolver is set", operation))
+		}
+		for _, scope := range info.Scopes {
+			if _, err := commandScope(scope); err != nil {
+				errs = append(errs, fmt.Errorf("operation %s: %w", operation, err))
+			}
+		}
+	}
+	if len(unbound) > 0 {
+		errs = append([]error{fmt.Errorf("unbound operations: %s", strings.Join(unbound, ", "))}, errs...)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	for i, operation := range operations {
+		info := infos[i]
+		mid := slices.Clip(middlewares)
+		if len(info.Roles) > 0 {
+			mid = append(mid, NewRoleMiddleware(b.roleResolver, info.Roles...))
+		}
+		mid = append(mid, info.Middlewares...)
 		if info.Command != "" {
-			b.BindCommand(info.Command, route[operation], middlewares...)
+			if len(info.ChatTypes) == 0 {
+				b.BindCommand(info.Command, route[operation], mid...)
+			} else {
+				command := "/" + strings.TrimPrefix(info.Command, "/")
+				b.BindMatch(func(update *Update) bool {
+					return update.Message != nil && strings.HasPrefix(update.Message.Text, command) && info.AllowsChat(update)
+				}, route[operation], mid...)
+			}
+			b.mu.Lock()
+			b.commands.add(info)
+			b.mu.Unlock()
 		}
 		if info.CallbackQuery != "" {
-			b.BindCallback(info.CallbackQuery, route[operation], middlewares...)
+			if len(info.ChatTypes) == 0 {
+				b.BindCallback(info.CallbackQuery, route[operation], mid...)
+			} else {
+				prefix := info.CallbackQuery + ":"
+				b.BindMatch(func(update *Update) bool {
+					return update.CallbackQuery != nil && strings.HasPrefix(update.CallbackQuery.Data, prefix) && info.AllowsChat(update)
+				}, route[operation], mid...)
+			}
+		}
+		if info.InlineQuery != "" {
+			b.BindMatch(func(update *Update) bool {
+				return update.InlineQuery != nil && strings.HasPrefix(update.InlineQuery.Query, info.InlineQuery)
+			}, route[operation], mid...)
 		}
 	}
+	return nil
 }
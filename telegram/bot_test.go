@@ -0,0 +1,240 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+func newTestAPIServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewAppValidateToken(t *testing.T) {
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "bad-token") {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":401,"description":"Unauthorized"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"id":7,"is_bot":true,"username":"demo_bot"}}`))
+	})
+	if _, err := NewApp(Config{Token: "bad-token"}, WithAPIServer(server.URL), WithValidateToken(true)); err == nil {
+		t.Error("expected an invalid token error")
+	}
+	app, err := NewApp(Config{Token: "good-token"}, WithAPIServer(server.URL), WithValidateToken(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	me, err := app.Self(context.Background())
+	if err != nil || me.Username != "demo_bot" {
+		t.Errorf("unexpected identity %+v, %v", me, err)
+	}
+}
+
+func TestStartWebhookHandling(t *testing.T) {
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/getWebhookInfo"):
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"url":"https://example.com/hook","pending_update_count":0}}`))
+		case strings.HasSuffix(r.URL.Path, "/deleteWebhook"):
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":401,"description":"Unauthorized"}`))
+		}
+	})
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL), WithKeepWebhook(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = app.Start(context.Background()); !errors.Is(err, ErrWebhookActive) {
+		t.Errorf("expected ErrWebhookActive, got %v", err)
+	}
+	app, err = NewApp(Config{Token: "token"}, WithAPIServer(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = app.Start(context.Background()); err == nil || !strings.Contains(err.Error(), "delete webhook") {
+		t.Errorf("expected delete webhook error, got %v", err)
+	}
+}
+
+func TestLifecycleHooks(t *testing.T) {
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls []string
+	startErr := errors.New("start failed")
+	app.OnStart(func(ctx context.Context) error {
+		calls = append(calls, "start")
+		return startErr
+	})
+	app.OnStop(func(ctx context.Context) error {
+		calls = append(calls, "stop")
+		return nil
+	})
+	if err = app.Start(context.Background()); !errors.Is(err, startErr) {
+		t.Errorf("expected start hook error, got %v", err)
+	}
+	if len(calls) != 1 {
+		t.Errorf("stop hooks must not run when the start failed: %v", calls)
+	}
+
+	var l lifecycle
+	hookErr := errors.New("rejected")
+	l.updates = append(l.updates, func(ctx context.Context, update *Update) error { return hookErr })
+	var handled error
+	routed := false
+	l.middleware(func() ErrorHandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *Update, err error) { handled = err }
+	})(func(ctx context.Context, b *bot.Bot, update *models.Update) { routed = true })(context.Background(), nil, &Update{})
+	if routed || !errors.Is(handled, hookErr) {
+		t.Errorf("update hook error is not propagated: routed=%v, err=%v", routed, handled)
+	}
+}
+
+func TestSetToken(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		paths []string
+	)
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		if strings.HasPrefix(r.URL.Path, "/botrevoked/") {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":401,"description":"Unauthorized"}`))
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/getMe") {
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"bot","username":"test_bot"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":2,"date":1,"chat":{"id":1,"type":"private"}}}`))
+	})
+	app, err := NewApp(Config{Token: "old"}, WithAPIServer(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.BindCommand("ping", func(ctx context.Context, update *Update) error {
+		return app.SendMessage(ctx, update, &Message{Text: "pong"})
+	})
+	ctx := context.Background()
+	if err = app.SetToken(ctx, "revoked"); err == nil {
+		t.Fatal("a rejected token must not be applied")
+	}
+	if err = app.SetToken(ctx, "new"); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	paths = nil
+	mu.Unlock()
+	// the handlers are registered on the rebuilt client, which calls the API with the new token
+	body := `{"update_id":1,"message":{"message_id":1,"date":1,"chat":{"id":1,"type":"private"},"text":"/ping"}}`
+	if err = app.HandleUpdateJSON(ctx, []byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(paths) != 1 || paths[0] != "/botnew/sendMessage" {
+		t.Errorf("unexpected API calls: %v", paths)
+	}
+	if app.currentConfig().Token != "new" {
+		t.Errorf("config token was not updated")
+	}
+}
+
+func TestUpdateOptions(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		drop    []string
+		allowed []string // allowed updates of each getUpdates call
+		webhook map[string]string
+	)
+	polled := make(chan struct{}, 1)
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/deleteWebhook"):
+			drop = append(drop, r.FormValue("drop_pending_updates"))
+		case strings.HasSuffix(r.URL.Path, "/setWebhook"):
+			webhook = map[string]string{
+				"allowed_updates":      r.FormValue("allowed_updates"),
+				"drop_pending_updates": r.FormValue("drop_pending_updates"),
+			}
+		case strings.HasSuffix(r.URL.Path, "/getUpdates"):
+			var params struct {
+				AllowedUpdates []string `json:"allowed_updates"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&params)
+			allowed = append(allowed, strings.Join(params.AllowedUpdates, "+"))
+			select {
+			case polled <- struct{}{}:
+			default:
+			}
+			_, _ = w.Write([]byte(`{"ok":true,"result":[]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+	config := Config{Token: "token", AllowedUpdates: []string{"message"}}
+	config.Webhook.URL = "https://example.com/hook"
+	newApp := func(options ...Option) *Bot {
+		app, err := NewApp(config, append([]Option{WithAPIServer(server.URL)}, options...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return app
+	}
+	start := func(app *Bot) {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- app.Start(ctx) }()
+		select {
+		case <-polled:
+		case <-time.After(2 * time.Second):
+			t.Fatal("polling did not start")
+		}
+		cancel()
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app := newApp(WithAllowedUpdates("message", "callback_query"), WithDropPendingUpdates(true))
+	start(app)
+	if err := app.SetWebhook(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	start(newApp(WithDropPendingUpdates(true), WithDropPendingOnStart(false)))
+
+	mu.Lock()
+	defer mu.Unlock()
+	// false is omitted from the request
+	if strings.Join(drop, ",") != "true," {
+		t.Errorf("unexpected drop_pending_updates on start: %v", drop)
+	}
+	if len(allowed) < 2 || allowed[0] != "message+callback_query" || allowed[len(allowed)-1] != "message" {
+		t.Errorf("unexpected allowed updates: %v", allowed)
+	}
+	if webhook["allowed_updates"] != `["message","callback_query"]` || webhook["drop_pending_updates"] != "true" {
+		t.Errorf("unexpected setWebhook params: %v", webhook)
+	}
+}
@@ -0,0 +1,162 @@
+package telegram
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+)
+
+// Broadcast result statuses.
+const (
+	BroadcastSent   = "sent"
+	BroadcastFailed = "failed"
+)
+
+// Error classes of failed broadcast sends, see ErrorClass.
+const (
+	ErrorClassRateLimited  = "rate_limited"
+	ErrorClassForbidden    = "forbidden"
+	ErrorClassBadRequest   = "bad_request"
+	ErrorClassNotFound     = "not_found"
+	ErrorClassCanceled     = "canceled"
+	ErrorClassUnauthorized = "unauthorized"
+	ErrorClassOther        = "other"
+)
+
+// ErrorClass classifies a Bot API error, e.g. to tell recipients who blocked the bot
+// (ErrorClassForbidden) from transient failures worth resending. It returns an empty
+// string for nil.
+func ErrorClass(err error) string {
+	var tooManyRequestsError *bot.TooManyRequestsError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &tooManyRequestsError), errors.Is(err, bot.ErrorTooManyRequests):
+		return ErrorClassRateLimited
+	case errors.Is(err, bot.ErrorForbidden):
+		return ErrorClassForbidden
+	case errors.Is(err, bot.ErrorBadRequest):
+		return ErrorClassBadRequest
+	case errors.Is(err, bot.ErrorNotFound):
+		return ErrorClassNotFound
+	case errors.Is(err, bot.ErrorUnauthorized):
+		return ErrorClassUnauthorized
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassCanceled
+	default:
+		return ErrorClassOther
+	}
+}
+
+// BroadcastResult is the outcome of the broadcast to a single recipient.
+type BroadcastResult struct {
+	Recipient  any           `json:"recipient"`             // The broadcast data item
+	Status     string        `json:"status"`                // BroadcastSent or BroadcastFailed
+	ErrorClass string        `json:"error_class,omitempty"` // Class of the error, see ErrorClass
+	Error      string        `json:"error,omitempty"`       // Error message of the last attempt
+	Retries    int           `json:"retries"`               // Retries after rate limit errors, see WithBroadcastRetries
+	Duration   time.Duration `json:"duration"`              // Time spent sending, including retries
+}
+
+// BroadcastReport collects the per-recipient results of a broadcast, see WithBroadcastReport.
+// It is safe for concurrent use.
+type BroadcastReport struct {
+	mu      sync.Mutex
+	results []BroadcastResult
+}
+
+func (r *BroadcastReport) add(result BroadcastResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = append(r.results, result)
+}
+
+// Results returns the collected results in completion order.
+func (r *BroadcastReport) Results() []BroadcastResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]BroadcastResult(nil), r.results...)
+}
+
+// Failed returns the recipients whose send failed, e.g. to resend to them only.
+func (r *BroadcastReport) Failed() []any {
+	var failed []any
+	for _, result := range r.Results() {
+		if result.Status == BroadcastFailed {
+			failed = append(failed, result.Recipient)
+		}
+	}
+	return failed
+}
+
+// WriteJSON writes the results as a JSON array.
+func (r *BroadcastReport) WriteJSON(w io.Writer) error {
+	results := r.Results()
+	if results == nil {
+		results = []BroadcastResult{}
+	}
+	return json.NewEncoder(w).Encode(results)
+}
+
+// WriteCSV writes the results as CSV with a header row. Recipients are formatted with
+// fmt.Sprint and durations in milliseconds.
+func (r *BroadcastReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"recipient", "status", "error_class", "error", "retries", "duration_ms"})
+	for _, result := range r.Results() {
+		_ = cw.Write([]string{
+			fmt.Sprint(result.Recipient),
+			result.Status,
+			result.ErrorClass,
+			result.Error,
+			strconv.Itoa(result.Retries),
+			strconv.FormatInt(result.Duration.Milliseconds(), 10),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// reportingSend wraps the send function of a broadcast to retry rate limited sends and
+// record every outcome into the report.
+func reportingSend[T any](opts *broadcastOptions, send func(context.Context, *bot.Bot, T) error) func(context.Context, *bot.Bot, T) error {
+	if opts.report == nil && opts.retries == 0 {
+		return send
+	}
+	return func(ctx context.Context, b *bot.Bot, item T) error {
+		start := time.Now()
+		attempts := 0
+		var lastErr error
+		err := RetryOnTooManyRequestsError(opts.retries, func() error {
+			attempts++
+			lastErr = send(ctx, b, item)
+			return lastErr
+		})
+		if err != nil && lastErr != nil {
+			err = lastErr
+		}
+		if opts.report != nil {
+			result := BroadcastResult{
+				Recipient: item,
+				Status:    BroadcastSent,
+				Retries:   attempts - 1,
+				Duration:  time.Since(start),
+			}
+			if err != nil {
+				result.Status = BroadcastFailed
+				result.ErrorClass = ErrorClass(err)
+				result.Error = err.Error()
+			}
+			opts.report.add(result)
+		}
+		return err
+	}
+}
@@ -0,0 +1,62 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram/bot"
+	"golang.org/x/time/rate"
+)
+
+func TestBroadcastReport(t *testing.T) {
+	report := &BroadcastReport{}
+	limited := false
+	err := BroadcastMessage(context.Background(), nil, []int64{1, 2, 3}, rate.NewLimiter(rate.Inf, 1), func(ctx context.Context, b *bot.Bot, id int64) error {
+		switch {
+		case id == 2:
+			return fmt.Errorf("%w, Forbidden: bot was blocked by the user", bot.ErrorForbidden)
+		case id == 3 && !limited:
+			limited = true
+			return &bot.TooManyRequestsError{Message: "too many requests", RetryAfter: 0}
+		}
+		return nil
+	}, WithBroadcastReport(report), WithBroadcastRetries(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := report.Results()
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if results[1].Status != BroadcastFailed || results[1].ErrorClass != ErrorClassForbidden {
+		t.Errorf("unexpected failure result: %+v", results[1])
+	}
+	if results[2].Status != BroadcastSent || results[2].Retries != 1 {
+		t.Errorf("unexpected retried result: %+v", results[2])
+	}
+	if failed := report.Failed(); len(failed) != 1 || failed[0] != int64(2) {
+		t.Errorf("unexpected failed recipients: %v", failed)
+	}
+
+	var buf bytes.Buffer
+	if err = report.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []map[string]any
+	if err = json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded) != 3 {
+		t.Errorf("invalid JSON export: %v, %s", err, buf.String())
+	}
+	buf.Reset()
+	if err = report.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[2], "2,failed,forbidden,") {
+		t.Errorf("unexpected CSV export: %q", lines)
+	}
+}
@@ -0,0 +1,90 @@
+package telegram
+
+import (
+	"context"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// BusinessConnectionID returns the business connection the update belongs to, or an empty string.
+func BusinessConnectionID(update *Update) string {
+	switch {
+	case update == nil:
+		return ""
+	case update.BusinessMessage != nil:
+		return update.BusinessMessage.BusinessConnectionID
+	case update.EditedBusinessMessage != nil:
+		return update.EditedBusinessMessage.BusinessConnectionID
+	case update.DeletedBusinessMessages != nil:
+		return update.DeletedBusinessMessages.BusinessConnectionID
+	case update.BusinessConnection != nil:
+		return update.BusinessConnection.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.Message.Message != nil:
+		return update.CallbackQuery.Message.Message.BusinessConnectionID
+	default:
+		return ""
+	}
+}
+
+// SendBusinessMessage sends a message on behalf of the business account of the given connection.
+// The function automatically chooses between text and photo messages based on media presence.
+func SendBusinessMessage(ctx context.Context, b *bot.Bot, connectionID string, chatID int64, m *Message) error {
+	if m == nil {
+		return nil
+	}
+	return runSendHooks(ctx, m, func() (*models.Message, error) {
+		return sendBusinessMessage(ctx, b, connectionID, chatID, m)
+	})
+}
+
+func sendBusinessMessage(ctx context.Context, b *bot.Bot, connectionID string, chatID int64, m *Message) (*models.Message, error) {
+	switch {
+	case m.Media != nil && m.MediaKind == MediaVoice:
+		param := m.toSendVoiceParams(chatID)
+		param.BusinessConnectionID = connectionID
+		return b.SendVoice(ctx, param)
+	case m.Media != nil && m.MediaKind == MediaVideoNote:
+		param := m.toSendVideoNoteParams(chatID)
+		param.BusinessConnectionID = connectionID
+		return b.SendVideoNote(ctx, param)
+	}
+	if m.Media == nil {
+		param := m.toSendMessageParams(chatID)
+		param.BusinessConnectionID = connectionID
+		return b.SendMessage(ctx, param)
+	}
+	param := m.toSendPhotoParams(chatID)
+	param.BusinessConnectionID = connectionID
+	return b.SendPhoto(ctx, param)
+}
+
+// BindBusinessMessage registers a handler for messages received by a connected business account.
+// Replies sent with SendMessage are delivered on behalf of the business account.
+func (b *Bot) BindBusinessMessage(handlerFunc HandlerFunc, middlewares ...MiddlewareFunc) {
+	b.BindMatch(func(update *Update) bool {
+		return update.BusinessMessage != nil
+	}, handlerFunc, middlewares...)
+}
+
+// BindEditedBusinessMessage registers a handler for edited messages of a connected business account.
+func (b *Bot) BindEditedBusinessMessage(handlerFunc HandlerFunc, middlewares ...MiddlewareFunc) {
+	b.BindMatch(func(update *Update) bool {
+		return update.EditedBusinessMessage != nil
+	}, handlerFunc, middlewares...)
+}
+
+// BindBusinessConnection registers a handler for the bot being connected to or disconnected from
+// a business account, or the connection being edited.
+func (b *Bot) BindBusinessConnection(handlerFunc HandlerFunc, middlewares ...MiddlewareFunc) {
+	b.BindMatch(func(update *Update) bool {
+		return update.BusinessConnection != nil
+	}, handlerFunc, middlewares...)
+}
+
+// BindDeletedBusinessMessages registers a handler for messages deleted from a connected business account.
+func (b *Bot) BindDeletedBusinessMessages(handlerFunc HandlerFunc, middlewares ...MiddlewareFunc) {
+	b.BindMatch(func(update *Update) bool {
+		return update.DeletedBusinessMessages != nil
+	}, handlerFunc, middlewares...)
+}
@@ -0,0 +1,92 @@
+package telegram
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestBusinessConnectionID(t *testing.T) {
+	tests := []struct {
+		name   string
+		update *Update
+		want   string
+	}{
+		{"nil", nil, ""},
+		{"message", &Update{Message: &models.Message{BusinessConnectionID: "ignored"}}, ""},
+		{"business message", &Update{BusinessMessage: &models.Message{BusinessConnectionID: "c1"}}, "c1"},
+		{"edited business message", &Update{EditedBusinessMessage: &models.Message{BusinessConnectionID: "c2"}}, "c2"},
+		{"deleted business messages", &Update{DeletedBusinessMessages: &models.BusinessMessagesDeleted{BusinessConnectionID: "c3"}}, "c3"},
+		{"business connection", &Update{BusinessConnection: &models.BusinessConnection{ID: "c4"}}, "c4"},
+		{"callback query", &Update{CallbackQuery: &models.CallbackQuery{Message: models.MaybeInaccessibleMessage{Message: &models.Message{BusinessConnectionID: "c5"}}}}, "c5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BusinessConnectionID(tt.update); got != tt.want {
+				t.Errorf("BusinessConnectionID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBusinessMessageReply(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		params = map[string]string{}
+	)
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/sendMessage") {
+			mu.Lock()
+			params["business_connection_id"] = r.FormValue("business_connection_id")
+			params["chat_id"] = r.FormValue("chat_id")
+			params["text"] = r.FormValue("text")
+			mu.Unlock()
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":2,"date":1,"chat":{"id":7,"type":"private"}}}`))
+	})
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var routed []string
+	app.BindBusinessMessage(func(ctx context.Context, update *Update) error {
+		routed = append(routed, "message")
+		return app.SendMessage(ctx, update, &Message{Text: "echo: " + update.BusinessMessage.Text})
+	})
+	app.BindEditedBusinessMessage(func(ctx context.Context, update *Update) error {
+		routed = append(routed, "edited")
+		return nil
+	})
+	app.BindDeletedBusinessMessages(func(ctx context.Context, update *Update) error {
+		routed = append(routed, "deleted")
+		return nil
+	})
+	app.BindBusinessConnection(func(ctx context.Context, update *Update) error {
+		routed = append(routed, "connection")
+		return nil
+	})
+
+	updates := []string{
+		`{"update_id":1,"business_message":{"message_id":1,"date":1,"business_connection_id":"conn-1","chat":{"id":7,"type":"private"},"text":"hi"}}`,
+		`{"update_id":2,"edited_business_message":{"message_id":1,"date":1,"business_connection_id":"conn-1","chat":{"id":7,"type":"private"},"text":"hey"}}`,
+		`{"update_id":3,"deleted_business_messages":{"business_connection_id":"conn-1","chat":{"id":7,"type":"private"},"message_ids":[1]}}`,
+		`{"update_id":4,"business_connection":{"id":"conn-1","user":{"id":7,"is_bot":false,"first_name":"A"},"user_chat_id":7,"date":1,"is_enabled":true}}`,
+	}
+	for _, body := range updates {
+		if err = app.HandleUpdateJSON(context.Background(), []byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if strings.Join(routed, ",") != "message,edited,deleted,connection" {
+		t.Errorf("unexpected routing: %v", routed)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if params["business_connection_id"] != "conn-1" || params["chat_id"] != "7" || params["text"] != "echo: hi" {
+		t.Errorf("unexpected reply: %v", params)
+	}
+}
@@ -0,0 +1,76 @@
+package telegram
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// ttlCacheCleanup is the number of entries after which expired entries are purged on insert.
+const ttlCacheCleanup = 1024
+
+type ttlEntry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// ttlCache caches values for a fixed duration and coalesces concurrent loads of the same key.
+type ttlCache[K comparable, V any] struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[K]ttlEntry[V]
+	sf      singleflight.Group
+}
+
+func newTTLCache[K comparable, V any](ttl time.Duration) *ttlCache[K, V] {
+	return &ttlCache[K, V]{ttl: ttl, entries: map[K]ttlEntry[V]{}}
+}
+
+// get returns the cached value of the key, or loads and caches it. flightKey identifies
+// the key for coalescing concurrent loads.
+func (c *ttlCache[K, V]) get(key K, flightKey string, load func() (V, error)) (V, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.value, nil
+	}
+	v, err, _ := c.sf.Do(flightKey, func() (any, error) {
+		value, err := load()
+		if err != nil {
+			return value, err
+		}
+		c.set(key, value)
+		return value, nil
+	})
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	return v.(V), nil
+}
+
+func (c *ttlCache[K, V]) set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if len(c.entries) >= ttlCacheCleanup {
+		for k, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	c.entries[key] = ttlEntry[V]{value: value, expires: now.Add(c.ttl)}
+}
+
+func (c *ttlCache[K, V]) delete(match func(K) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k := range c.entries {
+		if match(k) {
+			delete(c.entries, k)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package telegram
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-telegram/bot"
+)
+
+// CallbackAnswer is the answer to a callback query, shown to the user as a notification
+// at the top of the chat or as an alert popup.
+type CallbackAnswer struct {
+	Text      string        // Notification text, empty for no notification
+	ShowAlert bool          // Whether the text is shown as an alert popup
+	URL       string        // URL opened by the client, e.g. a game or a t.me/bot?start= link
+	CacheTime time.Duration // Duration the client may cache the answer
+}
+
+// CallbackAnswerOption defines a function type for configuring a callback answer.
+type CallbackAnswerOption func(*CallbackAnswer)
+
+// WithAnswerText sets the notification text of the answer.
+func WithAnswerText(text string) CallbackAnswerOption {
+	return func(a *CallbackAnswer) {
+		a.Text = text
+	}
+}
+
+// WithShowAlert shows the notification text as an alert popup instead of a toast.
+func WithShowAlert(show bool) CallbackAnswerOption {
+	return func(a *CallbackAnswer) {
+		a.ShowAlert = show
+	}
+}
+
+// WithAnswerURL sets the URL opened by the client.
+func WithAnswerURL(url string) CallbackAnswerOption {
+	return func(a *CallbackAnswer) {
+		a.URL = url
+	}
+}
+
+// WithCacheTime sets the duration the client may cache the answer.
+func WithCacheTime(d time.Duration) CallbackAnswerOption {
+	return func(a *CallbackAnswer) {
+		a.CacheTime = d
+	}
+}
+
+// AnswerCallback answers the callback query of the update. It does nothing for other updates.
+func AnswerCallback(ctx context.Context, b *bot.Bot, update *Update, options ...CallbackAnswerOption) error {
+	answer := &CallbackAnswer{}
+	for _, opt := range options {
+		opt(answer)
+	}
+	return answerCallback(ctx, b, update, answer)
+}
+
+func answerCallback(ctx context.Context, b *bot.Bot, update *Update, answer *CallbackAnswer) error {
+	if update == nil || update.CallbackQuery == nil {
+		return nil
+	}
+	_, err := b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: update.CallbackQuery.ID,
+		Text:            answer.Text,
+		ShowAlert:       answer.ShowAlert,
+		URL:             answer.URL,
+		CacheTime:       int(answer.CacheTime / time.Second),
+	})
+	return err
+}
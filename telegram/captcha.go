@@ -0,0 +1,291 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// CaptchaMode selects the challenge posted for new group members.
+type CaptchaMode int
+
+const (
+	// CaptchaButton asks the member to press a single button.
+	CaptchaButton CaptchaMode = iota
+	// CaptchaMath asks the member to pick the sum of two numbers among several answers.
+	CaptchaMath
+)
+
+// CaptchaData is the compact callback data of the captcha buttons.
+type CaptchaData struct {
+	User   int64 `json:"u"` // Member the challenge was posted for
+	Answer int   `json:"a"` // Answer of the button
+}
+
+// captchaOptions holds configuration for the Captcha.
+type captchaOptions struct {
+	mode    CaptchaMode
+	route   string
+	timeout time.Duration
+	text    func(user models.User, question string) string
+}
+
+// CaptchaOption defines a function type for configuring the Captcha.
+type CaptchaOption func(*captchaOptions)
+
+// WithCaptchaMode sets the kind of challenge. Defaults to CaptchaButton.
+func WithCaptchaMode(mode CaptchaMode) CaptchaOption {
+	return func(o *captchaOptions) {
+		o.mode = mode
+	}
+}
+
+// WithCaptchaRoute sets the callback route of the captcha buttons. Defaults to "captcha".
+func WithCaptchaRoute(route string) CaptchaOption {
+	return func(o *captchaOptions) {
+		o.route = route
+	}
+}
+
+// WithCaptchaTimeout sets how long a new member has to solve the challenge before being
+// removed from the group. Defaults to 2 minutes.
+func WithCaptchaTimeout(timeout time.Duration) CaptchaOption {
+	return func(o *captchaOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithCaptchaText sets the text of the challenge message. The question is empty for
+// CaptchaButton challenges.
+func WithCaptchaText(text func(user models.User, question string) string) CaptchaOption {
+	return func(o *captchaOptions) {
+		o.text = text
+	}
+}
+
+type captchaKey struct {
+	chatID int64
+	userID int64
+}
+
+type captchaChallenge struct {
+	answer    int
+	messageID int
+	timer     *time.Timer
+}
+
+// Captcha verifies that new group members are human. A new member is restricted and asked
+// to solve a challenge within the timeout. Solving it lifts the restriction, a wrong answer
+// or the timeout removes the member from the group, who may join again later.
+// The bot must be an administrator allowed to restrict and ban members.
+type Captcha struct {
+	app  *Bot
+	opts *captchaOptions
+
+	mu      sync.Mutex
+	pending map[captchaKey]*captchaChallenge
+}
+
+// NewCaptcha creates a captcha for the bot, see Captcha.Bind.
+func NewCaptcha(app *Bot, options ...CaptchaOption) *Captcha {
+	opts := &captchaOptions{
+		route:   "captcha",
+		timeout: 2 * time.Minute,
+		text: func(user models.User, question string) string {
+			if question == "" {
+				return fmt.Sprintf("Welcome, %s! Press the button to show you are human.", user.FirstName)
+			}
+			return fmt.Sprintf("Welcome, %s! Solve %s to show you are human.", user.FirstName, question)
+		},
+	}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return &Captcha{app: app, opts: opts, pending: map[captchaKey]*captchaChallenge{}}
+}
+
+// Bind registers the handlers of new member messages and of the captcha buttons.
+func (c *Captcha) Bind(middlewares ...MiddlewareFunc) {
+	c.app.BindMatch(func(update *Update) bool {
+		return update.Message != nil && len(update.Message.NewChatMembers) > 0
+	}, c.handleJoin, middlewares...)
+	c.app.BindCallback(c.opts.route, c.handleAnswer, middlewares...)
+}
+
+func (c *Captcha) handleJoin(ctx context.Context, update *Update) error {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		client = c.app.API()
+	}
+	chatID := update.Message.Chat.ID
+	for _, member := range update.Message.NewChatMembers {
+		if member.IsBot {
+			continue
+		}
+		if err := c.challenge(ctx, client, chatID, member); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// challenge restricts the member and posts the challenge.
+func (c *Captcha) challenge(ctx context.Context, client *bot.Bot, chatID int64, member models.User) error {
+	_, err := client.RestrictChatMember(ctx, &bot.RestrictChatMemberParams{
+		ChatID:      chatID,
+		UserID:      member.ID,
+		Permissions: &models.ChatPermissions{},
+	})
+	if err != nil {
+		return err
+	}
+	question, answer, buttons := c.question(member.ID)
+	// the message is also scheduled for deletion, so it is removed when the bot restarts
+	// before the challenge expires
+	m := &Message{
+		Text:        c.opts.text(member, question),
+		Button:      [][]Button{buttons},
+		DeleteAfter: c.opts.timeout,
+	}
+	var msg *models.Message
+	err = runSendHooks(contextWithSendHooks(ctx, c.app.sendHooks), m, func() (*models.Message, error) {
+		sent, err := sendBusinessMessage(ctx, client, "", chatID, m)
+		msg = sent
+		return sent, err
+	})
+	if err != nil {
+		return err
+	}
+	key := captchaKey{chatID: chatID, userID: member.ID}
+	challenge := &captchaChallenge{answer: answer, messageID: msg.ID}
+	expired := context.WithoutCancel(ctx)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if old, ok := c.pending[key]; ok {
+		old.timer.Stop()
+	}
+	// the timer is set under the lock, take waits for it before stopping it
+	challenge.timer = time.AfterFunc(c.opts.timeout, func() {
+		if c.take(key, challenge) {
+			c.fail(expired, client, key, challenge)
+		}
+	})
+	c.pending[key] = challenge
+	return nil
+}
+
+// question returns the question, the correct answer and the buttons of a new challenge.
+func (c *Captcha) question(userID int64) (string, int, []Button) {
+	button := func(text string, answer int) Button {
+		return NewButton(text, c.opts.route, CaptchaData{User: userID, Answer: answer}, c.app.DataOptions()...)
+	}
+	if c.opts.mode != CaptchaMath {
+		return "", 0, []Button{button("I'm not a robot", 0)}
+	}
+	x, y := rand.IntN(9)+1, rand.IntN(9)+1
+	answers := []int{x + y}
+	for len(answers) < 4 {
+		if wrong := rand.IntN(17) + 2; !slices.Contains(answers, wrong) {
+			answers = append(answers, wrong)
+		}
+	}
+	rand.Shuffle(len(answers), func(i, j int) { answers[i], answers[j] = answers[j], answers[i] })
+	buttons := make([]Button, len(answers))
+	for i, answer := range answers {
+		buttons[i] = button(strconv.Itoa(answer), answer)
+	}
+	return fmt.Sprintf("%d + %d", x, y), x + y, buttons
+}
+
+// take removes the challenge from the pending ones, it reports false if it was already resolved.
+func (c *Captcha) take(key captchaKey, challenge *captchaChallenge) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pending[key] != challenge {
+		return false
+	}
+	delete(c.pending, key)
+	challenge.timer.Stop()
+	return true
+}
+
+func (c *Captcha) handleAnswer(ctx context.Context, update *Update) error {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		client = c.app.API()
+	}
+	_, data, err := UnmarshalData[CaptchaData](update.CallbackQuery.Data, c.app.DataOptions()...)
+	if err != nil {
+		return err
+	}
+	if update.CallbackQuery.From.ID != data.User {
+		return AnswerCallback(ctx, client, update, WithAnswerText("This challenge is not for you."), WithShowAlert(true))
+	}
+	ref, err := UpdateMessageRef(update)
+	if err != nil {
+		return err
+	}
+	key := captchaKey{chatID: ref.ChatID, userID: data.User}
+	c.mu.Lock()
+	challenge := c.pending[key]
+	c.mu.Unlock()
+	if challenge == nil || !c.take(key, challenge) {
+		return AnswerCallback(ctx, client, update)
+	}
+	if data.Answer != challenge.answer {
+		c.fail(ctx, client, key, challenge)
+		return AnswerCallback(ctx, client, update, WithAnswerText("Wrong answer."))
+	}
+	_, err = client.RestrictChatMember(ctx, &bot.RestrictChatMemberParams{
+		ChatID:      key.chatID,
+		UserID:      key.userID,
+		Permissions: allChatPermissions(),
+	})
+	if err != nil {
+		return err
+	}
+	if err = DeleteTo(ctx, client, key.chatID, challenge.messageID); err != nil {
+		slog.ErrorContext(ctx, "delete captcha message", slog.Any("error", err))
+	}
+	return AnswerCallback(ctx, client, update, WithAnswerText("Welcome!"))
+}
+
+// fail removes the member from the group and deletes the challenge. The member is unbanned
+// right away, so they can join again.
+func (c *Captcha) fail(ctx context.Context, client *bot.Bot, key captchaKey, challenge *captchaChallenge) {
+	if _, err := client.BanChatMember(ctx, &bot.BanChatMemberParams{ChatID: key.chatID, UserID: key.userID}); err != nil {
+		slog.ErrorContext(ctx, "remove captcha member", slog.Int64("user_id", key.userID), slog.Any("error", err))
+	} else if _, err = client.UnbanChatMember(ctx, &bot.UnbanChatMemberParams{ChatID: key.chatID, UserID: key.userID, OnlyIfBanned: true}); err != nil {
+		slog.ErrorContext(ctx, "unban captcha member", slog.Int64("user_id", key.userID), slog.Any("error", err))
+	}
+	if err := DeleteTo(ctx, client, key.chatID, challenge.messageID); err != nil {
+		slog.ErrorContext(ctx, "delete captcha message", slog.Any("error", err))
+	}
+}
+
+func allChatPermissions() *models.ChatPermissions {
+	return &models.ChatPermissions{
+		CanSendMessages:       true,
+		CanSendAudios:         true,
+		CanSendDocuments:      true,
+		CanSendPhotos:         true,
+		CanSendVideos:         true,
+		CanSendVideoNotes:     true,
+		CanSendVoiceNotes:     true,
+		CanSendPolls:          true,
+		CanSendOtherMessages:  true,
+		CanAddWebPagePreviews: true,
+		CanChangeInfo:         true,
+		CanInviteUsers:        true,
+		CanPinMessages:        true,
+		CanManageTopics:       true,
+	}
+}
@@ -0,0 +1,109 @@
+package telegram
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCaptchaQuestion(t *testing.T) {
+	app := &Bot{}
+	c := NewCaptcha(app, WithCaptchaMode(CaptchaMath))
+	for range 50 {
+		question, answer, buttons := c.question(42)
+		if question == "" || len(buttons) != 4 {
+			t.Fatalf("unexpected challenge %q with %d buttons", question, len(buttons))
+		}
+		correct := 0
+		for _, button := range buttons {
+			route, data, err := UnmarshalData[CaptchaData](button.CallbackData, app.DataOptions()...)
+			if err != nil || route != "captcha" || data.User != 42 {
+				t.Fatalf("unexpected button data %q: %v", button.CallbackData, err)
+			}
+			if button.Text != strconv.Itoa(data.Answer) {
+				t.Errorf("button %q carries answer %d", button.Text, data.Answer)
+			}
+			if data.Answer == answer {
+				correct++
+			}
+		}
+		if correct != 1 {
+			t.Fatalf("expected exactly one correct answer, got %d", correct)
+		}
+	}
+
+	question, _, buttons := NewCaptcha(app).question(42)
+	if question != "" || len(buttons) != 1 {
+		t.Errorf("unexpected button challenge %q with %d buttons", question, len(buttons))
+	}
+}
+
+func TestCaptchaTake(t *testing.T) {
+	c := NewCaptcha(&Bot{})
+	key := captchaKey{chatID: 1, userID: 2}
+	challenge := &captchaChallenge{timer: time.NewTimer(time.Hour)}
+	c.pending[key] = challenge
+	if !c.take(key, challenge) {
+		t.Fatal("pending challenge must be taken")
+	}
+	if c.take(key, challenge) {
+		t.Error("challenge must be taken only once")
+	}
+}
+
+func TestCaptchaTimeout(t *testing.T) {
+	banned := make(chan struct{}, 1)
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/sendMessage"):
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":5,"date":1,"chat":{"id":-100}}}`))
+		case strings.HasSuffix(r.URL.Path, "/banChatMember"):
+			banned <- struct{}{}
+			fallthrough
+		default:
+			_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+		}
+	})
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the challenge expires right away, while it is still being registered
+	NewCaptcha(app, WithCaptchaTimeout(0)).Bind()
+	body := `{"update_id":1,"message":{"message_id":1,"date":1,"chat":{"id":-100},"new_chat_members":[{"id":7,"first_name":"A"}]}}`
+	if err = app.HandleUpdateJSON(context.Background(), []byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-banned:
+	case <-time.After(2 * time.Second):
+		t.Fatal("member was not removed after the timeout")
+	}
+}
+
+func TestCaptchaSchedulesDeletion(t *testing.T) {
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/sendMessage") {
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":5,"date":1,"chat":{"id":-100}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+	store := NewMemoryDeletionStore()
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL), WithDeletionStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	NewCaptcha(app, WithCaptchaTimeout(time.Hour)).Bind()
+	body := `{"update_id":1,"message":{"message_id":1,"date":1,"chat":{"id":-100},"new_chat_members":[{"id":7,"first_name":"A"}]}}`
+	if err = app.HandleUpdateJSON(context.Background(), []byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	pending, _ := store.PendingDeletions(context.Background())
+	if len(pending) != 1 || pending[0].ChatID != -100 || pending[0].MessageID != 5 {
+		t.Errorf("unexpected pending deletions: %+v", pending)
+	}
+}
@@ -0,0 +1,164 @@
+package telegram
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sync"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// captureOptions holds configuration for an update capture.
+type captureOptions struct {
+	redactors []func(*Update)
+}
+
+// CaptureOption defines a function type for configuring an update capture.
+type CaptureOption func(*captureOptions)
+
+// WithCaptureRedactor applies the function to a copy of every captured update before it is
+// written, e.g. to remove personal data from production traffic.
+func WithCaptureRedactor(fn func(update *Update)) CaptureOption {
+	return func(o *captureOptions) {
+		o.redactors = append(o.redactors, fn)
+	}
+}
+
+// WithCaptureRedactText blanks message texts, captions and callback and inline query payloads
+// of captured updates.
+func WithCaptureRedactText() CaptureOption {
+	return WithCaptureRedactor(func(update *Update) {
+		for _, message := range []*models.Message{update.Message, update.EditedMessage, update.ChannelPost, update.BusinessMessage} {
+			if message != nil {
+				message.Text, message.Caption = redact(message.Text), redact(message.Caption)
+			}
+		}
+		if update.CallbackQuery != nil {
+			update.CallbackQuery.Data = redact(update.CallbackQuery.Data)
+		}
+		if update.InlineQuery != nil {
+			update.InlineQuery.Query = redact(update.InlineQuery.Query)
+		}
+	})
+}
+
+func redact(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "[redacted]"
+}
+
+// capture writes every update as a JSON line to a writer.
+type capture struct {
+	mu   sync.Mutex
+	w    io.Writer
+	opts captureOptions
+}
+
+func (c *capture) write(update *Update) error {
+	raw, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+	if len(c.opts.redactors) > 0 {
+		// redactors work on a copy so handlers still see the original update
+		redacted := &Update{}
+		if err = json.Unmarshal(raw, redacted); err != nil {
+			return err
+		}
+		for _, fn := range c.opts.redactors {
+			fn(redacted)
+		}
+		if raw, err = json.Marshal(redacted); err != nil {
+			return err
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err = c.w.Write(append(raw, '\n'))
+	return err
+}
+
+// captures holds the active update captures.
+type captures struct {
+	mu   sync.RWMutex
+	list []*capture
+}
+
+// middleware writes every received update to the active captures, replayed updates are skipped.
+func (c *captures) middleware() bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			if ctx.Value(replayingKey{}) == nil {
+				c.mu.RLock()
+				list := c.list
+				c.mu.RUnlock()
+				for _, cp := range list {
+					if err := cp.write(update); err != nil {
+						slog.Error("capture update", slog.Int64("update_id", update.ID), slog.Any("error", err))
+					}
+				}
+			}
+			next(ctx, b, update)
+		}
+	}
+}
+
+// CaptureUpdates writes every received update as a line of JSON to w until the returned
+// function is called, so production traffic can be reproduced with ReplayUpdates.
+// Writes to w are serialized.
+func (b *Bot) CaptureUpdates(w io.Writer, options ...CaptureOption) (stop func()) {
+	c := &capture{w: w}
+	for _, opt := range options {
+		opt(&c.opts)
+	}
+	b.captures.mu.Lock()
+	b.captures.list = append(b.captures.list, c)
+	b.captures.mu.Unlock()
+	return func() {
+		b.captures.mu.Lock()
+		defer b.captures.mu.Unlock()
+		for i, cp := range b.captures.list {
+			if cp == c {
+				b.captures.list = append(b.captures.list[:i:i], b.captures.list[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// replayingKey marks the context of updates fed back by ReplayUpdates.
+type replayingKey struct{}
+
+// ReplayUpdates reads updates written by CaptureUpdates from r and processes them one after
+// another through the regular handler chain, waiting for each update to be handled like
+// HandleUpdate. Handler errors are reported to the error handler and do not stop the replay.
+// It returns the number of replayed updates.
+func (b *Bot) ReplayUpdates(ctx context.Context, r io.Reader) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16<<20)
+	ctx = context.WithValue(ctx, replayingKey{}, struct{}{})
+	replayed := 0
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return replayed, err
+		}
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		update := &Update{}
+		if err := json.Unmarshal(scanner.Bytes(), update); err != nil {
+			return replayed, err
+		}
+		if err := b.HandleUpdate(ctx, update); err != nil && ctx.Err() != nil {
+			return replayed, ctx.Err()
+		}
+		replayed++
+	}
+	return replayed, scanner.Err()
+}
@@ -0,0 +1,75 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+func TestCaptureAndReplayUpdates(t *testing.T) {
+	app, err := NewApp(Config{Token: "token"}, AppendBotOptions(bot.WithNotAsyncHandlers()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var texts []string
+	app.BindCommand("echo", func(ctx context.Context, update *Update) error {
+		texts = append(texts, update.Message.Text)
+		return nil
+	})
+
+	var buf bytes.Buffer
+	stop := app.CaptureUpdates(&buf, WithCaptureRedactText())
+	app.API().ProcessUpdate(context.Background(), &Update{ID: 1, Message: &models.Message{Text: "/echo secret"}})
+	stop()
+	app.API().ProcessUpdate(context.Background(), &Update{ID: 2, Message: &models.Message{Text: "/echo later"}})
+
+	if strings.Contains(buf.String(), "secret") || strings.Count(buf.String(), "\n") != 1 {
+		t.Fatalf("unexpected capture: %s", buf.String())
+	}
+	if texts[0] != "/echo secret" {
+		t.Errorf("redaction leaked into the handler: %q", texts[0])
+	}
+
+	var raw bytes.Buffer
+	stop = app.CaptureUpdates(&raw)
+	app.API().ProcessUpdate(context.Background(), &Update{ID: 3, Message: &models.Message{Text: "/echo replay"}})
+	stop()
+	texts = nil
+	n, err := app.ReplayUpdates(context.Background(), &raw)
+	if err != nil || n != 1 {
+		t.Fatalf("replay: n=%d, err=%v", n, err)
+	}
+	if len(texts) != 1 || texts[0] != "/echo replay" {
+		t.Errorf("unexpected replayed updates: %v", texts)
+	}
+}
+
+func TestReplayUpdatesWaits(t *testing.T) {
+	app, err := NewApp(Config{Token: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var handled []int64
+	app.BindCommand("slow", func(ctx context.Context, update *Update) error {
+		time.Sleep(10 * time.Millisecond)
+		handled = append(handled, update.ID)
+		return errors.New("fail")
+	})
+	input := `{"update_id":1,"message":{"message_id":1,"date":1,"chat":{"id":1},"text":"/slow"}}
+{"update_id":2,"message":{"message_id":2,"date":1,"chat":{"id":1},"text":"/slow"}}
+`
+	n, err := app.ReplayUpdates(context.Background(), strings.NewReader(input))
+	if err != nil || n != 2 {
+		t.Fatalf("replay: n=%d, err=%v", n, err)
+	}
+	// the handlers run asynchronously, the replay must have waited for both
+	if len(handled) != 2 || handled[0] != 1 || handled[1] != 2 {
+		t.Errorf("unexpected handled updates: %v", handled)
+	}
+}
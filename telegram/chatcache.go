@@ -0,0 +1,139 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// ErrNoClient is returned by context helpers when the context was not created for update processing.
+var ErrNoClient = errors.New("no bot client in context")
+
+// chatCacheOptions holds configuration for the ChatCache.
+type chatCacheOptions struct {
+	chatTTL   time.Duration // Duration chat information is cached
+	memberTTL time.Duration // Duration chat member information is cached
+}
+
+// ChatCacheOption defines a function type for configuring the ChatCache.
+type ChatCacheOption func(*chatCacheOptions)
+
+// WithChatTTL sets the duration chat information is cached. Defaults to 10 minutes.
+func WithChatTTL(ttl time.Duration) ChatCacheOption {
+	return func(o *chatCacheOptions) {
+		o.chatTTL = ttl
+	}
+}
+
+// WithMemberTTL sets the duration chat member information is cached. Defaults to 1 minute.
+func WithMemberTTL(ttl time.Duration) ChatCacheOption {
+	return func(o *chatCacheOptions) {
+		o.memberTTL = ttl
+	}
+}
+
+type memberKey struct {
+	chatID int64
+	userID int64
+}
+
+// ChatCache caches getChat and getChatMember results, coalescing concurrent calls for the
+// same chat or member into a single API call.
+type ChatCache struct {
+	chats   *ttlCache[int64, *models.ChatFullInfo]
+	members *ttlCache[memberKey, *models.ChatMember]
+}
+
+// NewChatCache creates an empty chat cache.
+func NewChatCache(options ...ChatCacheOption) *ChatCache {
+	opts := &chatCacheOptions{
+		chatTTL:   10 * time.Minute,
+		memberTTL: time.Minute,
+	}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return &ChatCache{
+		chats:   newTTLCache[int64, *models.ChatFullInfo](opts.chatTTL),
+		members: newTTLCache[memberKey, *models.ChatMember](opts.memberTTL),
+	}
+}
+
+// Chat returns the information of the chat, calling getChat on a cache miss.
+func (c *ChatCache) Chat(ctx context.Context, b *bot.Bot, chatID int64) (*models.ChatFullInfo, error) {
+	return c.chats.get(chatID, strconv.FormatInt(chatID, 10), func() (*models.ChatFullInfo, error) {
+		return b.GetChat(ctx, &bot.GetChatParams{ChatID: chatID})
+	})
+}
+
+// Member returns the membership of the user in the chat, calling getChatMember on a cache miss.
+func (c *ChatCache) Member(ctx context.Context, b *bot.Bot, chatID, userID int64) (*models.ChatMember, error) {
+	key := memberKey{chatID: chatID, userID: userID}
+	return c.members.get(key, strconv.FormatInt(chatID, 10)+":"+strconv.FormatInt(userID, 10), func() (*models.ChatMember, error) {
+		return b.GetChatMember(ctx, &bot.GetChatMemberParams{ChatID: chatID, UserID: userID})
+	})
+}
+
+// Invalidate drops the cached information of the chat and its members, e.g. after
+// a chat_member update.
+func (c *ChatCache) Invalidate(chatID int64) {
+	c.chats.delete(func(id int64) bool { return id == chatID })
+	c.members.delete(func(key memberKey) bool { return key.chatID == chatID })
+}
+
+type chatCacheKey struct{}
+
+// Middleware returns a middleware making the cache available to ChatInfo and ChatMemberInfo.
+// Membership changes reported by chat_member updates invalidate the cached chat.
+func (c *ChatCache) Middleware() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, update *Update) error {
+			if update.ChatMember != nil {
+				c.Invalidate(update.ChatMember.Chat.ID)
+			}
+			if update.MyChatMember != nil {
+				c.Invalidate(update.MyChatMember.Chat.ID)
+			}
+			return next(context.WithValue(ctx, chatCacheKey{}, c), update)
+		}
+	}
+}
+
+// ChatInfo returns the information of the chat through the ChatCache of the context, or
+// directly from the API if no cache is installed.
+func ChatInfo(ctx context.Context, chatID int64) (*models.ChatFullInfo, error) {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		return nil, ErrNoClient
+	}
+	if cache, ok := ctx.Value(chatCacheKey{}).(*ChatCache); ok {
+		return cache.Chat(ctx, client, chatID)
+	}
+	return client.GetChat(ctx, &bot.GetChatParams{ChatID: chatID})
+}
+
+// ChatMemberInfo returns the membership of the user in the chat through the ChatCache of the
+// context, or directly from the API if no cache is installed.
+func ChatMemberInfo(ctx context.Context, chatID, userID int64) (*models.ChatMember, error) {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		return nil, ErrNoClient
+	}
+	if cache, ok := ctx.Value(chatCacheKey{}).(*ChatCache); ok {
+		return cache.Member(ctx, client, chatID, userID)
+	}
+	return client.GetChatMember(ctx, &bot.GetChatMemberParams{ChatID: chatID, UserID: userID})
+}
+
+// IsChatAdmin reports whether the user is the owner or an administrator of the chat, see ChatMemberInfo.
+func IsChatAdmin(ctx context.Context, chatID, userID int64) (bool, error) {
+	member, err := ChatMemberInfo(ctx, chatID, userID)
+	if err != nil {
+		return false, err
+	}
+	return member.Type == models.ChatMemberTypeOwner || member.Type == models.ChatMemberTypeAdministrator, nil
+}
@@ -0,0 +1,49 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestTTLCache(t *testing.T) {
+	cache := newTTLCache[int64, int](time.Minute)
+	loads := 0
+	load := func() (int, error) {
+		loads++
+		return loads, nil
+	}
+	for range 3 {
+		if v, err := cache.get(1, "1", load); err != nil || v != 1 {
+			t.Fatalf("unexpected value %d, %v", v, err)
+		}
+	}
+	cache.delete(func(k int64) bool { return k == 1 })
+	if v, _ := cache.get(1, "1", load); v != 2 {
+		t.Errorf("invalidated entry was not reloaded, got %d", v)
+	}
+	failing := func() (int, error) { return 0, errors.New("failed") }
+	if _, err := cache.get(2, "2", failing); err == nil {
+		t.Error("load error is not returned")
+	}
+	if _, ok := cache.entries[2]; ok {
+		t.Error("failed load must not be cached")
+	}
+}
+
+func TestChatCacheInvalidate(t *testing.T) {
+	cache := NewChatCache()
+	cache.chats.set(1, &models.ChatFullInfo{ID: 1})
+	cache.members.set(memberKey{chatID: 1, userID: 2}, &models.ChatMember{})
+	cache.members.set(memberKey{chatID: 3, userID: 2}, &models.ChatMember{})
+	cache.Invalidate(1)
+	if len(cache.chats.entries) != 0 || len(cache.members.entries) != 1 {
+		t.Errorf("unexpected entries after invalidation: %d chats, %d members", len(cache.chats.entries), len(cache.members.entries))
+	}
+	if _, err := ChatInfo(context.Background(), 1); !errors.Is(err, ErrNoClient) {
+		t.Errorf("expected ErrNoClient, got %v", err)
+	}
+}
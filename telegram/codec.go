@@ -0,0 +1,67 @@
+package telegram
+
+import (
+	"encoding/base64"
+
+	"github.com/go-sphere/jsoncompressor"
+)
+
+// CallbackCodec encodes the data of callback buttons. Encoded data must only use characters
+// allowed in callback data and should be as short as possible, as Telegram limits callback
+// data to 64 bytes including the route. Marshal and Unmarshal always receive a pointer to the data.
+type CallbackCodec interface {
+	Marshal(v any) (string, error)
+	Unmarshal(data string, v any) error
+}
+
+// JSONCompressorCodec encodes data as compressed JSON, omitting field names. It is the default codec.
+var JSONCompressorCodec CallbackCodec = jsonCompressorCodec{}
+
+type jsonCompressorCodec struct{}
+
+func (jsonCompressorCodec) Marshal(v any) (string, error) {
+	b, err := jsoncompressor.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (jsonCompressorCodec) Unmarshal(data string, v any) error {
+	return jsoncompressor.Unmarshal([]byte(data), v)
+}
+
+// BinaryCodec adapts a binary serialization format to callback data using base64url encoding.
+type BinaryCodec struct {
+	marshal   func(v any) ([]byte, error)
+	unmarshal func(data []byte, v any) error
+}
+
+// NewBinaryCodec creates a codec from binary marshal functions, e.g. msgpack.Marshal and
+// msgpack.Unmarshal, or for protobuf messages:
+//
+//	telegram.NewBinaryCodec(
+//		func(v any) ([]byte, error) { return proto.Marshal(v.(proto.Message)) },
+//		func(b []byte, v any) error { return proto.Unmarshal(b, v.(proto.Message)) },
+//	)
+func NewBinaryCodec(marshal func(v any) ([]byte, error), unmarshal func(data []byte, v any) error) *BinaryCodec {
+	return &BinaryCodec{marshal: marshal, unmarshal: unmarshal}
+}
+
+// Marshal implements CallbackCodec.
+func (c *BinaryCodec) Marshal(v any) (string, error) {
+	b, err := c.marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Unmarshal implements CallbackCodec.
+func (c *BinaryCodec) Unmarshal(data string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil {
+		return err
+	}
+	return c.unmarshal(b, v)
+}
@@ -0,0 +1,115 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// ErrPermissionDenied is returned by the role middleware when the user has none of the required roles.
+var ErrPermissionDenied = errors.New("permission denied")
+
+// RoleResolver returns the roles of the user who triggered the update.
+type RoleResolver = func(ctx context.Context, update *Update) ([]string, error)
+
+// NewRoleMiddleware creates a middleware that only runs the handler for users having at least
+// one of the roles, other updates fail with ErrPermissionDenied. Without roles every user is allowed.
+func NewRoleMiddleware(resolver RoleResolver, roles ...string) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		if len(roles) == 0 {
+			return next
+		}
+		return func(ctx context.Context, update *Update) error {
+			granted, err := resolver(ctx, update)
+			if err != nil {
+				return err
+			}
+			for _, role := range granted {
+				if slices.Contains(roles, role) {
+					return next(ctx, update)
+				}
+			}
+			return ErrPermissionDenied
+		}
+	}
+}
+
+// Command menu scopes accepted in MethodExtraData.Scopes.
+const (
+	CommandScopeDefault               = "default"
+	CommandScopeAllPrivateChats       = "all_private_chats"
+	CommandScopeAllGroupChats         = "all_group_chats"
+	CommandScopeAllChatAdministrators = "all_chat_administrators"
+)
+
+func commandScope(name string) (models.BotCommandScope, error) {
+	switch name {
+	case CommandScopeDefault:
+		return &models.BotCommandScopeDefault{}, nil
+	case CommandScopeAllPrivateChats:
+		return &models.BotCommandScopeAllPrivateChats{}, nil
+	case CommandScopeAllGroupChats:
+		return &models.BotCommandScopeAllGroupChats{}, nil
+	case CommandScopeAllChatAdministrators:
+		return &models.BotCommandScopeAllChatAdministrators{}, nil
+	default:
+		return nil, fmt.Errorf("unknown command scope %q", name)
+	}
+}
+
+// commandMenu collects the described commands per scope, in registration order.
+type commandMenu struct {
+	scopes   []string
+	commands map[string][]models.BotCommand
+}
+
+func (m *commandMenu) add(info *MethodExtraData) {
+	if info.Command == "" || info.Description == "" {
+		return
+	}
+	if m.commands == nil {
+		m.commands = map[string][]models.BotCommand{}
+	}
+	scopes := info.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{CommandScopeDefault}
+	}
+	for _, scope := range scopes {
+		if _, ok := m.commands[scope]; !ok {
+			m.scopes = append(m.scopes, scope)
+		}
+		m.commands[scope] = append(m.commands[scope], models.BotCommand{
+			Command:     strings.TrimPrefix(info.Command, "/"),
+			Description: info.Description,
+		})
+	}
+}
+
+// SyncCommands publishes the described commands bound with BindRoute as the bot's command
+// menu, calling SetMyCommands once per scope.
+func (b *Bot) SyncCommands(ctx context.Context) error {
+	b.mu.RLock()
+	menu := b.commands
+	b.mu.RUnlock()
+	var errs []error
+	for _, name := range menu.scopes {
+		scope, err := commandScope(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		_, err = b.API().SetMyCommands(ctx, &bot.SetMyCommandsParams{
+			Commands: menu.commands[name],
+			Scope:    scope,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("set commands of scope %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
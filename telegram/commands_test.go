@@ -0,0 +1,47 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRoleMiddleware(t *testing.T) {
+	resolver := func(ctx context.Context, update *Update) ([]string, error) {
+		return []string{"editor"}, nil
+	}
+	next := func(ctx context.Context, update *Update) error { return nil }
+	if err := NewRoleMiddleware(resolver, "admin", "editor")(next)(context.Background(), &Update{}); err != nil {
+		t.Errorf("user with role denied: %v", err)
+	}
+	if err := NewRoleMiddleware(resolver, "admin")(next)(context.Background(), &Update{}); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("expected ErrPermissionDenied, got %v", err)
+	}
+}
+
+func TestCommandMenu(t *testing.T) {
+	var menu commandMenu
+	menu.add(NewMethodExtraData(map[string]string{"command": "/start", "description": "Start"}))
+	menu.add(NewMethodExtraData(map[string]string{"command": "ban", "description": "Ban", "scopes": "all_chat_administrators, default"}))
+	menu.add(NewMethodExtraData(map[string]string{"command": "hidden"}))
+	if len(menu.scopes) != 2 || menu.scopes[0] != CommandScopeDefault {
+		t.Fatalf("unexpected scopes: %v", menu.scopes)
+	}
+	if got := menu.commands[CommandScopeDefault]; len(got) != 2 || got[0].Command != "start" || got[1].Command != "ban" {
+		t.Errorf("unexpected default commands: %+v", got)
+	}
+	if got := menu.commands[CommandScopeAllChatAdministrators]; len(got) != 1 {
+		t.Errorf("unexpected admin commands: %+v", got)
+	}
+}
+
+func TestBindRouteRequiresRoleResolver(t *testing.T) {
+	b := &Bot{}
+	handler := func(ctx context.Context, update *Update) error { return nil }
+	err := b.BindRoute(RouteMap{"Ban": handler}, func(string) *MethodExtraData {
+		return &MethodExtraData{Command: "ban", Roles: []string{"admin"}}
+	}, []string{"Ban"})
+	if err == nil {
+		t.Error("expected missing role resolver error")
+	}
+}
@@ -0,0 +1,138 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+
+	"github.com/go-telegram/bot"
+	"golang.org/x/time/rate"
+)
+
+// Config defines the configuration parameters for the Telegram bot.
+// It can be loaded from JSON or YAML files; call ExpandEnv to resolve references to
+// environment variables such as "${BOT_TOKEN}", then Validate before passing it to NewApp.
+type Config struct {
+	Token              string          `json:"token" yaml:"token"`
+	APIEndpoint        string          `json:"api_endpoint" yaml:"api_endpoint"`                 // Bot API server URL, empty for api.telegram.org
+	Proxy              string          `json:"proxy" yaml:"proxy"`                               // HTTP(S) or SOCKS5 proxy URL used to reach the Bot API
+	Mode               string          `json:"mode" yaml:"mode"`                                 // ModePolling (default) or ModeWebhook, used by Run
+	Webhook            WebhookConfig   `json:"webhook" yaml:"webhook"`                           // Webhook settings, required in webhook mode
+	AllowedUpdates     []string        `json:"allowed_updates" yaml:"allowed_updates"`           // Update types to receive, empty for Telegram's default
+	DropPendingUpdates bool            `json:"drop_pending_updates" yaml:"drop_pending_updates"` // Whether updates received while offline are discarded
+	Workers            int             `json:"workers" yaml:"workers"`                           // Number of concurrent update workers, 0 for the default
+	RateLimit          RateLimitConfig `json:"rate_limit" yaml:"rate_limit"`                     // Outbound rate limit, e.g. for broadcasts
+}
+
+// WebhookConfig defines the webhook registered with Telegram.
+type WebhookConfig struct {
+	URL            string `json:"url" yaml:"url"`                         // Public HTTPS URL receiving updates
+	SecretToken    string `json:"secret_token" yaml:"secret_token"`       // Secret sent in the X-Telegram-Bot-Api-Secret-Token header
+	MaxConnections int    `json:"max_connections" yaml:"max_connections"` // Maximum simultaneous connections, 1-100, 0 for the default
+	Listen         string `json:"listen" yaml:"listen"`                   // Address served by StartWebhookTLS, defaults to ":8443"
+	CertFile       string `json:"cert_file" yaml:"cert_file"`             // PEM certificate served by Run in webhook mode, see StartWebhookTLS
+	KeyFile        string `json:"key_file" yaml:"key_file"`               // PEM private key of CertFile
+	SelfSigned     bool   `json:"self_signed" yaml:"self_signed"`         // Whether the certificate is uploaded to Telegram when the webhook is set
+}
+
+// RateLimitConfig defines a token bucket rate limit.
+type RateLimitConfig struct {
+	PerSecond float64 `json:"per_second" yaml:"per_second"` // Sustained rate, 0 disables limiting
+	Burst     int     `json:"burst" yaml:"burst"`           // Maximum burst, defaults to 1
+}
+
+// NewLimiter creates a rate limiter from the configuration, suitable for BroadcastMessage.
+func (c RateLimitConfig) NewLimiter() *rate.Limiter {
+	if c.PerSecond <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	return rate.NewLimiter(rate.Limit(c.PerSecond), max(c.Burst, 1))
+}
+
+// ExpandEnv replaces ${var} or $var in the string fields according to the values of the
+// current environment variables.
+func (c *Config) ExpandEnv() {
+	for _, field := range []*string{&c.Token, &c.APIEndpoint, &c.Proxy, &c.Mode, &c.Webhook.URL, &c.Webhook.SecretToken, &c.Webhook.Listen, &c.Webhook.CertFile, &c.Webhook.KeyFile} {
+		*field = os.ExpandEnv(*field)
+	}
+}
+
+var secretTokenPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+// updateTypes are the update types accepted in allowed_updates.
+var updateTypes = map[string]struct{}{
+	"message": {}, "edited_message": {}, "channel_post": {}, "edited_channel_post": {},
+	"business_connection": {}, "business_message": {}, "edited_business_message": {}, "deleted_business_messages": {},
+	"message_reaction": {}, "message_reaction_count": {}, "inline_query": {}, "chosen_inline_result": {},
+	"callback_query": {}, "shipping_query": {}, "pre_checkout_query": {}, "purchased_paid_media": {},
+	"poll": {}, "poll_answer": {}, "my_chat_member": {}, "chat_member": {}, "chat_join_request": {},
+	"chat_boost": {}, "removed_chat_boost": {},
+}
+
+// Validate checks the configuration and returns all problems found.
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Token == "" {
+		errs = append(errs, errors.New("token is required"))
+	}
+	if c.APIEndpoint != "" {
+		if u, err := url.Parse(c.APIEndpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("invalid api_endpoint %q", c.APIEndpoint))
+		}
+	}
+	if c.Proxy != "" {
+		if u, err := url.Parse(c.Proxy); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("invalid proxy %q", c.Proxy))
+		}
+	}
+	switch c.Mode {
+	case "", ModePolling:
+	case ModeWebhook:
+		if u, err := url.Parse(c.Webhook.URL); err != nil || u.Scheme != "https" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("webhook mode requires an https webhook url, got %q", c.Webhook.URL))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown mode %q", c.Mode))
+	}
+	if c.Webhook.SecretToken != "" && !secretTokenPattern.MatchString(c.Webhook.SecretToken) {
+		errs = append(errs, errors.New("webhook secret_token must be 1-256 characters of A-Z, a-z, 0-9, _ and -"))
+	}
+	if (c.Webhook.CertFile == "") != (c.Webhook.KeyFile == "") {
+		errs = append(errs, errors.New("webhook cert_file and key_file must be set together"))
+	}
+	if c.Webhook.SelfSigned && c.Webhook.CertFile == "" {
+		errs = append(errs, errors.New("webhook self_signed requires cert_file"))
+	}
+	if c.Webhook.MaxConnections < 0 || c.Webhook.MaxConnections > 100 {
+		errs = append(errs, fmt.Errorf("webhook max_connections must be between 1 and 100, got %d", c.Webhook.MaxConnections))
+	}
+	for _, t := range c.AllowedUpdates {
+		if _, ok := updateTypes[t]; !ok {
+			errs = append(errs, fmt.Errorf("unknown allowed update type %q", t))
+		}
+	}
+	if c.Workers < 0 {
+		errs = append(errs, fmt.Errorf("workers must not be negative, got %d", c.Workers))
+	}
+	if c.RateLimit.PerSecond < 0 || c.RateLimit.Burst < 0 {
+		errs = append(errs, errors.New("rate_limit values must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// botOptions returns the client options derived from the configuration.
+func (c *Config) botOptions() []bot.Option {
+	var opts []bot.Option
+	if c.Workers > 0 {
+		opts = append(opts, bot.WithWorkers(c.Workers))
+	}
+	if len(c.AllowedUpdates) > 0 {
+		opts = append(opts, bot.WithAllowedUpdates(c.AllowedUpdates))
+	}
+	if c.Webhook.SecretToken != "" {
+		opts = append(opts, bot.WithWebhookSecretToken(c.Webhook.SecretToken))
+	}
+	return opts
+}
@@ -0,0 +1,34 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	t.Setenv("TEST_BOT_TOKEN", "123:abc")
+	config := Config{Token: "${TEST_BOT_TOKEN}"}
+	config.ExpandEnv()
+	if config.Token != "123:abc" {
+		t.Fatalf("token is not expanded: %q", config.Token)
+	}
+	if err := config.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	invalid := Config{
+		Mode:           ModeWebhook,
+		Webhook:        WebhookConfig{URL: "http://example.com", SecretToken: "bad token", KeyFile: "key.pem", SelfSigned: true},
+		AllowedUpdates: []string{"message", "unknown"},
+		Workers:        -1,
+	}
+	err := invalid.Validate()
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{"token is required", "https webhook url", "secret_token", `"unknown"`, "workers", "set together", "self_signed"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("missing %q in %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// VerdictAction is the action taken on a message by the content filter, ordered by severity.
+type VerdictAction int
+
+// Content filter actions.
+const (
+	VerdictAllow  VerdictAction = iota // Route the message
+	VerdictFlag                        // Route the message and notify the flag hooks
+	VerdictDelete                      // Delete the message without routing it
+	VerdictBan                         // Delete the message and ban its sender from the chat
+)
+
+func (a VerdictAction) String() string {
+	switch a {
+	case VerdictAllow:
+		return "allow"
+	case VerdictFlag:
+		return "flag"
+	case VerdictDelete:
+		return "delete"
+	case VerdictBan:
+		return "ban"
+	default:
+		return fmt.Sprintf("VerdictAction(%d)", int(a))
+	}
+}
+
+// Verdict is the decision of a content filter about a message.
+type Verdict struct {
+	Action VerdictAction
+	Reason string // Why the action was taken, e.g. the matched keyword
+}
+
+// ContentFilterFunc decides about an incoming message, text or media.
+type ContentFilterFunc = func(ctx context.Context, update *Update) (Verdict, error)
+
+// ContentFlagHook is notified about messages flagged by the content filter.
+type ContentFlagHook = func(ctx context.Context, update *Update, verdict Verdict)
+
+// contentFilterOptions holds configuration for the content filter.
+type contentFilterOptions struct {
+	filter      ContentFilterFunc // Policy deciding about every incoming message
+	flagHooks   []ContentFlagHook // Hooks notified about flagged messages
+	banDuration time.Duration     // Duration of bans, zero for forever
+}
+
+// ContentFilterOption defines a function type for configuring the content filter.
+type ContentFilterOption func(*contentFilterOptions)
+
+// WithContentFlagHook adds a hook notified about flagged messages, e.g. to queue them for review.
+// Multiple calls append hooks.
+func WithContentFlagHook(hook ContentFlagHook) ContentFilterOption {
+	return func(o *contentFilterOptions) {
+		o.flagHooks = append(o.flagHooks, hook)
+	}
+}
+
+// WithContentBanDuration limits bans issued for VerdictBan to the duration. Defaults to forever.
+func WithContentBanDuration(d time.Duration) ContentFilterOption {
+	return func(o *contentFilterOptions) {
+		o.banDuration = d
+	}
+}
+
+type contentVerdictKey struct{}
+
+// ContentVerdict returns the verdict of the content filter for the update being processed,
+// e.g. to let handlers treat flagged messages differently.
+func ContentVerdict(ctx context.Context) (Verdict, bool) {
+	verdict, ok := ctx.Value(contentVerdictKey{}).(Verdict)
+	return verdict, ok
+}
+
+// filteredMessage returns the message of the update checked by the content filter. Business
+// messages are not filtered, they belong to the chats of the business account.
+func filteredMessage(update *Update) *models.Message {
+	return effectiveMessage(update, false)
+}
+
+// newContentFilterMiddleware creates a middleware applying the verdict of the filter to every
+// incoming message before it is routed. A failing filter lets the message pass, so an outage
+// of a moderation API does not stop the bot. Flagged messages are also reported to the admin
+// chat, see WithAdminChat.
+func newContentFilterMiddleware(app *Bot, opts *contentFilterOptions) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, update *Update) error {
+			msg := filteredMessage(update)
+			if msg == nil {
+				return next(ctx, update)
+			}
+			verdict, err := opts.filter(ctx, update)
+			if err != nil {
+				slog.WarnContext(ctx, "content filter error", slog.Int64("chat_id", msg.Chat.ID), slog.Any("error", err))
+				return next(ctx, update)
+			}
+			ctx = context.WithValue(ctx, contentVerdictKey{}, verdict)
+			switch verdict.Action {
+			case VerdictAllow:
+				return next(ctx, update)
+			case VerdictFlag:
+				app.NotifyAdmin("flagged message %d in chat %d: %s", msg.ID, msg.Chat.ID, verdict.Reason)
+				for _, hook := range opts.flagHooks {
+					hook(ctx, update, verdict)
+				}
+				return next(ctx, update)
+			}
+			client := ClientFromContext(ctx)
+			if client == nil {
+				return ErrNoClient
+			}
+			slog.InfoContext(ctx, "content filter removed message", slog.Int64("chat_id", msg.Chat.ID),
+				slog.String("action", verdict.Action.String()), slog.String("reason", verdict.Reason))
+			if err = DeleteTo(ctx, client, msg.Chat.ID, msg.ID); err != nil {
+				return err
+			}
+			if verdict.Action != VerdictBan || msg.From == nil || msg.Chat.Type == models.ChatTypePrivate {
+				return nil
+			}
+			params := &bot.BanChatMemberParams{ChatID: msg.Chat.ID, UserID: msg.From.ID}
+			if opts.banDuration > 0 {
+				params.UntilDate = int(time.Now().Add(opts.banDuration).Unix())
+			}
+			if _, err = client.BanChatMember(ctx, params); err != nil {
+				return fmt.Errorf("ban user %d in chat %d: %w", msg.From.ID, msg.Chat.ID, err)
+			}
+			return nil
+		}
+	}
+}
+
+// MessageContent returns the text or the media caption of the message checked by the content
+// filter, empty for updates without one. Business messages are not checked by the content
+// filter, so their content is empty too.
+func MessageContent(update *Update) string {
+	msg := filteredMessage(update)
+	if msg == nil {
+		return ""
+	}
+	if msg.Text != "" {
+		return msg.Text
+	}
+	return msg.Caption
+}
+
+// KeywordFilter returns a content filter taking the action on messages whose text or caption
+// contains one of the keywords as whole words, ignoring case and punctuation. Keywords may be
+// phrases of several words, e.g. "free money", matching the words in sequence.
+func KeywordFilter(action VerdictAction, keywords ...string) ContentFilterFunc {
+	phrases := make([][]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		if words := keywordFields(keyword); len(words) > 0 {
+			phrases = append(phrases, words)
+		}
+	}
+	return func(ctx context.Context, update *Update) (Verdict, error) {
+		fields := keywordFields(MessageContent(update))
+		for i := range fields {
+			for _, phrase := range phrases {
+				if i+len(phrase) <= len(fields) && slices.Equal(fields[i:i+len(phrase)], phrase) {
+					return Verdict{Action: action, Reason: "keyword " + strings.Join(phrase, " ")}, nil
+				}
+			}
+		}
+		return Verdict{}, nil
+	}
+}
+
+// keywordFields splits the lower-cased text into words of letters and numbers.
+func keywordFields(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// ModerationFunc classifies a text with an external moderation API, returning a score between
+// 0 and 1 per category, e.g. "spam" or "harassment".
+type ModerationFunc = func(ctx context.Context, text string) (map[string]float64, error)
+
+// ModerationThreshold takes the action when the score of the category reaches the threshold.
+type ModerationThreshold struct {
+	Category string
+	Score    float64
+	Action   VerdictAction
+}
+
+// ModerationFilter adapts an external moderation API to a content filter. Messages without text
+// or caption are not sent to the API. The most severe action of the reached thresholds is taken.
+func ModerationFilter(moderate ModerationFunc, thresholds ...ModerationThreshold) ContentFilterFunc {
+	return func(ctx context.Context, update *Update) (Verdict, error) {
+		text := MessageContent(update)
+		if text == "" {
+			return Verdict{}, nil
+		}
+		scores, err := moderate(ctx, text)
+		if err != nil {
+			return Verdict{}, err
+		}
+		var verdict Verdict
+		for _, threshold := range thresholds {
+			score, ok := scores[threshold.Category]
+			if ok && score >= threshold.Score && threshold.Action > verdict.Action {
+				verdict = Verdict{Action: threshold.Action, Reason: fmt.Sprintf("%s score %.2f", threshold.Category, score)}
+			}
+		}
+		return verdict, nil
+	}
+}
+
+// ChainContentFilters combines filters into one taking the most severe verdict. A failing
+// filter fails the chain.
+func ChainContentFilters(filters ...ContentFilterFunc) ContentFilterFunc {
+	return func(ctx context.Context, update *Update) (Verdict, error) {
+		var verdict Verdict
+		for _, filter := range filters {
+			v, err := filter(ctx, update)
+			if err != nil {
+				return Verdict{}, err
+			}
+			if v.Action > verdict.Action {
+				verdict = v
+			}
+		}
+		return verdict, nil
+	}
+}
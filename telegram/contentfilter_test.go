@@ -0,0 +1,99 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sync"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestContentFilter(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls = append(calls, path.Base(r.URL.Path))
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+	var flagged []Verdict
+	filter := ChainContentFilters(
+		KeywordFilter(VerdictFlag, "casino"),
+		KeywordFilter(VerdictDelete, "spam"),
+		ModerationFilter(func(ctx context.Context, text string) (map[string]float64, error) {
+			if text == "down" {
+				return nil, errors.New("unavailable")
+			}
+			return map[string]float64{"scam": float64(len(text)) / 20}, nil
+		}, ModerationThreshold{Category: "scam", Score: 0.9, Action: VerdictBan}),
+	)
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL), WithContentFilter(filter,
+		WithContentFlagHook(func(ctx context.Context, update *Update, verdict Verdict) {
+			flagged = append(flagged, verdict)
+		}),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var routed []string
+	app.BindMatch(func(update *Update) bool { return update.Message != nil }, func(ctx context.Context, update *Update) error {
+		verdict, _ := ContentVerdict(ctx)
+		routed = append(routed, update.Message.Text+":"+verdict.Action.String())
+		return nil
+	})
+
+	ctx := context.Background()
+	for i, text := range []string{"hello", "CASINO night", "buy spam", "down", "send me your wallet seed now"} {
+		body := fmt.Sprintf(`{"update_id":%d,"message":{"message_id":%d,"from":{"id":7},"chat":{"id":-100,"type":"supergroup"},"text":%q}}`, i+1, i+1, text)
+		if err = app.HandleUpdateJSON(ctx, []byte(body)); err != nil {
+			t.Errorf("unexpected error for %q: %v", text, err)
+		}
+	}
+
+	if fmt.Sprint(routed) != "[hello:allow CASINO night:flag down:allow]" {
+		t.Errorf("unexpected routed messages: %v", routed)
+	}
+	if len(flagged) != 1 || flagged[0].Reason != "keyword casino" {
+		t.Errorf("unexpected flagged messages: %v", flagged)
+	}
+	if fmt.Sprint(calls) != "[deleteMessage deleteMessage banChatMember]" {
+		t.Errorf("unexpected API calls: %v", calls)
+	}
+}
+
+func TestKeywordFilter(t *testing.T) {
+	filter := KeywordFilter(VerdictDelete, "free money", "Casino")
+	tests := []struct {
+		text string
+		want string // reason, empty when allowed
+	}{
+		{"Get FREE   money, now!", "keyword free money"},
+		{"free, money", "keyword free money"},
+		{"money for free", ""},
+		{"freemoney", ""},
+		{"casinos", ""},
+		{"the casino.", "keyword casino"},
+	}
+	for _, tt := range tests {
+		update := &Update{Message: &models.Message{Text: tt.text}}
+		verdict, err := filter(context.Background(), update)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if verdict.Reason != tt.want {
+			t.Errorf("%q: got reason %q, want %q", tt.text, verdict.Reason, tt.want)
+		}
+	}
+	// business messages are not checked by the content filter
+	business := &Update{BusinessMessage: &models.Message{Text: "free money"}}
+	if verdict, _ := filter(context.Background(), business); verdict.Action != VerdictAllow {
+		t.Errorf("business message was filtered: %+v", verdict)
+	}
+}
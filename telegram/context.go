@@ -2,43 +2,128 @@ package telegram
 
 import (
 	"context"
+	"sync"
 	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
 )
 
-var _ context.Context = (*values)(nil)
+// Key is a typed key of a value carried by the context, created with NewKey. Keys are compared
+// by identity, so they never collide with each other, even when sharing a name, nor with the
+// string or struct keys of other packages.
+type Key[T any] struct {
+	name string
+}
+
+// NewKey creates a key for values of type T. The name is only used for debugging.
+func NewKey[T any](name string) *Key[T] {
+	return &Key[T]{name: name}
+}
+
+// String returns the name of the key.
+func (k *Key[T]) String() string {
+	return k.name
+}
+
+// valueBag holds the values set with SetValue while an update is processed.
+type valueBag struct {
+	mu   sync.RWMutex
+	data map[any]any
+}
+
+type valueBagKey struct{}
 
-type values struct {
-	context.Context
-	data map[string]any
+// WithValue returns a copy of ctx carrying the value under the key, visible to the derived
+// context only. It shadows values set with SetValue for the same key.
+func WithValue[T any](ctx context.Context, key *Key[T], v T) context.Context {
+	return context.WithValue(ctx, key, v)
 }
 
-func contextWithValues(ctx context.Context, data map[string]any) context.Context {
-	if len(data) == 0 {
-		return ctx
+// SetValue stores the value under the key for the rest of the update processing, so a
+// middleware can pass data downstream, or a handler back to the middlewares wrapping it,
+// without re-wrapping the context. It returns false if the context was not created for
+// update processing.
+func SetValue[T any](ctx context.Context, key *Key[T], v T) bool {
+	bag, ok := ctx.Value(valueBagKey{}).(*valueBag)
+	if !ok {
+		return false
 	}
-	return &values{
-		Context: ctx,
-		data:    data,
+	bag.mu.Lock()
+	defer bag.mu.Unlock()
+	bag.data[key] = v
+	return true
+}
+
+// Value returns the value stored under the key with WithValue or SetValue.
+func Value[T any](ctx context.Context, key *Key[T]) (T, bool) {
+	if v, ok := ctx.Value(key).(T); ok {
+		return v, true
 	}
+	if bag, ok := ctx.Value(valueBagKey{}).(*valueBag); ok {
+		bag.mu.RLock()
+		defer bag.mu.RUnlock()
+		if v, ok := bag.data[key].(T); ok {
+			return v, true
+		}
+	}
+	var zero T
+	return zero, false
 }
 
-func (c *values) Deadline() (deadline time.Time, ok bool) {
-	return c.Context.Deadline()
+type updateStartTimeKey struct{}
+
+type clientKey struct{}
+
+// ClientFromContext returns the bot client processing the current update,
+// or nil if the context was not created for update processing.
+func ClientFromContext(ctx context.Context) *bot.Bot {
+	client, _ := ctx.Value(clientKey{}).(*bot.Bot)
+	return client
 }
 
-func (c *values) Done() <-chan struct{} {
-	return c.Context.Done()
+// UpdateStartTime returns the time the update was received by the bot.
+// It returns the zero time if the context was not created for update processing.
+func UpdateStartTime(ctx context.Context) time.Time {
+	if t, ok := ctx.Value(updateStartTimeKey{}).(time.Time); ok {
+		return t
+	}
+	return time.Time{}
 }
 
-func (c *values) Err() error {
-	return c.Context.Err()
+// UpdateBudget returns the processing time left for the current update.
+// The second return value is false if no processing budget was configured.
+func UpdateBudget(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(deadline), true
 }
 
-func (c *values) Value(key any) any {
-	if strKey, ok := key.(string); ok {
-		if v, exist := c.data[strKey]; exist {
-			return v
+// BaseContextFunc derives the base context used to process a single update,
+// e.g. to attach values or cancellation tied to the bot's lifecycle.
+type BaseContextFunc = func(ctx context.Context, update *Update) context.Context
+
+// newUpdateContextMiddleware creates a middleware that prepares the per-update context.
+// It records the receipt time, creates the value bag of SetValue, stores the client and the Responder of the update, applies the base context
+// and enforces the processing budget.
+func newUpdateContextMiddleware(base BaseContextFunc, timeout time.Duration) bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			ctx = context.WithValue(ctx, updateStartTimeKey{}, time.Now())
+			ctx = context.WithValue(ctx, valueBagKey{}, &valueBag{data: map[any]any{}})
+			ctx = context.WithValue(ctx, clientKey{}, b)
+			ctx = contextWithResponder(ctx, b, update)
+			if base != nil {
+				ctx = base(ctx, update)
+			}
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+			next(ctx, b, update)
 		}
 	}
-	return c.Context.Value(key)
 }
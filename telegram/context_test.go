@@ -0,0 +1,87 @@
+package telegram
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+func TestContextValues(t *testing.T) {
+	locale := NewKey[string]("locale")
+	other := NewKey[string]("locale")
+
+	if SetValue(context.Background(), locale, "en") {
+		t.Error("SetValue must fail outside update processing")
+	}
+	if v, ok := Value(WithValue(context.Background(), locale, "en"), locale); !ok || v != "en" {
+		t.Errorf("WithValue: got %q, %v", v, ok)
+	}
+
+	newUpdateContextMiddleware(nil, 0)(func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		// a middleware sets the value, the wrapped handler reads it from the same context
+		if !SetValue(ctx, locale, "de") {
+			t.Fatal("SetValue failed during update processing")
+		}
+		if v, _ := Value(ctx, locale); v != "de" {
+			t.Errorf("SetValue: got %q", v)
+		}
+		if _, ok := Value(ctx, other); ok {
+			t.Error("keys with the same name must not collide")
+		}
+		if ctx.Value("locale") != nil {
+			t.Error("typed keys must not answer string keys")
+		}
+		if v, _ := Value(WithValue(ctx, locale, "fr"), locale); v != "fr" {
+			t.Errorf("WithValue must shadow SetValue: got %q", v)
+		}
+	})(context.Background(), nil, &Update{})
+}
+
+func TestUpdateContext(t *testing.T) {
+	tenant := NewKey[string]("tenant")
+	app, err := NewApp(Config{Token: "token"},
+		WithBaseContext(func(ctx context.Context, update *Update) context.Context {
+			return WithValue(ctx, tenant, "acme")
+		}),
+		WithUpdateTimeout(time.Minute),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		start  time.Time
+		budget time.Duration
+		ok     bool
+		value  string
+	)
+	app.BindCommand("check", func(ctx context.Context, update *Update) error {
+		start = UpdateStartTime(ctx)
+		budget, ok = UpdateBudget(ctx)
+		value, _ = Value(ctx, tenant)
+		return nil
+	})
+	before := time.Now()
+	body := `{"update_id":1,"message":{"message_id":1,"date":1,"chat":{"id":1,"type":"private"},"text":"/check"}}`
+	if err = app.HandleUpdateJSON(context.Background(), []byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	if start.Before(before) || time.Since(start) > time.Second {
+		t.Errorf("unexpected update start time %s", start)
+	}
+	if !ok || budget <= 0 || budget > time.Minute {
+		t.Errorf("unexpected budget %s, %v", budget, ok)
+	}
+	if value != "acme" {
+		t.Errorf("base context was not applied: %q", value)
+	}
+
+	if !UpdateStartTime(context.Background()).IsZero() {
+		t.Error("start time outside of update processing must be zero")
+	}
+	if _, ok = UpdateBudget(context.Background()); ok {
+		t.Error("no budget expected outside of update processing")
+	}
+}
@@ -0,0 +1,159 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+)
+
+// DeadLetter is an update whose handler returned an error or panicked.
+type DeadLetter struct {
+	Update      *Update   `json:"update"`
+	Error       string    `json:"error"`        // Error of the last failed attempt
+	Panic       bool      `json:"panic"`        // Whether the last attempt panicked
+	Attempts    int       `json:"attempts"`     // Number of failed attempts
+	FirstFailed time.Time `json:"first_failed"` // Time of the first failed attempt
+	LastFailed  time.Time `json:"last_failed"`  // Time of the last failed attempt
+}
+
+// DeadLetterFilter selects dead letters to replay.
+type DeadLetterFilter struct {
+	UpdateType  string // Only updates of this type, see UpdateType, empty for all
+	MaxAttempts int    // Only letters that failed at most this many times, 0 for all
+	Limit       int    // Maximum number of letters, 0 for all
+}
+
+// Match reports whether the letter satisfies the filter, ignoring Limit.
+func (f DeadLetterFilter) Match(letter *DeadLetter) bool {
+	if f.UpdateType != "" && UpdateType(letter.Update) != f.UpdateType {
+		return false
+	}
+	if f.MaxAttempts > 0 && letter.Attempts > f.MaxAttempts {
+		return false
+	}
+	return true
+}
+
+// DeadLetterStore persists failed updates so they can be replayed later.
+type DeadLetterStore interface {
+	// Put records a failed attempt, incrementing Attempts when the update is already stored.
+	Put(ctx context.Context, letter *DeadLetter) error
+	// List returns the letters matching the filter ordered by FirstFailed.
+	List(ctx context.Context, filter DeadLetterFilter) ([]*DeadLetter, error)
+	// Delete removes the letter of the update.
+	Delete(ctx context.Context, updateID int64) error
+}
+
+// MemoryDeadLetterStore is an in-memory DeadLetterStore, suitable for tests and single instance bots.
+type MemoryDeadLetterStore struct {
+	mu      sync.Mutex
+	letters map[int64]*DeadLetter
+}
+
+// NewMemoryDeadLetterStore creates an empty in-memory dead-letter store.
+func NewMemoryDeadLetterStore() *MemoryDeadLetterStore {
+	return &MemoryDeadLetterStore{letters: map[int64]*DeadLetter{}}
+}
+
+// Put implements DeadLetterStore.
+func (s *MemoryDeadLetterStore) Put(ctx context.Context, letter *DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record := *letter
+	if old, ok := s.letters[letter.Update.ID]; ok {
+		record.Attempts = old.Attempts + letter.Attempts
+		record.FirstFailed = old.FirstFailed
+	}
+	s.letters[letter.Update.ID] = &record
+	return nil
+}
+
+// List implements DeadLetterStore.
+func (s *MemoryDeadLetterStore) List(ctx context.Context, filter DeadLetterFilter) ([]*DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	letters := make([]*DeadLetter, 0, len(s.letters))
+	for _, letter := range s.letters {
+		if filter.Match(letter) {
+			record := *letter
+			letters = append(letters, &record)
+		}
+	}
+	sort.Slice(letters, func(i, j int) bool {
+		return letters[i].FirstFailed.Before(letters[j].FirstFailed)
+	})
+	if filter.Limit > 0 && len(letters) > filter.Limit {
+		letters = letters[:filter.Limit]
+	}
+	return letters, nil
+}
+
+// Delete implements DeadLetterStore.
+func (s *MemoryDeadLetterStore) Delete(ctx context.Context, updateID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.letters, updateID)
+	return nil
+}
+
+// withDeadLetters wraps an error handler so every failed update is stored before being handled.
+func withDeadLetters(store DeadLetterStore, next ErrorHandlerFunc) ErrorHandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *Update, err error) {
+		if update != nil {
+			var panicErr *PanicError
+			now := time.Now()
+			e := store.Put(context.WithoutCancel(ctx), &DeadLetter{
+				Update:      update,
+				Error:       err.Error(),
+				Panic:       errors.As(err, &panicErr),
+				Attempts:    1,
+				FirstFailed: now,
+				LastFailed:  now,
+			})
+			if e != nil {
+				slog.Error("store dead letter error", slog.Int64("update_id", update.ID), slog.String("error", e.Error()))
+			}
+		}
+		if next != nil {
+			next(ctx, b, update, err)
+		}
+	}
+}
+
+// ReplayDeadLetters reprocesses the stored updates matching the filter through the regular
+// handler chain with HandleUpdate, e.g. after a fix is deployed. Each update is replayed once
+// the previous one finished. Letters that succeed are removed from the store, letters that
+// fail again stay with an incremented attempt count. It returns the number of
+// successfully replayed updates. Replayed updates are skipped by NewIdempotencyMiddleware
+// if it shares its store with the original processing.
+func (b *Bot) ReplayDeadLetters(ctx context.Context, filter DeadLetterFilter) (int, error) {
+	if b.deadLetters == nil {
+		return 0, errors.New("dead-letter store is not configured")
+	}
+	letters, err := b.deadLetters.List(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	replayed := 0
+	for _, letter := range letters {
+		if err = ctx.Err(); err != nil {
+			return replayed, err
+		}
+		if err = b.HandleUpdate(ctx, letter.Update); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return replayed, ctxErr
+			}
+			continue
+		}
+		if err = b.deadLetters.Delete(ctx, letter.Update.ID); err != nil {
+			return replayed, err
+		}
+		replayed++
+	}
+	return replayed, nil
+}
@@ -0,0 +1,70 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+func TestDeadLetters(t *testing.T) {
+	store := NewMemoryDeadLetterStore()
+	handled := 0
+	handler := withDeadLetters(store, func(ctx context.Context, b *bot.Bot, update *Update, err error) {
+		handled++
+	})
+	ctx := context.Background()
+	update := &Update{ID: 7, Message: &models.Message{Text: "hi"}}
+	handler(ctx, nil, update, errors.New("fail"))
+	handler(ctx, nil, update, &PanicError{Value: "boom"})
+	if handled != 2 {
+		t.Fatalf("unexpected handled count %d", handled)
+	}
+	letters, err := store.List(ctx, DeadLetterFilter{UpdateType: "message"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(letters) != 1 || letters[0].Attempts != 2 || !letters[0].Panic {
+		t.Fatalf("unexpected letters: %+v", letters)
+	}
+	if letters, _ = store.List(ctx, DeadLetterFilter{MaxAttempts: 1}); len(letters) != 0 {
+		t.Errorf("expected no letters with at most one attempt, got %d", len(letters))
+	}
+}
+
+func TestReplayDeadLetters(t *testing.T) {
+	store := NewMemoryDeadLetterStore()
+	app, err := NewApp(Config{Token: "token"}, WithDeadLetterStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixed := false
+	app.BindCommand("flaky", func(ctx context.Context, update *Update) error {
+		if !fixed {
+			return errors.New("not fixed yet")
+		}
+		return nil
+	})
+	app.BindCommand("broken", func(ctx context.Context, update *Update) error {
+		return errors.New("still broken")
+	})
+	ctx := context.Background()
+	for _, body := range []string{
+		`{"update_id":1,"message":{"message_id":1,"from":{"id":7},"chat":{"id":7},"text":"/flaky"}}`,
+		`{"update_id":2,"message":{"message_id":2,"from":{"id":7},"chat":{"id":7},"text":"/broken"}}`,
+	} {
+		_ = app.HandleUpdateJSON(ctx, []byte(body))
+	}
+
+	fixed = true
+	replayed, err := app.ReplayDeadLetters(ctx, DeadLetterFilter{})
+	if err != nil || replayed != 1 {
+		t.Fatalf("ReplayDeadLetters = %d, %v", replayed, err)
+	}
+	letters, _ := store.List(ctx, DeadLetterFilter{})
+	if len(letters) != 1 || letters[0].Update.ID != 2 || letters[0].Attempts != 2 {
+		t.Fatalf("unexpected letters after replay: %+v", letters)
+	}
+}
@@ -0,0 +1,119 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// debugUpdateLimit bounds the echoed update JSON so the reply fits into a single message.
+const debugUpdateLimit = MaxMessageLength - 64
+
+// debugOptions holds configuration for the diagnostics commands.
+type debugOptions struct {
+	stats       *StatsCollector // Collector included in "/debug stats"
+	middlewares []MiddlewareFunc
+}
+
+// DebugOption defines a function type for configuring the diagnostics commands.
+type DebugOption func(*debugOptions)
+
+// WithDebugStats includes the statistics of the collector in "/debug stats".
+func WithDebugStats(collector *StatsCollector) DebugOption {
+	return func(o *debugOptions) {
+		o.stats = collector
+	}
+}
+
+// WithDebugMiddlewares applies the middlewares to the diagnostics commands.
+func WithDebugMiddlewares(middlewares ...MiddlewareFunc) DebugOption {
+	return func(o *debugOptions) {
+		o.middlewares = append(o.middlewares, middlewares...)
+	}
+}
+
+// BindDebugCommands registers developer commands for production troubleshooting:
+//   - "/ping" replies with the round trip latency of a getMe call to the Bot API
+//   - "/debug update" replies with the raw JSON of the replied-to message, or of the update itself
+//   - "/debug stats" replies with the health, runtime and, see WithDebugStats, update statistics
+//
+// Only the given admins receive a reply, updates from other users are ignored silently.
+func (b *Bot) BindDebugCommands(admins []int64, options ...DebugOption) {
+	opts := &debugOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+	middlewares := append([]MiddlewareFunc{newAdminOnlyMiddleware(admins)}, opts.middlewares...)
+	b.BindCommand("ping", func(ctx context.Context, update *Update) error {
+		start := time.Now()
+		if _, err := b.API().GetMe(ctx); err != nil {
+			return err
+		}
+		return b.SendMessage(ctx, update, &Message{
+			Text: fmt.Sprintf("pong: %s", time.Since(start).Round(time.Millisecond)),
+		})
+	}, middlewares...)
+	b.BindCommand("debug", func(ctx context.Context, update *Update) error {
+		args := strings.Fields(update.Message.Text)
+		switch {
+		case len(args) > 1 && args[1] == "update":
+			return b.SendMessage(ctx, update, debugUpdateMessage(update))
+		case len(args) > 1 && args[1] == "stats":
+			return b.SendMessage(ctx, update, &Message{Text: b.debugStats(ctx, opts.stats)})
+		default:
+			return b.SendMessage(ctx, update, &Message{Text: "usage: /debug update|stats"})
+		}
+	}, middlewares...)
+}
+
+// newAdminOnlyMiddleware drops updates of users not contained in admins.
+func newAdminOnlyMiddleware(admins []int64) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, update *Update) error {
+			user := UpdateUser(update)
+			if user == nil || !slices.Contains(admins, user.ID) {
+				return nil
+			}
+			return next(ctx, update)
+		}
+	}
+}
+
+func debugUpdateMessage(update *Update) *Message {
+	var payload any = update
+	if update.Message != nil && update.Message.ReplyToMessage != nil {
+		payload = update.Message.ReplyToMessage
+	}
+	raw, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return &Message{Text: fmt.Sprintf("encode update: %v", err)}
+	}
+	text := string(raw)
+	if runes := []rune(text); len(runes) > debugUpdateLimit {
+		text = string(runes[:debugUpdateLimit]) + "\n…"
+	}
+	return NewTextBuilder().Pre(text, "json").Message()
+}
+
+func (b *Bot) debugStats(ctx context.Context, collector *StatsCollector) string {
+	health := b.Health(ctx)
+	var memory runtime.MemStats
+	runtime.ReadMemStats(&memory)
+	var s strings.Builder
+	fmt.Fprintf(&s, "Mode: %s\n", health.Mode)
+	fmt.Fprintf(&s, "API reachable: %t\n", health.APIReachable)
+	if !health.LastUpdate.IsZero() {
+		fmt.Fprintf(&s, "Last update: %s\n", health.LastUpdate.Format(time.DateTime))
+	}
+	fmt.Fprintf(&s, "Queue depth: %d\n", health.QueueDepth)
+	fmt.Fprintf(&s, "Goroutines: %d\n", runtime.NumGoroutine())
+	fmt.Fprintf(&s, "Heap: %d KiB\n", memory.HeapAlloc/1024)
+	if collector != nil {
+		s.WriteString(collector.Stats().String())
+	}
+	return s.String()
+}
@@ -0,0 +1,44 @@
+package telegram
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestDebugUpdateMessage(t *testing.T) {
+	update := &Update{ID: 1, Message: &models.Message{
+		Text:           "/debug update",
+		ReplyToMessage: &models.Message{ID: 42, Text: "original"},
+	}}
+	m := debugUpdateMessage(update)
+	if !strings.Contains(m.Text, `"message_id": 42`) || strings.Contains(m.Text, "/debug update") {
+		t.Errorf("expected the replied-to message, got %s", m.Text)
+	}
+	if len(m.Entities) != 1 || m.Entities[0].Type != models.MessageEntityTypePre {
+		t.Errorf("expected a pre entity, got %+v", m.Entities)
+	}
+
+	update.Message.ReplyToMessage = &models.Message{Text: strings.Repeat("x", 2*MaxMessageLength)}
+	if m = debugUpdateMessage(update); len([]rune(m.Text)) > MaxMessageLength {
+		t.Errorf("update JSON is not truncated: %d", len([]rune(m.Text)))
+	}
+}
+
+func TestAdminOnlyMiddleware(t *testing.T) {
+	called := false
+	h := newAdminOnlyMiddleware([]int64{1})(func(ctx context.Context, update *Update) error {
+		called = true
+		return nil
+	})
+	_ = h(context.Background(), &Update{Message: &models.Message{From: &models.User{ID: 2}}})
+	if called {
+		t.Error("non admin reached the handler")
+	}
+	_ = h(context.Background(), &Update{Message: &models.Message{From: &models.User{ID: 1}}})
+	if !called {
+		t.Error("admin did not reach the handler")
+	}
+}
@@ -0,0 +1,64 @@
+package telegram
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+// maxStartParamLength is the maximum length of a deep-link start parameter accepted by Telegram.
+const maxStartParamLength = 64
+
+// StartLink builds a t.me deep link that opens a chat with the bot and sends "/start <param>",
+// where the parameter encodes the route and data as produced by EncodeStartData.
+// Telegram silently drops parameters longer than 64 characters, so keep the data small.
+func StartLink[T any](botUsername, route string, data T) string {
+	return startLink(botUsername, MarshalStartData(route, data))
+}
+
+// BotStartLink is like StartLink, resolving the username of the bot with Bot.Self.
+// Unlike StartLink, it returns the error of encoding the data.
+func BotStartLink[T any](ctx context.Context, b *Bot, route string, data T) (string, error) {
+	me, err := b.Self(ctx)
+	if err != nil {
+		return "", err
+	}
+	param, err := EncodeStartData(route, data)
+	if err != nil {
+		return "", err
+	}
+	return startLink(me.Username, param), nil
+}
+
+func startLink(botUsername, param string) string {
+	return "https://t.me/" + strings.TrimPrefix(botUsername, "@") + "?start=" + url.QueryEscape(param)
+}
+
+// StartParam returns the deep-link parameter of a "/start <param>" message, or an empty string.
+func StartParam(update *Update) string {
+	if update == nil || update.Message == nil {
+		return ""
+	}
+	fields := strings.Fields(update.Message.Text)
+	if len(fields) < 2 {
+		return ""
+	}
+	command, _, _ := strings.Cut(fields[0], "@")
+	if command != "/start" {
+		return ""
+	}
+	return fields[1]
+}
+
+// BindStart registers a handler for "/start" deep links whose parameter belongs to the given route.
+// Both the bare route ("/start ref") and encoded payloads ("/start ref_<data>") are matched;
+// use StartParam and UnmarshalStartData to decode the payload inside the handler.
+func (b *Bot) BindStart(param string, handlerFunc HandlerFunc, middlewares ...MiddlewareFunc) {
+	b.BindMatch(func(update *Update) bool {
+		start := StartParam(update)
+		if len(start) > maxStartParamLength {
+			return false
+		}
+		return start == param || strings.HasPrefix(start, param+"_")
+	}, handlerFunc, middlewares...)
+}
@@ -0,0 +1,315 @@
+package telegram
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// MaxMessageLength is the maximum length of a message text in UTF-16 code units.
+const MaxMessageLength = 4096
+
+// SpanStyle is the inline style of a Span.
+type SpanStyle int
+
+// Inline styles of a Span.
+const (
+	StylePlain SpanStyle = iota
+	StyleBold
+	StyleItalic
+	StyleCode
+	StyleLink
+)
+
+// Span is a run of inline text of a Document.
+type Span struct {
+	Text  string
+	Style SpanStyle
+	URL   string // Target of StyleLink spans
+}
+
+// SpanText creates a plain span.
+func SpanText(text string) Span { return Span{Text: text} }
+
+// SpanBold creates a bold span.
+func SpanBold(text string) Span { return Span{Text: text, Style: StyleBold} }
+
+// SpanItalic creates an italic span.
+func SpanItalic(text string) Span { return Span{Text: text, Style: StyleItalic} }
+
+// SpanCode creates an inline monospace span.
+func SpanCode(text string) Span { return Span{Text: text, Style: StyleCode} }
+
+// SpanLink creates a span linking to the URL.
+func SpanLink(text, url string) Span { return Span{Text: text, Style: StyleLink, URL: url} }
+
+// DocumentBlock is a block element of a Document: Paragraph, List, CodeBlock or Table.
+type DocumentBlock interface {
+	// render returns the rendered pieces of the block, each shorter than MaxMessageLength,
+	// and the separator joining consecutive pieces kept in one message.
+	render(f *docFormat) (pieces []string, sep string)
+}
+
+// Paragraph is a block of inline spans.
+type Paragraph []Span
+
+// List is a bulleted or numbered list of inline items.
+type List struct {
+	Items   []Paragraph
+	Ordered bool
+}
+
+// CodeBlock is a preformatted block of code.
+type CodeBlock struct {
+	Code     string
+	Language string
+}
+
+// Table is rendered as a preformatted block with aligned columns.
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// Document is a structured message rendered to Telegram HTML or MarkdownV2 with RenderDocument.
+type Document []DocumentBlock
+
+// docFormat holds the escaping and markup rules of a parse mode.
+type docFormat struct {
+	escape     func(string) string
+	escapeCode func(string) string
+	bold       func(string) string
+	italic     func(string) string
+	code       func(string) string
+	link       func(text, url string) string
+	pre        func(code, language string) string
+}
+
+var htmlFormat = &docFormat{
+	escape:     htmlEscaper.Replace,
+	escapeCode: htmlEscaper.Replace,
+	bold:       func(s string) string { return "<b>" + s + "</b>" },
+	italic:     func(s string) string { return "<i>" + s + "</i>" },
+	code:       func(s string) string { return "<code>" + s + "</code>" },
+	link: func(text, url string) string {
+		return `<a href="` + htmlEscaper.Replace(url) + `">` + text + "</a>"
+	},
+	pre: func(code, language string) string {
+		if language == "" {
+			return "<pre>" + code + "</pre>"
+		}
+		return `<pre><code class="language-` + htmlEscaper.Replace(language) + `">` + code + "</code></pre>"
+	},
+}
+
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+var markdownV2Escaper = strings.NewReplacer(
+	`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`, "~", `\~`, "`", "\\`",
+	">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`, "|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+)
+
+var markdownV2CodeEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`")
+
+var markdownV2Format = &docFormat{
+	escape:     markdownV2Escaper.Replace,
+	escapeCode: markdownV2CodeEscaper.Replace,
+	bold:       func(s string) string { return "*" + s + "*" },
+	italic:     func(s string) string { return "_" + s + "_" },
+	code:       func(s string) string { return "`" + s + "`" },
+	link: func(text, url string) string {
+		return "[" + text + "](" + strings.NewReplacer(`\`, `\\`, ")", `\)`).Replace(url) + ")"
+	},
+	pre: func(code, language string) string {
+		return "```" + language + "\n" + code + "\n```"
+	},
+}
+
+// EscapeHTML escapes text for messages sent with the HTML parse mode.
+func EscapeHTML(text string) string {
+	return htmlEscaper.Replace(text)
+}
+
+// EscapeMarkdownV2 escapes text for messages sent with the MarkdownV2 parse mode.
+func EscapeMarkdownV2(text string) string {
+	return markdownV2Escaper.Replace(text)
+}
+
+// RenderDocument renders the document in the parse mode, HTML or MarkdownV2, and splits it
+// into messages shorter than MaxMessageLength. Other parse modes render HTML. Blocks are kept together when possible,
+// oversized lists, code blocks and tables are split between their lines, so every message
+// is valid markup on its own.
+func RenderDocument(doc Document, mode models.ParseMode) []*Message {
+	f := htmlFormat
+	if mode == models.ParseModeMarkdown {
+		f = markdownV2Format
+	} else {
+		mode = models.ParseModeHTML
+	}
+	var texts []string
+	var current strings.Builder
+	size := 0
+	flush := func() {
+		if size > 0 {
+			texts = append(texts, current.String())
+			current.Reset()
+			size = 0
+		}
+	}
+	for _, block := range doc {
+		pieces, sep := block.render(f)
+		for j, piece := range pieces {
+			joint := sep
+			if j == 0 {
+				joint = "\n\n"
+			}
+			if size > 0 && size+utf16Len(joint)+utf16Len(piece) > MaxMessageLength {
+				flush()
+			}
+			if size > 0 {
+				current.WriteString(joint)
+				size += utf16Len(joint)
+			}
+			current.WriteString(piece)
+			size += utf16Len(piece)
+		}
+	}
+	flush()
+	messages := make([]*Message, len(texts))
+	for i, text := range texts {
+		messages[i] = &Message{Text: text, ParseMode: mode}
+	}
+	return messages
+}
+
+func (f *docFormat) span(s Span) string {
+	switch s.Style {
+	case StyleBold:
+		return f.bold(f.escape(s.Text))
+	case StyleItalic:
+		return f.italic(f.escape(s.Text))
+	case StyleCode:
+		return f.code(f.escapeCode(s.Text))
+	case StyleLink:
+		return f.link(f.escape(s.Text), s.URL)
+	default:
+		return f.escape(s.Text)
+	}
+}
+
+// splitText splits text into chunks of at most size runes.
+func splitText(text string, size int) []string {
+	var chunks []string
+	for utf8.RuneCountInString(text) > size {
+		i, n := 0, 0
+		for n < size {
+			_, w := utf8.DecodeRuneInString(text[i:])
+			i += w
+			n++
+		}
+		chunks = append(chunks, text[:i])
+		text = text[i:]
+	}
+	return append(chunks, text)
+}
+
+// docChunkSize is the number of runes raw text is split into before rendering, so a chunk
+// still fits a message once escaped, which grows a rune to at most 6 code units, and marked up.
+const docChunkSize = 512
+
+// renderSpans packs the rendered spans into pieces shorter than MaxMessageLength.
+func (f *docFormat) renderSpans(spans []Span, prefix string) []string {
+	var pieces []string
+	current := prefix
+	for _, s := range spans {
+		for _, text := range splitText(s.Text, docChunkSize) {
+			rendered := f.span(Span{Text: text, Style: s.Style, URL: s.URL})
+			if current != "" && utf16Len(current)+utf16Len(rendered) > MaxMessageLength {
+				pieces = append(pieces, current)
+				current = ""
+			}
+			current += rendered
+		}
+	}
+	return append(pieces, current)
+}
+
+func (p Paragraph) render(f *docFormat) ([]string, string) {
+	return f.renderSpans(p, ""), ""
+}
+
+func (l List) render(f *docFormat) ([]string, string) {
+	var pieces []string
+	for i, item := range l.Items {
+		prefix := "• "
+		if l.Ordered {
+			prefix = strconv.Itoa(i+1) + ". "
+		}
+		pieces = append(pieces, f.renderSpans(item, f.escape(prefix))...)
+	}
+	return pieces, "\n"
+}
+
+// renderPre renders the lines as preformatted blocks shorter than MaxMessageLength.
+func (f *docFormat) renderPre(lines []string, language string) ([]string, string) {
+	var pieces []string
+	var chunk []string
+	size := 0
+	for _, line := range lines {
+		for _, part := range splitText(line, docChunkSize) {
+			escaped := f.escapeCode(part)
+			if len(chunk) > 0 && size+utf16Len(escaped)+1 > MaxMessageLength-128 {
+				pieces = append(pieces, f.pre(strings.Join(chunk, "\n"), language))
+				chunk, size = nil, 0
+			}
+			chunk = append(chunk, escaped)
+			size += utf16Len(escaped) + 1
+		}
+	}
+	pieces = append(pieces, f.pre(strings.Join(chunk, "\n"), language))
+	return pieces, "\n"
+}
+
+func (c CodeBlock) render(f *docFormat) ([]string, string) {
+	return f.renderPre(strings.Split(strings.TrimRight(c.Code, "\n"), "\n"), c.Language)
+}
+
+func (t Table) render(f *docFormat) ([]string, string) {
+	rows := t.Rows
+	if len(t.Header) > 0 {
+		rows = append([][]string{t.Header}, rows...)
+	}
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+	lines := make([]string, 0, len(rows)+1)
+	for r, row := range rows {
+		var line strings.Builder
+		for i, cell := range row {
+			if i > 0 {
+				line.WriteString(" | ")
+			}
+			line.WriteString(cell)
+			if i < len(row)-1 {
+				line.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)))
+			}
+		}
+		lines = append(lines, line.String())
+		if r == 0 && len(t.Header) > 0 {
+			var rule []string
+			for _, w := range widths {
+				rule = append(rule, strings.Repeat("-", w))
+			}
+			lines = append(lines, strings.Join(rule, "-+-"))
+		}
+	}
+	return f.renderPre(lines, "")
+}
@@ -0,0 +1,67 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestRenderDocumentHTML(t *testing.T) {
+	doc := Document{
+		Paragraph{SpanBold("Report <daily>"), SpanText(" for "), SpanLink("A&B", `https://example.com/?a=1&b="2"`)},
+		List{Items: []Paragraph{{SpanText("one")}, {SpanCode("x < y")}}, Ordered: true},
+		Table{Header: []string{"name", "n"}, Rows: [][]string{{"apples", "3"}}},
+	}
+	messages := RenderDocument(doc, models.ParseModeHTML)
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(messages))
+	}
+	want := `<b>Report &lt;daily&gt;</b> for <a href="https://example.com/?a=1&amp;b=&quot;2&quot;">A&amp;B</a>` + "\n\n" +
+		"1. one\n2. <code>x &lt; y</code>\n\n" +
+		"<pre>name   | n\n-------+--\napples | 3</pre>"
+	if messages[0].Text != want {
+		t.Errorf("unexpected text:\n%s\nwant:\n%s", messages[0].Text, want)
+	}
+}
+
+func TestRenderDocumentMarkdownV2(t *testing.T) {
+	doc := Document{
+		Paragraph{SpanItalic("v1.2 (beta)"), SpanText("!")},
+		CodeBlock{Code: "a := `b`\n", Language: "go"},
+	}
+	messages := RenderDocument(doc, models.ParseModeMarkdown)
+	want := "_v1\\.2 \\(beta\\)_\\!\n\n```go\na := \\`b\\`\n```"
+	if len(messages) != 1 || messages[0].Text != want {
+		t.Errorf("unexpected messages: %+v", messages)
+	}
+}
+
+func TestRenderDocumentParseMode(t *testing.T) {
+	doc := Document{Paragraph{SpanBold("bold")}}
+	for _, mode := range []models.ParseMode{"", models.ParseModeMarkdownV1, models.ParseModeHTML} {
+		messages := RenderDocument(doc, mode)
+		if len(messages) != 1 || messages[0].Text != "<b>bold</b>" || messages[0].ParseMode != models.ParseModeHTML {
+			t.Errorf("mode %q: unexpected messages: %+v", mode, messages)
+		}
+	}
+	if messages := RenderDocument(doc, models.ParseModeMarkdown); messages[0].ParseMode != models.ParseModeMarkdown {
+		t.Errorf("unexpected MarkdownV2 parse mode %q", messages[0].ParseMode)
+	}
+}
+
+func TestRenderDocumentSplit(t *testing.T) {
+	code := strings.Repeat(strings.Repeat("<", 100)+"\n", 200)
+	messages := RenderDocument(Document{CodeBlock{Code: code}}, models.ParseModeHTML)
+	if len(messages) < 2 {
+		t.Fatalf("expected the code block to be split, got %d messages", len(messages))
+	}
+	for _, m := range messages {
+		if utf16Len(m.Text) > MaxMessageLength {
+			t.Errorf("message exceeds limit: %d", utf16Len(m.Text))
+		}
+		if !strings.HasPrefix(m.Text, "<pre>") || !strings.HasSuffix(m.Text, "</pre>") {
+			t.Errorf("split block is not valid markup: %q...", m.Text[:20])
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package telegram
+
+import (
+	"context"
+	"unicode/utf16"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// maxCustomEmojiIDs is the number of IDs getCustomEmojiStickers accepts per call.
+const maxCustomEmojiIDs = 200
+
+// CustomEmojiIDs returns the distinct custom emoji IDs referenced by the entities, in order.
+func CustomEmojiIDs(entities []models.MessageEntity) []string {
+	var ids []string
+	seen := map[string]bool{}
+	for _, e := range entities {
+		if e.Type == models.MessageEntityTypeCustomEmoji && e.CustomEmojiID != "" && !seen[e.CustomEmojiID] {
+			seen[e.CustomEmojiID] = true
+			ids = append(ids, e.CustomEmojiID)
+		}
+	}
+	return ids
+}
+
+// GetCustomEmojiStickers resolves custom emoji IDs to their stickers, keyed by ID.
+// IDs are requested in batches of the API limit.
+func GetCustomEmojiStickers(ctx context.Context, b *bot.Bot, ids ...string) (map[string]*models.Sticker, error) {
+	stickers := make(map[string]*models.Sticker, len(ids))
+	for start := 0; start < len(ids); start += maxCustomEmojiIDs {
+		batch := ids[start:min(start+maxCustomEmojiIDs, len(ids))]
+		result, err := b.GetCustomEmojiStickers(ctx, &bot.GetCustomEmojiStickersParams{CustomEmojiIDs: batch})
+		if err != nil {
+			return nil, err
+		}
+		for _, sticker := range result {
+			stickers[sticker.CustomEmojiID] = sticker
+		}
+	}
+	return stickers, nil
+}
+
+// StripCustomEmoji removes the custom emoji entities, so the text renders with the fallback
+// emoji, e.g. when re-sending user content from a bot that can not use custom emoji.
+func StripCustomEmoji(entities []models.MessageEntity) []models.MessageEntity {
+	stripped := make([]models.MessageEntity, 0, len(entities))
+	for _, e := range entities {
+		if e.Type != models.MessageEntityTypeCustomEmoji {
+			stripped = append(stripped, e)
+		}
+	}
+	return stripped
+}
+
+// ReplaceCustomEmoji replaces the text of every custom emoji entity with the result of
+// replace and removes the entity. The offsets and lengths of the remaining entities are
+// adjusted to the new text.
+func ReplaceCustomEmoji(text string, entities []models.MessageEntity, replace func(models.MessageEntity) string) (string, []models.MessageEntity) {
+	units := utf16.Encode([]rune(text))
+	result := make([]uint16, 0, len(units))
+	type edit struct{ end, delta int }
+	var edits []edit
+	pos := 0
+	for _, e := range entities {
+		start, end := e.Offset, e.Offset+e.Length
+		if e.Type != models.MessageEntityTypeCustomEmoji || start < pos || end > len(units) {
+			continue
+		}
+		replacement := utf16.Encode([]rune(replace(e)))
+		result = append(result, units[pos:start]...)
+		result = append(result, replacement...)
+		edits = append(edits, edit{end: end, delta: len(replacement) - e.Length})
+		pos = end
+	}
+	result = append(result, units[pos:]...)
+	// shift maps an offset of the original text to the new text.
+	shift := func(offset int) int {
+		moved := offset
+		for _, ed := range edits {
+			if ed.end <= offset {
+				moved += ed.delta
+			}
+		}
+		return moved
+	}
+	kept := make([]models.MessageEntity, 0, len(entities))
+	for _, e := range entities {
+		if e.Type == models.MessageEntityTypeCustomEmoji {
+			continue
+		}
+		start, end := shift(e.Offset), shift(e.Offset+e.Length)
+		e.Offset, e.Length = start, end-start
+		kept = append(kept, e)
+	}
+	return string(utf16.Decode(result)), kept
+}
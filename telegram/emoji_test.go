@@ -0,0 +1,36 @@
+package telegram
+
+import (
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestReplaceCustomEmoji(t *testing.T) {
+	b := NewTextBuilder().CustomEmoji("🔥", "1").Text(" ").Bold("hot ").CustomEmoji("🔥", "1").Bold("!").Text(" ").Italic("end")
+	text, entities := ReplaceCustomEmoji(b.String(), b.Entities(), func(e models.MessageEntity) string {
+		return ":fire:"
+	})
+	if text != ":fire: hot :fire:! end" {
+		t.Fatalf("unexpected text %q", text)
+	}
+	want := []models.MessageEntity{
+		{Type: models.MessageEntityTypeBold, Offset: 7, Length: 4},
+		{Type: models.MessageEntityTypeBold, Offset: 17, Length: 1},
+		{Type: models.MessageEntityTypeItalic, Offset: 19, Length: 3},
+	}
+	if len(entities) != len(want) {
+		t.Fatalf("unexpected entities %+v", entities)
+	}
+	for i := range want {
+		if entities[i] != want[i] {
+			t.Errorf("entity %d = %+v, want %+v", i, entities[i], want[i])
+		}
+	}
+	if ids := CustomEmojiIDs(b.Entities()); len(ids) != 1 || ids[0] != "1" {
+		t.Errorf("unexpected ids %v", ids)
+	}
+	if len(StripCustomEmoji(b.Entities())) != 3 {
+		t.Error("custom emoji entities are not stripped")
+	}
+}
@@ -1,26 +1,149 @@
 package telegram
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-sphere/jsoncompressor"
 )
 
 // query prefix must be unique and has suffix ":" to separate the data
 // update.CallbackQuery.Data format: $route:json($data)
+// with version or expiry: $route:!base36($version).base36($expiry)!json($data)
+
+var (
+	// ErrCallbackExpired is returned by UnmarshalData when the data was encoded with a TTL that has elapsed.
+	ErrCallbackExpired = errors.New("callback data expired")
+	// ErrCallbackVersionMismatch is returned by UnmarshalData when the data was encoded with another schema version.
+	ErrCallbackVersionMismatch = errors.New("callback data version mismatch")
+)
+
+const dataHeaderMark = "!"
+
+// encodeDataHeader returns the header carrying the version and expiry, empty if neither is set.
+func encodeDataHeader(opts *dataOptions) string {
+	if opts.version == 0 && opts.ttl <= 0 {
+		return ""
+	}
+	var expiry int64
+	if opts.ttl > 0 {
+		expiry = time.Now().Add(opts.ttl).Unix()
+	}
+	return dataHeaderMark + strconv.FormatInt(int64(opts.version), 36) + "." + strconv.FormatInt(expiry, 36) + dataHeaderMark
+}
+
+// decodeDataHeader checks and strips the header of the payload. JSON never starts with "!",
+// so payloads without header are left untouched.
+func decodeDataHeader(payload string, opts *dataOptions) (string, error) {
+	version, expiry := int64(0), int64(0)
+	if rest, ok := strings.CutPrefix(payload, dataHeaderMark); ok {
+		header, body, found := strings.Cut(rest, dataHeaderMark)
+		if !found {
+			return "", fmt.Errorf("invalid data header")
+		}
+		v, e, _ := strings.Cut(header, ".")
+		var err error
+		if version, err = strconv.ParseInt(v, 36, 64); err != nil {
+			return "", fmt.Errorf("invalid data version: %w", err)
+		}
+		if expiry, err = strconv.ParseInt(e, 36, 64); err != nil {
+			return "", fmt.Errorf("invalid data expiry: %w", err)
+		}
+		payload = body
+	}
+	if version != int64(opts.version) {
+		return "", ErrCallbackVersionMismatch
+	}
+	if expiry > 0 && time.Now().Unix() > expiry {
+		return "", ErrCallbackExpired
+	}
+	return payload, nil
+}
+
+// dataOptions holds configuration for encoding and decoding callback data.
+type dataOptions struct {
+	codec      CallbackCodec // Encoding of the data
+	signingKey []byte        // HMAC key, nil disables signing
+	verify     bool          // Whether decoding requires a valid signature
+	ttl        time.Duration // Validity of encoded data, 0 for no expiry
+	version    int           // Schema version of the encoded data
+}
+
+// DataOption defines a function type for configuring callback data encoding.
+type DataOption func(*dataOptions)
+
+// WithSigningKey signs encoded callback data with HMAC-SHA256, and requires a valid
+// signature when decoding, so forged button payloads are rejected.
+func WithSigningKey(key []byte) DataOption {
+	return func(o *dataOptions) {
+		o.signingKey = key
+		o.verify = true
+	}
+}
+
+// WithCodec sets the codec encoding the data. Defaults to JSONCompressorCodec.
+func WithCodec(codec CallbackCodec) DataOption {
+	return func(o *dataOptions) {
+		o.codec = codec
+	}
+}
+
+// WithDataTTL embeds an expiry in encoded callback data, so buttons on old messages are
+// rejected with ErrCallbackExpired once the TTL has elapsed. It is ignored when decoding.
+func WithDataTTL(ttl time.Duration) DataOption {
+	return func(o *dataOptions) {
+		o.ttl = ttl
+	}
+}
+
+// WithDataVersion embeds the schema version of the data type when encoding, and requires it
+// when decoding, so data encoded for an older struct layout is rejected with
+// ErrCallbackVersionMismatch instead of being decoded into the new one.
+// Data encoded without a version has version 0.
+func WithDataVersion(version int) DataOption {
+	return func(o *dataOptions) {
+		o.version = version
+	}
+}
+
+func newDataOptions(options ...DataOption) *dataOptions {
+	opts := &dataOptions{codec: JSONCompressorCodec}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return opts
+}
 
 // UnmarshalData decodes Telegram callback query data into a route and typed data structure.
 // The input data should be formatted as "route:compressed_json_data".
 // Returns the route string, the unmarshaled data of type T, and any error encountered.
 // This is commonly used for handling Telegram bot callback queries with structured data.
-func UnmarshalData[T any](data string) (string, *T, error) {
+// With WithSigningKey the data must carry a valid signature, otherwise ErrInvalidSignature is returned.
+// Data encoded with WithDataTTL or WithDataVersion may fail with ErrCallbackExpired or
+// ErrCallbackVersionMismatch, in which case the route is still returned.
+func UnmarshalData[T any](data string, options ...DataOption) (string, *T, error) {
+	opts := newDataOptions(options...)
+	if opts.verify {
+		var err error
+		if data, err = verifyCallbackData(opts.signingKey, data); err != nil {
+			return "", nil, err
+		}
+	}
 	cmp := strings.SplitN(data, ":", 2)
 	if len(cmp) != 2 {
 		return "", nil, fmt.Errorf("invalid data format")
 	}
+	payload, err := decodeDataHeader(cmp[1], opts)
+	if err != nil {
+		return cmp[0], nil, err
+	}
 	var v T
-	err := jsoncompressor.Unmarshal([]byte(cmp[1]), &v)
+	err = opts.codec.Unmarshal(payload, &v)
 	if err != nil {
 		return cmp[0], nil, err
 	}
@@ -30,7 +153,72 @@ func UnmarshalData[T any](data string) (string, *T, error) {
 // MarshalData encodes a route and typed data into Telegram callback query format.
 // The data is compressed using JSON compression and formatted as "route:compressed_json_data".
 // Returns the formatted string suitable for use in Telegram callback queries.
-func MarshalData[T any](route string, data T) string {
-	b, _ := jsoncompressor.Marshal(data)
-	return fmt.Sprintf("%s:%s", route, string(b))
+// With WithSigningKey a versioned HMAC signature is appended, WithDataTTL and WithDataVersion
+// prefix the data with its expiry and schema version.
+// Encoding errors are logged and produce an empty string, use EncodeData to handle them.
+func MarshalData[T any](route string, data T, options ...DataOption) string {
+	encoded, err := EncodeData(route, data, options...)
+	if err != nil {
+		slog.Error("marshal callback data error", slog.String("route", route), slog.String("error", err.Error()))
+	}
+	return encoded
+}
+
+// EncodeData is like MarshalData but returns the encoding error.
+func EncodeData[T any](route string, data T, options ...DataOption) (string, error) {
+	opts := newDataOptions(options...)
+	payload, err := opts.codec.Marshal(&data)
+	if err != nil {
+		return "", err
+	}
+	encoded := fmt.Sprintf("%s:%s%s", route, encodeDataHeader(opts), payload)
+	if opts.signingKey != nil {
+		encoded = signCallbackData(opts.signingKey, encoded)
+	}
+	return encoded, nil
+}
+
+// start parameter format: $route_base64url(json($data))
+// Telegram only allows [A-Za-z0-9_-] up to 64 characters, so routes must not contain "_".
+
+// MarshalStartData encodes a route and typed data into a deep-link start parameter.
+// The data is compressed using JSON compression and base64url encoded to satisfy
+// Telegram's start parameter character set.
+// Encoding errors are logged and produce an empty string.
+//
+// Deprecated: Use EncodeStartData, which returns the encoding error.
+func MarshalStartData[T any](route string, data T) string {
+	encoded, err := EncodeStartData(route, data)
+	if err != nil {
+		slog.Error("marshal start data error", slog.String("route", route), slog.String("error", err.Error()))
+	}
+	return encoded
+}
+
+// EncodeStartData is like MarshalStartData but returns the encoding error.
+func EncodeStartData[T any](route string, data T) (string, error) {
+	b, err := jsoncompressor.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return route + "_" + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// UnmarshalStartData decodes a deep-link start parameter into a route and typed data structure.
+// The input should be formatted as "route_base64url_data" as produced by EncodeStartData.
+func UnmarshalStartData[T any](param string) (string, *T, error) {
+	route, payload, found := strings.Cut(param, "_")
+	if !found {
+		return "", nil, fmt.Errorf("invalid start parameter format")
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return route, nil, err
+	}
+	var v T
+	err = jsoncompressor.Unmarshal(raw, &v)
+	if err != nil {
+		return route, nil, err
+	}
+	return route, &v, nil
 }
@@ -1,8 +1,11 @@
 package telegram
 
 import (
+	"errors"
 	"log"
+	"strconv"
 	"testing"
+	"time"
 )
 
 type testDataStruct struct {
@@ -41,3 +44,93 @@ func TestUnmarshalData(t *testing.T) {
 	}
 	log.Printf("route: %s, data: %v", route, data)
 }
+
+func TestStartData(t *testing.T) {
+	param := MarshalStartData("ref", testDataStruct{Number: 1, Text: "a"})
+	if len(param) > maxStartParamLength {
+		t.Fatalf("start parameter is too long: %s", param)
+	}
+	route, data, err := UnmarshalStartData[testDataStruct](param)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if route != "ref" || data.Number != 1 || data.Text != "a" {
+		t.Errorf("Unmarshaled start data is invalid, got: %s %v", route, data)
+	}
+
+	if _, err = EncodeStartData("ref", make(chan int)); err == nil {
+		t.Error("expected an encoding error")
+	}
+	if param = MarshalStartData("ref", make(chan int)); param != "" {
+		t.Errorf("expected an empty parameter on error, got %q", param)
+	}
+}
+
+func TestSignedData(t *testing.T) {
+	key := WithSigningKey([]byte("secret"))
+	signed := MarshalData("test", testDataStruct{Number: 1, Text: "a"}, key)
+	route, data, err := UnmarshalData[testDataStruct](signed, key)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if route != "test" || data.Number != 1 {
+		t.Errorf("Unmarshaled data is invalid, got: %s %+v", route, data)
+	}
+	forged := MarshalData("test", testDataStruct{Number: 2, Text: "a"})
+	if _, _, err = UnmarshalData[testDataStruct](forged, key); err != ErrInvalidSignature {
+		t.Errorf("expected ErrInvalidSignature for unsigned data, got %v", err)
+	}
+	tampered := signed[:6] + "9" + signed[7:]
+	if _, _, err = UnmarshalData[testDataStruct](tampered, key); err != ErrInvalidSignature {
+		t.Errorf("expected ErrInvalidSignature for tampered data, got %v", err)
+	}
+}
+
+func TestDataHeader(t *testing.T) {
+	encoded := MarshalData("test", testDataStruct{Number: 1}, WithDataVersion(2), WithDataTTL(time.Hour))
+	route, data, err := UnmarshalData[testDataStruct](encoded, WithDataVersion(2))
+	if err != nil || route != "test" || data.Number != 1 {
+		t.Fatalf("Unmarshal failed: %s %+v %v", route, data, err)
+	}
+	if route, _, err = UnmarshalData[testDataStruct](encoded); !errors.Is(err, ErrCallbackVersionMismatch) || route != "test" {
+		t.Errorf("expected ErrCallbackVersionMismatch, got %s %v", route, err)
+	}
+	if _, _, err = UnmarshalData[testDataStruct](`test:[1,""]`, WithDataVersion(1)); !errors.Is(err, ErrCallbackVersionMismatch) {
+		t.Errorf("expected ErrCallbackVersionMismatch for legacy data, got %v", err)
+	}
+	expired := MarshalData("test", testDataStruct{Number: 1}, WithDataTTL(-time.Hour))
+	if _, _, err = UnmarshalData[testDataStruct](expired); err != nil {
+		t.Errorf("negative TTL must not embed an expiry, got %v", err)
+	}
+	expired = "test:!0." + strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 36) + `![1,""]`
+	if _, _, err = UnmarshalData[testDataStruct](expired); !errors.Is(err, ErrCallbackExpired) {
+		t.Errorf("expected ErrCallbackExpired, got %v", err)
+	}
+}
+
+func TestBinaryCodec(t *testing.T) {
+	codec := NewBinaryCodec(
+		func(v any) ([]byte, error) {
+			return []byte(strconv.Itoa(*v.(*int))), nil
+		},
+		func(data []byte, v any) error {
+			n, err := strconv.Atoi(string(data))
+			*v.(*int) = n
+			return err
+		},
+	)
+	encoded, err := EncodeData("num", 42, WithCodec(codec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded != "num:NDI" {
+		t.Errorf("Marshaled data is invalid, got: %s", encoded)
+	}
+	_, n, err := UnmarshalData[int](encoded, WithCodec(codec))
+	if err != nil || *n != 42 {
+		t.Errorf("Unmarshal failed: %v %v", n, err)
+	}
+	if _, err = EncodeData("bad", func() {}); err == nil {
+		t.Error("expected marshal error")
+	}
+}
@@ -0,0 +1,132 @@
+package telegram
+
+import (
+	"strings"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// TextBuilder builds message text together with explicit MessageEntity ranges, as an
+// alternative to parse modes. Interpolated content never needs escaping, which makes it
+// safe for untrusted user input. Offsets are computed in UTF-16 code units as required
+// by the Bot API.
+type TextBuilder struct {
+	text     strings.Builder
+	offset   int
+	entities []models.MessageEntity
+}
+
+// NewTextBuilder creates an empty text builder.
+func NewTextBuilder() *TextBuilder {
+	return &TextBuilder{}
+}
+
+// utf16Len returns the length of s in UTF-16 code units.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// Text appends plain text.
+func (t *TextBuilder) Text(s string) *TextBuilder {
+	t.text.WriteString(s)
+	t.offset += utf16Len(s)
+	return t
+}
+
+// Entity appends text covered by an entity of the given type. The entity is configured by
+// the optional setup function, e.g. to set its URL. Empty text adds no entity.
+func (t *TextBuilder) Entity(entityType models.MessageEntityType, s string, setup func(*models.MessageEntity)) *TextBuilder {
+	start := t.offset
+	t.Text(s)
+	if t.offset == start {
+		return t
+	}
+	entity := models.MessageEntity{Type: entityType, Offset: start, Length: t.offset - start}
+	if setup != nil {
+		setup(&entity)
+	}
+	t.entities = append(t.entities, entity)
+	return t
+}
+
+// Bold appends bold text.
+func (t *TextBuilder) Bold(s string) *TextBuilder {
+	return t.Entity(models.MessageEntityTypeBold, s, nil)
+}
+
+// Italic appends italic text.
+func (t *TextBuilder) Italic(s string) *TextBuilder {
+	return t.Entity(models.MessageEntityTypeItalic, s, nil)
+}
+
+// Underline appends underlined text.
+func (t *TextBuilder) Underline(s string) *TextBuilder {
+	return t.Entity(models.MessageEntityTypeUnderline, s, nil)
+}
+
+// Strikethrough appends strikethrough text.
+func (t *TextBuilder) Strikethrough(s string) *TextBuilder {
+	return t.Entity(models.MessageEntityTypeStrikethrough, s, nil)
+}
+
+// Spoiler appends text hidden behind a spoiler.
+func (t *TextBuilder) Spoiler(s string) *TextBuilder {
+	return t.Entity(models.MessageEntityTypeSpoiler, s, nil)
+}
+
+// Code appends inline monospace text.
+func (t *TextBuilder) Code(s string) *TextBuilder {
+	return t.Entity(models.MessageEntityTypeCode, s, nil)
+}
+
+// Pre appends a code block, highlighted for the language if it is not empty.
+func (t *TextBuilder) Pre(s, language string) *TextBuilder {
+	return t.Entity(models.MessageEntityTypePre, s, func(e *models.MessageEntity) {
+		e.Language = language
+	})
+}
+
+// TextLink appends text linking to the URL.
+func (t *TextBuilder) TextLink(s, url string) *TextBuilder {
+	return t.Entity(models.MessageEntityTypeTextLink, s, func(e *models.MessageEntity) {
+		e.URL = url
+	})
+}
+
+// TextMention appends text mentioning the user, also users without a username.
+func (t *TextBuilder) TextMention(s string, user *models.User) *TextBuilder {
+	return t.Entity(models.MessageEntityTypeTextMention, s, func(e *models.MessageEntity) {
+		e.User = user
+	})
+}
+
+// CustomEmoji appends a custom emoji, where emoji is the fallback shown by clients that
+// can not display it.
+func (t *TextBuilder) CustomEmoji(emoji, customEmojiID string) *TextBuilder {
+	return t.Entity(models.MessageEntityTypeCustomEmoji, emoji, func(e *models.MessageEntity) {
+		e.CustomEmojiID = customEmojiID
+	})
+}
+
+// String returns the built text.
+func (t *TextBuilder) String() string {
+	return t.text.String()
+}
+
+// Entities returns the entities of the built text.
+func (t *TextBuilder) Entities() []models.MessageEntity {
+	return t.entities
+}
+
+// Message returns a message with the built text and entities.
+func (t *TextBuilder) Message() *Message {
+	return &Message{Text: t.String(), Entities: t.Entities()}
+}
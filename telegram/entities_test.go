@@ -0,0 +1,34 @@
+package telegram
+
+import (
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestTextBuilder(t *testing.T) {
+	m := NewTextBuilder().
+		Text("👋 Hi ").
+		Bold("<b>user</b>").
+		Text(" ").
+		CustomEmoji("🔥", "123").
+		Message()
+	if m.Text != "👋 Hi <b>user</b> 🔥" {
+		t.Errorf("unexpected text: %q", m.Text)
+	}
+	want := []models.MessageEntity{
+		{Type: models.MessageEntityTypeBold, Offset: 6, Length: 11},
+		{Type: models.MessageEntityTypeCustomEmoji, Offset: 18, Length: 2, CustomEmojiID: "123"},
+	}
+	if len(m.Entities) != len(want) {
+		t.Fatalf("unexpected entities: %+v", m.Entities)
+	}
+	for i := range want {
+		if m.Entities[i] != want[i] {
+			t.Errorf("entity %d = %+v, want %+v", i, m.Entities[i], want[i])
+		}
+	}
+	if len(NewTextBuilder().Bold("").Entities()) != 0 {
+		t.Error("empty text must not add an entity")
+	}
+}
@@ -0,0 +1,185 @@
+package telegram
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// UpdateEnvelope wraps an Update with accessors that hide which payload the update carries, so
+// handlers need no nil checks for the common questions. The raw update stays accessible through
+// the embedded *Update, e.g. u.CallbackQuery, or through Raw.
+type UpdateEnvelope struct {
+	*Update
+}
+
+// NewUpdateEnvelope wraps the update, a nil update behaves like an update without payload.
+func NewUpdateEnvelope(update *Update) *UpdateEnvelope {
+	if update == nil {
+		update = &Update{}
+	}
+	return &UpdateEnvelope{Update: update}
+}
+
+// EnvelopeHandlerFunc defines a handler receiving the update wrapped in an UpdateEnvelope.
+type EnvelopeHandlerFunc = func(ctx context.Context, u *UpdateEnvelope) error
+
+// EnvelopeHandler adapts an EnvelopeHandlerFunc to a HandlerFunc, so it can be bound like
+// any other handler, e.g. app.BindCommand("start", EnvelopeHandler(start)).
+func EnvelopeHandler(h EnvelopeHandlerFunc) HandlerFunc {
+	return func(ctx context.Context, update *Update) error {
+		return h(ctx, NewUpdateEnvelope(update))
+	}
+}
+
+// Raw returns the wrapped update.
+func (u *UpdateEnvelope) Raw() *Update {
+	return u.Update
+}
+
+// EffectiveMessage returns the message carried by the update: a new or edited message,
+// channel post or business message, nil for other updates. The message carrying the keyboard
+// of a callback query is not returned, see UpdateMessageRef.
+func (u *UpdateEnvelope) EffectiveMessage() *models.Message {
+	return effectiveMessage(u.Update, true)
+}
+
+// effectiveMessage returns the new or edited message or channel post of the update, and with
+// business the new or edited business message, nil for other updates.
+func effectiveMessage(update *Update, business bool) *models.Message {
+	switch {
+	case update == nil:
+		return nil
+	case update.Message != nil:
+		return update.Message
+	case update.EditedMessage != nil:
+		return update.EditedMessage
+	case update.ChannelPost != nil:
+		return update.ChannelPost
+	case update.EditedChannelPost != nil:
+		return update.EditedChannelPost
+	case !business:
+		return nil
+	case update.BusinessMessage != nil:
+		return update.BusinessMessage
+	case update.EditedBusinessMessage != nil:
+		return update.EditedBusinessMessage
+	default:
+		return nil
+	}
+}
+
+// ChatID returns the chat the update belongs to, including the chat of the message carrying
+// the keyboard of a callback query and the chat of membership updates, 0 if there is none.
+func (u *UpdateEnvelope) ChatID() int64 {
+	if ref, err := UpdateMessageRef(u.Update); err == nil {
+		return ref.ChatID
+	}
+	switch {
+	case u.EffectiveMessage() != nil:
+		return u.EffectiveMessage().Chat.ID
+	case u.MyChatMember != nil:
+		return u.MyChatMember.Chat.ID
+	case u.ChatMember != nil:
+		return u.ChatMember.Chat.ID
+	case u.ChatJoinRequest != nil:
+		return u.ChatJoinRequest.Chat.ID
+	case u.MessageReaction != nil:
+		return u.MessageReaction.Chat.ID
+	default:
+		return 0
+	}
+}
+
+// Sender returns the user who triggered the update, nil if there is none, e.g. for
+// anonymous channel posts.
+func (u *UpdateEnvelope) Sender() *models.User {
+	if user := UpdateUser(u.Update); user != nil {
+		return user
+	}
+	switch {
+	case u.EffectiveMessage() != nil:
+		return u.EffectiveMessage().From
+	case u.InlineQuery != nil:
+		return u.InlineQuery.From
+	case u.ChosenInlineResult != nil:
+		return &u.ChosenInlineResult.From
+	case u.ShippingQuery != nil:
+		return u.ShippingQuery.From
+	case u.PreCheckoutQuery != nil:
+		return u.PreCheckoutQuery.From
+	case u.MyChatMember != nil:
+		return &u.MyChatMember.From
+	case u.ChatMember != nil:
+		return &u.ChatMember.From
+	case u.ChatJoinRequest != nil:
+		return &u.ChatJoinRequest.From
+	case u.MessageReaction != nil:
+		return u.MessageReaction.User
+	default:
+		return nil
+	}
+}
+
+// SenderID returns the ID of the user who triggered the update, 0 if there is none.
+func (u *UpdateEnvelope) SenderID() int64 {
+	if user := u.Sender(); user != nil {
+		return user.ID
+	}
+	return 0
+}
+
+// Text returns the text, or the caption of media, of the effective message.
+func (u *UpdateEnvelope) Text() string {
+	msg := u.EffectiveMessage()
+	if msg == nil {
+		return ""
+	}
+	if msg.Text != "" {
+		return msg.Text
+	}
+	return msg.Caption
+}
+
+// IsCommand reports whether the effective message is a bot command like "/start".
+func (u *UpdateEnvelope) IsCommand() bool {
+	msg := u.EffectiveMessage()
+	return msg != nil && strings.HasPrefix(msg.Text, "/") && len(msg.Text) > 1
+}
+
+// Command returns the name of the command without the slash and the bot mention, e.g.
+// "start" for "/start@demo_bot", or "" if the update is no command.
+func (u *UpdateEnvelope) Command() string {
+	if !u.IsCommand() {
+		return ""
+	}
+	command, _, _ := strings.Cut(strings.Fields(u.Text())[0], "@")
+	return strings.TrimPrefix(command, "/")
+}
+
+// Args returns the whitespace separated arguments following the command, nil if the update
+// is no command or has no arguments.
+func (u *UpdateEnvelope) Args() []string {
+	if !u.IsCommand() {
+		return nil
+	}
+	args := strings.Fields(u.Text())[1:]
+	if len(args) == 0 {
+		return nil
+	}
+	return args
+}
+
+// IsCallback reports whether the update is a callback query of an inline keyboard button.
+func (u *UpdateEnvelope) IsCallback() bool {
+	return u.CallbackQuery != nil
+}
+
+// CallbackData returns the data of the pressed button, "" if the update is no callback query.
+func (u *UpdateEnvelope) CallbackData() string {
+	if u.CallbackQuery == nil {
+		return ""
+	}
+	return u.CallbackQuery.Data
+}
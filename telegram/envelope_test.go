@@ -0,0 +1,98 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestUpdateEnvelope(t *testing.T) {
+	tests := []struct {
+		name   string
+		update string
+		want   string // chat, sender, text, command, args, callback
+	}{
+		{
+			name:   "command",
+			update: `{"message":{"message_id":1,"from":{"id":7},"chat":{"id":-100},"text":"/grant@demo_bot 42 10"}}`,
+			want:   "-100 7 /grant@demo_bot 42 10 grant [42 10] false",
+		},
+		{
+			name:   "caption",
+			update: `{"edited_message":{"message_id":1,"from":{"id":7},"chat":{"id":7},"caption":"photo"}}`,
+			want:   "7 7 photo  [] false",
+		},
+		{
+			name:   "callback",
+			update: `{"callback_query":{"id":"q","from":{"id":8},"message":{"message_id":1,"date":1,"chat":{"id":9}},"data":"vote:1"}}`,
+			want:   "9 8   [] true",
+		},
+		{
+			name:   "join request",
+			update: `{"chat_join_request":{"chat":{"id":-200},"from":{"id":5},"user_chat_id":5,"date":1}}`,
+			want:   "-200 5   [] false",
+		},
+		{
+			name:   "empty",
+			update: `{}`,
+			want:   "0 0   [] false",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var update Update
+			if err := json.Unmarshal([]byte(tt.update), &update); err != nil {
+				t.Fatal(err)
+			}
+			u := NewUpdateEnvelope(&update)
+			got := fmt.Sprintf("%d %d %s %s %v %t", u.ChatID(), u.SenderID(), u.Text(), u.Command(), u.Args(), u.IsCallback())
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvelopeHandler(t *testing.T) {
+	var got string
+	h := EnvelopeHandler(func(ctx context.Context, u *UpdateEnvelope) error {
+		got = u.CallbackData()
+		if u.Raw() == nil {
+			t.Error("raw update is nil")
+		}
+		return nil
+	})
+	_ = h(context.Background(), nil)
+	if got != "" {
+		t.Errorf("unexpected callback data %q", got)
+	}
+}
+
+func TestEffectiveMessage(t *testing.T) {
+	tests := []struct {
+		update   string
+		business bool
+		want     int // message ID, 0 for none
+	}{
+		{`{"message":{"message_id":1,"chat":{"id":1}}}`, false, 1},
+		{`{"edited_channel_post":{"message_id":2,"chat":{"id":1}}}`, false, 2},
+		{`{"business_message":{"message_id":3,"chat":{"id":1}}}`, false, 0},
+		{`{"business_message":{"message_id":3,"chat":{"id":1}}}`, true, 3},
+		{`{"edited_business_message":{"message_id":4,"chat":{"id":1}}}`, true, 4},
+		{`{"callback_query":{"id":"q","from":{"id":1},"message":{"message_id":5,"chat":{"id":1}}}}`, true, 0},
+	}
+	for _, tt := range tests {
+		var update Update
+		if err := json.Unmarshal([]byte(tt.update), &update); err != nil {
+			t.Fatal(err)
+		}
+		got := 0
+		if msg := effectiveMessage(&update, tt.business); msg != nil {
+			got = msg.ID
+		}
+		if got != tt.want {
+			t.Errorf("effectiveMessage(%s, %v) = %d, want %d", tt.update, tt.business, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/go-telegram/bot"
+)
+
+// ErrorFormatter converts a handler error into the text shown to the user.
+// Returning an empty string suppresses the reply.
+type ErrorFormatter interface {
+	FormatError(ctx context.Context, update *Update, err error) string
+}
+
+// ErrorFormatterFunc is a function type that implements the ErrorFormatter interface.
+type ErrorFormatterFunc func(ctx context.Context, update *Update, err error) string
+
+// FormatError implements the ErrorFormatter interface by calling the function.
+func (f ErrorFormatterFunc) FormatError(ctx context.Context, update *Update, err error) string {
+	return f(ctx, update, err)
+}
+
+// NewDefaultErrorHandler creates an error handler that logs the error together with update metadata.
+// If formatter is not nil, the formatted text is also sent to the user, as a message or as the
+// answer of a callback query, which is an alert popup for a UserError with ErrorReplyAlert.
+// It handles every update type and never dereferences missing fields.
+func NewDefaultErrorHandler(formatter ErrorFormatter) ErrorHandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *Update, err error) {
+		attrs := append(updateLogAttrs(ctx, update), slog.Any("error", privacyPolicyFrom(ctx).Error(err)))
+		slog.ErrorContext(ctx, "receive error", attrs...)
+		if formatter == nil || b == nil || update == nil {
+			return
+		}
+		text := formatter.FormatError(ctx, update, err)
+		if text == "" {
+			return
+		}
+		sendErrorText(ctx, b, update, text, errorAlert(err))
+	}
+}
+
+// ChainErrorHandlers combines error handlers into one that runs them in order, e.g. logging,
+// then reporting, then replying to the user.
+func ChainErrorHandlers(handlers ...ErrorHandlerFunc) ErrorHandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *Update, err error) {
+		for _, handler := range handlers {
+			if handler != nil {
+				handler(ctx, b, update, err)
+			}
+		}
+	}
+}
+
+// WithErrorMiddlewares wraps an error handler with a middleware chain, applied in order so the
+// first middleware sees the error first. A middleware that does not call next marks the error
+// as handled, the remaining middlewares and the handler are skipped.
+func WithErrorMiddlewares(h ErrorHandlerFunc, middlewares ...ErrorMiddlewareFunc) ErrorHandlerFunc {
+	if h == nil {
+		h = func(context.Context, *bot.Bot, *Update, error) {}
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+// UpdateType returns the name of the payload carried by the update, e.g. "message" or "callback_query".
+func UpdateType(update *Update) string {
+	switch {
+	case update == nil:
+		return ""
+	case update.Message != nil:
+		return "message"
+	case update.EditedMessage != nil:
+		return "edited_message"
+	case update.ChannelPost != nil:
+		return "channel_post"
+	case update.EditedChannelPost != nil:
+		return "edited_channel_post"
+	case update.BusinessConnection != nil:
+		return "business_connection"
+	case update.BusinessMessage != nil:
+		return "business_message"
+	case update.EditedBusinessMessage != nil:
+		return "edited_business_message"
+	case update.DeletedBusinessMessages != nil:
+		return "deleted_business_messages"
+	case update.MessageReaction != nil:
+		return "message_reaction"
+	case update.MessageReactionCount != nil:
+		return "message_reaction_count"
+	case update.CallbackQuery != nil:
+		return "callback_query"
+	case update.InlineQuery != nil:
+		return "inline_query"
+	case update.ChosenInlineResult != nil:
+		return "chosen_inline_result"
+	case update.ShippingQuery != nil:
+		return "shipping_query"
+	case update.PreCheckoutQuery != nil:
+		return "pre_checkout_query"
+	case update.Poll != nil:
+		return "poll"
+	case update.PollAnswer != nil:
+		return "poll_answer"
+	case update.MyChatMember != nil:
+		return "my_chat_member"
+	case update.ChatMember != nil:
+		return "chat_member"
+	case update.ChatJoinRequest != nil:
+		return "chat_join_request"
+	default:
+		return "unknown"
+	}
+}
+
+// updateLogAttrs returns the log attributes of the update, masked with the privacy policy of
+// the context.
+func updateLogAttrs(ctx context.Context, update *Update) []any {
+	if update == nil {
+		return nil
+	}
+	policy := privacyPolicyFrom(ctx)
+	attrs := []any{
+		slog.Int64("update_id", update.ID),
+		slog.String("type", UpdateType(update)),
+	}
+	switch {
+	case update.Message != nil:
+		attrs = append(attrs, slog.Int64("chat_id", policy.ID(update.Message.Chat.ID)), slog.String("text", policy.Text(update.Message.Text)))
+		if update.Message.From != nil {
+			attrs = append(attrs, slog.Int64("user_id", policy.ID(update.Message.From.ID)))
+		}
+	case update.CallbackQuery != nil:
+		attrs = append(attrs, slog.Int64("user_id", policy.ID(update.CallbackQuery.From.ID)), slog.String("data", policy.Text(update.CallbackQuery.Data)))
+		if origin := update.CallbackQuery.Message.Message; origin != nil {
+			attrs = append(attrs, slog.Int64("chat_id", policy.ID(origin.Chat.ID)))
+		}
+	}
+	return attrs
+}
+
+// ErrorReplyMode decides how a UserError is presented to the user.
+type ErrorReplyMode int
+
+const (
+	// ErrorReplySilent does not notify the user.
+	ErrorReplySilent ErrorReplyMode = iota
+	// ErrorReplyMessage sends a message for regular messages and a toast for callback queries.
+	ErrorReplyMessage
+	// ErrorReplyAlert sends a message for regular messages and an alert popup for callback queries.
+	ErrorReplyAlert
+)
+
+// Translator resolves a message key into localized text for the update's user.
+type Translator = func(ctx context.Context, update *Update, key string, args ...any) string
+
+// UserError is an error whose message is safe to show to users.
+// Key and Args are passed to the Translator when one is configured.
+type UserError struct {
+	Message string
+	Key     string
+	Args    []any
+	Mode    ErrorReplyMode
+	Err     error
+}
+
+// NewUserError creates a user facing error replied as a message.
+func NewUserError(message string) *UserError {
+	return &UserError{Message: message, Mode: ErrorReplyMessage}
+}
+
+// WrapUserError wraps an internal error with a message that is safe to show to users.
+func WrapUserError(err error, message string) *UserError {
+	return &UserError{Message: message, Mode: ErrorReplyMessage, Err: err}
+}
+
+// WithKey sets the translation key and arguments of the error.
+func (e *UserError) WithKey(key string, args ...any) *UserError {
+	e.Key = key
+	e.Args = args
+	return e
+}
+
+// WithMode sets how the error is presented to the user.
+func (e *UserError) WithMode(mode ErrorReplyMode) *UserError {
+	e.Mode = mode
+	return e
+}
+
+func (e *UserError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *UserError) Unwrap() error {
+	return e.Err
+}
+
+// defaultErrorText is replied for errors that are not UserError.
+const defaultErrorText = "Something went wrong, please try again later."
+
+// userErrorFormatterOptions holds configuration for the formatter of NewUserErrorFormatter.
+type userErrorFormatterOptions struct {
+	translator   Translator // Optional i18n hook for UserError keys
+	fallbackText string     // Text replied for errors that are not UserError
+}
+
+// UserErrorFormatterOption defines a function type for configuring NewUserErrorFormatter.
+type UserErrorFormatterOption func(*userErrorFormatterOptions)
+
+// WithTranslator sets the translator used to localize UserError keys and the fallback text.
+func WithTranslator(translator Translator) UserErrorFormatterOption {
+	return func(o *userErrorFormatterOptions) {
+		o.translator = translator
+	}
+}
+
+// WithFallbackText sets the text replied for internal errors that are not UserError.
+// An empty text keeps internal errors silent.
+func WithFallbackText(text string) UserErrorFormatterOption {
+	return func(o *userErrorFormatterOptions) {
+		o.fallbackText = text
+	}
+}
+
+// NewUserErrorFormatter creates an ErrorFormatter that only exposes UserError messages to
+// users. Any other error is answered with the fallback text, which by default is a generic
+// message, so raw internal error strings never leak. UserErrors with ErrorReplyAlert are shown
+// in an alert popup for callback queries, see NewDefaultErrorHandler.
+func NewUserErrorFormatter(opts ...UserErrorFormatterOption) ErrorFormatter {
+	o := &userErrorFormatterOptions{fallbackText: defaultErrorText}
+	for _, opt := range opts {
+		opt(o)
+	}
+	translate := func(ctx context.Context, update *Update, key, text string, args ...any) string {
+		if o.translator == nil || key == "" {
+			return text
+		}
+		if translated := o.translator(ctx, update, key, args...); translated != "" {
+			return translated
+		}
+		return text
+	}
+	return ErrorFormatterFunc(func(ctx context.Context, update *Update, err error) string {
+		var userErr *UserError
+		if !errors.As(err, &userErr) {
+			return translate(ctx, update, o.fallbackText, o.fallbackText)
+		}
+		if userErr.Mode == ErrorReplySilent {
+			return ""
+		}
+		return translate(ctx, update, userErr.Key, userErr.Message, userErr.Args...)
+	})
+}
+
+// errorAlert reports whether the error asks for an alert popup, see ErrorReplyAlert.
+func errorAlert(err error) bool {
+	var userErr *UserError
+	return errors.As(err, &userErr) && userErr.Mode == ErrorReplyAlert
+}
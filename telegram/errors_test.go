@@ -0,0 +1,126 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+func TestDefaultErrorHandler(t *testing.T) {
+	handler := NewDefaultErrorHandler(nil)
+	updates := []*Update{
+		nil,
+		{},
+		{CallbackQuery: &models.CallbackQuery{Data: "menu:1"}},
+		{Message: &models.Message{Text: "/start"}},
+	}
+	for _, update := range updates {
+		handler(context.Background(), nil, update, errors.New("failed"))
+	}
+}
+
+func TestUpdateType(t *testing.T) {
+	if got := UpdateType(&Update{CallbackQuery: &models.CallbackQuery{}}); got != "callback_query" {
+		t.Errorf("UpdateType is invalid, got: %s", got)
+	}
+}
+
+func TestUserErrorFormatter(t *testing.T) {
+	formatter := NewUserErrorFormatter(WithTranslator(func(ctx context.Context, update *Update, key string, args ...any) string {
+		if key == "not_found" {
+			return "未找到"
+		}
+		return ""
+	}))
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"internal", errors.New("sql: connection refused"), defaultErrorText},
+		{"user", NewUserError("try again"), "try again"},
+		{"translated", fmt.Errorf("load: %w", WrapUserError(errors.New("sql: no rows"), "not found").WithKey("not_found")), "未找到"},
+		{"silent", NewUserError("hidden").WithMode(ErrorReplySilent), ""},
+	}
+	for _, tt := range tests {
+		if got := formatter.FormatError(context.Background(), nil, tt.err); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := NewUserErrorFormatter(WithFallbackText("")).FormatError(context.Background(), nil, errors.New("internal")); got != "" {
+		t.Errorf("internal error was not silenced: %q", got)
+	}
+}
+
+func TestSendErrorMessage(t *testing.T) {
+	var mu sync.Mutex
+	var sent []string
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		sent = append(sent, path.Base(r.URL.Path)+" "+r.FormValue("text")+" "+r.FormValue("show_alert"))
+		mu.Unlock()
+		if strings.HasSuffix(r.URL.Path, "/sendMessage") {
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":1,"date":1,"chat":{"id":7}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	message := &Update{Message: &models.Message{Chat: models.Chat{ID: 7}}}
+	callback := &Update{CallbackQuery: &models.CallbackQuery{ID: "q"}}
+	SendErrorMessage(ctx, app.API(), message, errors.New("sql: connection refused"))
+	SendErrorMessage(ctx, app.API(), callback, NewUserError("Not allowed.").WithMode(ErrorReplyAlert))
+	want := []string{"sendMessage " + defaultErrorText + " ", "answerCallbackQuery Not allowed. true"}
+	if !slices.Equal(sent, want) {
+		t.Errorf("got %q, want %q", sent, want)
+	}
+}
+
+func TestWithErrorMiddlewares(t *testing.T) {
+	var calls []string
+	record := func(name string, handled bool) ErrorMiddlewareFunc {
+		return func(next ErrorHandlerFunc) ErrorHandlerFunc {
+			return func(ctx context.Context, b *bot.Bot, update *Update, err error) {
+				calls = append(calls, name)
+				if !handled {
+					next(ctx, b, update, err)
+				}
+			}
+		}
+	}
+	final := func(ctx context.Context, b *bot.Bot, update *Update, err error) {
+		calls = append(calls, "reply")
+	}
+
+	h := WithErrorMiddlewares(final, record("log", false), record("report", false))
+	h(context.Background(), nil, nil, errors.New("failed"))
+	if strings.Join(calls, ",") != "log,report,reply" {
+		t.Errorf("unexpected order: %v", calls)
+	}
+
+	calls = nil
+	h = WithErrorMiddlewares(final, record("log", true), record("report", false))
+	h(context.Background(), nil, nil, errors.New("failed"))
+	if strings.Join(calls, ",") != "log" {
+		t.Errorf("handled error must stop the chain: %v", calls)
+	}
+
+	calls = nil
+	ChainErrorHandlers(final, nil, final)(context.Background(), nil, nil, errors.New("failed"))
+	if len(calls) != 2 {
+		t.Errorf("expected both handlers to run: %v", calls)
+	}
+}
@@ -0,0 +1,131 @@
+package telegram
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// ExtractedEntity is a message entity together with the text it covers.
+type ExtractedEntity struct {
+	models.MessageEntity
+	Text string // Covered text, e.g. "@name" for a mention
+}
+
+// Mention is a user mentioned in a message, either by username or, for users without
+// one, by a text mention carrying the user.
+type Mention struct {
+	Username string       // Username without the leading "@", empty for text mentions
+	User     *models.User // Mentioned user of a text mention, nil for username mentions
+}
+
+// CommandEntity is a bot command found in a message, e.g. "/start@my_bot".
+type CommandEntity struct {
+	Command string // Command name without the leading "/"
+	Bot     string // Username of the addressed bot, empty if the command is not addressed
+}
+
+// EntityText returns the text covered by the entity. Entity offsets are counted in UTF-16
+// code units, so the text is sliced accordingly. Entities outside the text return "".
+func EntityText(text string, entity models.MessageEntity) string {
+	units := utf16.Encode([]rune(text))
+	start, end := entity.Offset, entity.Offset+entity.Length
+	if start < 0 || start > end || end > len(units) {
+		return ""
+	}
+	return string(utf16.Decode(units[start:end]))
+}
+
+// updateTextEntities returns the text or caption of the update's message with its entities.
+func updateTextEntities(update *Update) (string, []models.MessageEntity) {
+	msg := effectiveMessage(update, true)
+	if msg == nil {
+		return "", nil
+	}
+	if msg.Text != "" {
+		return msg.Text, msg.Entities
+	}
+	return msg.Caption, msg.CaptionEntities
+}
+
+// ExtractEntities returns the entities of the message text or caption of the update with the
+// text they cover, in message order. Without types all entities are returned.
+func ExtractEntities(update *Update, types ...models.MessageEntityType) []ExtractedEntity {
+	if update == nil {
+		return nil
+	}
+	text, entities := updateTextEntities(update)
+	if len(entities) == 0 {
+		return nil
+	}
+	units := utf16.Encode([]rune(text))
+	var extracted []ExtractedEntity
+	for _, entity := range entities {
+		if len(types) > 0 && !slices.Contains(types, entity.Type) {
+			continue
+		}
+		start, end := entity.Offset, entity.Offset+entity.Length
+		if start < 0 || start > end || end > len(units) {
+			continue
+		}
+		extracted = append(extracted, ExtractedEntity{MessageEntity: entity, Text: string(utf16.Decode(units[start:end]))})
+	}
+	return extracted
+}
+
+// ExtractURLs returns the URLs of the message: links written in the text and the targets of
+// text links.
+func ExtractURLs(update *Update) []string {
+	var urls []string
+	for _, e := range ExtractEntities(update, models.MessageEntityTypeURL, models.MessageEntityTypeTextLink) {
+		if e.Type == models.MessageEntityTypeTextLink {
+			urls = append(urls, e.URL)
+		} else {
+			urls = append(urls, e.Text)
+		}
+	}
+	return urls
+}
+
+// ExtractMentions returns the users mentioned in the message.
+func ExtractMentions(update *Update) []Mention {
+	var mentions []Mention
+	for _, e := range ExtractEntities(update, models.MessageEntityTypeMention, models.MessageEntityTypeTextMention) {
+		if e.Type == models.MessageEntityTypeTextMention {
+			mentions = append(mentions, Mention{User: e.User})
+		} else {
+			mentions = append(mentions, Mention{Username: strings.TrimPrefix(e.Text, "@")})
+		}
+	}
+	return mentions
+}
+
+// ExtractHashtags returns the hashtags of the message without the leading "#".
+func ExtractHashtags(update *Update) []string {
+	return extractTags(update, models.MessageEntityTypeHashtag, "#")
+}
+
+// ExtractCashtags returns the cashtags of the message without the leading "$", e.g. "USD".
+func ExtractCashtags(update *Update) []string {
+	return extractTags(update, models.MessageEntityTypeCashtag, "$")
+}
+
+func extractTags(update *Update, entityType models.MessageEntityType, prefix string) []string {
+	var tags []string
+	for _, e := range ExtractEntities(update, entityType) {
+		tags = append(tags, strings.TrimPrefix(e.Text, prefix))
+	}
+	return tags
+}
+
+// ExtractBotCommands returns the bot commands of the message, wherever they appear in the text.
+func ExtractBotCommands(update *Update) []CommandEntity {
+	var commands []CommandEntity
+	for _, e := range ExtractEntities(update, models.MessageEntityTypeBotCommand) {
+		command, bot, _ := strings.Cut(strings.TrimPrefix(e.Text, "/"), "@")
+		commands = append(commands, CommandEntity{Command: command, Bot: bot})
+	}
+	return commands
+}
@@ -0,0 +1,66 @@
+package telegram
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestExtractEntities(t *testing.T) {
+	text := "👋 /start@demo_bot see https://go.dev and docs, ask @gopher #go $USD"
+	entity := func(entityType models.MessageEntityType, s string) models.MessageEntity {
+		return models.MessageEntity{Type: entityType, Offset: utf16Index(text, s), Length: utf16Len(s)}
+	}
+	docs := entity(models.MessageEntityTypeTextLink, "docs")
+	docs.URL = "https://pkg.go.dev"
+	user := &models.User{ID: 7}
+	ask := entity(models.MessageEntityTypeTextMention, "ask")
+	ask.User = user
+	update := &Update{Message: &models.Message{Text: text, Entities: []models.MessageEntity{
+		entity(models.MessageEntityTypeBotCommand, "/start@demo_bot"),
+		entity(models.MessageEntityTypeURL, "https://go.dev"),
+		docs,
+		ask,
+		entity(models.MessageEntityTypeMention, "@gopher"),
+		entity(models.MessageEntityTypeHashtag, "#go"),
+		entity(models.MessageEntityTypeCashtag, "$USD"),
+		{Type: models.MessageEntityTypeBold, Offset: 100, Length: 5},
+	}}}
+
+	if urls := ExtractURLs(update); fmt.Sprint(urls) != "[https://go.dev https://pkg.go.dev]" {
+		t.Errorf("unexpected URLs: %v", urls)
+	}
+	if mentions := ExtractMentions(update); len(mentions) != 2 || mentions[0].User != user || mentions[1].Username != "gopher" {
+		t.Errorf("unexpected mentions: %+v", mentions)
+	}
+	if tags := ExtractHashtags(update); fmt.Sprint(tags) != "[go]" {
+		t.Errorf("unexpected hashtags: %v", tags)
+	}
+	if tags := ExtractCashtags(update); fmt.Sprint(tags) != "[USD]" {
+		t.Errorf("unexpected cashtags: %v", tags)
+	}
+	if commands := ExtractBotCommands(update); len(commands) != 1 || commands[0] != (CommandEntity{Command: "start", Bot: "demo_bot"}) {
+		t.Errorf("unexpected commands: %+v", commands)
+	}
+	if all := ExtractEntities(update); len(all) != 7 {
+		t.Errorf("out of range entity was not skipped: %d entities", len(all))
+	}
+
+	caption := &Update{Message: &models.Message{Caption: "#photo", CaptionEntities: []models.MessageEntity{
+		{Type: models.MessageEntityTypeHashtag, Offset: 0, Length: 6},
+	}}}
+	if tags := ExtractHashtags(caption); fmt.Sprint(tags) != "[photo]" {
+		t.Errorf("unexpected caption hashtags: %v", tags)
+	}
+}
+
+// utf16Index returns the UTF-16 offset of the first occurrence of sub in s.
+func utf16Index(s, sub string) int {
+	for i := range s {
+		if len(s[i:]) >= len(sub) && s[i:i+len(sub)] == sub {
+			return utf16Len(s[:i])
+		}
+	}
+	return -1
+}
@@ -0,0 +1,79 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/go-telegram/bot"
+)
+
+// OpenFile opens the file with the given ID for reading. Files are downloaded from the
+// configured Bot API server; a self-hosted server running in --local mode returns absolute
+// paths from getFile, which are read directly from the shared filesystem instead.
+// Downloads use http.DefaultClient, use Bot.OpenFile to download with the HTTP client
+// configured for the bot. The caller must close the returned reader.
+func OpenFile(ctx context.Context, b *bot.Bot, fileID string) (io.ReadCloser, error) {
+	return openFile(ctx, b, http.DefaultClient, fileID)
+}
+
+func openFile(ctx context.Context, b *bot.Bot, client bot.HttpClient, fileID string) (io.ReadCloser, error) {
+	file, err := b.GetFile(ctx, &bot.GetFileParams{FileID: fileID})
+	if err != nil {
+		return nil, err
+	}
+	if filepath.IsAbs(file.FilePath) {
+		return os.Open(file.FilePath)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.FileDownloadLink(file), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		// the download URL contains the token
+		return nil, redactTokenError(err, b.Token())
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("download file %s: %s", fileID, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// DownloadFile reads the whole file with the given ID into memory, see OpenFile.
+func DownloadFile(ctx context.Context, b *bot.Bot, fileID string) ([]byte, error) {
+	return readFile(OpenFile(ctx, b, fileID))
+}
+
+func readFile(r io.ReadCloser, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+	return io.ReadAll(r)
+}
+
+// OpenFile opens the file with the given ID like the OpenFile function, but downloads it
+// with the HTTP client configured for the bot, so proxy and TLS options apply.
+func (b *Bot) OpenFile(ctx context.Context, fileID string) (io.ReadCloser, error) {
+	var client bot.HttpClient = http.DefaultClient
+	if b.httpClient != nil {
+		client = b.httpClient
+	}
+	if metrics, ok := client.(*metricsHTTPClient); ok {
+		// downloads are no Bot API calls
+		client = metrics.client
+	}
+	return openFile(ctx, b.API(), client, fileID)
+}
+
+// DownloadFile reads the whole file with the given ID into memory, see Bot.OpenFile.
+func (b *Bot) DownloadFile(ctx context.Context, fileID string) ([]byte, error) {
+	return readFile(b.OpenFile(ctx, fileID))
+}
@@ -0,0 +1,70 @@
+package telegram
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type recordingTransport struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.paths = append(t.paths, req.URL.Path)
+	t.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestDownloadFile(t *testing.T) {
+	local := filepath.Join(t.TempDir(), "local.txt")
+	if err := os.WriteFile(local, []byte("from disk"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/file/bottoken/docs/a.txt":
+			_, _ = w.Write([]byte("from server"))
+		case strings.HasPrefix(r.URL.Path, "/file/"):
+			http.NotFound(w, r)
+		default:
+			path := map[string]string{"a": "docs/a.txt", "gone": "docs/gone.txt", "local": local}[r.FormValue("file_id")]
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"file_id":"f","file_unique_id":"u","file_path":"` + path + `"}}`))
+		}
+	})
+	transport := &recordingTransport{}
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL),
+		WithHTTPClient(&http.Client{Transport: transport}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		fileID  string
+		want    string
+		wantErr bool
+	}{
+		{fileID: "a", want: "from server"},
+		{fileID: "local", want: "from disk"},
+		{fileID: "gone", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fileID, func(t *testing.T) {
+			data, err := app.DownloadFile(ctx, tt.fileID)
+			if (err != nil) != tt.wantErr || string(data) != tt.want {
+				t.Errorf("got %q, %v", data, err)
+			}
+		})
+	}
+	if !slices.Contains(transport.paths, "/file/bottoken/docs/a.txt") {
+		t.Errorf("download did not use the configured client: %v", transport.paths)
+	}
+}
@@ -0,0 +1,198 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"log/slog"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// ErrFlagNotFound is returned by a FlagStore when the flag is unknown.
+var ErrFlagNotFound = errors.New("feature flag not found")
+
+// FlagRule describes which users a feature flag is enabled for.
+type FlagRule struct {
+	Name    string  `json:"name"`
+	Percent int     `json:"percent"`            // Share of users the flag is enabled for, 0 to 100
+	UserIDs []int64 `json:"user_ids,omitempty"` // Users the flag is always enabled for
+}
+
+// Enabled reports whether the rule enables the flag for the user. The percentage rollout
+// hashes the flag name with the user ID, so a user keeps the decision as the percentage grows
+// and different flags roll out to different users.
+func (r *FlagRule) Enabled(userID int64) bool {
+	if slices.Contains(r.UserIDs, userID) {
+		return true
+	}
+	if r.Percent <= 0 {
+		return false
+	}
+	if r.Percent >= 100 {
+		return true
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(r.Name + ":" + strconv.FormatInt(userID, 10)))
+	return int(h.Sum32()%100) < r.Percent
+}
+
+// FlagStore persists feature flag rules.
+type FlagStore interface {
+	// SetFlag inserts or replaces the rule of the flag.
+	SetFlag(ctx context.Context, rule *FlagRule) error
+	// GetFlag returns the rule of the flag or ErrFlagNotFound.
+	GetFlag(ctx context.Context, name string) (*FlagRule, error)
+	// DeleteFlag removes the flag, deleting an unknown flag is not an error.
+	DeleteFlag(ctx context.Context, name string) error
+	// ListFlags returns all rules ordered by name.
+	ListFlags(ctx context.Context) ([]*FlagRule, error)
+}
+
+// MemoryFlagStore is an in-memory FlagStore, suitable for tests and single instance bots.
+type MemoryFlagStore struct {
+	mu    sync.RWMutex
+	flags map[string]FlagRule
+}
+
+// NewMemoryFlagStore creates an empty in-memory flag store.
+func NewMemoryFlagStore() *MemoryFlagStore {
+	return &MemoryFlagStore{flags: map[string]FlagRule{}}
+}
+
+// SetFlag implements FlagStore.
+func (s *MemoryFlagStore) SetFlag(ctx context.Context, rule *FlagRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record := *rule
+	record.UserIDs = slices.Clone(rule.UserIDs)
+	s.flags[rule.Name] = record
+	return nil
+}
+
+// GetFlag implements FlagStore.
+func (s *MemoryFlagStore) GetFlag(ctx context.Context, name string) (*FlagRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rule, ok := s.flags[name]
+	if !ok {
+		return nil, ErrFlagNotFound
+	}
+	rule.UserIDs = slices.Clone(rule.UserIDs)
+	return &rule, nil
+}
+
+// DeleteFlag implements FlagStore.
+func (s *MemoryFlagStore) DeleteFlag(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.flags, name)
+	return nil
+}
+
+// ListFlags implements FlagStore.
+func (s *MemoryFlagStore) ListFlags(ctx context.Context) ([]*FlagRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rules := make([]*FlagRule, 0, len(s.flags))
+	for _, rule := range s.flags {
+		rule.UserIDs = slices.Clone(rule.UserIDs)
+		rules = append(rules, &rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Name < rules[j].Name
+	})
+	return rules, nil
+}
+
+// Flags rolls features out gradually to a subset of users, backed by a FlagStore.
+type Flags struct {
+	store FlagStore
+}
+
+// NewFlags creates feature flags backed by the store.
+func NewFlags(store FlagStore) *Flags {
+	return &Flags{store: store}
+}
+
+// Enable enables the flag for the percentage of users and the given users.
+func (f *Flags) Enable(ctx context.Context, name string, percent int, userIDs ...int64) error {
+	return f.store.SetFlag(ctx, &FlagRule{Name: name, Percent: percent, UserIDs: userIDs})
+}
+
+// Disable disables the flag for every user.
+func (f *Flags) Disable(ctx context.Context, name string) error {
+	return f.store.DeleteFlag(ctx, name)
+}
+
+// Enabled reports whether the flag is enabled for the user. Unknown flags are disabled.
+func (f *Flags) Enabled(ctx context.Context, name string, userID int64) (bool, error) {
+	rule, err := f.store.GetFlag(ctx, name)
+	if errors.Is(err, ErrFlagNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return rule.Enabled(userID), nil
+}
+
+// flagScope is the feature flag context of an update.
+type flagScope struct {
+	flags  *Flags
+	userID int64
+}
+
+var flagScopeKey = NewKey[*flagScope]("flags")
+
+// Middleware returns a middleware making the flags available to handlers through FlagEnabled
+// for the user of the update.
+func (f *Flags) Middleware() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, update *Update) error {
+			scope := &flagScope{flags: f}
+			if user := UpdateUser(update); user != nil {
+				scope.userID = user.ID
+			}
+			return next(WithValue(ctx, flagScopeKey, scope), update)
+		}
+	}
+}
+
+// Require returns a middleware gating a binding behind the flag: updates of users the flag is
+// not enabled for are dropped without reaching the handler.
+func (f *Flags) Require(name string) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, update *Update) error {
+			user := UpdateUser(update)
+			if user == nil {
+				return nil
+			}
+			enabled, err := f.Enabled(ctx, name, user.ID)
+			if err != nil {
+				return err
+			}
+			if !enabled {
+				return nil
+			}
+			return next(ctx, update)
+		}
+	}
+}
+
+// FlagEnabled reports whether the flag is enabled for the user of the current update, see
+// Flags.Middleware. It returns false outside the middleware and when the store fails.
+func FlagEnabled(ctx context.Context, name string) bool {
+	scope, ok := Value(ctx, flagScopeKey)
+	if !ok || scope.userID == 0 {
+		return false
+	}
+	enabled, err := scope.flags.Enabled(ctx, name, scope.userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "check feature flag", slog.String("flag", name), slog.Any("error", err))
+		return false
+	}
+	return enabled
+}
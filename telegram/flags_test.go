@@ -0,0 +1,59 @@
+package telegram
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestFlags(t *testing.T) {
+	ctx := context.Background()
+	flags := NewFlags(NewMemoryFlagStore())
+	if ok, err := flags.Enabled(ctx, "new_menu", 1); ok || err != nil {
+		t.Errorf("unknown flag must be disabled: %v, %v", ok, err)
+	}
+
+	_ = flags.Enable(ctx, "new_menu", 30, 42)
+	enabled := 0
+	for id := int64(1); id <= 1000; id++ {
+		if ok, _ := flags.Enabled(ctx, "new_menu", id); ok {
+			enabled++
+		}
+	}
+	if enabled < 230 || enabled > 370 {
+		t.Errorf("expected about 30%% of users, got %d of 1000", enabled)
+	}
+	if ok, _ := flags.Enabled(ctx, "new_menu", 42); !ok {
+		t.Error("listed user must be enabled")
+	}
+
+	// users keep the decision as the rollout grows
+	rule := &FlagRule{Name: "new_menu", Percent: 30}
+	wider := &FlagRule{Name: "new_menu", Percent: 60}
+	for id := int64(1); id <= 1000; id++ {
+		if rule.Enabled(id) && !wider.Enabled(id) {
+			t.Fatalf("user %d lost the flag when the rollout grew", id)
+		}
+	}
+
+	var seen bool
+	update := &Update{Message: &models.Message{From: &models.User{ID: 42}}}
+	_ = flags.Middleware()(func(ctx context.Context, update *Update) error {
+		seen = FlagEnabled(ctx, "new_menu")
+		return nil
+	})(ctx, update)
+	if !seen {
+		t.Error("FlagEnabled must see the flag of the update user")
+	}
+
+	_ = flags.Disable(ctx, "new_menu")
+	called := false
+	_ = flags.Require("new_menu")(func(ctx context.Context, update *Update) error {
+		called = true
+		return nil
+	})(ctx, update)
+	if called {
+		t.Error("disabled flag must gate the handler")
+	}
+}
@@ -0,0 +1,214 @@
+package telegram
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// Greeting is a welcome or goodbye message template saved in the ChatSettings of a chat.
+// The text and the buttons may contain the placeholders {first_name}, {last_name},
+// {full_name}, {username}, {mention}, {id} and {chat}, values in the text are escaped for
+// the parse mode.
+type Greeting struct {
+	Text        string             `json:"text"`
+	ParseMode   models.ParseMode   `json:"parse_mode,omitempty"`
+	Media       string             `json:"media,omitempty"`        // File ID or URL of a photo sent with the text as caption
+	Buttons     [][]GreetingButton `json:"buttons,omitempty"`      // Rows of URL buttons
+	DeleteAfter time.Duration      `json:"delete_after,omitempty"` // Delay before the message is deleted, zero keeps it
+}
+
+// GreetingButton is a URL button of a Greeting.
+type GreetingButton struct {
+	Text string `json:"text"`
+	URL  string `json:"url"`
+}
+
+// clone returns a deep copy of the greeting.
+func (g *Greeting) clone() *Greeting {
+	if g == nil {
+		return nil
+	}
+	c := *g
+	c.Buttons = make([][]GreetingButton, len(g.Buttons))
+	for i, row := range g.Buttons {
+		c.Buttons[i] = append([]GreetingButton(nil), row...)
+	}
+	return &c
+}
+
+// Render returns the message greeting the member of the chat.
+func (g *Greeting) Render(chat models.Chat, member models.User) *Message {
+	escape := func(s string) string { return s }
+	mention := func(name string) string { return name }
+	switch g.ParseMode {
+	case models.ParseModeHTML:
+		escape = EscapeHTML
+		mention = func(name string) string {
+			return `<a href="tg://user?id=` + strconv.FormatInt(member.ID, 10) + `">` + name + "</a>"
+		}
+	case models.ParseModeMarkdown:
+		escape = EscapeMarkdownV2
+		mention = func(name string) string {
+			return "[" + name + "](tg://user?id=" + strconv.FormatInt(member.ID, 10) + ")"
+		}
+	}
+	fullName := strings.TrimSpace(member.FirstName + " " + member.LastName)
+	username := member.Username
+	if username != "" {
+		username = "@" + username
+	}
+	vars := []string{
+		"first_name", member.FirstName,
+		"last_name", member.LastName,
+		"full_name", fullName,
+		"username", username,
+		"id", strconv.FormatInt(member.ID, 10),
+		"chat", chat.Title,
+	}
+	textPairs := make([]string, 0, len(vars)+2)
+	rawPairs := make([]string, 0, len(vars)+2)
+	for i := 0; i < len(vars); i += 2 {
+		textPairs = append(textPairs, "{"+vars[i]+"}", escape(vars[i+1]))
+		rawPairs = append(rawPairs, "{"+vars[i]+"}", vars[i+1])
+	}
+	textPairs = append(textPairs, "{mention}", mention(escape(fullName)))
+	rawPairs = append(rawPairs, "{mention}", fullName)
+	text, raw := strings.NewReplacer(textPairs...), strings.NewReplacer(rawPairs...)
+
+	m := &Message{Text: text.Replace(g.Text), ParseMode: g.ParseMode}
+	if g.Media != "" {
+		m.Media = NewStringInputFile(g.Media)
+	}
+	for _, row := range g.Buttons {
+		buttons := make([]Button, len(row))
+		for i, button := range row {
+			buttons[i] = Button{Text: raw.Replace(button.Text), URL: raw.Replace(button.URL)}
+		}
+		m.Button = append(m.Button, buttons)
+	}
+	return m
+}
+
+// greetingOptions holds configuration for the Greetings.
+type greetingOptions struct {
+	defaults ChatSettings // Settings of chats without saved settings
+	bots     bool         // Whether bots joining or leaving are greeted
+}
+
+// GreetingOption defines a function type for configuring the Greetings.
+type GreetingOption func(*greetingOptions)
+
+// WithGreetingDefaults sets the welcome and goodbye messages of chats without saved settings.
+func WithGreetingDefaults(welcome, goodbye *Greeting) GreetingOption {
+	return func(o *greetingOptions) {
+		o.defaults.Welcome = welcome
+		o.defaults.Goodbye = goodbye
+	}
+}
+
+// WithGreetingBots greets bots joining and leaving too. By default they are ignored.
+func WithGreetingBots(enabled bool) GreetingOption {
+	return func(o *greetingOptions) {
+		o.bots = enabled
+	}
+}
+
+// Greetings sends the welcome and goodbye messages saved in the chat settings when members
+// join or leave a group.
+//
+// Only one handler receives an update, so bots also using a Captcha should not Bind the
+// greetings but call Welcome once a member solved the challenge.
+type Greetings struct {
+	app   *Bot
+	store SettingsStore
+	opts  *greetingOptions
+}
+
+// NewGreetings creates the greetings of the bot reading the templates from the store.
+func NewGreetings(app *Bot, store SettingsStore, options ...GreetingOption) *Greetings {
+	opts := &greetingOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return &Greetings{app: app, store: store, opts: opts}
+}
+
+// SetWelcome saves the welcome message of the chat, nil disables it.
+func (g *Greetings) SetWelcome(ctx context.Context, chatID int64, welcome *Greeting) error {
+	return g.update(ctx, chatID, func(settings *ChatSettings) {
+		settings.Welcome = welcome.clone()
+	})
+}
+
+// SetGoodbye saves the goodbye message of the chat, nil disables it.
+func (g *Greetings) SetGoodbye(ctx context.Context, chatID int64, goodbye *Greeting) error {
+	return g.update(ctx, chatID, func(settings *ChatSettings) {
+		settings.Goodbye = goodbye.clone()
+	})
+}
+
+func (g *Greetings) update(ctx context.Context, chatID int64, change func(*ChatSettings)) error {
+	settings, err := LoadChatSettings(ctx, g.store, chatID, g.opts.defaults)
+	if err != nil {
+		return err
+	}
+	change(settings)
+	return g.store.SaveSettings(ctx, settings)
+}
+
+// Bind registers the handler of member join and leave service messages.
+func (g *Greetings) Bind(middlewares ...MiddlewareFunc) {
+	g.app.BindMatch(func(update *Update) bool {
+		return update.Message != nil && (len(update.Message.NewChatMembers) > 0 || update.Message.LeftChatMember != nil)
+	}, g.handle, middlewares...)
+}
+
+func (g *Greetings) handle(ctx context.Context, update *Update) error {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		client = g.app.API()
+	}
+	chat := update.Message.Chat
+	for _, member := range update.Message.NewChatMembers {
+		if err := g.Welcome(ctx, client, chat, member); err != nil {
+			return err
+		}
+	}
+	if member := update.Message.LeftChatMember; member != nil {
+		return g.Goodbye(ctx, client, chat, *member)
+	}
+	return nil
+}
+
+// Welcome sends the welcome message of the chat to the member, if any.
+func (g *Greetings) Welcome(ctx context.Context, client *bot.Bot, chat models.Chat, member models.User) error {
+	return g.send(ctx, client, chat, member, func(settings *ChatSettings) *Greeting { return settings.Welcome })
+}
+
+// Goodbye sends the goodbye message of the chat for the member, if any.
+func (g *Greetings) Goodbye(ctx context.Context, client *bot.Bot, chat models.Chat, member models.User) error {
+	return g.send(ctx, client, chat, member, func(settings *ChatSettings) *Greeting { return settings.Goodbye })
+}
+
+func (g *Greetings) send(ctx context.Context, client *bot.Bot, chat models.Chat, member models.User, greeting func(*ChatSettings) *Greeting) error {
+	if member.IsBot && !g.opts.bots {
+		return nil
+	}
+	settings, err := LoadChatSettings(ctx, g.store, chat.ID, g.opts.defaults)
+	if err != nil {
+		return err
+	}
+	template := greeting(settings)
+	if template == nil {
+		return nil
+	}
+	m := template.Render(chat, member)
+	// the deletion is scheduled by the send hooks and kept in the deletion store
+	m.DeleteAfter = template.DeleteAfter
+	return SendBusinessMessage(contextWithSendHooks(ctx, g.app.sendHooks), client, "", chat.ID, m)
+}
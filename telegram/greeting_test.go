@@ -0,0 +1,90 @@
+package telegram
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestGreetingRender(t *testing.T) {
+	greeting := &Greeting{
+		Text:      "Welcome {mention} to {chat}!",
+		ParseMode: models.ParseModeHTML,
+		Buttons:   [][]GreetingButton{{{Text: "Rules of {chat}", URL: "https://example.com/rules?u={id}"}}},
+	}
+	chat := models.Chat{ID: -100, Title: "Go & Friends"}
+	member := models.User{ID: 42, FirstName: "Ann", LastName: "<Lee>"}
+	m := greeting.Render(chat, member)
+	if want := `Welcome <a href="tg://user?id=42">Ann &lt;Lee&gt;</a> to Go &amp; Friends!`; m.Text != want {
+		t.Errorf("unexpected text %q", m.Text)
+	}
+	if button := m.Button[0][0]; button.Text != "Rules of Go & Friends" || button.URL != "https://example.com/rules?u=42" {
+		t.Errorf("unexpected button %+v", button)
+	}
+
+	plain := (&Greeting{Text: "Bye {username} ({full_name})"}).Render(chat, models.User{FirstName: "Bob", Username: "bob"})
+	if plain.Text != "Bye @bob (Bob)" {
+		t.Errorf("unexpected text %q", plain.Text)
+	}
+}
+
+func TestGreetingsSettings(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemorySettingsStore()
+	defaults := &Greeting{Text: "Hello {first_name}"}
+	greetings := NewGreetings(&Bot{}, store, WithGreetingDefaults(defaults, nil))
+	welcome := &Greeting{Text: "Hi {first_name}"}
+	if err := greetings.SetWelcome(ctx, 1, welcome); err != nil {
+		t.Fatal(err)
+	}
+	welcome.Text = "changed"
+	settings, err := store.GetSettings(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.Welcome.Text != "Hi {first_name}" || settings.Goodbye != nil {
+		t.Errorf("unexpected settings %+v", settings)
+	}
+	// a bot joining is not greeted, so no client call is made
+	if err = greetings.Welcome(ctx, nil, models.Chat{ID: 1}, models.User{IsBot: true}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGreetingsSendPath(t *testing.T) {
+	sent := make(chan string, 1)
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/sendMessage") {
+			sent <- r.FormValue("text")
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":9,"date":1,"chat":{"id":-100}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+	store := NewMemoryDeletionStore()
+	footer := func(ctx context.Context, m *Message) error {
+		m.Text += " [bot]"
+		return nil
+	}
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL), WithDeletionStore(store), WithSendHooks(footer, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	welcome := &Greeting{Text: "Hi {first_name}", DeleteAfter: time.Hour}
+	greetings := NewGreetings(app, NewMemorySettingsStore(), WithGreetingDefaults(welcome, nil))
+	if err = greetings.Welcome(ctx, app.API(), models.Chat{ID: -100}, models.User{ID: 7, FirstName: "Ann"}); err != nil {
+		t.Fatal(err)
+	}
+	if text := <-sent; text != "Hi Ann [bot]" {
+		t.Errorf("send hooks were not applied: %q", text)
+	}
+	pending, _ := store.PendingDeletions(ctx)
+	if len(pending) != 1 || pending[0].MessageID != 9 {
+		t.Errorf("unexpected pending deletions: %+v", pending)
+	}
+}
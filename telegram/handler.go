@@ -2,10 +2,15 @@ package telegram
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"runtime/debug"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf16"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
@@ -25,6 +30,9 @@ type (
 	// ErrorHandlerFunc defines a function type for handling errors that occur during update processing.
 	// It receives the error along with the bot instance and update that caused the error.
 	ErrorHandlerFunc = func(ctx context.Context, bot *bot.Bot, update *Update, err error)
+	// ErrorMiddlewareFunc defines a function type for creating middleware that wraps ErrorHandlerFunc.
+	// Not calling next marks the error as handled and stops the chain.
+	ErrorMiddlewareFunc = func(next ErrorHandlerFunc) ErrorHandlerFunc
 )
 
 // WithMiddleware wraps a HandlerFunc with middleware chain and error handling.
@@ -44,33 +52,209 @@ func WithMiddleware(h HandlerFunc, e ErrorHandlerFunc, middleware ...MiddlewareF
 	}
 }
 
+// NewBotMiddleware adapts a MiddlewareFunc to a bot.Middleware of the underlying client, so it
+// runs for every update, including the no-route handler and handlers registered directly on
+// the client. Errors returned by the middleware are passed to the error handler.
+func NewBotMiddleware(middleware MiddlewareFunc, e ErrorHandlerFunc) bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			handler := middleware(func(ctx context.Context, update *Update) error {
+				next(ctx, b, update)
+				return nil
+			})
+			if err := handler(ctx, update); err != nil && e != nil {
+				e(ctx, b, update, err)
+			}
+		}
+	}
+}
+
+// SingleFlightKeyFunc derives the deduplication key of a callback query update.
+// Returning an empty string disables deduplication for the update.
+type SingleFlightKeyFunc = func(update *Update) string
+
+func callbackMessageKey(query *models.CallbackQuery) string {
+	if origin := query.Message.Message; origin != nil {
+		return strconv.FormatInt(origin.Chat.ID, 10) + ":" + strconv.Itoa(origin.ID)
+	}
+	if query.Message.InaccessibleMessage != nil {
+		return strconv.FormatInt(query.Message.InaccessibleMessage.Chat.ID, 10) + ":" + strconv.Itoa(query.Message.InaccessibleMessage.MessageID)
+	}
+	return query.InlineMessageID
+}
+
+// SingleFlightKeyMessage deduplicates callback queries by the message carrying the keyboard.
+// Any two buttons pressed on the same message are coalesced.
+func SingleFlightKeyMessage(update *Update) string {
+	if update.CallbackQuery == nil {
+		return ""
+	}
+	return callbackMessageKey(update.CallbackQuery)
+}
+
+// SingleFlightKeyMessageData deduplicates callback queries by message and callback data,
+// so different buttons on the same message are processed independently.
+func SingleFlightKeyMessageData(update *Update) string {
+	if update.CallbackQuery == nil {
+		return ""
+	}
+	return callbackMessageKey(update.CallbackQuery) + "|" + update.CallbackQuery.Data
+}
+
+// SingleFlightKeyUserData deduplicates callback queries by user and callback data,
+// so repeated presses of the same button by one user are coalesced across messages.
+func SingleFlightKeyUserData(update *Update) string {
+	if update.CallbackQuery == nil {
+		return ""
+	}
+	return strconv.FormatInt(update.CallbackQuery.From.ID, 10) + "|" + update.CallbackQuery.Data
+}
+
+// singleFlightOptions holds configuration for the single flight middleware.
+type singleFlightOptions struct {
+	key    SingleFlightKeyFunc // Derives the deduplication key
+	window time.Duration       // Duration completed keys are remembered
+}
+
+// SingleFlightOption defines a function type for configuring the single flight middleware.
+type SingleFlightOption func(*singleFlightOptions)
+
+// WithSingleFlightKey sets the function deriving the deduplication key.
+// Defaults to SingleFlightKeyMessage.
+func WithSingleFlightKey(fn SingleFlightKeyFunc) SingleFlightOption {
+	return func(o *singleFlightOptions) {
+		o.key = fn
+	}
+}
+
+// WithDedupWindow remembers successfully completed keys for the given duration and drops
+// repeated updates with the same key, instead of only coalescing concurrent calls.
+// Keys whose handler returned an error are forgotten, so the update can be retried.
+func WithDedupWindow(window time.Duration) SingleFlightOption {
+	return func(o *singleFlightOptions) {
+		o.window = window
+	}
+}
+
 // NewSingleFlightMiddleware creates a middleware that prevents duplicate callback query processing.
-// It uses singleflight to ensure that multiple identical callback queries from the same message
-// are processed only once, based on the message ID as the deduplication key.
-func NewSingleFlightMiddleware() MiddlewareFunc {
+// It uses singleflight to ensure that multiple identical callback queries are processed only once.
+// By default the message ID is used as the deduplication key; use WithSingleFlightKey to
+// choose another key and WithDedupWindow to also drop duplicates arriving shortly after completion.
+func NewSingleFlightMiddleware(options ...SingleFlightOption) MiddlewareFunc {
+	opts := &singleFlightOptions{
+		key: SingleFlightKeyMessage,
+	}
+	for _, opt := range options {
+		opt(opts)
+	}
 	sf := &singleflight.Group{}
+	var (
+		mu   sync.Mutex
+		done = map[string]time.Time{}
+	)
+	seen := func(key string) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		now := time.Now()
+		if len(done) > 1024 {
+			for k, exp := range done {
+				if now.After(exp) {
+					delete(done, k)
+				}
+			}
+		}
+		exp, ok := done[key]
+		return ok && now.Before(exp)
+	}
+	remember := func(key string) {
+		mu.Lock()
+		defer mu.Unlock()
+		done[key] = time.Now().Add(opts.window)
+	}
 	return func(next HandlerFunc) HandlerFunc {
 		return func(ctx context.Context, update *Update) error {
 			if update.CallbackQuery == nil {
 				return next(ctx, update)
 			}
-			key := strconv.Itoa(update.CallbackQuery.Message.Message.ID)
+			key := opts.key(update)
+			if key == "" {
+				return next(ctx, update)
+			}
+			if opts.window > 0 && seen(key) {
+				return nil
+			}
 			_, err, _ := sf.Do(key, func() (any, error) {
-				return nil, next(ctx, update)
+				e := next(ctx, update)
+				// failed updates are not remembered, so a retry of the user is processed again
+				if opts.window > 0 && e == nil {
+					remember(key)
+				}
+				return nil, e
 			})
 			return err
 		}
 	}
 }
 
+// PanicError is the error produced when a handler panics.
+// It carries the recovered value and the stack trace of the panicking goroutine.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic recovered in bot handler: %v", e.Value)
+}
+
+// PanicReporter receives every recovered panic, e.g. to forward it to an error tracking service.
+type PanicReporter = func(ctx context.Context, update *Update, err *PanicError)
+
+// recoveryOptions holds configuration for the recovery middleware.
+type recoveryOptions struct {
+	errorHandler ErrorHandlerFunc // Handler notified with the converted panic error
+	reporter     PanicReporter    // Hook notified with the panic and its stack trace
+}
+
+// RecoveryOption defines a function type for configuring the recovery middleware.
+type RecoveryOption func(*recoveryOptions)
+
+// WithRecoveryErrorHandler delivers recovered panics as *PanicError to the given error handler,
+// so the user can still get an error reply.
+func WithRecoveryErrorHandler(fn ErrorHandlerFunc) RecoveryOption {
+	return func(o *recoveryOptions) {
+		o.errorHandler = fn
+	}
+}
+
+// WithRecoveryReporter sets a hook that receives every recovered panic with its stack trace.
+func WithRecoveryReporter(reporter PanicReporter) RecoveryOption {
+	return func(o *recoveryOptions) {
+		o.reporter = reporter
+	}
+}
+
 // NewRecoveryMiddleware creates a middleware that recovers from panics in bot handlers.
-// It logs any panic that occurs during update processing and prevents the bot from crashing.
-func NewRecoveryMiddleware() bot.Middleware {
+// It logs any panic that occurs during update processing together with its stack trace and
+// prevents the bot from crashing. The panic is converted into a *PanicError which is passed to
+// the configured reporter and error handler.
+func NewRecoveryMiddleware(options ...RecoveryOption) bot.Middleware {
+	opts := &recoveryOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
 	return func(next bot.HandlerFunc) bot.HandlerFunc {
 		return func(ctx context.Context, bot *bot.Bot, update *models.Update) {
 			defer func() {
 				if r := recover(); r != nil {
-					slog.Error("panic recovered in bot handler", slog.Any("error", r))
+					err := &PanicError{Value: r, Stack: debug.Stack()}
+					slog.Error("panic recovered in bot handler", slog.Any("error", r), slog.String("stack", string(err.Stack)))
+					if opts.reporter != nil {
+						opts.reporter(ctx, update, err)
+					}
+					if opts.errorHandler != nil {
+						opts.errorHandler(ctx, bot, update, err)
+					}
 				}
 			}()
 			next(ctx, bot, update)
@@ -78,79 +262,163 @@ func NewRecoveryMiddleware() bot.Middleware {
 	}
 }
 
+// checkMention reports whether the text mentions the bot and optionally removes the mentions.
+// Entity offsets are UTF-16 code units, so the text is processed in UTF-16 to stay correct
+// for messages containing emoji or other characters outside the BMP.
+func checkMention(text string, entities []models.MessageEntity, id int64, username string, trimMention bool) (string, bool) {
+	type replacement struct {
+		start, end int
+		value      []uint16
+	}
+	units := utf16.Encode([]rune(text))
+	isMention := false
+	var replacements []replacement
+	for _, entity := range entities {
+		start, end := entity.Offset, entity.Offset+entity.Length
+		if start < 0 || end > len(units) || start > end {
+			continue
+		}
+		entityStr := string(utf16.Decode(units[start:end]))
+		switch entity.Type {
+		case models.MessageEntityTypeMention: // "mention"适用于有用户名的普通用户
+			if strings.EqualFold(entityStr, "@"+username) {
+				isMention = true
+				replacements = append(replacements, replacement{start: start, end: end})
+			}
+		case models.MessageEntityTypeTextMention: // "text_mention"适用于没有用户名的用户或需要通过ID提及用户的情况
+			if entity.User != nil && entity.User.ID == id {
+				isMention = true
+				replacements = append(replacements, replacement{start: start, end: end})
+			}
+		case models.MessageEntityTypeBotCommand: // "bot_command"适用于命令
+			if strings.HasSuffix(strings.ToLower(entityStr), "@"+strings.ToLower(username)) {
+				isMention = true
+				command := entityStr[:len(entityStr)-len(username)-1]
+				replacements = append(replacements, replacement{start: start, end: end, value: utf16.Encode([]rune(command))})
+			}
+		default:
+			continue
+		}
+	}
+	if !trimMention || len(replacements) == 0 {
+		return text, isMention
+	}
+	// 从后往前替换，保证前面实体的偏移量不受影响
+	sort.Slice(replacements, func(i, j int) bool {
+		return replacements[i].start > replacements[j].start
+	})
+	for _, r := range replacements {
+		units = append(units[:r.start], append(r.value, units[r.end:]...)...)
+	}
+	return string(utf16.Decode(units)), isMention
+}
+
+// groupFilterOptions holds configuration for the group message filter middleware.
+type groupFilterOptions struct {
+	allowCommands    bool               // Pass commands that are not addressed to another bot
+	allowForumTopics bool               // Pass messages sent in forum topics
+	allowedUsers     map[int64]struct{} // Users whose messages always pass
+	allowedChats     map[int64]struct{} // If not empty, only these chats are processed
+	deniedChats      map[int64]struct{} // Chats that are never processed
+}
+
+// GroupFilterOption defines a function type for configuring the group message filter middleware.
+type GroupFilterOption func(*groupFilterOptions)
+
+// WithGroupCommands passes every command in groups even without mentioning the bot,
+// unless the command is explicitly addressed to another bot (e.g. "/help@other_bot").
+func WithGroupCommands(allow bool) GroupFilterOption {
+	return func(o *groupFilterOptions) {
+		o.allowCommands = allow
+	}
+}
+
+// WithGroupForumTopics passes every message sent inside a forum topic.
+func WithGroupForumTopics(allow bool) GroupFilterOption {
+	return func(o *groupFilterOptions) {
+		o.allowForumTopics = allow
+	}
+}
+
+// WithGroupAllowedUsers passes every message sent by the given users, e.g. admins.
+func WithGroupAllowedUsers(ids ...int64) GroupFilterOption {
+	return func(o *groupFilterOptions) {
+		for _, id := range ids {
+			o.allowedUsers[id] = struct{}{}
+		}
+	}
+}
+
+// WithGroupAllowedChats restricts processing of group messages to the given chats.
+func WithGroupAllowedChats(ids ...int64) GroupFilterOption {
+	return func(o *groupFilterOptions) {
+		for _, id := range ids {
+			o.allowedChats[id] = struct{}{}
+		}
+	}
+}
+
+// WithGroupDeniedChats drops every message from the given chats.
+func WithGroupDeniedChats(ids ...int64) GroupFilterOption {
+	return func(o *groupFilterOptions) {
+		for _, id := range ids {
+			o.deniedChats[id] = struct{}{}
+		}
+	}
+}
+
+// isCommandFor reports whether the message starts with a command not addressed to another bot.
+func isCommandFor(text string, entities []models.MessageEntity, username string) bool {
+	if len(entities) == 0 || entities[0].Type != models.MessageEntityTypeBotCommand || entities[0].Offset != 0 {
+		return false
+	}
+	units := utf16.Encode([]rune(text))
+	if entities[0].Length > len(units) {
+		return false
+	}
+	command := string(utf16.Decode(units[:entities[0].Length]))
+	_, target, found := strings.Cut(command, "@")
+	return !found || strings.EqualFold(target, username)
+}
+
 // NewGroupMessageFilterMiddleware creates a middleware that filters group messages based on bot mentions.
 // It only processes group messages where the bot is explicitly mentioned through @username, replies,
 // or text mentions. The middleware caches bot information to reduce API calls and optionally
-// removes mention text from the message content.
+// removes mention text from the message content. Additional pass-through rules can be configured
+// with GroupFilterOption. Bot.GroupMessageFilter creates the same middleware sharing Bot.Self.
 //
 // Parameters:
 //   - b: The bot instance used to retrieve bot information
 //   - trimMention: Whether to remove mention text from processed messages
 //   - infoExpire: Duration to cache bot information before refreshing
-func NewGroupMessageFilterMiddleware(b *bot.Bot, trimMention bool, infoExpire time.Duration) MiddlewareFunc {
-	var (
-		ts   time.Time
-		sf   singleflight.Group
-		user *models.User
-	)
+//   - options: Optional pass-through rules and chat allow/deny lists
+func NewGroupMessageFilterMiddleware(b *bot.Bot, trimMention bool, infoExpire time.Duration, options ...GroupFilterOption) MiddlewareFunc {
+	self := newTTLCache[struct{}, *models.User](infoExpire)
+	return newGroupMessageFilter(func(ctx context.Context) (*models.User, error) {
+		return self.get(struct{}{}, "getMe", func() (*models.User, error) {
+			return b.GetMe(ctx)
+		})
+	}, trimMention, options...)
+}
 
-	isGroupChatType := func(t models.ChatType) bool {
-		return t == models.ChatTypeGroup || t == models.ChatTypeSupergroup || t == models.ChatTypeChannel
+// GroupMessageFilter creates the group message filter middleware, see NewGroupMessageFilterMiddleware,
+// resolving the bot's identity with Self.
+func (b *Bot) GroupMessageFilter(trimMention bool, options ...GroupFilterOption) MiddlewareFunc {
+	return newGroupMessageFilter(b.Self, trimMention, options...)
+}
+
+func newGroupMessageFilter(self func(ctx context.Context) (*models.User, error), trimMention bool, options ...GroupFilterOption) MiddlewareFunc {
+	opts := &groupFilterOptions{
+		allowedUsers: map[int64]struct{}{},
+		allowedChats: map[int64]struct{}{},
+		deniedChats:  map[int64]struct{}{},
+	}
+	for _, opt := range options {
+		opt(opts)
 	}
 
-	getBotInfo := func(ctx context.Context, sf *singleflight.Group) (int64, string, error) {
-		v, err, _ := sf.Do("getMe", func() (any, error) {
-			// 判断缓存存在且未过期，则直接使用
-			if user != nil && time.Since(ts) < infoExpire {
-				return user, nil
-			}
-			// 获取bot信息
-			u, err := b.GetMe(ctx)
-			if err != nil {
-				return nil, err
-			}
-			user = u
-			ts = time.Now()
-			return u, nil
-		})
-		if err != nil {
-			return 0, "", err
-		}
-		return v.(*models.User).ID, v.(*models.User).Username, nil
-	}
-
-	checkMention := func(text string, entities []models.MessageEntity, id int64, username string, trimMention bool) (string, bool) {
-		isMention := false
-		for _, entity := range entities {
-			entityStr := text[entity.Offset : entity.Offset+entity.Length]
-			switch entity.Type {
-			case models.MessageEntityTypeMention: // "mention"适用于有用户名的普通用户
-				if entityStr == "@"+username {
-					isMention = true
-					if trimMention {
-						text = text[:entity.Offset] + text[entity.Offset+entity.Length:]
-					}
-				}
-			case models.MessageEntityTypeTextMention: // "text_mention"适用于没有用户名的用户或需要通过ID提及用户的情况
-				if entity.User.ID == id {
-					isMention = true
-					if trimMention {
-						text = text[:entity.Offset] + text[entity.Offset+entity.Length:]
-					}
-				}
-			case models.MessageEntityTypeBotCommand: // "bot_command"适用于命令
-				if strings.HasSuffix(entityStr, "@"+username) {
-					isMention = true
-					if trimMention {
-						entityStr = strings.ReplaceAll(entityStr, "@"+username, "")
-						text = text[:entity.Offset] + entityStr + text[entity.Offset+entity.Length:]
-					}
-				}
-			default:
-				continue
-			}
-		}
-		return text, isMention
+	isGroupChatType := func(t models.ChatType) bool {
+		return t == models.ChatTypeGroup || t == models.ChatTypeSupergroup || t == models.ChatTypeChannel
 	}
 
 	return func(next HandlerFunc) HandlerFunc {
@@ -160,20 +428,45 @@ func NewGroupMessageFilterMiddleware(b *bot.Bot, trimMention bool, infoExpire ti
 				return next(ctx, update)
 			}
 
-			id, username, err := getBotInfo(ctx, &sf)
+			// 判断群是否在黑名单或白名单中
+			chatID := update.Message.Chat.ID
+			if _, denied := opts.deniedChats[chatID]; denied {
+				return nil
+			}
+			if _, allowed := opts.allowedChats[chatID]; len(opts.allowedChats) > 0 && !allowed {
+				return nil
+			}
+
+			// 判断是否是指定用户或论坛话题，是则处理
+			if update.Message.From != nil {
+				if _, allowed := opts.allowedUsers[update.Message.From.ID]; allowed {
+					return next(ctx, update)
+				}
+			}
+			if opts.allowForumTopics && update.Message.IsTopicMessage {
+				return next(ctx, update)
+			}
+
+			me, err := self(ctx)
 			if err != nil {
 				// 获取bot信息失败，放弃处理
 				slog.Error("get bot info error", slog.String("error", err.Error()))
 				return err
 			}
+			id, username := me.ID, me.Username
 
 			// 判断是不是回复消息，判断回复的消息是否是指定的bot，是则处理
-			if update.Message.ReplyToMessage != nil && update.Message.ReplyToMessage.From.ID == id {
+			if update.Message.ReplyToMessage != nil && update.Message.ReplyToMessage.From != nil && update.Message.ReplyToMessage.From.ID == id {
 				return next(ctx, update)
 			}
 
 			isMention := false
 
+			// 判断是否是发给本bot的命令，是则处理
+			if opts.allowCommands && isCommandFor(update.Message.Text, update.Message.Entities, username) {
+				isMention = true
+			}
+
 			// 判断Text中是否有提及bot，有则处理
 			if update.Message.Entities != nil && update.Message.Text != "" {
 				text, mention := checkMention(update.Message.Text, update.Message.Entities, id, username, trimMention)
@@ -182,9 +475,9 @@ func NewGroupMessageFilterMiddleware(b *bot.Bot, trimMention bool, infoExpire ti
 			}
 
 			// 判断Caption中是否有提及bot，有则处理
-			if !isMention && update.Message.CaptionEntities != nil && update.Message.Caption != "" {
-				text, mention := checkMention(update.Message.Caption, update.Message.CaptionEntities, id, username, trimMention)
-				update.Message.Text = text
+			if update.Message.CaptionEntities != nil && update.Message.Caption != "" {
+				caption, mention := checkMention(update.Message.Caption, update.Message.CaptionEntities, id, username, trimMention)
+				update.Message.Caption = caption
 				isMention = mention || isMention
 			}
 
@@ -0,0 +1,298 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+func newCallbackUpdate(userID int64, messageID int, data string) *Update {
+	return &Update{
+		CallbackQuery: &models.CallbackQuery{
+			From: models.User{ID: userID},
+			Message: models.MaybeInaccessibleMessage{
+				Message: &models.Message{ID: messageID, Chat: models.Chat{ID: 1}},
+			},
+			Data: data,
+		},
+	}
+}
+
+func TestSingleFlightMiddleware(t *testing.T) {
+	calls := 0
+	handler := NewSingleFlightMiddleware(
+		WithSingleFlightKey(SingleFlightKeyMessageData),
+		WithDedupWindow(time.Minute),
+	)(func(ctx context.Context, update *Update) error {
+		calls++
+		return nil
+	})
+	ctx := context.Background()
+	_ = handler(ctx, newCallbackUpdate(1, 10, "menu:a"))
+	_ = handler(ctx, newCallbackUpdate(2, 10, "menu:a"))
+	_ = handler(ctx, newCallbackUpdate(1, 10, "menu:b"))
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestSingleFlightForgetsFailedKeys(t *testing.T) {
+	calls := 0
+	handler := NewSingleFlightMiddleware(WithDedupWindow(time.Minute))(func(ctx context.Context, update *Update) error {
+		calls++
+		if calls == 1 {
+			return errors.New("fail")
+		}
+		return nil
+	})
+	ctx := context.Background()
+	if err := handler(ctx, newCallbackUpdate(1, 10, "menu:a")); err == nil {
+		t.Fatal("expected the handler error")
+	}
+	// the failed update is retried, the successful one is deduplicated
+	_ = handler(ctx, newCallbackUpdate(1, 10, "menu:a"))
+	_ = handler(ctx, newCallbackUpdate(1, 10, "menu:a"))
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	var (
+		reported *PanicError
+		handled  error
+	)
+	middleware := NewRecoveryMiddleware(
+		WithRecoveryReporter(func(ctx context.Context, update *Update, err *PanicError) {
+			reported = err
+		}),
+		WithRecoveryErrorHandler(func(ctx context.Context, b *bot.Bot, update *Update, err error) {
+			handled = err
+		}),
+	)
+	handler := middleware(func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		panic("boom")
+	})
+	handler(context.Background(), nil, &models.Update{ID: 1})
+	if reported == nil || reported.Value != "boom" || !strings.Contains(string(reported.Stack), "TestRecoveryMiddleware") {
+		t.Fatalf("panic was not reported with its stack: %+v", reported)
+	}
+	var panicErr *PanicError
+	if !errors.As(handled, &panicErr) || panicErr != reported {
+		t.Errorf("panic was not delivered to the error handler: %v", handled)
+	}
+}
+
+func TestPanicReachesErrorHandler(t *testing.T) {
+	reports := make(chan *ErrorReport, 1)
+	app, err := NewApp(Config{Token: "token"}, WithErrorReporter(ErrorReporterFunc(func(ctx context.Context, report *ErrorReport) {
+		reports <- report
+	})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.BindCommand("crash", func(ctx context.Context, update *Update) error {
+		panic("boom")
+	})
+	body := `{"update_id":7,"message":{"message_id":1,"date":1,"chat":{"id":1,"type":"private"},"text":"/crash"}}`
+	_ = app.HandleUpdateJSON(context.Background(), []byte(body))
+	select {
+	case report := <-reports:
+		if !report.IsPanic() || report.UpdateID != 7 || len(report.Stack) == 0 {
+			t.Errorf("unexpected report: %+v", report)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("panic was not reported")
+	}
+}
+
+func TestGroupMessageFilter(t *testing.T) {
+	self := func(ctx context.Context) (*models.User, error) {
+		return &models.User{ID: 1, Username: "test_bot"}, nil
+	}
+	command := func(text string) []models.MessageEntity {
+		length := len(text)
+		if i := strings.Index(text, " "); i > 0 {
+			length = i
+		}
+		return []models.MessageEntity{{Type: models.MessageEntityTypeBotCommand, Offset: 0, Length: length}}
+	}
+	group := func(chatID, userID int64, text string) *models.Message {
+		return &models.Message{Chat: models.Chat{ID: chatID, Type: models.ChatTypeSupergroup}, From: &models.User{ID: userID}, Text: text}
+	}
+	tests := []struct {
+		name    string
+		options []GroupFilterOption
+		msg     *models.Message
+		want    bool
+	}{
+		{"private chat", nil, &models.Message{Chat: models.Chat{ID: 5, Type: models.ChatTypePrivate}, Text: "hi"}, true},
+		{"no mention", nil, group(-1, 5, "hi"), false},
+		{"command without option", nil, &models.Message{Chat: models.Chat{ID: -1, Type: models.ChatTypeGroup}, Text: "/help", Entities: command("/help")}, false},
+		{"command", []GroupFilterOption{WithGroupCommands(true)}, &models.Message{Chat: models.Chat{ID: -1, Type: models.ChatTypeGroup}, Text: "/help", Entities: command("/help")}, true},
+		{"command for another bot", []GroupFilterOption{WithGroupCommands(true)}, &models.Message{Chat: models.Chat{ID: -1, Type: models.ChatTypeGroup}, Text: "/help@other_bot", Entities: command("/help@other_bot")}, false},
+		{"allowed user", []GroupFilterOption{WithGroupAllowedUsers(5)}, group(-1, 5, "hi"), true},
+		{"forum topic", []GroupFilterOption{WithGroupForumTopics(true)}, &models.Message{Chat: models.Chat{ID: -1, Type: models.ChatTypeSupergroup}, IsTopicMessage: true, Text: "hi"}, true},
+		{"reply to the bot", nil, &models.Message{Chat: models.Chat{ID: -1, Type: models.ChatTypeGroup}, Text: "ok", ReplyToMessage: &models.Message{From: &models.User{ID: 1}}}, true},
+		{"denied chat", []GroupFilterOption{WithGroupAllowedUsers(5), WithGroupDeniedChats(-1)}, group(-1, 5, "hi"), false},
+		{"chat not allowed", []GroupFilterOption{WithGroupAllowedUsers(5), WithGroupAllowedChats(-2)}, group(-1, 5, "hi"), false},
+		{"allowed chat", []GroupFilterOption{WithGroupAllowedUsers(5), WithGroupAllowedChats(-1)}, group(-1, 5, "hi"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			passed := false
+			handler := newGroupMessageFilter(self, false, tt.options...)(func(ctx context.Context, update *Update) error {
+				passed = true
+				return nil
+			})
+			if err := handler(context.Background(), &Update{Message: tt.msg}); err != nil {
+				t.Fatal(err)
+			}
+			if passed != tt.want {
+				t.Errorf("passed = %v, want %v", passed, tt.want)
+			}
+		})
+	}
+
+	// mentions in captions are trimmed from the caption, the text is left alone
+	var got *models.Message
+	handler := newGroupMessageFilter(self, true)(func(ctx context.Context, update *Update) error {
+		got = update.Message
+		return nil
+	})
+	photo := &models.Message{
+		Chat:            models.Chat{ID: -1, Type: models.ChatTypeSupergroup},
+		Caption:         "@test_bot look",
+		CaptionEntities: []models.MessageEntity{{Type: models.MessageEntityTypeMention, Offset: 0, Length: 9}},
+	}
+	if err := handler(context.Background(), &Update{Message: photo}); err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.Text != "" || strings.TrimSpace(got.Caption) != "look" {
+		t.Errorf("unexpected caption handling: %+v", got)
+	}
+}
+
+func TestCheckMention(t *testing.T) {
+	// "👋 " is 3 UTF-16 code units, "你好 " is 3 UTF-16 code units
+	text := "👋 @test_bot 你好 /help@test_bot"
+	entities := []models.MessageEntity{
+		{Type: models.MessageEntityTypeMention, Offset: 3, Length: 9},
+		{Type: models.MessageEntityTypeBotCommand, Offset: 16, Length: 14},
+	}
+	trimmed, mention := checkMention(text, entities, 1, "test_bot", true)
+	if !mention {
+		t.Fatal("mention is not detected")
+	}
+	if trimmed != "👋  你好 /help" {
+		t.Errorf("trimmed text is invalid, got: %q", trimmed)
+	}
+	_, mention = checkMention("👋 @other_bot", []models.MessageEntity{
+		{Type: models.MessageEntityTypeMention, Offset: 3, Length: 10},
+	}, 1, "test_bot", false)
+	if mention {
+		t.Error("unexpected mention")
+	}
+}
+
+func TestUpdateMiddlewaresCoverNoRoute(t *testing.T) {
+	var seen []string
+	mw := func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, update *Update) error {
+			seen = append(seen, update.Message.Text)
+			if update.Message.Text == "deny" {
+				return errors.New("denied")
+			}
+			return next(ctx, update)
+		}
+	}
+	var handled error
+	app, err := NewApp(Config{Token: "token"},
+		AppendBotOptions(bot.WithNotAsyncHandlers()),
+		AppendUpdateMiddlewares(mw),
+		WithErrorHandler(func(ctx context.Context, b *bot.Bot, update *Update, err error) { handled = err }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	routed := 0
+	app.BindNoRoute(func(ctx context.Context, update *Update) error {
+		routed++
+		return nil
+	})
+	app.API().ProcessUpdate(context.Background(), &Update{Message: &models.Message{Text: "hello"}})
+	app.API().ProcessUpdate(context.Background(), &Update{Message: &models.Message{Text: "deny"}})
+	if len(seen) != 2 || routed != 1 {
+		t.Errorf("middleware did not cover the no-route handler: seen=%v, routed=%d", seen, routed)
+	}
+	if handled == nil || handled.Error() != "denied" {
+		t.Errorf("middleware error not handled: %v", handled)
+	}
+}
+
+func TestWithNoRouteHandler(t *testing.T) {
+	var handled error
+	app, err := NewApp(Config{Token: "token"},
+		AppendBotOptions(bot.WithNotAsyncHandlers()),
+		WithNoRouteHandler(func(ctx context.Context, update *Update) error {
+			return errors.New("no route")
+		}),
+		WithErrorHandler(func(ctx context.Context, b *bot.Bot, update *Update, err error) { handled = err }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.API().ProcessUpdate(context.Background(), &Update{Message: &models.Message{Text: "hello"}})
+	if handled == nil || handled.Error() != "no route" {
+		t.Errorf("no-route error not handled: %v", handled)
+	}
+}
+
+func TestAuthOptIn(t *testing.T) {
+	bind := func(app *Bot, command string, middlewares ...MiddlewareFunc) *map[string]any {
+		info := new(map[string]any)
+		app.BindCommand(command, func(ctx context.Context, update *Update) error {
+			*info = AuthInfo(ctx)
+			return nil
+		}, middlewares...)
+		return info
+	}
+	update := func(text string) *Update {
+		return &Update{Message: &models.Message{Text: text, From: &models.User{ID: 7, Username: "ada"}}}
+	}
+
+	app, err := NewApp(Config{Token: "token"}, AppendBotOptions(bot.WithNotAsyncHandlers()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := bind(app, "plain")
+	app.API().ProcessUpdate(context.Background(), update("/plain"))
+	if *info != nil {
+		t.Errorf("auth must be disabled by default: %v", *info)
+	}
+
+	app, err = NewApp(Config{Token: "token"}, AppendBotOptions(bot.WithNotAsyncHandlers()), WithAuth(DefaultAuthExtractor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	authed := bind(app, "private")
+	public := bind(app, "public", SkipAuth())
+	app.API().ProcessUpdate(context.Background(), update("/private"))
+	app.API().ProcessUpdate(context.Background(), update("/public"))
+	if (*authed)["uid"] != int64(7) {
+		t.Errorf("expected auth info, got %v", *authed)
+	}
+	if *public != nil {
+		t.Errorf("SkipAuth route must not be authenticated: %v", *public)
+	}
+
+	h := NewAuthMiddleware(AuthExtractorFunc(nil))(func(ctx context.Context, update *Update) error { return nil })
+	if err = h(context.Background(), update("/x")); err != nil {
+		t.Errorf("nil extractor must be a no-op: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// Update delivery modes reported by Health.
+const (
+	ModePolling = "polling"
+	ModeWebhook = "webhook"
+)
+
+// defaultHealthCacheTTL is how long a getMe probe result is reused.
+const defaultHealthCacheTTL = 30 * time.Second
+
+var errBotClosed = errors.New("bot is closed")
+
+// Health describes the state of the bot.
+type Health struct {
+	APIReachable bool      `json:"api_reachable"`        // Whether the last getMe probe succeeded
+	APIError     string    `json:"api_error,omitempty"`  // Error of the last getMe probe
+	CheckedAt    time.Time `json:"checked_at"`           // Time of the last getMe probe
+	Mode         string    `json:"mode"`                 // ModePolling, ModeWebhook or empty if not started
+	LastUpdate   time.Time `json:"last_update"`          // Time the last update was received, zero if none
+	QueueDepth   int64     `json:"queue_depth"`          // Number of updates currently being processed
+	PollFailures int       `json:"poll_failures"`        // Consecutive failed getUpdates calls in polling mode
+	PollError    string    `json:"poll_error,omitempty"` // Error of the last failed getUpdates call
+}
+
+// Ready reports whether the bot is started and can reach the Bot API.
+func (h Health) Ready() bool {
+	return h.Mode != "" && h.APIReachable
+}
+
+// botStatus tracks the runtime state reported by Bot.Health.
+type botStatus struct {
+	mode       atomic.Value // string
+	lastUpdate atomic.Int64 // unix nanoseconds
+	inFlight   atomic.Int64
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	apiErr    error
+}
+
+func newBotStatus() *botStatus {
+	s := &botStatus{}
+	s.mode.Store("")
+	return s
+}
+
+// middleware records the receipt of every update and the number of updates in progress.
+func (s *botStatus) middleware() bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			s.lastUpdate.Store(time.Now().UnixNano())
+			s.inFlight.Add(1)
+			defer s.inFlight.Add(-1)
+			next(ctx, b, update)
+		}
+	}
+}
+
+// Health probes the Bot API with getMe, reusing the result for 30 seconds,
+// and reports the update delivery mode and processing state.
+func (b *Bot) Health(ctx context.Context) Health {
+	s := b.status
+	s.mu.Lock()
+	if s.checkedAt.IsZero() || time.Since(s.checkedAt) > defaultHealthCacheTTL {
+		var err error
+		if client := b.API(); client == nil {
+			err = errBotClosed
+		} else {
+			_, err = client.GetMe(ctx)
+		}
+		s.checkedAt = time.Now()
+		s.apiErr = err
+	}
+	health := Health{
+		APIReachable: s.apiErr == nil,
+		CheckedAt:    s.checkedAt,
+		Mode:         s.mode.Load().(string),
+		QueueDepth:   s.inFlight.Load(),
+	}
+	if s.apiErr != nil {
+		health.APIError = s.apiErr.Error()
+	}
+	s.mu.Unlock()
+	if ts := s.lastUpdate.Load(); ts > 0 {
+		health.LastUpdate = time.Unix(0, ts)
+	}
+	if health.Mode == ModePolling && b.poller != nil {
+		if state := b.poller.current(); !state.Healthy {
+			health.PollFailures = state.Failures
+			health.PollError = state.LastError.Error()
+		}
+	}
+	return health
+}
+
+// HealthHandler returns an http.Handler serving "/healthz" and "/readyz" for container probes.
+// "/healthz" always answers 200 while the process is alive, "/readyz" answers 503 until the bot
+// is started and the Bot API is reachable. Both respond with the Health as JSON.
+func (b *Bot) HealthHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, b.Health(r.Context()), http.StatusOK)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		health := b.Health(r.Context())
+		status := http.StatusOK
+		if !health.Ready() {
+			status = http.StatusServiceUnavailable
+		}
+		writeHealth(w, health, status)
+	})
+	return mux
+}
+
+func writeHealth(w http.ResponseWriter, health Health, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(health)
+}
@@ -0,0 +1,80 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestHealth(t *testing.T) {
+	var getMe atomic.Int32
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/getMe") {
+			getMe.Add(1)
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"bot","username":"test_bot"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	probes := getMe.Load()
+
+	health := app.Health(ctx)
+	if !health.APIReachable || health.Mode != "" || health.Ready() || !health.LastUpdate.IsZero() {
+		t.Errorf("unexpected health before start: %+v", health)
+	}
+	rec := httptest.NewRecorder()
+	app.HealthHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("readyz before start: got %d", rec.Code)
+	}
+
+	app.status.mode.Store(ModePolling)
+	if err = app.HandleUpdateJSON(ctx, []byte(`{"update_id":1,"message":{"message_id":1,"date":1,"chat":{"id":1},"text":"hi"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	rec = httptest.NewRecorder()
+	app.HealthHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("readyz after start: got %d", rec.Code)
+	}
+	if err = json.NewDecoder(rec.Body).Decode(&health); err != nil {
+		t.Fatal(err)
+	}
+	if health.Mode != ModePolling || health.LastUpdate.IsZero() || health.QueueDepth != 0 {
+		t.Errorf("unexpected health after an update: %+v", health)
+	}
+	// the getMe probe is cached
+	if n := getMe.Load() - probes; n != 1 {
+		t.Errorf("expected 1 getMe probe, got %d", n)
+	}
+}
+
+func TestHealthUnreachable(t *testing.T) {
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"ok":false,"error_code":401,"description":"Unauthorized"}`))
+	})
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.status.mode.Store(ModeWebhook)
+	health := app.Health(context.Background())
+	if health.APIReachable || health.APIError == "" || health.Ready() {
+		t.Errorf("unexpected health: %+v", health)
+	}
+	rec := httptest.NewRecorder()
+	app.HealthHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("healthz must answer while alive, got %d", rec.Code)
+	}
+}
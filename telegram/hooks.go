@@ -0,0 +1,71 @@
+package telegram
+
+import (
+	"context"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// BeforeSendHook runs before a Message is sent or edited. It may modify the message,
+// e.g. to append a signature footer, or return an error to cancel the send.
+type BeforeSendHook = func(ctx context.Context, m *Message) error
+
+// AfterSendHook runs after a Message was sent or edited with the API result.
+type AfterSendHook = func(ctx context.Context, m *Message, sent *models.Message, err error)
+
+// sendHooks holds the outbound hooks applied to all send and edit paths.
+type sendHooks struct {
+	before []BeforeSendHook
+	after  []AfterSendHook
+}
+
+func (h *sendHooks) empty() bool {
+	return h == nil || (len(h.before) == 0 && len(h.after) == 0)
+}
+
+type sendHooksKey struct{}
+
+func contextWithSendHooks(ctx context.Context, hooks *sendHooks) context.Context {
+	if hooks.empty() || ctx.Value(sendHooksKey{}) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, sendHooksKey{}, hooks)
+}
+
+// runSendHooks wraps a send operation with the hooks attached to the context.
+// The inline keyboard is validated once the hooks ran, so oversized keyboards fail
+// with ErrKeyboardTooLarge instead of an opaque API error.
+func runSendHooks(ctx context.Context, m *Message, send func() (*models.Message, error)) error {
+	hooks, _ := ctx.Value(sendHooksKey{}).(*sendHooks)
+	if hooks.empty() {
+		if err := ValidateKeyboard(m.Button); err != nil {
+			return err
+		}
+		_, err := send()
+		return err
+	}
+	for _, before := range hooks.before {
+		if err := before(ctx, m); err != nil {
+			return err
+		}
+	}
+	if err := ValidateKeyboard(m.Button); err != nil {
+		return err
+	}
+	sent, err := send()
+	for _, after := range hooks.after {
+		after(ctx, m, sent, err)
+	}
+	return err
+}
+
+// newSendHooksMiddleware creates a middleware that makes the outbound hooks available
+// to every send helper called while processing an update.
+func newSendHooksMiddleware(hooks *sendHooks) bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			next(contextWithSendHooks(ctx, hooks), b, update)
+		}
+	}
+}
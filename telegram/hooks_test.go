@@ -0,0 +1,211 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestSendHooks(t *testing.T) {
+	var calls atomic.Int32
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.FormValue("text") == "fail [signed]" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":3,"date":1,"chat":{"id":1,"type":"private"},"text":"` + r.FormValue("text") + `"}}`))
+	})
+	errBlocked := errors.New("blocked")
+	var (
+		sent    []string
+		results []error
+	)
+	before := func(ctx context.Context, m *Message) error {
+		if strings.Contains(m.Text, "spam") {
+			return errBlocked
+		}
+		m.Text += " [signed]"
+		return nil
+	}
+	after := func(ctx context.Context, m *Message, msg *models.Message, err error) {
+		if err == nil {
+			sent = append(sent, msg.Text)
+		}
+		results = append(results, err)
+	}
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL), WithSendHooks(before, after))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	update := &Update{Message: &models.Message{Chat: models.Chat{ID: 1}}}
+
+	if err = app.SendMessage(ctx, update, &Message{Text: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || sent[0] != "hello [signed]" || results[0] != nil {
+		t.Errorf("before hook was not applied: %v, %v", sent, results)
+	}
+
+	// a failing before hook cancels the send, the after hooks are not run
+	if err = app.SendMessage(ctx, update, &Message{Text: "spam"}); !errors.Is(err, errBlocked) {
+		t.Errorf("expected the hook error, got %v", err)
+	}
+	if calls.Load() != 1 || len(results) != 1 {
+		t.Errorf("canceled send reached the API: %d calls, %d results", calls.Load(), len(results))
+	}
+
+	// the after hooks receive the API error
+	if _, err = app.SendTo(ctx, 1, &Message{Text: "fail"}); err == nil {
+		t.Fatal("expected the API error")
+	}
+	if len(results) != 2 || results[1] == nil {
+		t.Errorf("after hook did not receive the error: %v", results)
+	}
+
+	// package-level helpers outside of update processing do not run the hooks
+	if _, err = SendTo(ctx, app.API(), 1, &Message{Text: "plain"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Errorf("hooks ran without the bot context: %v", results)
+	}
+
+	// the context of an update handler carries the hooks
+	app.BindCommand("start", func(ctx context.Context, update *Update) error {
+		_, err := SendTo(ctx, app.API(), update.Message.Chat.ID, &Message{Text: "welcome"})
+		return err
+	})
+	body := `{"update_id":1,"message":{"message_id":1,"date":1,"chat":{"id":1,"type":"private"},"text":"/start"}}`
+	if err = app.HandleUpdateJSON(ctx, []byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 2 || sent[1] != "welcome [signed]" {
+		t.Errorf("hooks did not run in the handler: %v", sent)
+	}
+}
+
+func TestEditToHooks(t *testing.T) {
+	var texts []string
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		texts = append(texts, r.FormValue("text"))
+		if r.FormValue("text") == "same [signed]" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: message is not modified"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":5,"date":1,"chat":{"id":1,"type":"private"},"text":"` + r.FormValue("text") + `"}}`))
+	})
+	var edited []string
+	var results []error
+	before := func(ctx context.Context, m *Message) error {
+		m.Text += " [signed]"
+		return nil
+	}
+	after := func(ctx context.Context, m *Message, msg *models.Message, err error) {
+		if err == nil && msg != nil {
+			edited = append(edited, msg.Text)
+		}
+		results = append(results, err)
+	}
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL), WithSendHooks(before, after))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err = app.EditTo(ctx, 1, 5, &Message{Text: "updated"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(edited) != 1 || edited[0] != "updated [signed]" {
+		t.Errorf("hooks did not run on the edit: %v", edited)
+	}
+
+	// unchanged content is not an error, for the caller nor the after hooks
+	if err = app.EditTo(ctx, 1, 5, &Message{Text: "same"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[1] != nil {
+		t.Errorf("unexpected hook results: %v", results)
+	}
+
+	// invalid keyboards are rejected before the API is called
+	row := make([]Button, MaxButtonsPerRow+1)
+	for i := range row {
+		row[i] = NewURLButton("link", "https://example.com")
+	}
+	if err = app.EditTo(ctx, 1, 5, &Message{Text: "menu", Button: [][]Button{row}}); !errors.Is(err, ErrKeyboardTooLarge) {
+		t.Errorf("expected the keyboard validation error, got %v", err)
+	}
+	if len(texts) != 2 {
+		t.Errorf("invalid edit reached the API: %v", texts)
+	}
+}
+
+func TestSendHooksOutsideUpdates(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		texts []string
+	)
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		texts = append(texts, r.FormValue("text"))
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":3,"date":1,"chat":{"id":1,"type":"private"}}}`))
+	})
+	before := func(ctx context.Context, m *Message) error {
+		m.Text += " [signed]"
+		return nil
+	}
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL), WithSendHooks(before, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	sent := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(texts)
+	}
+
+	if _, err = app.SendMessages(ctx, 1, []*Message{{Text: "one"}, {Text: "two"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := sent(); !slices.Equal(got, []string{"one [signed]", "two [signed]"}) {
+		t.Errorf("SendMessages did not run the hooks: %v", got)
+	}
+
+	store := NewMemoryOutboxStore()
+	if err = store.Enqueue(ctx, NewOutboxMessage(1, &Message{Text: "queued"})); err != nil {
+		t.Fatal(err)
+	}
+	if err = NewOutboxSender(store, app.API(), WithOutboxSendHooks(app)).Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := sent(); len(got) != 3 || got[2] != "queued [signed]" {
+		t.Errorf("OutboxSender did not run the hooks: %v", got)
+	}
+
+	// every recipient of an admin API broadcast gets the message signed once
+	handler := app.AdminAPIHandler([]string{"key"})
+	req := httptest.NewRequest(http.MethodPost, "/broadcasts", strings.NewReader(`{"chat_ids":[1,2],"text":"news"}`))
+	req.Header.Set("Authorization", "Bearer key")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	deadline := time.Now().Add(2 * time.Second)
+	for len(sent()) < 5 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := sent(); len(got) != 5 || got[3] != "news [signed]" || got[4] != "news [signed]" {
+		t.Errorf("admin API broadcast did not run the hooks: %v", got)
+	}
+}
@@ -0,0 +1,77 @@
+package telegram
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// UpdateStore records the IDs of processed updates.
+type UpdateStore interface {
+	// MarkProcessed atomically records the update ID and reports whether it was seen for the first time.
+	MarkProcessed(ctx context.Context, updateID int64) (bool, error)
+}
+
+// MemoryUpdateStore is an in-memory UpdateStore remembering a bounded number of recent update IDs.
+// It protects against webhook redeliveries but does not survive restarts; use a persistent
+// implementation when restart replays matter.
+type MemoryUpdateStore struct {
+	mu       sync.Mutex
+	seen     map[int64]struct{}
+	ring     []int64
+	next     int
+	capacity int
+}
+
+// NewMemoryUpdateStore creates an in-memory store remembering up to capacity update IDs.
+func NewMemoryUpdateStore(capacity int) *MemoryUpdateStore {
+	if capacity <= 0 {
+		capacity = 10000
+	}
+	return &MemoryUpdateStore{
+		seen:     make(map[int64]struct{}, capacity),
+		ring:     make([]int64, 0, capacity),
+		capacity: capacity,
+	}
+}
+
+// MarkProcessed implements UpdateStore.
+func (s *MemoryUpdateStore) MarkProcessed(ctx context.Context, updateID int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.seen[updateID]; ok {
+		return false, nil
+	}
+	if len(s.ring) < s.capacity {
+		s.ring = append(s.ring, updateID)
+	} else {
+		delete(s.seen, s.ring[s.next])
+		s.ring[s.next] = updateID
+		s.next = (s.next + 1) % s.capacity
+	}
+	s.seen[updateID] = struct{}{}
+	return true, nil
+}
+
+// NewIdempotencyMiddleware creates a middleware that skips updates whose update_id was already
+// processed according to the store, protecting against webhook redeliveries and restart replays.
+// Updates are marked before the handler runs, giving at-most-once semantics for side effects.
+// Synthetic updates without an ID are always processed.
+func NewIdempotencyMiddleware(store UpdateStore) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, update *Update) error {
+			if update.ID == 0 {
+				return next(ctx, update)
+			}
+			first, err := store.MarkProcessed(ctx, update.ID)
+			if err != nil {
+				return err
+			}
+			if !first {
+				slog.Debug("skip duplicate update", slog.Int64("update_id", update.ID))
+				return nil
+			}
+			return next(ctx, update)
+		}
+	}
+}
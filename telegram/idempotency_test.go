@@ -0,0 +1,48 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	var handled []int64
+	handler := NewIdempotencyMiddleware(NewMemoryUpdateStore(2))(func(ctx context.Context, update *Update) error {
+		handled = append(handled, update.ID)
+		return nil
+	})
+	ctx := context.Background()
+	// 0 is a synthetic update, 1 is evicted by 2 and 3 and processed again
+	for _, id := range []int64{1, 1, 0, 0, 2, 3, 3, 1} {
+		if err := handler(ctx, &Update{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []int64{1, 0, 0, 2, 3, 1}
+	if len(handled) != len(want) {
+		t.Fatalf("handled %v, want %v", handled, want)
+	}
+	for i := range want {
+		if handled[i] != want[i] {
+			t.Fatalf("handled %v, want %v", handled, want)
+		}
+	}
+}
+
+type failingUpdateStore struct{}
+
+func (failingUpdateStore) MarkProcessed(context.Context, int64) (bool, error) {
+	return false, errors.New("store unavailable")
+}
+
+func TestIdempotencyMiddlewareStoreError(t *testing.T) {
+	called := false
+	handler := NewIdempotencyMiddleware(failingUpdateStore{})(func(ctx context.Context, update *Update) error {
+		called = true
+		return nil
+	})
+	if err := handler(context.Background(), &Update{ID: 1}); err == nil || called {
+		t.Errorf("a store error must stop the update: err=%v, called=%v", err, called)
+	}
+}
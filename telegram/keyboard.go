@@ -0,0 +1,187 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// Telegram limits of inline keyboards.
+const (
+	MaxButtonsPerRow   = 8   // Buttons allowed in a single row
+	MaxKeyboardButtons = 100 // Buttons allowed in a keyboard
+)
+
+// ErrKeyboardTooLarge is returned when a keyboard exceeds Telegram's button limits.
+var ErrKeyboardTooLarge = errors.New("keyboard exceeds telegram limits")
+
+// ValidateKeyboard checks the keyboard against MaxButtonsPerRow and MaxKeyboardButtons.
+// The returned error wraps ErrKeyboardTooLarge and names the violated limit.
+func ValidateKeyboard(rows [][]Button) error {
+	total := 0
+	for i, row := range rows {
+		if len(row) > MaxButtonsPerRow {
+			return fmt.Errorf("%w: row %d has %d buttons, at most %d allowed", ErrKeyboardTooLarge, i, len(row), MaxButtonsPerRow)
+		}
+		total += len(row)
+	}
+	if total > MaxKeyboardButtons {
+		return fmt.Errorf("%w: %d buttons, at most %d allowed", ErrKeyboardTooLarge, total, MaxKeyboardButtons)
+	}
+	return nil
+}
+
+// ChunkButtons lays out the buttons in rows of perRow buttons, capped at MaxButtonsPerRow.
+func ChunkButtons(buttons []Button, perRow int) [][]Button {
+	if perRow <= 0 || perRow > MaxButtonsPerRow {
+		perRow = MaxButtonsPerRow
+	}
+	rows := make([][]Button, 0, (len(buttons)+perRow-1)/perRow)
+	for len(buttons) > 0 {
+		n := min(perRow, len(buttons))
+		rows = append(rows, buttons[:n:n])
+		buttons = buttons[n:]
+	}
+	return rows
+}
+
+// splitRows splits rows wider than MaxButtonsPerRow into several rows.
+func splitRows(rows [][]Button) [][]Button {
+	split := make([][]Button, 0, len(rows))
+	for _, row := range rows {
+		if len(row) <= MaxButtonsPerRow {
+			split = append(split, row)
+			continue
+		}
+		split = append(split, ChunkButtons(row, MaxButtonsPerRow)...)
+	}
+	return split
+}
+
+// PageData is the compact callback data of the page navigation buttons.
+type PageData struct {
+	Page int `json:"p"`
+}
+
+// PageNavigator returns the navigation row of a page, empty if no navigation is needed.
+// It must not return more than MaxButtonsPerRow buttons.
+type PageNavigator = func(page, pages int) []Button
+
+// NewPageNavigator creates a navigator with previous and next buttons routed to the route
+// with PageData, around a page indicator that is routed the same way to the current page.
+func NewPageNavigator(route string, options ...DataOption) PageNavigator {
+	return func(page, pages int) []Button {
+		if pages <= 1 {
+			return nil
+		}
+		row := make([]Button, 0, 3)
+		if page > 0 {
+			row = append(row, NewButton("‹", route, PageData{Page: page - 1}, options...))
+		}
+		row = append(row, NewButton(strconv.Itoa(page+1)+"/"+strconv.Itoa(pages), route, PageData{Page: page}, options...))
+		if page < pages-1 {
+			row = append(row, NewButton("›", route, PageData{Page: page + 1}, options...))
+		}
+		return row
+	}
+}
+
+// PaginateKeyboard splits a keyboard of any size into pages respecting Telegram's limits.
+// Rows wider than MaxButtonsPerRow are split, and every page keeps room for the navigation row
+// appended by nav. It returns the rows of the requested page, clamped to the valid range,
+// and the number of pages.
+func PaginateKeyboard(rows [][]Button, page int, nav PageNavigator) ([][]Button, int) {
+	rows = splitRows(rows)
+	budget := MaxKeyboardButtons - MaxButtonsPerRow
+	var pages [][][]Button
+	var current [][]Button
+	count := 0
+	for _, row := range rows {
+		if count+len(row) > budget && len(current) > 0 {
+			pages = append(pages, current)
+			current, count = nil, 0
+		}
+		current = append(current, row)
+		count += len(row)
+	}
+	if len(current) > 0 || len(pages) == 0 {
+		pages = append(pages, current)
+	}
+	page = max(0, min(page, len(pages)-1))
+	result := pages[page]
+	if nav != nil {
+		if row := nav(page, len(pages)); len(row) > 0 {
+			result = append(result[:len(result):len(result)], row)
+		}
+	}
+	return result, len(pages)
+}
+
+// KeyboardEqual reports whether the two inline keyboards have the same buttons in the same layout.
+func KeyboardEqual(a, b [][]Button) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if len(a[i]) != len(b[i]) {
+			return false
+		}
+		for j := range a[i] {
+			if !reflect.DeepEqual(a[i][j], b[i][j]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// MessageUnchanged reports whether editing the current message into m would not change it,
+// in which case the edit can be skipped. Messages with media or a parse mode are never
+// reported as unchanged, since their rendered content can not be compared with the source.
+func MessageUnchanged(current *models.Message, m *Message) bool {
+	if current == nil || m.Media != nil || m.ParseMode != "" {
+		return false
+	}
+	text, entities := current.Text, current.Entities
+	if len(current.Photo) > 0 {
+		text, entities = current.Caption, current.CaptionEntities
+	}
+	if text != m.Text || !formattingEqual(entities, m.Entities) {
+		return false
+	}
+	var keyboard [][]Button
+	if current.ReplyMarkup != nil {
+		keyboard = current.ReplyMarkup.InlineKeyboard
+	}
+	return KeyboardEqual(keyboard, m.Button)
+}
+
+// formattingEqual reports whether the entities of a sent message match the requested ones,
+// ignoring the entities Telegram detects automatically, such as URLs and mentions.
+func formattingEqual(sent, requested []models.MessageEntity) bool {
+	formatting := make([]models.MessageEntity, 0, len(sent))
+	for _, e := range sent {
+		switch e.Type {
+		case models.MessageEntityTypeMention, models.MessageEntityTypeHashtag, models.MessageEntityTypeCashtag,
+			models.MessageEntityTypeBotCommand, models.MessageEntityTypeURL, models.MessageEntityTypeEmail,
+			models.MessageEntityTypePhoneNumber:
+			continue
+		}
+		formatting = append(formatting, e)
+	}
+	if len(formatting) != len(requested) {
+		return false
+	}
+	for i, e := range formatting {
+		r := requested[i]
+		if e.Type != r.Type || e.Offset != r.Offset || e.Length != r.Length || e.URL != r.URL ||
+			e.Language != r.Language || e.CustomEmojiID != r.CustomEmojiID ||
+			(e.User == nil) != (r.User == nil) || (e.User != nil && e.User.ID != r.User.ID) {
+			return false
+		}
+	}
+	return true
+}
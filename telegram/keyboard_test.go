@@ -0,0 +1,69 @@
+package telegram
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func testButtons(n int) []Button {
+	buttons := make([]Button, n)
+	for i := range buttons {
+		buttons[i] = Button{Text: strconv.Itoa(i), CallbackData: "item:" + strconv.Itoa(i)}
+	}
+	return buttons
+}
+
+func TestValidateKeyboard(t *testing.T) {
+	if err := ValidateKeyboard(ChunkButtons(testButtons(100), 5)); err != nil {
+		t.Errorf("keyboard within limits rejected: %v", err)
+	}
+	if err := ValidateKeyboard([][]Button{testButtons(9)}); !errors.Is(err, ErrKeyboardTooLarge) {
+		t.Errorf("expected row limit error, got %v", err)
+	}
+	if err := ValidateKeyboard(ChunkButtons(testButtons(101), 8)); !errors.Is(err, ErrKeyboardTooLarge) {
+		t.Errorf("expected button limit error, got %v", err)
+	}
+}
+
+func TestPaginateKeyboard(t *testing.T) {
+	rows := [][]Button{testButtons(250)}
+	nav := NewPageNavigator("page")
+	first, pages := PaginateKeyboard(rows, 0, nav)
+	if pages != 3 {
+		t.Fatalf("expected 3 pages, got %d", pages)
+	}
+	if err := ValidateKeyboard(first); err != nil {
+		t.Errorf("page exceeds limits: %v", err)
+	}
+	if navRow := first[len(first)-1]; len(navRow) != 2 || navRow[0].Text != "1/3" {
+		t.Errorf("unexpected navigation row: %+v", navRow)
+	}
+	last, _ := PaginateKeyboard(rows, 10, nav)
+	if navRow := last[len(last)-1]; navRow[len(navRow)-1].Text != "3/3" {
+		t.Errorf("page is not clamped: %+v", navRow)
+	}
+	_, data, err := UnmarshalData[PageData](last[len(last)-1][0].CallbackData)
+	if err != nil || data.Page != 1 {
+		t.Errorf("unexpected previous page data: %+v, %v", data, err)
+	}
+}
+
+func TestMessageUnchanged(t *testing.T) {
+	keyboard := ChunkButtons(testButtons(3), 2)
+	current := &models.Message{Text: "menu", ReplyMarkup: &models.InlineKeyboardMarkup{InlineKeyboard: ChunkButtons(testButtons(3), 2)}}
+	if !MessageUnchanged(current, &Message{Text: "menu", Button: keyboard}) {
+		t.Error("identical message reported as changed")
+	}
+	if MessageUnchanged(current, &Message{Text: "menu", Button: ChunkButtons(testButtons(3), 3)}) {
+		t.Error("different layout reported as unchanged")
+	}
+	if MessageUnchanged(current, &Message{Text: "menu", Button: keyboard, ParseMode: models.ParseModeHTML}) {
+		t.Error("message with parse mode must not be compared")
+	}
+	if !MessageUnchanged(&models.Message{Text: "plain"}, &Message{Text: "plain"}) {
+		t.Error("message without keyboard reported as changed")
+	}
+}
@@ -0,0 +1,94 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// LifecycleHook runs at a lifecycle point of the bot, see Bot.OnStart and Bot.OnStop.
+type LifecycleHook = func(ctx context.Context) error
+
+// UpdateHook runs for every received update before it is routed, see Bot.OnUpdate.
+type UpdateHook = func(ctx context.Context, update *Update) error
+
+// lifecycle holds the registered lifecycle hooks.
+type lifecycle struct {
+	mu      sync.RWMutex
+	start   []LifecycleHook
+	stop    []LifecycleHook
+	updates []UpdateHook
+}
+
+func (l *lifecycle) runStart(ctx context.Context) error {
+	l.mu.RLock()
+	hooks := l.start
+	l.mu.RUnlock()
+	for _, hook := range hooks {
+		if err := hook(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *lifecycle) runStop(ctx context.Context) error {
+	l.mu.RLock()
+	hooks := l.stop
+	l.mu.RUnlock()
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		errs = append(errs, hooks[i](ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// middleware runs the update hooks, an error stops the update from being routed and is
+// passed to the error handler.
+func (l *lifecycle) middleware(errorHandler func() ErrorHandlerFunc) bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			l.mu.RLock()
+			hooks := l.updates
+			l.mu.RUnlock()
+			for _, hook := range hooks {
+				if err := hook(ctx, update); err != nil {
+					if handler := errorHandler(); handler != nil {
+						handler(ctx, b, update, err)
+					}
+					return
+				}
+			}
+			next(ctx, b, update)
+		}
+	}
+}
+
+// OnStart registers a hook run by Start and StartWebhook before updates are received, e.g. to
+// warm caches or announce availability. Hooks run in registration order and the first error
+// aborts the start.
+func (b *Bot) OnStart(hook LifecycleHook) {
+	b.lifecycle.mu.Lock()
+	defer b.lifecycle.mu.Unlock()
+	b.lifecycle.start = append(b.lifecycle.start, hook)
+}
+
+// OnStop registers a hook run once the update loop ended, e.g. to flush state. Hooks run in
+// reverse registration order with a context that is not canceled, and their errors are
+// returned by Start and StartWebhook.
+func (b *Bot) OnStop(hook LifecycleHook) {
+	b.lifecycle.mu.Lock()
+	defer b.lifecycle.mu.Unlock()
+	b.lifecycle.stop = append(b.lifecycle.stop, hook)
+}
+
+// OnUpdate registers a hook run for every received update before it is routed. An error
+// skips the update and is passed to the error handler.
+func (b *Bot) OnUpdate(hook UpdateHook) {
+	b.lifecycle.mu.Lock()
+	defer b.lifecycle.mu.Unlock()
+	b.lifecycle.updates = append(b.lifecycle.updates, hook)
+}
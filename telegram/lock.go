@@ -0,0 +1,167 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrLockTimeout is returned by the lock middleware when the lock could not be acquired in time.
+var ErrLockTimeout = errors.New("lock: timeout acquiring lock")
+
+// Locker provides mutual exclusion across bot replicas, e.g. backed by Redis.
+type Locker interface {
+	// TryLock acquires the lock for key if it is free. The lock expires after ttl unless released
+	// with the returned function, so a crashed replica can not hold it forever.
+	TryLock(ctx context.Context, key string, ttl time.Duration) (release func(ctx context.Context) error, ok bool, err error)
+}
+
+// MemoryLocker is an in-process Locker, suitable for tests and single instance bots.
+type MemoryLocker struct {
+	mu    sync.Mutex
+	locks map[string]memoryLock
+	seq   uint64
+}
+
+type memoryLock struct {
+	id      uint64
+	expires time.Time
+}
+
+// NewMemoryLocker creates an in-process locker.
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{locks: map[string]memoryLock{}}
+}
+
+// TryLock implements Locker.
+func (l *MemoryLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (func(context.Context) error, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if lock, ok := l.locks[key]; ok && now.Before(lock.expires) {
+		return nil, false, nil
+	}
+	l.seq++
+	id := l.seq
+	l.locks[key] = memoryLock{id: id, expires: now.Add(ttl)}
+	return func(context.Context) error {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.locks[key].id == id {
+			delete(l.locks, key)
+		}
+		return nil
+	}, true, nil
+}
+
+// LockKeyFunc returns the lock key of an update, or an empty string to process it without lock.
+type LockKeyFunc = func(update *Update) string
+
+// LockKeyChat locks per chat, so the updates of a chat are not processed concurrently across
+// replicas. Waiting updates acquire the lock in no particular order.
+func LockKeyChat(update *Update) string {
+	ref, err := UpdateMessageRef(update)
+	if err != nil {
+		return ""
+	}
+	return "chat:" + strconv.FormatInt(ref.ChatID, 10)
+}
+
+// LockKeyUpdate locks per update, so replicas receiving the same update do not process it
+// concurrently. The lock is released after the handler, a replica still waiting for it then
+// processes the update again; combine it with NewIdempotencyMiddleware to process it once.
+func LockKeyUpdate(update *Update) string {
+	if update.ID == 0 {
+		return ""
+	}
+	return "update:" + strconv.FormatInt(update.ID, 10)
+}
+
+// lockOptions holds configuration for the lock middleware.
+type lockOptions struct {
+	key   LockKeyFunc   // Lock key of an update
+	ttl   time.Duration // Expiry of a held lock
+	wait  time.Duration // Maximum time waiting for a lock
+	retry time.Duration // Interval between acquisition attempts
+}
+
+// LockOption defines a function type for configuring the lock middleware.
+type LockOption func(*lockOptions)
+
+// WithLockKey sets the function deriving the lock key. Defaults to LockKeyChat.
+func WithLockKey(fn LockKeyFunc) LockOption {
+	return func(o *lockOptions) {
+		o.key = fn
+	}
+}
+
+// WithLockTTL sets the expiry of a held lock, which must exceed the handler duration. Defaults to 30 seconds.
+func WithLockTTL(ttl time.Duration) LockOption {
+	return func(o *lockOptions) {
+		o.ttl = ttl
+	}
+}
+
+// WithLockWait sets how long an update waits for a busy lock before failing with ErrLockTimeout,
+// and the interval between attempts. Defaults to 10 seconds and 50 milliseconds.
+func WithLockWait(wait, retry time.Duration) LockOption {
+	return func(o *lockOptions) {
+		o.wait = wait
+		if retry > 0 {
+			o.retry = retry
+		}
+	}
+}
+
+// NewLockMiddleware creates a middleware serializing the processing of updates sharing a lock key,
+// e.g. updates of the same chat handled by different webhook replicas.
+func NewLockMiddleware(locker Locker, options ...LockOption) MiddlewareFunc {
+	opts := &lockOptions{
+		key:   LockKeyChat,
+		ttl:   30 * time.Second,
+		wait:  10 * time.Second,
+		retry: 50 * time.Millisecond,
+	}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, update *Update) error {
+			key := opts.key(update)
+			if key == "" {
+				return next(ctx, update)
+			}
+			release, err := acquireLock(ctx, locker, key, opts)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				_ = release(context.WithoutCancel(ctx))
+			}()
+			return next(ctx, update)
+		}
+	}
+}
+
+func acquireLock(ctx context.Context, locker Locker, key string, opts *lockOptions) (func(context.Context) error, error) {
+	deadline := time.Now().Add(opts.wait)
+	for {
+		release, ok, err := locker.TryLock(ctx, key, opts.ttl)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return release, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, ErrLockTimeout
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(opts.retry):
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package telegram
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// defaultMaintenanceMessage is the reply sent during maintenance when no message is set.
+const defaultMaintenanceMessage = "The bot is under maintenance, please try again later."
+
+// maintenance holds the maintenance mode state enforced for every update.
+type maintenance struct {
+	mu      sync.RWMutex
+	on      bool
+	message string
+	admins  []int64
+}
+
+func (m *maintenance) state() (bool, string) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.on, m.message
+}
+
+// middleware answers the updates of non-admin users with the maintenance message while the
+// maintenance mode is on, without routing them.
+func (m *maintenance) middleware() bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			on, message := m.state()
+			if !on {
+				next(ctx, b, update)
+				return
+			}
+			if user := UpdateUser(update); user != nil && slices.Contains(m.admins, user.ID) {
+				next(ctx, b, update)
+				return
+			}
+			if message != "" {
+				sendErrorText(ctx, b, update, message, false)
+			}
+		}
+	}
+}
+
+// SetMaintenance turns the maintenance mode on or off. While it is on, updates of users not
+// configured with WithMaintenanceAdmins are answered with the message instead of being routed,
+// e.g. during a deploy window. An empty message uses a default text.
+func (b *Bot) SetMaintenance(on bool, message string) {
+	if message == "" {
+		message = defaultMaintenanceMessage
+	}
+	b.maintenance.mu.Lock()
+	defer b.maintenance.mu.Unlock()
+	b.maintenance.on = on
+	b.maintenance.message = message
+}
+
+// Maintenance reports whether the maintenance mode is on.
+func (b *Bot) Maintenance() bool {
+	on, _ := b.maintenance.state()
+	return on
+}
@@ -0,0 +1,38 @@
+package telegram
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+func TestMaintenance(t *testing.T) {
+	app, err := NewApp(Config{Token: "token"}, AppendBotOptions(bot.WithNotAsyncHandlers()), WithMaintenanceAdmins(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var routed []int64
+	app.BindNoRoute(func(ctx context.Context, update *Update) error {
+		routed = append(routed, update.CallbackQuery.From.ID)
+		return nil
+	})
+	// callback queries are answered with an alert, the client call fails without a server
+	process := func(userID int64) {
+		app.API().ProcessUpdate(context.Background(), &Update{CallbackQuery: &models.CallbackQuery{From: models.User{ID: userID}}})
+	}
+
+	process(2)
+	app.SetMaintenance(true, "")
+	if !app.Maintenance() {
+		t.Fatal("maintenance mode is not on")
+	}
+	process(1)
+	process(2)
+	app.SetMaintenance(false, "")
+	process(2)
+	if len(routed) != 3 || routed[1] != 1 {
+		t.Errorf("unexpected routed updates: %v", routed)
+	}
+}
@@ -0,0 +1,121 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-telegram/bot"
+)
+
+// ErrNoMessage is returned when an update does not reference a message.
+var ErrNoMessage = errors.New("update does not reference a message")
+
+// MessageRef identifies a message within a chat.
+type MessageRef struct {
+	ChatID    int64
+	MessageID int
+}
+
+// UpdateMessageRef returns the message referenced by the update: the received message,
+// or the message carrying the keyboard for callback queries.
+func UpdateMessageRef(update *Update) (MessageRef, error) {
+	if update == nil {
+		return MessageRef{}, ErrNoMessage
+	}
+	switch {
+	case update.Message != nil:
+		return MessageRef{ChatID: update.Message.Chat.ID, MessageID: update.Message.ID}, nil
+	case update.CallbackQuery != nil && update.CallbackQuery.Message.Message != nil:
+		origin := update.CallbackQuery.Message.Message
+		return MessageRef{ChatID: origin.Chat.ID, MessageID: origin.ID}, nil
+	case update.CallbackQuery != nil && update.CallbackQuery.Message.InaccessibleMessage != nil:
+		origin := update.CallbackQuery.Message.InaccessibleMessage
+		return MessageRef{ChatID: origin.Chat.ID, MessageID: origin.MessageID}, nil
+	case update.EditedMessage != nil:
+		return MessageRef{ChatID: update.EditedMessage.Chat.ID, MessageID: update.EditedMessage.ID}, nil
+	case update.ChannelPost != nil:
+		return MessageRef{ChatID: update.ChannelPost.Chat.ID, MessageID: update.ChannelPost.ID}, nil
+	case update.BusinessMessage != nil:
+		return MessageRef{ChatID: update.BusinessMessage.Chat.ID, MessageID: update.BusinessMessage.ID}, nil
+	default:
+		return MessageRef{}, ErrNoMessage
+	}
+}
+
+// MessageError describes a failed message management operation.
+type MessageError struct {
+	Op  string     // Operation name, e.g. "pin" or "delete"
+	Ref MessageRef // Message the operation was applied to
+	Err error      // Underlying API error
+}
+
+func (e *MessageError) Error() string {
+	return fmt.Sprintf("%s message %d in chat %d: %v", e.Op, e.Ref.MessageID, e.Ref.ChatID, e.Err)
+}
+
+func (e *MessageError) Unwrap() error {
+	return e.Err
+}
+
+func messageError(op string, ref MessageRef, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &MessageError{Op: op, Ref: ref, Err: err}
+}
+
+// PinMessage pins the message in its chat, optionally without notifying chat members.
+func PinMessage(ctx context.Context, b *bot.Bot, ref MessageRef, silent bool) error {
+	_, err := b.PinChatMessage(ctx, &bot.PinChatMessageParams{
+		ChatID:              ref.ChatID,
+		MessageID:           ref.MessageID,
+		DisableNotification: silent,
+	})
+	return messageError("pin", ref, err)
+}
+
+// UnpinMessage unpins the message in its chat.
+func UnpinMessage(ctx context.Context, b *bot.Bot, ref MessageRef) error {
+	_, err := b.UnpinChatMessage(ctx, &bot.UnpinChatMessageParams{
+		ChatID:    ref.ChatID,
+		MessageID: ref.MessageID,
+	})
+	return messageError("unpin", ref, err)
+}
+
+// DeleteMessage deletes the message from its chat.
+func DeleteMessage(ctx context.Context, b *bot.Bot, ref MessageRef) error {
+	_, err := b.DeleteMessage(ctx, &bot.DeleteMessageParams{
+		ChatID:    ref.ChatID,
+		MessageID: ref.MessageID,
+	})
+	return messageError("delete", ref, err)
+}
+
+// ForwardMessage forwards the message to another chat and returns the forwarded message.
+func ForwardMessage(ctx context.Context, b *bot.Bot, ref MessageRef, toChatID int64) (MessageRef, error) {
+	msg, err := b.ForwardMessage(ctx, &bot.ForwardMessageParams{
+		ChatID:     toChatID,
+		FromChatID: ref.ChatID,
+		MessageID:  ref.MessageID,
+	})
+	if err != nil {
+		return MessageRef{}, messageError("forward", ref, err)
+	}
+	return MessageRef{ChatID: msg.Chat.ID, MessageID: msg.ID}, nil
+}
+
+// CopyMessage copies the message to another chat without a link to the original
+// and returns the copied message.
+func CopyMessage(ctx context.Context, b *bot.Bot, ref MessageRef, toChatID int64) (MessageRef, error) {
+	msg, err := b.CopyMessage(ctx, &bot.CopyMessageParams{
+		ChatID:     toChatID,
+		FromChatID: ref.ChatID,
+		MessageID:  ref.MessageID,
+	})
+	if err != nil {
+		return MessageRef{}, messageError("copy", ref, err)
+	}
+	return MessageRef{ChatID: toChatID, MessageID: msg.ID}, nil
+}
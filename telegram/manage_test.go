@@ -0,0 +1,78 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram/bot"
+)
+
+func TestMessageManagement(t *testing.T) {
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		chatID := r.FormValue("from_chat_id")
+		if chatID == "" {
+			chatID = r.FormValue("chat_id")
+		}
+		switch {
+		case chatID == "13":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: message not found"}`))
+		case method == "forwardMessage":
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":21,"date":1,"chat":{"id":2,"type":"private"}}}`))
+		case method == "copyMessage":
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":22}}`))
+		default:
+			_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+		}
+	})
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, ctx := app.API(), context.Background()
+	forward := func(ref MessageRef) error {
+		_, err := ForwardMessage(ctx, b, ref, 2)
+		return err
+	}
+	copyTo := func(ref MessageRef) error {
+		_, err := CopyMessage(ctx, b, ref, 2)
+		return err
+	}
+	tests := []struct {
+		op  string
+		run func(ref MessageRef) error
+	}{
+		{"pin", func(ref MessageRef) error { return PinMessage(ctx, b, ref, true) }},
+		{"unpin", func(ref MessageRef) error { return UnpinMessage(ctx, b, ref) }},
+		{"delete", func(ref MessageRef) error { return DeleteMessage(ctx, b, ref) }},
+		{"forward", forward},
+		{"copy", copyTo},
+	}
+	for _, tt := range tests {
+		if err := tt.run(MessageRef{ChatID: 1, MessageID: 5}); err != nil {
+			t.Errorf("%s: unexpected error %v", tt.op, err)
+		}
+		failed := MessageRef{ChatID: 13, MessageID: 5}
+		err := tt.run(failed)
+		var msgErr *MessageError
+		if !errors.As(err, &msgErr) || msgErr.Op != tt.op || msgErr.Ref != failed {
+			t.Errorf("%s: expected a MessageError, got %#v", tt.op, err)
+		}
+		if !errors.Is(err, bot.ErrorBadRequest) {
+			t.Errorf("%s: the API error is not wrapped: %v", tt.op, err)
+		}
+	}
+
+	forwarded, err := ForwardMessage(ctx, b, MessageRef{ChatID: 1, MessageID: 5}, 2)
+	if err != nil || forwarded != (MessageRef{ChatID: 2, MessageID: 21}) {
+		t.Errorf("unexpected forwarded message %+v, %v", forwarded, err)
+	}
+	copied, err := CopyMessage(ctx, b, MessageRef{ChatID: 1, MessageID: 5}, 2)
+	if err != nil || copied != (MessageRef{ChatID: 2, MessageID: 22}) {
+		t.Errorf("unexpected copied message %+v, %v", copied, err)
+	}
+}
@@ -0,0 +1,300 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// ErrMembershipNotFound is returned by a MembershipStore when the chat is unknown.
+var ErrMembershipNotFound = errors.New("chat membership not found")
+
+// ErrBotNotAdmin is returned by MembershipTracker.RequireAdmin when the bot is not an
+// administrator of the chat.
+var ErrBotNotAdmin = errors.New("bot is not an administrator of the chat")
+
+// ErrChatUnreachable is returned by MembershipTracker.SkipUnreachable for chats the bot left,
+// was removed from or was blocked in, without calling the API.
+var ErrChatUnreachable = errors.New("chat is unreachable")
+
+// ChatMembership is the status of the bot in a chat, as reported by my_chat_member updates.
+// In private chats the kicked status means the user blocked the bot.
+type ChatMembership struct {
+	ChatID    int64                 `json:"chat_id"`
+	ChatType  models.ChatType       `json:"chat_type"`
+	Title     string                `json:"title,omitempty"`
+	Status    models.ChatMemberType `json:"status"`
+	UpdatedAt time.Time             `json:"updated_at"`
+}
+
+// Active reports whether the bot is in the chat and can send to it.
+func (m *ChatMembership) Active() bool {
+	switch m.Status {
+	case models.ChatMemberTypeOwner, models.ChatMemberTypeAdministrator,
+		models.ChatMemberTypeMember, models.ChatMemberTypeRestricted:
+		return true
+	default:
+		return false
+	}
+}
+
+// Admin reports whether the bot is an administrator of the chat.
+func (m *ChatMembership) Admin() bool {
+	return m.Status == models.ChatMemberTypeOwner || m.Status == models.ChatMemberTypeAdministrator
+}
+
+// Blocked reports whether the user of a private chat blocked the bot.
+func (m *ChatMembership) Blocked() bool {
+	return m.ChatType == models.ChatTypePrivate && m.Status == models.ChatMemberTypeBanned
+}
+
+// MembershipFilter selects chats from a MembershipStore.
+type MembershipFilter struct {
+	ChatTypes       []models.ChatType // Only chats of these types, empty for all
+	AdminOnly       bool              // Only chats the bot administrates
+	IncludeInactive bool              // Whether chats the bot left, was removed from or was blocked in are included
+}
+
+// Match reports whether the membership satisfies the filter.
+func (f MembershipFilter) Match(m *ChatMembership) bool {
+	if !m.Active() && !f.IncludeInactive {
+		return false
+	}
+	if f.AdminOnly && !m.Admin() {
+		return false
+	}
+	if len(f.ChatTypes) > 0 && !slices.Contains(f.ChatTypes, m.ChatType) {
+		return false
+	}
+	return true
+}
+
+// MembershipStore persists the status of the bot in the chats it was added to.
+type MembershipStore interface {
+	// SetMembership inserts or replaces the membership of the chat.
+	SetMembership(ctx context.Context, membership *ChatMembership) error
+	// GetMembership returns the membership of the chat or ErrMembershipNotFound.
+	GetMembership(ctx context.Context, chatID int64) (*ChatMembership, error)
+	// ListMemberships returns the memberships matching the filter ordered by chat ID.
+	ListMemberships(ctx context.Context, filter MembershipFilter) ([]*ChatMembership, error)
+}
+
+// MemoryMembershipStore is an in-memory MembershipStore, suitable for tests and single instance bots.
+type MemoryMembershipStore struct {
+	mu          sync.RWMutex
+	memberships map[int64]ChatMembership
+}
+
+// NewMemoryMembershipStore creates an empty in-memory membership store.
+func NewMemoryMembershipStore() *MemoryMembershipStore {
+	return &MemoryMembershipStore{memberships: map[int64]ChatMembership{}}
+}
+
+// SetMembership implements MembershipStore.
+func (s *MemoryMembershipStore) SetMembership(ctx context.Context, membership *ChatMembership) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.memberships[membership.ChatID] = *membership
+	return nil
+}
+
+// GetMembership implements MembershipStore.
+func (s *MemoryMembershipStore) GetMembership(ctx context.Context, chatID int64) (*ChatMembership, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	membership, ok := s.memberships[chatID]
+	if !ok {
+		return nil, ErrMembershipNotFound
+	}
+	return &membership, nil
+}
+
+// ListMemberships implements MembershipStore.
+func (s *MemoryMembershipStore) ListMemberships(ctx context.Context, filter MembershipFilter) ([]*ChatMembership, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	memberships := make([]*ChatMembership, 0, len(s.memberships))
+	for _, membership := range s.memberships {
+		if filter.Match(&membership) {
+			memberships = append(memberships, &membership)
+		}
+	}
+	sort.Slice(memberships, func(i, j int) bool {
+		return memberships[i].ChatID < memberships[j].ChatID
+	})
+	return memberships, nil
+}
+
+// membershipOptions holds configuration for the MembershipTracker.
+type membershipOptions struct {
+	users UserStore // Optional user store receiving the blocked status of private chats
+}
+
+// MembershipOption defines a function type for configuring the MembershipTracker.
+type MembershipOption func(*membershipOptions)
+
+// WithMembershipUserStore also marks users who block or unblock the bot in the user store,
+// so RecipientIDs excludes them.
+func WithMembershipUserStore(users UserStore) MembershipOption {
+	return func(o *membershipOptions) {
+		o.users = users
+	}
+}
+
+// MembershipTracker keeps track of the chats the bot is in, whether it administrates them and
+// which users blocked it, by consuming my_chat_member updates. Its queries are used to skip
+// unreachable broadcast recipients and to guard moderation handlers.
+type MembershipTracker struct {
+	store MembershipStore
+	opts  membershipOptions
+}
+
+// NewMembershipTracker creates a tracker backed by the store. Its Middleware must be installed
+// and "my_chat_member" allowed for the store to be kept up to date.
+func NewMembershipTracker(store MembershipStore, options ...MembershipOption) *MembershipTracker {
+	t := &MembershipTracker{store: store}
+	for _, opt := range options {
+		opt(&t.opts)
+	}
+	return t
+}
+
+// Middleware returns a middleware recording the my_chat_member updates into the store. Store
+// failures are logged and do not interrupt update processing.
+func (t *MembershipTracker) Middleware() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, update *Update) error {
+			if update.MyChatMember != nil {
+				if err := t.record(ctx, update.MyChatMember); err != nil {
+					slog.ErrorContext(ctx, "record chat membership error",
+						slog.Int64("chat_id", update.MyChatMember.Chat.ID), slog.String("error", err.Error()))
+				}
+			}
+			return next(ctx, update)
+		}
+	}
+}
+
+func (t *MembershipTracker) record(ctx context.Context, updated *models.ChatMemberUpdated) error {
+	membership := &ChatMembership{
+		ChatID:    updated.Chat.ID,
+		ChatType:  updated.Chat.Type,
+		Title:     updated.Chat.Title,
+		Status:    updated.NewChatMember.Type,
+		UpdatedAt: time.Unix(int64(updated.Date), 0),
+	}
+	if err := t.store.SetMembership(ctx, membership); err != nil {
+		return err
+	}
+	if t.opts.users == nil || membership.ChatType != models.ChatTypePrivate {
+		return nil
+	}
+	err := t.opts.users.SetBlocked(ctx, membership.ChatID, membership.Blocked())
+	if errors.Is(err, ErrUserNotFound) {
+		return nil
+	}
+	return err
+}
+
+// Membership returns the status of the bot in the chat or ErrMembershipNotFound.
+func (t *MembershipTracker) Membership(ctx context.Context, chatID int64) (*ChatMembership, error) {
+	return t.store.GetMembership(ctx, chatID)
+}
+
+// Chats returns the chats matching the filter, e.g. the groups the bot administrates.
+func (t *MembershipTracker) Chats(ctx context.Context, filter MembershipFilter) ([]*ChatMembership, error) {
+	return t.store.ListMemberships(ctx, filter)
+}
+
+// IsBlocked reports whether the user blocked the bot. Unknown users did not.
+func (t *MembershipTracker) IsBlocked(ctx context.Context, userID int64) (bool, error) {
+	membership, err := t.store.GetMembership(ctx, userID)
+	if errors.Is(err, ErrMembershipNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return membership.Blocked(), nil
+}
+
+// IsAdmin reports whether the bot is an administrator of the chat. Chats joined before the
+// tracker was installed are unknown to the store; their status is looked up with
+// ChatMemberInfo when the context carries a client, and recorded.
+func (t *MembershipTracker) IsAdmin(ctx context.Context, chatID int64) (bool, error) {
+	membership, err := t.store.GetMembership(ctx, chatID)
+	if errors.Is(err, ErrMembershipNotFound) {
+		client := ClientFromContext(ctx)
+		if client == nil {
+			return false, nil
+		}
+		member, e := ChatMemberInfo(ctx, chatID, client.ID())
+		if e != nil {
+			return false, e
+		}
+		membership = &ChatMembership{ChatID: chatID, Status: member.Type, UpdatedAt: time.Now()}
+		if chat, e := ChatInfo(ctx, chatID); e == nil {
+			membership.ChatType, membership.Title = chat.Type, chat.Title
+		}
+		err = t.store.SetMembership(ctx, membership)
+	}
+	if err != nil {
+		return false, err
+	}
+	return membership.Admin(), nil
+}
+
+// RequireAdmin returns a middleware rejecting updates of chats the bot does not administrate
+// with ErrBotNotAdmin, e.g. for handlers that ban, restrict or delete. Updates without a chat
+// pass unchanged.
+func (t *MembershipTracker) RequireAdmin() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, update *Update) error {
+			ref, err := UpdateMessageRef(update)
+			if err != nil {
+				return next(ctx, update)
+			}
+			admin, err := t.IsAdmin(ctx, ref.ChatID)
+			if err != nil {
+				return err
+			}
+			if !admin {
+				return ErrBotNotAdmin
+			}
+			return next(ctx, update)
+		}
+	}
+}
+
+// SkipUnreachable wraps a broadcast send function so chats the bot left, was removed from or
+// was blocked in fail with ErrChatUnreachable without an API call. Sends rejected as forbidden
+// mark the chat as unreachable for the following broadcasts.
+func (t *MembershipTracker) SkipUnreachable(send func(context.Context, *bot.Bot, int64) error) func(context.Context, *bot.Bot, int64) error {
+	return func(ctx context.Context, b *bot.Bot, chatID int64) error {
+		membership, err := t.store.GetMembership(ctx, chatID)
+		if err == nil && !membership.Active() {
+			return ErrChatUnreachable
+		}
+		err = send(ctx, b, chatID)
+		if errors.Is(err, bot.ErrorForbidden) {
+			if membership == nil {
+				membership = &ChatMembership{ChatID: chatID}
+				if chatID > 0 {
+					membership.ChatType = models.ChatTypePrivate
+				}
+			}
+			membership.Status, membership.UpdatedAt = models.ChatMemberTypeBanned, time.Now()
+			if e := t.store.SetMembership(ctx, membership); e != nil {
+				slog.ErrorContext(ctx, "mark chat unreachable error", slog.Int64("chat_id", chatID), slog.String("error", e.Error()))
+			}
+		}
+		return err
+	}
+}
@@ -0,0 +1,73 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+func TestMembershipTracker(t *testing.T) {
+	ctx := context.Background()
+	users := NewMemoryUserStore()
+	_ = users.UpsertUser(ctx, &UserRecord{ID: 7})
+	tracker := NewMembershipTracker(NewMemoryMembershipStore(), WithMembershipUserStore(users))
+
+	handler := tracker.Middleware()(func(ctx context.Context, update *Update) error { return nil })
+	updated := func(chat models.Chat, status models.ChatMemberType) *Update {
+		return &Update{MyChatMember: &models.ChatMemberUpdated{
+			Chat:          chat,
+			Date:          1,
+			NewChatMember: models.ChatMember{Type: status},
+		}}
+	}
+	group := models.Chat{ID: -100, Type: models.ChatTypeSupergroup, Title: "group"}
+	private := models.Chat{ID: 7, Type: models.ChatTypePrivate}
+	_ = handler(ctx, updated(group, models.ChatMemberTypeMember))
+	_ = handler(ctx, updated(private, models.ChatMemberTypeBanned))
+
+	if admin, err := tracker.IsAdmin(ctx, -100); err != nil || admin {
+		t.Errorf("IsAdmin = %v, %v, want false", admin, err)
+	}
+	_ = handler(ctx, updated(group, models.ChatMemberTypeAdministrator))
+	if admin, err := tracker.IsAdmin(ctx, -100); err != nil || !admin {
+		t.Errorf("IsAdmin = %v, %v, want true", admin, err)
+	}
+	if blocked, err := tracker.IsBlocked(ctx, 7); err != nil || !blocked {
+		t.Errorf("IsBlocked = %v, %v, want true", blocked, err)
+	}
+	if user, _ := users.GetUser(ctx, 7); !user.Blocked {
+		t.Error("user is not marked blocked")
+	}
+	if chats, _ := tracker.Chats(ctx, MembershipFilter{AdminOnly: true}); len(chats) != 1 || chats[0].Title != "group" {
+		t.Errorf("unexpected admin chats: %v", chats)
+	}
+
+	var sent []int64
+	send := tracker.SkipUnreachable(func(ctx context.Context, b *bot.Bot, chatID int64) error {
+		sent = append(sent, chatID)
+		if chatID == 8 {
+			return fmt.Errorf("%w, bot was blocked by the user", bot.ErrorForbidden)
+		}
+		return nil
+	})
+	for _, id := range []int64{-100, 7, 8} {
+		_ = send(ctx, nil, id)
+	}
+	if err := send(ctx, nil, 8); !errors.Is(err, ErrChatUnreachable) {
+		t.Errorf("unexpected error after forbidden send: %v", err)
+	}
+	if fmt.Sprint(sent) != "[-100 8]" {
+		t.Errorf("unexpected sends: %v", sent)
+	}
+
+	_ = handler(ctx, updated(group, models.ChatMemberTypeLeft))
+	guarded := tracker.RequireAdmin()(func(ctx context.Context, update *Update) error { return nil })
+	err := guarded(ctx, &Update{Message: &models.Message{Chat: group}})
+	if !errors.Is(err, ErrBotNotAdmin) {
+		t.Errorf("unexpected RequireAdmin error: %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package telegram
 
 import (
 	"bytes"
+	"time"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
@@ -12,10 +13,10 @@ type Button = models.InlineKeyboardButton
 
 // NewButton creates an inline keyboard button with text, callback route, and data.
 // The route and data are marshaled together to form the callback data.
-func NewButton[T any](text, route string, data T) Button {
+func NewButton[T any](text, route string, data T, options ...DataOption) Button {
 	return Button{
 		Text:         text,
-		CallbackData: MarshalData(route, data),
+		CallbackData: MarshalData(route, data, options...),
 	}
 }
 
@@ -44,13 +45,68 @@ func NewStringInputFile(url string) models.InputFile {
 	}
 }
 
+// SendStrategy decides how SendMessage responds to callback queries.
+type SendStrategy int
+
+const (
+	// EditOnly edits the message carrying the keyboard and returns edit errors.
+	EditOnly SendStrategy = iota
+	// SendOnly sends a new message to the chat of the callback query.
+	SendOnly
+	// EditOrSend edits the message carrying the keyboard, and sends a new message if the
+	// original is too old, was deleted or is otherwise inaccessible.
+	EditOrSend
+)
+
+// MediaKind is the kind of the media attached to a Message.
+type MediaKind int
+
+const (
+	// MediaPhoto sends the media as a photo, with the text as caption.
+	MediaPhoto MediaKind = iota
+	// MediaVoice sends the media as a voice message, OGG encoded with OPUS, with the text as caption.
+	MediaVoice
+	// MediaVideoNote sends the media as a round video note, a square MPEG4 video up to a minute.
+	// Video notes have no caption, the text is ignored.
+	MediaVideoNote
+)
+
+// NewVoiceMessage creates a voice message from OGG/OPUS audio. Telegram computes the waveform
+// from the audio, so only the duration is sent along.
+func NewVoiceMessage(ogg []byte, duration time.Duration) *Message {
+	return &Message{
+		Media:     NewBytesInputFile("voice.ogg", ogg),
+		MediaKind: MediaVoice,
+		Duration:  duration,
+	}
+}
+
+// NewVideoNoteMessage creates a video note message from a square MPEG4 video with the given
+// diameter in pixels.
+func NewVideoNoteMessage(mp4 []byte, duration time.Duration, length int) *Message {
+	return &Message{
+		Media:     NewBytesInputFile("video_note.mp4", mp4),
+		MediaKind: MediaVideoNote,
+		Duration:  duration,
+		Length:    length,
+	}
+}
+
 // Message represents a complete message that can be sent or edited in Telegram.
 // It supports text content, media attachments, formatting, and inline keyboards.
 type Message struct {
-	Text      string                          // Message text content
-	Media     models.InputFile                // Optional media attachment (photo, document, etc.)
-	ParseMode models.ParseMode                // Text parsing mode (HTML, Markdown, etc.)
-	Button    [][]models.InlineKeyboardButton // Inline keyboard layout as rows of buttons
+	Text           string                          // Message text content
+	Media          models.InputFile                // Optional media attachment (photo, document, etc.)
+	MediaKind      MediaKind                       // Kind of the media attachment, defaults to MediaPhoto
+	Duration       time.Duration                   // Duration of voice and video note media
+	Length         int                             // Diameter of video note media in pixels
+	ParseMode      models.ParseMode                // Text parsing mode (HTML, Markdown, etc.)
+	Entities       []models.MessageEntity          // Explicit text entities, used instead of ParseMode, see TextBuilder
+	Button         [][]models.InlineKeyboardButton // Inline keyboard layout as rows of buttons
+	Keyboard       [][]models.KeyboardButton       // Reply keyboard layout, used for new messages when Button is empty
+	Strategy       SendStrategy                    // Response to callback queries, defaults to EditOnly
+	CallbackAnswer *CallbackAnswer                 // Answer to the callback query once the message is sent, nil leaves it unanswered
+	DeleteAfter    time.Duration                   // Delay before the sent message is deleted, zero keeps it, see AutoDelete
 }
 
 func (m *Message) toSendMessageParams(chatID int64) *bot.SendMessageParams {
@@ -58,26 +114,38 @@ func (m *Message) toSendMessageParams(chatID int64) *bot.SendMessageParams {
 		ChatID:    chatID,
 		Text:      m.Text,
 		ParseMode: m.ParseMode,
+		Entities:  m.Entities,
 	}
 	if len(m.Button) > 0 {
 		params.ReplyMarkup = &models.InlineKeyboardMarkup{
 			InlineKeyboard: m.Button,
 		}
+	} else if len(m.Keyboard) > 0 {
+		params.ReplyMarkup = &models.ReplyKeyboardMarkup{
+			Keyboard:       m.Keyboard,
+			ResizeKeyboard: true,
+		}
 	}
 	return params
 }
 
 func (m *Message) toSendPhotoParams(chatID int64) *bot.SendPhotoParams {
 	params := &bot.SendPhotoParams{
-		ChatID:    chatID,
-		Photo:     m.Media,
-		Caption:   m.Text,
-		ParseMode: m.ParseMode,
+		ChatID:          chatID,
+		Photo:           m.Media,
+		Caption:         m.Text,
+		ParseMode:       m.ParseMode,
+		CaptionEntities: m.Entities,
 	}
 	if len(m.Button) > 0 {
 		params.ReplyMarkup = &models.InlineKeyboardMarkup{
 			InlineKeyboard: m.Button,
 		}
+	} else if len(m.Keyboard) > 0 {
+		params.ReplyMarkup = &models.ReplyKeyboardMarkup{
+			Keyboard:       m.Keyboard,
+			ResizeKeyboard: true,
+		}
 	}
 	return params
 }
@@ -88,6 +156,7 @@ func (m *Message) toEditMessageTextParams(chatID int64, messageID int) *bot.Edit
 		MessageID: messageID,
 		Text:      m.Text,
 		ParseMode: m.ParseMode,
+		Entities:  m.Entities,
 	}
 	if len(m.Button) > 0 {
 		params.ReplyMarkup = &models.InlineKeyboardMarkup{
@@ -99,10 +168,11 @@ func (m *Message) toEditMessageTextParams(chatID int64, messageID int) *bot.Edit
 
 func (m *Message) toEditMessageCaptionParams(chatID int64, messageID int) *bot.EditMessageCaptionParams {
 	params := &bot.EditMessageCaptionParams{
-		ChatID:    chatID,
-		MessageID: messageID,
-		Caption:   m.Text,
-		ParseMode: m.ParseMode,
+		ChatID:          chatID,
+		MessageID:       messageID,
+		Caption:         m.Text,
+		ParseMode:       m.ParseMode,
+		CaptionEntities: m.Entities,
 	}
 	if len(m.Button) > 0 {
 		params.ReplyMarkup = &models.InlineKeyboardMarkup{
@@ -119,8 +189,9 @@ func (m *Message) toEditMessageMediaParams(chatID int64, messageID int) *bot.Edi
 		Media:     nil,
 	}
 	photo := &models.InputMediaPhoto{
-		Caption:   m.Text,
-		ParseMode: m.ParseMode,
+		Caption:         m.Text,
+		ParseMode:       m.ParseMode,
+		CaptionEntities: m.Entities,
 	}
 	if upload, ok := m.Media.(*models.InputFileUpload); ok {
 		photo.Media = "attach://" + upload.Filename
@@ -137,3 +208,39 @@ func (m *Message) toEditMessageMediaParams(chatID int64, messageID int) *bot.Edi
 	}
 	return params
 }
+
+func (m *Message) replyMarkup() models.ReplyMarkup {
+	if len(m.Button) > 0 {
+		return &models.InlineKeyboardMarkup{
+			InlineKeyboard: m.Button,
+		}
+	} else if len(m.Keyboard) > 0 {
+		return &models.ReplyKeyboardMarkup{
+			Keyboard:       m.Keyboard,
+			ResizeKeyboard: true,
+		}
+	}
+	return nil
+}
+
+func (m *Message) toSendVoiceParams(chatID int64) *bot.SendVoiceParams {
+	return &bot.SendVoiceParams{
+		ChatID:          chatID,
+		Voice:           m.Media,
+		Caption:         m.Text,
+		ParseMode:       m.ParseMode,
+		CaptionEntities: m.Entities,
+		Duration:        int(m.Duration / time.Second),
+		ReplyMarkup:     m.replyMarkup(),
+	}
+}
+
+func (m *Message) toSendVideoNoteParams(chatID int64) *bot.SendVideoNoteParams {
+	return &bot.SendVideoNoteParams{
+		ChatID:      chatID,
+		VideoNote:   m.Media,
+		Duration:    int(m.Duration / time.Second),
+		Length:      m.Length,
+		ReplyMarkup: m.replyMarkup(),
+	}
+}
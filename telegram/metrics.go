@@ -0,0 +1,63 @@
+package telegram
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/go-telegram/bot"
+)
+
+// defaultPollTimeout matches the HTTP client timeout used by the underlying bot client.
+const defaultPollTimeout = time.Minute
+
+// MetricsRecorder receives instrumentation events from the bot, e.g. to expose them
+// to a monitoring system. Implementations must be safe for concurrent use.
+type MetricsRecorder interface {
+	// ObserveUpdate is called after an update has been handled.
+	ObserveUpdate(updateType string, duration time.Duration, err error)
+	// ObserveAPICall is called after every request to the Bot API.
+	ObserveAPICall(method string, duration time.Duration, err error)
+	// ObserveRateLimitWait is called with the time spent waiting for a rate limiter.
+	ObserveRateLimitWait(duration time.Duration)
+	// ObserveBroadcast is called with the progress of a broadcast.
+	ObserveBroadcast(name string, sent, failed, total int)
+}
+
+func newMetricsMiddleware(recorder MetricsRecorder) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, update *Update) error {
+			start := time.Now()
+			err := next(ctx, update)
+			recorder.ObserveUpdate(UpdateType(update), time.Since(start), err)
+			return err
+		}
+	}
+}
+
+// metricsHTTPClient measures every Bot API request made by the underlying client.
+type metricsHTTPClient struct {
+	client   bot.HttpClient
+	recorder MetricsRecorder
+}
+
+func (c *metricsHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	callErr := err
+	if callErr == nil && resp.StatusCode >= http.StatusBadRequest {
+		callErr = &apiStatusError{code: resp.StatusCode}
+	}
+	// the method is the last path segment, the token in the path is never recorded
+	c.recorder.ObserveAPICall(path.Base(req.URL.Path), time.Since(start), callErr)
+	return resp, err
+}
+
+type apiStatusError struct {
+	code int
+}
+
+func (e *apiStatusError) Error() string {
+	return http.StatusText(e.code)
+}
@@ -0,0 +1,63 @@
+package telegram
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingMetrics struct {
+	mu       sync.Mutex
+	updates  []string
+	apiCalls []string
+}
+
+func (m *recordingMetrics) ObserveUpdate(updateType string, _ time.Duration, _ error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.updates = append(m.updates, updateType)
+}
+
+func (m *recordingMetrics) ObserveAPICall(method string, _ time.Duration, _ error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.apiCalls = append(m.apiCalls, method)
+}
+
+func (m *recordingMetrics) ObserveRateLimitWait(time.Duration) {}
+
+func (m *recordingMetrics) ObserveBroadcast(string, int, int, int) {}
+
+func TestMetricsWrapCustomClient(t *testing.T) {
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":1,"type":"private"}}}`))
+	})
+	transport := &countingTransport{}
+	recorder := &recordingMetrics{}
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL), WithMetrics(recorder),
+		WithHTTPClient(&http.Client{Transport: transport, Timeout: time.Minute}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.BindCommand("start", func(ctx context.Context, update *Update) error {
+		return app.SendMessage(ctx, update, &Message{Text: "hi"})
+	})
+	body := `{"update_id":1,"message":{"message_id":1,"date":0,"text":"/start","chat":{"id":1,"type":"private"},"from":{"id":1,"first_name":"a"},"entities":[{"type":"bot_command","offset":0,"length":6}]}}`
+	if err = app.HandleUpdateJSON(context.Background(), []byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	if transport.requests.Load() == 0 {
+		t.Error("the custom client was replaced")
+	}
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if strings.Join(recorder.updates, ",") != "message" {
+		t.Errorf("unexpected updates: %v", recorder.updates)
+	}
+	if !strings.Contains(strings.Join(recorder.apiCalls, ","), "sendMessage") {
+		t.Errorf("unexpected api calls: %v", recorder.apiCalls)
+	}
+}
@@ -1,6 +1,11 @@
 package telegram
 
-import "github.com/go-telegram/bot/models"
+import (
+	"slices"
+	"strings"
+
+	"github.com/go-telegram/bot/models"
+)
 
 // Update is an alias for the Telegram bot library's Update type.
 // It represents an incoming update from the Telegram Bot API.
@@ -9,15 +14,42 @@ type Update = models.Update
 // MethodExtraData holds additional routing information extracted from Telegram updates.
 // It provides convenient access to command and callback query data for request handling.
 type MethodExtraData struct {
-	Command       string // The command extracted from the update (e.g., "/start")
-	CallbackQuery string // The callback query data from inline keyboard interactions
+	Command       string           // The command extracted from the update (e.g., "/start")
+	CallbackQuery string           // The callback query data from inline keyboard interactions
+	InlineQuery   string           // Prefix of inline queries routed to the operation
+	Description   string           // Command description shown in the command menu
+	Scopes        []string         // Command menu scopes (e.g., "all_private_chats"), empty for the default scope
+	Roles         []string         // Roles allowed to run the operation, empty for everyone
+	ChatTypes     []string         // Chat types the operation is bound in (e.g., "private"), empty for all
+	Middlewares   []MiddlewareFunc // Middlewares applied only to this operation
+}
+
+// AllowsChat reports whether the update was sent in a chat type the operation is bound in.
+func (m *MethodExtraData) AllowsChat(update *Update) bool {
+	return len(m.ChatTypes) == 0 || slices.Contains(m.ChatTypes, updateChatType(update))
 }
 
 // NewMethodExtraData creates a new MethodExtraData instance from a raw string map.
 // This is typically used during request processing to extract routing information.
+// Scopes, roles and chat types are read from comma separated lists.
 func NewMethodExtraData(raw map[string]string) *MethodExtraData {
 	return &MethodExtraData{
 		Command:       raw["command"],
 		CallbackQuery: raw["callback_query"],
+		InlineQuery:   raw["inline_query"],
+		Description:   raw["description"],
+		Scopes:        splitList(raw["scopes"]),
+		Roles:         splitList(raw["roles"]),
+		ChatTypes:     splitList(raw["chat_types"]),
+	}
+}
+
+func splitList(raw string) []string {
+	var list []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
 	}
+	return list
 }
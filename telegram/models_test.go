@@ -0,0 +1,56 @@
+package telegram
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestMethodExtraDataAllowsChat(t *testing.T) {
+	extra := NewMethodExtraData(map[string]string{"command": "start", "chat_types": "private, group"})
+	if len(extra.ChatTypes) != 2 {
+		t.Fatalf("unexpected chat types: %v", extra.ChatTypes)
+	}
+	private := &Update{Message: &models.Message{Chat: models.Chat{Type: models.ChatTypePrivate}}}
+	channel := &Update{Message: &models.Message{Chat: models.Chat{Type: models.ChatTypeChannel}}}
+	if !extra.AllowsChat(private) || extra.AllowsChat(channel) {
+		t.Error("chat type scoping is not applied")
+	}
+	if !NewMethodExtraData(nil).AllowsChat(channel) {
+		t.Error("unscoped operation must allow every chat")
+	}
+}
+
+func TestBindRouteUnbound(t *testing.T) {
+	b := &Bot{}
+	handler := func(ctx context.Context, update *Update) error { return nil }
+	extra := map[string]*MethodExtraData{
+		"Start": {Command: "start"},
+		"Menu":  {CallbackQuery: "menu"},
+		"Empty": {},
+	}
+	err := b.BindRoute(RouteMap{"Start": handler, "Empty": handler}, func(op string) *MethodExtraData {
+		return extra[op]
+	}, []string{"Start", "Menu", "Empty", "Missing"})
+	if err == nil || !strings.Contains(err.Error(), "Menu, Empty, Missing") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(b.routes) != 0 {
+		t.Error("routes must not be registered when operations are unbound")
+	}
+}
+
+func TestBotStartLinkUsesSelf(t *testing.T) {
+	b := &Bot{self: newTTLCache[struct{}, *models.User](time.Hour)}
+	b.self.set(struct{}{}, &models.User{ID: 1, Username: "demo_bot"})
+	link, err := BotStartLink(context.Background(), b, "ref", 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(link, "https://t.me/demo_bot?start=ref_") {
+		t.Errorf("unexpected link %q", link)
+	}
+}
@@ -0,0 +1,87 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// OffsetStore persists the polling offset, the ID of the next update to poll, so a restarted
+// bot resumes where it stopped instead of relying on the offset confirmed to Telegram.
+type OffsetStore interface {
+	// LoadOffset returns the saved offset of the bot, zero if there is none.
+	LoadOffset(ctx context.Context, botID int64) (int64, error)
+	// SaveOffset saves the offset of the bot.
+	SaveOffset(ctx context.Context, botID int64, offset int64) error
+}
+
+// FileOffsetStore is an OffsetStore keeping the offsets of the bots in a JSON file, suitable
+// for single instance bots with a persistent disk.
+type FileOffsetStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileOffsetStore creates an offset store writing to the file at path.
+func NewFileOffsetStore(path string) *FileOffsetStore {
+	return &FileOffsetStore{path: path}
+}
+
+func (s *FileOffsetStore) read() (map[string]int64, error) {
+	offsets := map[string]int64{}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return offsets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &offsets); err != nil {
+		return nil, err
+	}
+	return offsets, nil
+}
+
+// LoadOffset implements OffsetStore.
+func (s *FileOffsetStore) LoadOffset(ctx context.Context, botID int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	offsets, err := s.read()
+	if err != nil {
+		return 0, err
+	}
+	return offsets[strconv.FormatInt(botID, 10)], nil
+}
+
+// SaveOffset implements OffsetStore. The file is replaced atomically, so a crash while saving
+// keeps the previous offset.
+func (s *FileOffsetStore) SaveOffset(ctx context.Context, botID int64, offset int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	offsets, err := s.read()
+	if err != nil {
+		return err
+	}
+	offsets[strconv.FormatInt(botID, 10)] = offset
+	data, err := json.Marshal(offsets)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
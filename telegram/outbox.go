@@ -0,0 +1,275 @@
+package telegram
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/time/rate"
+)
+
+// OutboxMessage is a text message queued in an outbox.
+type OutboxMessage struct {
+	ID        string           `json:"id"`      // Unique ID of the queued message
+	ChatID    int64            `json:"chat_id"` // Recipient chat
+	Text      string           `json:"text"`
+	ParseMode models.ParseMode `json:"parse_mode,omitempty"`
+	Button    [][]Button       `json:"button,omitempty"`
+	Attempts  int              `json:"attempts"`             // Number of failed send attempts
+	RetryAt   time.Time        `json:"retry_at"`             // Earliest time of the next attempt
+	LastError string           `json:"last_error,omitempty"` // Error of the last failed attempt
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// NewOutboxMessage creates an outbox entry with a random ID for the message.
+// Only the text, parse mode and inline keyboard of the message are queued.
+func NewOutboxMessage(chatID int64, m *Message) *OutboxMessage {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	now := time.Now()
+	return &OutboxMessage{
+		ID:        hex.EncodeToString(buf),
+		ChatID:    chatID,
+		Text:      m.Text,
+		ParseMode: m.ParseMode,
+		Button:    m.Button,
+		RetryAt:   now,
+		CreatedAt: now,
+	}
+}
+
+func (m *OutboxMessage) message() *Message {
+	return &Message{Text: m.Text, ParseMode: m.ParseMode, Button: m.Button}
+}
+
+// OutboxStore persists queued messages. Persistent implementations should let handlers enqueue
+// within their own database transaction, so a message is queued if and only if the handler's
+// changes are committed.
+type OutboxStore interface {
+	// Enqueue adds the message to the outbox.
+	Enqueue(ctx context.Context, m *OutboxMessage) error
+	// Claim atomically claims and returns up to limit pending messages whose RetryAt is not
+	// after now and which are not claimed, oldest first. Claimed messages are not returned
+	// again before now + lease, so concurrent senders do not send a message twice, while the
+	// messages of a crashed sender are sent once the lease expired.
+	Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*OutboxMessage, error)
+	// MarkSent removes the message from the pending messages.
+	MarkSent(ctx context.Context, id string) error
+	// MarkFailed records a failed attempt and releases the claim. A zero retryAt abandons the message.
+	MarkFailed(ctx context.Context, id string, lastErr string, retryAt time.Time) error
+}
+
+// MemoryOutboxStore is an in-memory OutboxStore, suitable for tests. It does not survive crashes.
+type MemoryOutboxStore struct {
+	mu       sync.Mutex
+	messages map[string]*OutboxMessage
+	claims   map[string]time.Time // End of the lease of claimed messages
+}
+
+// NewMemoryOutboxStore creates an empty in-memory outbox.
+func NewMemoryOutboxStore() *MemoryOutboxStore {
+	return &MemoryOutboxStore{messages: map[string]*OutboxMessage{}, claims: map[string]time.Time{}}
+}
+
+// Enqueue implements OutboxStore.
+func (s *MemoryOutboxStore) Enqueue(ctx context.Context, m *OutboxMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record := *m
+	s.messages[m.ID] = &record
+	return nil
+}
+
+// Claim implements OutboxStore.
+func (s *MemoryOutboxStore) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*OutboxMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []*OutboxMessage
+	for _, m := range s.messages {
+		if !m.RetryAt.IsZero() && !m.RetryAt.After(now) && !s.claims[m.ID].After(now) {
+			record := *m
+			due = append(due, &record)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].CreatedAt.Before(due[j].CreatedAt)
+	})
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	for _, m := range due {
+		s.claims[m.ID] = now.Add(lease)
+	}
+	return due, nil
+}
+
+// MarkSent implements OutboxStore.
+func (s *MemoryOutboxStore) MarkSent(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.messages, id)
+	delete(s.claims, id)
+	return nil
+}
+
+// MarkFailed implements OutboxStore.
+func (s *MemoryOutboxStore) MarkFailed(ctx context.Context, id string, lastErr string, retryAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m, ok := s.messages[id]; ok {
+		m.Attempts++
+		m.LastError = lastErr
+		m.RetryAt = retryAt
+	}
+	delete(s.claims, id)
+	return nil
+}
+
+// outboxOptions holds configuration for the OutboxSender.
+type outboxOptions struct {
+	interval    time.Duration // Polling interval of the store
+	batch       int           // Maximum number of messages fetched per poll
+	lease       time.Duration // How long fetched messages are claimed by the sender
+	maxAttempts int           // Attempts before a message is abandoned
+	backoff     time.Duration // Base delay between attempts, doubled on every failure
+	limiter     *rate.Limiter // Outbound rate limit
+	hooks       *sendHooks    // Send hooks run for every message
+}
+
+// OutboxOption defines a function type for configuring the OutboxSender.
+type OutboxOption func(*outboxOptions)
+
+// WithOutboxInterval sets how often the store is polled for due messages. Defaults to one second.
+func WithOutboxInterval(interval time.Duration) OutboxOption {
+	return func(o *outboxOptions) {
+		o.interval = interval
+	}
+}
+
+// WithOutboxBatch sets the maximum number of messages fetched per poll. Defaults to 100.
+func WithOutboxBatch(batch int) OutboxOption {
+	return func(o *outboxOptions) {
+		o.batch = batch
+	}
+}
+
+// WithOutboxLease sets how long the messages fetched by a poll are claimed by the sender, so
+// other senders skip them. It must exceed the time to send a batch at the rate limit, the
+// messages of a crashed sender are retried once it expired. Defaults to 5 minutes.
+func WithOutboxLease(lease time.Duration) OutboxOption {
+	return func(o *outboxOptions) {
+		o.lease = lease
+	}
+}
+
+// WithOutboxRetry sets the number of attempts before a message is abandoned, and the base delay
+// between attempts which doubles on every failure. Defaults to 5 attempts and 5 seconds.
+func WithOutboxRetry(maxAttempts int, backoff time.Duration) OutboxOption {
+	return func(o *outboxOptions) {
+		o.maxAttempts = maxAttempts
+		o.backoff = backoff
+	}
+}
+
+// WithOutboxRateLimiter limits the rate of outbound messages. Defaults to 25 messages per second.
+func WithOutboxRateLimiter(limiter *rate.Limiter) OutboxOption {
+	return func(o *outboxOptions) {
+		o.limiter = limiter
+	}
+}
+
+// WithOutboxSendHooks runs the send hooks of the app, see WithSendHooks, for every message
+// sent by the OutboxSender.
+func WithOutboxSendHooks(app *Bot) OutboxOption {
+	return func(o *outboxOptions) {
+		o.hooks = app.sendHooks
+	}
+}
+
+// OutboxSender flushes an OutboxStore in the background with retries and rate limiting.
+// Messages are removed from the store only after Telegram accepted them, so no message is
+// lost across crashes. Several senders may flush the same store, each claims the messages
+// it sends. Telegram has no idempotency key, so a crash between a successful send
+// and MarkSent resends that single message once.
+type OutboxSender struct {
+	store OutboxStore
+	bot   *bot.Bot
+	opts  *outboxOptions
+}
+
+// NewOutboxSender creates a sender flushing the store with the bot client.
+func NewOutboxSender(store OutboxStore, b *bot.Bot, options ...OutboxOption) *OutboxSender {
+	opts := &outboxOptions{
+		interval:    time.Second,
+		batch:       100,
+		lease:       5 * time.Minute,
+		maxAttempts: 5,
+		backoff:     5 * time.Second,
+		limiter:     rate.NewLimiter(25, 1),
+	}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return &OutboxSender{store: store, bot: b, opts: opts}
+}
+
+// Run flushes the outbox until ctx is done.
+func (s *OutboxSender) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.opts.interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Flush(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("flush outbox error", slog.String("error", err.Error()))
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush sends the messages that are currently due.
+func (s *OutboxSender) Flush(ctx context.Context) error {
+	due, err := s.store.Claim(ctx, time.Now(), s.opts.lease, s.opts.batch)
+	if err != nil {
+		return err
+	}
+	ctx = contextWithSendHooks(ctx, s.opts.hooks)
+	for _, m := range due {
+		if err = s.opts.limiter.Wait(ctx); err != nil {
+			return err
+		}
+		message := m.message()
+		sendErr := runSendHooks(ctx, message, func() (*models.Message, error) {
+			return sendBusinessMessage(ctx, s.bot, "", m.ChatID, message)
+		})
+		if sendErr == nil {
+			err = s.store.MarkSent(ctx, m.ID)
+		} else {
+			err = s.store.MarkFailed(ctx, m.ID, sendErr.Error(), s.retryAt(m, sendErr))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *OutboxSender) retryAt(m *OutboxMessage, err error) time.Time {
+	if m.Attempts+1 >= s.opts.maxAttempts || errors.Is(err, bot.ErrorForbidden) {
+		return time.Time{}
+	}
+	var tooManyRequestsError *bot.TooManyRequestsError
+	if errors.As(err, &tooManyRequestsError) {
+		return time.Now().Add(time.Duration(tooManyRequestsError.RetryAfter) * time.Second)
+	}
+	return time.Now().Add(s.opts.backoff << m.Attempts)
+}
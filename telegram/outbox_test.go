@@ -0,0 +1,53 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+)
+
+func TestOutboxSender_RetryAt(t *testing.T) {
+	s := NewOutboxSender(NewMemoryOutboxStore(), nil, WithOutboxRetry(3, time.Second))
+	m := &OutboxMessage{Attempts: 1}
+	if d := time.Until(s.retryAt(m, errors.New("fail"))); d < time.Second || d > 2*time.Second {
+		t.Errorf("unexpected backoff: %s", d)
+	}
+	if !s.retryAt(m, bot.ErrorForbidden).IsZero() {
+		t.Error("forbidden messages must be abandoned")
+	}
+	if !s.retryAt(&OutboxMessage{Attempts: 2}, errors.New("fail")).IsZero() {
+		t.Error("messages must be abandoned after max attempts")
+	}
+}
+
+func TestMemoryOutboxStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryOutboxStore()
+	m := NewOutboxMessage(1, &Message{Text: "hi"})
+	_ = store.Enqueue(ctx, m)
+	now := time.Now()
+	due, _ := store.Claim(ctx, now, time.Minute, 10)
+	if len(due) != 1 || due[0].Text != "hi" {
+		t.Fatalf("unexpected due messages: %+v", due)
+	}
+	if due, _ = store.Claim(ctx, now, time.Minute, 10); len(due) != 0 {
+		t.Error("claimed message was claimed again")
+	}
+	if due, _ = store.Claim(ctx, now.Add(2*time.Minute), time.Minute, 10); len(due) != 1 {
+		t.Error("message was not claimed again after the lease expired")
+	}
+	_ = store.MarkFailed(ctx, m.ID, "fail", now.Add(time.Hour))
+	if due, _ = store.Claim(ctx, now.Add(30*time.Minute), time.Minute, 10); len(due) != 0 {
+		t.Error("message retried before retry time")
+	}
+	if due, _ = store.Claim(ctx, now.Add(time.Hour), time.Minute, 10); len(due) != 1 {
+		t.Error("failed message was not released")
+	}
+	_ = store.MarkSent(ctx, m.ID)
+	if due, _ = store.Claim(ctx, now.Add(2*time.Hour), time.Minute, 10); len(due) != 0 {
+		t.Error("sent message is still pending")
+	}
+}
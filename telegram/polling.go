@@ -0,0 +1,341 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// defaultAPIServer is the Bot API server used without Config.APIEndpoint and WithAPIServer.
+const defaultAPIServer = "https://api.telegram.org"
+
+// pollStallMargin is added to the long polling timeout to bound a getUpdates call, so a
+// connection silently dropped by the network is abandoned instead of stalling the loop.
+const pollStallMargin = 10 * time.Second
+
+// PollingState describes the health of long polling, see WithPollingHealthHook.
+type PollingState struct {
+	Healthy   bool      // Whether the last getUpdates call succeeded
+	Failures  int       // Number of consecutive failed calls
+	LastError error     // Error of the last failed call
+	Since     time.Time // Time polling became healthy or started failing
+}
+
+// PollingHealthHook is notified after every failed getUpdates call and once polling recovers.
+type PollingHealthHook = func(ctx context.Context, state PollingState)
+
+// pollingOptions holds configuration for long polling.
+type pollingOptions struct {
+	minBackoff time.Duration       // Delay after the first failure
+	maxBackoff time.Duration       // Upper bound of the doubled delay
+	hooks      []PollingHealthHook // Hooks notified about the polling health
+	offsets    OffsetStore         // Store persisting the offset across restarts
+	fence      Locker              // Lock preventing concurrent pollers of the same bot
+}
+
+// poller runs the long polling loop of Bot.Start. Unlike the loop of the underlying client,
+// it backs off exponentially with jitter, honors retry_after, stops on an invalid token,
+// bounds every call so stalled connections are retried, and reports its health. With an
+// offset store it only saves the offset past an update once its handlers finished, so updates
+// still being processed are delivered again after a crash.
+type poller struct {
+	opts    pollingOptions
+	server  string
+	client  bot.HttpClient
+	timeout time.Duration // Long polling timeout, the call is bounded by timeout + pollStallMargin
+
+	mu      sync.Mutex
+	offset  int64              // ID of the next update to dispatch
+	pending map[int64]struct{} // IDs of dispatched updates still being processed
+	saved   int64              // Offset last saved to the offset store
+	loaded  bool               // Whether the offset was loaded from the offset store
+	state   PollingState
+}
+
+func newPoller(opts pollingOptions, server string, client bot.HttpClient, timeout time.Duration) *poller {
+	timeout = max(timeout, 2*time.Second)
+	return &poller{
+		opts:    opts,
+		server:  server,
+		client:  client,
+		timeout: timeout,
+		pending: map[int64]struct{}{},
+		state:   PollingState{Healthy: true, Since: time.Now()},
+	}
+}
+
+// run polls updates for the client until ctx is done. It only returns an error when polling
+// can not succeed anymore, i.e. the token is rejected.
+func (p *poller) run(ctx context.Context, client *bot.Bot, allowedUpdates []string) error {
+	var backoff time.Duration
+	for ctx.Err() == nil {
+		wait, err := p.poll(ctx, client, allowedUpdates)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, bot.ErrorUnauthorized) {
+			return fmt.Errorf("get updates: %w", err)
+		}
+		if err != nil {
+			backoff = p.nextBackoff(backoff)
+			var tooManyRequestsError *bot.TooManyRequestsError
+			if errors.As(err, &tooManyRequestsError) {
+				backoff = max(backoff, time.Duration(tooManyRequestsError.RetryAfter)*time.Second)
+			}
+			p.failed(ctx, err)
+			wait = backoff
+		} else {
+			backoff = 0
+		}
+		if wait > 0 {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(wait):
+			}
+		}
+	}
+	return nil
+}
+
+// poll performs a single polling cycle. With a fence it only polls while holding the lock of
+// the bot, otherwise it returns the delay before trying again.
+func (p *poller) poll(ctx context.Context, client *bot.Bot, allowedUpdates []string) (time.Duration, error) {
+	botID := client.ID()
+	if p.opts.fence != nil {
+		key := "poll:" + strconv.FormatInt(botID, 10)
+		release, ok, err := p.opts.fence.TryLock(ctx, key, p.timeout+2*pollStallMargin)
+		if err != nil {
+			return 0, fmt.Errorf("acquire polling fence: %w", err)
+		}
+		if !ok {
+			return p.opts.minBackoff, nil
+		}
+		defer func() {
+			if err := release(context.WithoutCancel(ctx)); err != nil {
+				slog.WarnContext(ctx, "release polling fence", slog.Any("error", err))
+			}
+		}()
+	}
+	if err := p.loadOffset(ctx, botID); err != nil {
+		return 0, err
+	}
+	p.saveOffset(ctx, botID)
+	updates, err := p.getUpdates(ctx, client.Token(), allowedUpdates)
+	if err != nil {
+		return 0, err
+	}
+	p.succeeded(ctx)
+	for _, update := range updates {
+		p.mu.Lock()
+		if update.ID < p.offset {
+			// already dispatched, or handled by another poller of the fence
+			p.mu.Unlock()
+			continue
+		}
+		p.offset = update.ID + 1
+		p.mu.Unlock()
+		p.dispatch(ctx, client, update)
+	}
+	return 0, nil
+}
+
+// dispatch processes the update. With an offset store, the update stays pending until its
+// handlers finished, so the saved offset does not move past it before.
+func (p *poller) dispatch(ctx context.Context, client *bot.Bot, update *models.Update) {
+	if p.opts.offsets == nil {
+		client.ProcessUpdate(ctx, update)
+		return
+	}
+	result := &updateResult{done: make(chan struct{})}
+	p.mu.Lock()
+	p.pending[update.ID] = struct{}{}
+	p.mu.Unlock()
+	client.ProcessUpdate(context.WithValue(ctx, updateResultKey{}, result), update)
+	go func() {
+		<-result.done
+		p.mu.Lock()
+		delete(p.pending, update.ID)
+		p.mu.Unlock()
+	}()
+}
+
+// committed returns the offset to save: the ID of the oldest update still being processed,
+// or the ID of the next update once all dispatched updates finished.
+func (p *poller) committed() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	offset := p.offset
+	for id := range p.pending {
+		offset = min(offset, id)
+	}
+	return offset
+}
+
+// saveOffset saves the committed offset to the offset store when it advanced.
+func (p *poller) saveOffset(ctx context.Context, botID int64) {
+	if p.opts.offsets == nil {
+		return
+	}
+	offset := p.committed()
+	p.mu.Lock()
+	saved := p.saved
+	p.mu.Unlock()
+	if offset == saved {
+		return
+	}
+	if err := p.opts.offsets.SaveOffset(ctx, botID, offset); err != nil {
+		slog.WarnContext(ctx, "save polling offset", slog.Any("error", err))
+		return
+	}
+	p.mu.Lock()
+	p.saved = offset
+	p.mu.Unlock()
+}
+
+// loadOffset resumes from the stored offset on the first poll, and on every poll with a fence
+// since another poller may have advanced it.
+func (p *poller) loadOffset(ctx context.Context, botID int64) error {
+	p.mu.Lock()
+	loaded := p.loaded
+	p.mu.Unlock()
+	if p.opts.offsets == nil || (loaded && p.opts.fence == nil) {
+		return nil
+	}
+	offset, err := p.opts.offsets.LoadOffset(ctx, botID)
+	if err != nil {
+		return fmt.Errorf("load polling offset: %w", err)
+	}
+	p.mu.Lock()
+	if !p.loaded {
+		p.saved = offset
+	}
+	p.offset = max(p.offset, offset)
+	p.loaded = true
+	p.mu.Unlock()
+	return nil
+}
+
+// nextBackoff doubles the delay within the configured bounds, with 20% jitter so several
+// instances recovering from the same outage do not retry in lockstep.
+func (p *poller) nextBackoff(backoff time.Duration) time.Duration {
+	backoff = min(max(2*backoff, p.opts.minBackoff), p.opts.maxBackoff)
+	return backoff - time.Duration(rand.Int64N(int64(backoff)/5+1))
+}
+
+func (p *poller) failed(ctx context.Context, err error) {
+	p.mu.Lock()
+	if p.state.Healthy {
+		p.state.Healthy, p.state.Since = false, time.Now()
+	}
+	p.state.Failures++
+	p.state.LastError = err
+	state := p.state
+	p.mu.Unlock()
+	slog.WarnContext(ctx, "get updates failed", slog.Int("failures", state.Failures), slog.Any("error", err))
+	p.notify(ctx, state)
+}
+
+func (p *poller) succeeded(ctx context.Context) {
+	p.mu.Lock()
+	if p.state.Healthy {
+		p.mu.Unlock()
+		return
+	}
+	failures := p.state.Failures
+	p.state = PollingState{Healthy: true, Since: time.Now()}
+	state := p.state
+	p.mu.Unlock()
+	slog.InfoContext(ctx, "get updates recovered", slog.Int("failures", failures))
+	p.notify(ctx, state)
+}
+
+func (p *poller) notify(ctx context.Context, state PollingState) {
+	for _, hook := range p.opts.hooks {
+		hook(ctx, state)
+	}
+}
+
+// current returns the current polling health.
+func (p *poller) current() PollingState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state
+}
+
+// getUpdates performs a single long polling call.
+func (p *poller) getUpdates(ctx context.Context, token string, allowedUpdates []string) ([]*models.Update, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout+pollStallMargin)
+	defer cancel()
+	p.mu.Lock()
+	offset := p.offset
+	p.mu.Unlock()
+	// ask for the next unseen update: with the offset of an update still being processed
+	// Telegram would resend it right away, only the saved offset waits for the handlers
+	params := map[string]any{
+		"offset":  offset,
+		"timeout": int((p.timeout - time.Second).Seconds()),
+	}
+	if len(allowedUpdates) > 0 {
+		params["allowed_updates"] = allowedUpdates
+	}
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.server+"/bot"+token+"/getUpdates", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		// the error of the HTTP client contains the URL, and so the token
+		return nil, redactTokenError(err, token)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		OK          bool             `json:"ok"`
+		Result      []*models.Update `json:"result"`
+		ErrorCode   int              `json:"error_code"`
+		Description string           `json:"description"`
+		Parameters  struct {
+			RetryAfter int `json:"retry_after"`
+		} `json:"parameters"`
+	}
+	if err = json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("decode updates, status %d: %w", resp.StatusCode, err)
+	}
+	if result.OK {
+		return result.Result, nil
+	}
+	switch result.ErrorCode {
+	case http.StatusUnauthorized:
+		return nil, fmt.Errorf("%w, %s", bot.ErrorUnauthorized, result.Description)
+	case http.StatusConflict:
+		return nil, fmt.Errorf("%w, %s", bot.ErrorConflict, result.Description)
+	case http.StatusTooManyRequests:
+		return nil, &bot.TooManyRequestsError{
+			Message:    fmt.Sprintf("%s, %s", bot.ErrorTooManyRequests, result.Description),
+			RetryAfter: result.Parameters.RetryAfter,
+		}
+	default:
+		return nil, fmt.Errorf("get updates: %d %s", result.ErrorCode, result.Description)
+	}
+}
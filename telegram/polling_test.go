@@ -0,0 +1,356 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+)
+
+func TestPollingRecovery(t *testing.T) {
+	var calls atomic.Int32
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/getUpdates") {
+			_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+			return
+		}
+		switch calls.Add(1) {
+		case 1, 2:
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":502,"description":"Bad Gateway"}`))
+		case 3:
+			_, _ = w.Write([]byte(`{"ok":true,"result":[{"update_id":7,"message":{"message_id":1,"chat":{"id":1},"text":"hi"}}]}`))
+		default:
+			time.Sleep(10 * time.Millisecond)
+			_, _ = w.Write([]byte(`{"ok":true,"result":[]}`))
+		}
+	})
+	var mu sync.Mutex
+	var states []PollingState
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL),
+		AppendBotOptions(bot.WithNotAsyncHandlers()),
+		WithPollingBackoff(time.Millisecond, 5*time.Millisecond),
+		WithPollingHealthHook(func(ctx context.Context, state PollingState) {
+			mu.Lock()
+			states = append(states, state)
+			mu.Unlock()
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	received := make(chan string, 1)
+	app.BindNoRoute(func(ctx context.Context, update *Update) error {
+		received <- update.Message.Text
+		return nil
+	})
+	done := make(chan error, 1)
+	go func() { done <- app.Start(ctx) }()
+
+	select {
+	case text := <-received:
+		if text != "hi" {
+			t.Errorf("unexpected update %q", text)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("polling did not recover")
+	}
+	cancel()
+	if err = <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(states) != 3 || states[1].Failures != 2 || states[1].Healthy || !states[2].Healthy {
+		t.Errorf("unexpected polling states: %+v", states)
+	}
+}
+
+func TestPollingUnauthorized(t *testing.T) {
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/getUpdates") {
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":401,"description":"Unauthorized"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = app.Start(context.Background()); !errors.Is(err, bot.ErrorUnauthorized) {
+		t.Errorf("expected unauthorized error, got %v", err)
+	}
+}
+
+func TestPollingOffsetStoreAndFence(t *testing.T) {
+	offsets := make(chan int64, 100)
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/getUpdates") {
+			_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+			return
+		}
+		var params struct {
+			Offset int64 `json:"offset"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		offsets <- params.Offset
+		if params.Offset <= 7 {
+			_, _ = w.Write([]byte(`{"ok":true,"result":[{"update_id":7,"message":{"message_id":1,"chat":{"id":1},"text":"hi"}}]}`))
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"ok":true,"result":[]}`))
+	})
+	store := NewFileOffsetStore(filepath.Join(t.TempDir(), "offsets.json"))
+	locker := NewMemoryLocker()
+	run := func() (stop func()) {
+		app, err := NewApp(Config{Token: "123:token"}, WithAPIServer(server.URL),
+			WithOffsetStore(store), WithPollingFence(locker), WithPollingBackoff(time.Millisecond, time.Millisecond))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			_ = app.Start(ctx)
+			close(done)
+		}()
+		return func() {
+			cancel()
+			<-done
+		}
+	}
+	waitOffset := func(want int64) {
+		t.Helper()
+		timeout := time.After(2 * time.Second)
+		for {
+			select {
+			case offset := <-offsets:
+				if offset == want {
+					return
+				}
+			case <-timeout:
+				t.Fatalf("offset %d was not polled", want)
+			}
+		}
+	}
+
+	stop := run()
+	waitOffset(8)
+	// the offset is saved by a later poll, once the update was processed
+	for offset, _ := store.LoadOffset(context.Background(), 123); offset != 8; offset, _ = store.LoadOffset(context.Background(), 123) {
+		waitOffset(8)
+	}
+	stop()
+	if offset, _ := store.LoadOffset(context.Background(), 123); offset != 8 {
+		t.Fatalf("expected saved offset 8, got %d", offset)
+	}
+
+	// another replica holds the fence
+	release, _, _ := locker.TryLock(context.Background(), "poll:123", time.Minute)
+	for len(offsets) > 0 {
+		<-offsets
+	}
+	stop = run()
+	defer stop()
+	time.Sleep(20 * time.Millisecond)
+	if len(offsets) != 0 {
+		t.Fatal("polled without holding the fence")
+	}
+	_ = release(context.Background())
+	if offset := <-offsets; offset != 8 {
+		t.Errorf("restarted poller resumed from offset %d", offset)
+	}
+}
+
+func TestPollingOffsetAfterProcessing(t *testing.T) {
+	offsets := make(chan int64, 100)
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/getUpdates") {
+			_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+			return
+		}
+		var params struct {
+			Offset int64 `json:"offset"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		offsets <- params.Offset
+		if params.Offset <= 7 {
+			_, _ = w.Write([]byte(`{"ok":true,"result":[` +
+				`{"update_id":7,"message":{"message_id":1,"chat":{"id":1},"text":"/slow"}},` +
+				`{"update_id":8,"message":{"message_id":2,"chat":{"id":1},"text":"/fast"}}]}`))
+			return
+		}
+		// long polling without new updates
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"ok":true,"result":[]}`))
+	})
+	store := NewFileOffsetStore(filepath.Join(t.TempDir(), "offsets.json"))
+	app, err := NewApp(Config{Token: "123:token"}, WithAPIServer(server.URL),
+		WithOffsetStore(store), WithPollingBackoff(time.Millisecond, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var slow, fast atomic.Int32
+	release := make(chan struct{})
+	app.BindCommand("slow", func(ctx context.Context, update *Update) error {
+		slow.Add(1)
+		<-release
+		return nil
+	})
+	app.BindCommand("fast", func(ctx context.Context, update *Update) error {
+		fast.Add(1)
+		return nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = app.Start(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Telegram is asked for the next updates while the slow update is processed, without
+	// redelivering it in a busy loop, but the saved offset keeps it
+	time.Sleep(100 * time.Millisecond)
+	if calls := len(offsets); calls > 10 {
+		t.Fatalf("getUpdates called %d times while the slow update was processed", calls)
+	}
+	for len(offsets) > 0 {
+		if offset := <-offsets; offset != 0 && offset != 9 {
+			t.Fatalf("unexpected offset %d", offset)
+		}
+	}
+	if offset, _ := store.LoadOffset(ctx, 123); offset != 7 {
+		t.Errorf("expected saved offset 7 while processing, got %d", offset)
+	}
+	close(release)
+	timeout := time.After(2 * time.Second)
+	for {
+		if offset, _ := store.LoadOffset(ctx, 123); offset == 9 {
+			break
+		}
+		select {
+		case <-offsets:
+		case <-timeout:
+			t.Fatal("offset 9 was not saved")
+		}
+	}
+	if offset, _ := store.LoadOffset(ctx, 123); offset != 9 {
+		t.Errorf("expected saved offset 9, got %d", offset)
+	}
+	if slow.Load() != 1 || fast.Load() != 1 {
+		t.Errorf("updates processed %d and %d times", slow.Load(), fast.Load())
+	}
+}
+
+func TestPollingSlowHandler(t *testing.T) {
+	var calls atomic.Int32
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/getUpdates") {
+			_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+			return
+		}
+		calls.Add(1)
+		var params struct {
+			Offset int64 `json:"offset"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		if params.Offset <= 7 {
+			_, _ = w.Write([]byte(`{"ok":true,"result":[{"update_id":7,"message":{"message_id":1,"chat":{"id":1},"text":"/slow"}}]}`))
+			return
+		}
+		// long polling without new updates
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"ok":true,"result":[]}`))
+	})
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var slow atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	app.BindCommand("slow", func(ctx context.Context, update *Update) error {
+		if slow.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		return nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = app.Start(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	defer close(release)
+
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("update was not dispatched")
+	}
+	calls.Store(0)
+	time.Sleep(100 * time.Millisecond)
+	if n := calls.Load(); n > 10 {
+		t.Errorf("getUpdates called %d times while the handler was running", n)
+	}
+	if n := slow.Load(); n != 1 {
+		t.Errorf("update processed %d times", n)
+	}
+}
+
+type countingTransport struct {
+	calls    atomic.Int32 // getUpdates requests
+	requests atomic.Int32 // all requests
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	if strings.HasSuffix(req.URL.Path, "/getUpdates") {
+		t.calls.Add(1)
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestPollingUsesBotClient(t *testing.T) {
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/getUpdates") {
+			_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"ok":true,"result":[]}`))
+	})
+	transport := &countingTransport{}
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL),
+		WithHTTPClient(&http.Client{Transport: transport, Timeout: time.Minute}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err = app.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if transport.calls.Load() == 0 {
+		t.Error("polling did not use the configured client")
+	}
+}
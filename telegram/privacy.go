@@ -0,0 +1,139 @@
+package telegram
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// phoneNumberPattern matches international and local phone numbers with common separators.
+var phoneNumberPattern = regexp.MustCompile(`\+?\d[\d ().-]{6,}\d`)
+
+// PrivacyPolicy controls how personal data is masked in the logs, error reports and audit
+// events produced by the package, see WithPrivacyPolicy. A nil policy masks nothing.
+type PrivacyPolicy struct {
+	MaskPhoneNumbers bool   // Replace phone numbers in texts with "[PHONE]"
+	MaskUserIDs      bool   // Replace user and chat IDs with stable pseudonyms and drop usernames
+	IDSalt           string // Secret mixed into the pseudonyms, so they can not be reversed by enumerating IDs
+	MaxTextLength    int    // Truncate texts after this many characters, 0 keeps texts whole
+}
+
+// Text masks the phone numbers of the text and truncates it to MaxTextLength characters.
+func (p *PrivacyPolicy) Text(text string) string {
+	if p == nil {
+		return text
+	}
+	if p.MaskPhoneNumbers {
+		text = phoneNumberPattern.ReplaceAllString(text, "[PHONE]")
+	}
+	if p.MaxTextLength > 0 && utf8.RuneCountInString(text) > p.MaxTextLength {
+		text = string([]rune(text)[:p.MaxTextLength]) + "…"
+	}
+	return text
+}
+
+// ID returns the pseudonym of a user or chat ID with MaskUserIDs, the ID otherwise. Pseudonyms
+// keep the sign of the ID, so group chats stay distinguishable from users, and zero stays zero.
+func (p *PrivacyPolicy) ID(id int64) int64 {
+	if p == nil || !p.MaskUserIDs || id == 0 {
+		return id
+	}
+	sum := sha256.Sum256([]byte(p.IDSalt + ":" + strconv.FormatInt(id, 10)))
+	pseudonym := int64(binary.BigEndian.Uint64(sum[:8]) >> 1)
+	if id < 0 {
+		return -pseudonym
+	}
+	return pseudonym
+}
+
+// Username returns "[USER]" for non-empty usernames with MaskUserIDs, the username otherwise.
+func (p *PrivacyPolicy) Username(username string) string {
+	if p == nil || !p.MaskUserIDs || username == "" {
+		return username
+	}
+	return "[USER]"
+}
+
+// Error returns the error with its message masked like Text. The masked error unwraps to the
+// original error, so errors.Is and errors.As keep working.
+func (p *PrivacyPolicy) Error(err error) error {
+	if p == nil || err == nil {
+		return err
+	}
+	msg := err.Error()
+	if masked := p.Text(msg); masked != msg {
+		return &redactedError{err: err, msg: masked}
+	}
+	return err
+}
+
+// AuditRedactor returns an AuditRedactor applying the policy to audit events, install it with
+// WithAuditRedactor.
+func (p *PrivacyPolicy) AuditRedactor() AuditRedactor {
+	return func(event *AuditEvent) {
+		event.UserID = p.ID(event.UserID)
+		event.ChatID = p.ID(event.ChatID)
+		event.Username = p.Username(event.Username)
+		event.Payload = p.Text(event.Payload)
+		event.Error = p.Text(event.Error)
+	}
+}
+
+// redactReport applies the policy to an error report before it is shipped.
+func (p *PrivacyPolicy) redactReport(report *ErrorReport) {
+	if p == nil {
+		return
+	}
+	report.Err = p.Error(report.Err)
+	report.UserID = p.ID(report.UserID)
+	report.ChatID = p.ID(report.ChatID)
+}
+
+type redactedError struct {
+	err error
+	msg string
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
+
+type privacyPolicyKey struct{}
+
+// privacyPolicyFrom returns the privacy policy of the app processing the update, nil without one.
+func privacyPolicyFrom(ctx context.Context) *PrivacyPolicy {
+	policy, _ := ctx.Value(privacyPolicyKey{}).(*PrivacyPolicy)
+	return policy
+}
+
+func contextWithPrivacyPolicy(ctx context.Context, policy *PrivacyPolicy) context.Context {
+	if policy == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, privacyPolicyKey{}, policy)
+}
+
+// newPrivacyMiddleware creates a middleware making the privacy policy available to the logging
+// of handlers and error handlers called while processing an update.
+func newPrivacyMiddleware(policy *PrivacyPolicy) bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			next(contextWithPrivacyPolicy(ctx, policy), b, update)
+		}
+	}
+}
+
+// withPrivacyPolicy wraps an error handler so errors raised outside the update middlewares,
+// e.g. recovered panics, are logged and reported with the privacy policy too.
+func withPrivacyPolicy(policy *PrivacyPolicy, next ErrorHandlerFunc) ErrorHandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *Update, err error) {
+		if next != nil {
+			next(contextWithPrivacyPolicy(ctx, policy), b, update, err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestPrivacyPolicy(t *testing.T) {
+	policy := &PrivacyPolicy{MaskPhoneNumbers: true, MaskUserIDs: true, IDSalt: "salt", MaxTextLength: 20}
+	if got := policy.Text("call +1 (555) 123-4567"); got != "call [PHONE]" {
+		t.Errorf("phone was not masked: %q", got)
+	}
+	if got := policy.Text("a very long message about nothing"); got != "a very long message …" {
+		t.Errorf("text was not truncated: %q", got)
+	}
+	if id := policy.ID(42); id == 42 || id <= 0 || id != policy.ID(42) {
+		t.Errorf("unexpected pseudonym %d", id)
+	}
+	if id := policy.ID(-100123); id >= 0 {
+		t.Errorf("pseudonym of a group chat lost its sign: %d", id)
+	}
+	if (*PrivacyPolicy)(nil).ID(42) != 42 {
+		t.Error("nil policy masked the ID")
+	}
+
+	event := &AuditEvent{UserID: 42, Username: "alice", Payload: "my number is 5551234567"}
+	policy.AuditRedactor()(event)
+	if event.UserID == 42 || event.Username != "[USER]" || event.Payload != "my number is [PHONE]" {
+		t.Errorf("unexpected audit event: %+v", event)
+	}
+}
+
+func TestWithPrivacyPolicy(t *testing.T) {
+	var logs bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	var report *ErrorReport
+	app, err := NewApp(Config{Token: "token"},
+		WithPrivacyPolicy(PrivacyPolicy{MaskPhoneNumbers: true, MaskUserIDs: true}),
+		WithErrorReporter(ErrorReporterFunc(func(ctx context.Context, r *ErrorReport) {
+			report = r
+		})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errInvalid := errors.New("invalid phone")
+	app.BindCommand("phone", func(ctx context.Context, update *Update) error {
+		return fmt.Errorf("%w %s", errInvalid, strings.TrimPrefix(update.Message.Text, "/phone "))
+	})
+	body := `{"update_id":1,"message":{"message_id":1,"from":{"id":7},"chat":{"id":7},"text":"/phone +4915112345678"}}`
+	_ = app.HandleUpdateJSON(context.Background(), []byte(body))
+
+	if report == nil || report.UserID == 7 || report.Err.Error() != "invalid phone [PHONE]" || !errors.Is(report.Err, errInvalid) {
+		t.Fatalf("report was not masked: %+v", report)
+	}
+	if !strings.Contains(logs.String(), "receive error") || strings.Contains(logs.String(), "4915112345678") || strings.Contains(logs.String(), "user_id=7") {
+		t.Errorf("log leaked personal data: %s", logs.String())
+	}
+}
@@ -0,0 +1,141 @@
+package telegram
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-telegram/bot"
+)
+
+// quietHoursOptions holds configuration for QuietHours.
+type quietHoursOptions struct {
+	location *time.Location // Time zone of chats without one
+	settings SettingsStore  // Optional store providing the time zone of every chat
+}
+
+// QuietHoursOption defines a function type for configuring QuietHours.
+type QuietHoursOption func(*quietHoursOptions)
+
+// WithQuietHoursLocation sets the time zone of chats without one. Defaults to UTC.
+func WithQuietHoursLocation(loc *time.Location) QuietHoursOption {
+	return func(o *quietHoursOptions) {
+		o.location = loc
+	}
+}
+
+// WithQuietHoursSettings evaluates the quiet hours in the time zone of the chat settings.
+func WithQuietHoursSettings(store SettingsStore) QuietHoursOption {
+	return func(o *quietHoursOptions) {
+		o.settings = store
+	}
+}
+
+type urgentKey struct{}
+
+// WithUrgent marks sends made with the context as urgent, so QuietHours does not defer them.
+func WithUrgent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, urgentKey{}, true)
+}
+
+// QuietHours defers non-urgent sends, e.g. broadcasts and reminders, that fall into a daily
+// quiet window of the chat's local time. Deferred messages are queued in an outbox with the
+// end of the window as RetryAt, so an OutboxSender flushing the same store delivers them once
+// sending is allowed again. Business hours are expressed as the inverse window, e.g. quiet
+// from "18:00" to "09:00".
+type QuietHours struct {
+	outbox     OutboxStore
+	start, end int // Minutes after midnight
+	opts       quietHoursOptions
+}
+
+// NewQuietHours creates a gate deferring sends between start and end, in the "15:04" format.
+// The window wraps around midnight when end is before start.
+func NewQuietHours(outbox OutboxStore, start, end string, options ...QuietHoursOption) (*QuietHours, error) {
+	startHour, startMinute, err := ParseClock(start)
+	if err != nil {
+		return nil, err
+	}
+	endHour, endMinute, err := ParseClock(end)
+	if err != nil {
+		return nil, err
+	}
+	q := &QuietHours{
+		outbox: outbox,
+		start:  startHour*60 + startMinute,
+		end:    endHour*60 + endMinute,
+		opts:   quietHoursOptions{location: time.UTC},
+	}
+	for _, opt := range options {
+		opt(&q.opts)
+	}
+	return q, nil
+}
+
+func (q *QuietHours) location(ctx context.Context, chatID int64) *time.Location {
+	if q.opts.settings == nil {
+		return q.opts.location
+	}
+	settings, err := LoadChatSettings(ctx, q.opts.settings, chatID, ChatSettings{})
+	if err != nil || settings.Timezone == "" {
+		return q.opts.location
+	}
+	return settings.Location()
+}
+
+// Quiet reports whether now falls into the quiet window of the chat, and if so the time
+// the window ends.
+func (q *QuietHours) Quiet(ctx context.Context, chatID int64, now time.Time) (bool, time.Time) {
+	loc := q.location(ctx, chatID)
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	var quiet bool
+	if q.start <= q.end {
+		quiet = minute >= q.start && minute < q.end
+	} else {
+		quiet = minute >= q.start || minute < q.end
+	}
+	if !quiet {
+		return false, time.Time{}
+	}
+	return true, NextDailyAt(now, q.end/60, q.end%60, loc)
+}
+
+// Send sends the message to the chat like SendTo, unless the chat is in its quiet window.
+// The message is then queued for the end of the window and deferred is true. Urgent sends,
+// see WithUrgent, and replies to an update of the same chat are never deferred. Like
+// NewOutboxMessage, only the text, parse mode and inline keyboard of deferred messages are queued.
+func (q *QuietHours) Send(ctx context.Context, b *bot.Bot, chatID int64, m *Message) (deferred bool, err error) {
+	if q.pass(ctx, chatID) {
+		_, err = SendTo(ctx, b, chatID, m)
+		return false, err
+	}
+	quiet, resume := q.Quiet(ctx, chatID, time.Now())
+	if !quiet {
+		_, err = SendTo(ctx, b, chatID, m)
+		return false, err
+	}
+	queued := NewOutboxMessage(chatID, m)
+	queued.RetryAt = resume
+	return true, q.outbox.Enqueue(ctx, queued)
+}
+
+// pass reports whether the send must not be deferred.
+func (q *QuietHours) pass(ctx context.Context, chatID int64) bool {
+	if urgent, _ := ctx.Value(urgentKey{}).(bool); urgent {
+		return true
+	}
+	if responder := ResponderFromContext(ctx); responder != nil {
+		ref, err := UpdateMessageRef(responder.Update())
+		return err == nil && ref.ChatID == chatID
+	}
+	return false
+}
+
+// BroadcastSender returns a send function for BroadcastMessage and BroadcastFrom that sends
+// the message through the gate, deferring it for recipients in their quiet window.
+func (q *QuietHours) BroadcastSender(m *Message) func(context.Context, *bot.Bot, int64) error {
+	return func(ctx context.Context, b *bot.Bot, chatID int64) error {
+		_, err := q.Send(ctx, b, chatID, m)
+		return err
+	}
+}
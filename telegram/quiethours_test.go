@@ -0,0 +1,73 @@
+package telegram
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestQuietHoursWindow(t *testing.T) {
+	ctx := context.Background()
+	settings := NewMemorySettingsStore()
+	_ = settings.SaveSettings(ctx, &ChatSettings{ChatID: 2, Timezone: "Asia/Tokyo"})
+	q, err := NewQuietHours(NewMemoryOutboxStore(), "22:00", "08:00", WithQuietHoursSettings(settings))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2024, 5, 1, 23, 30, 0, 0, time.UTC)
+	quiet, resume := q.Quiet(ctx, 1, now)
+	if !quiet || !resume.Equal(time.Date(2024, 5, 2, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("Quiet = %v, %s, want resume at 08:00 UTC", quiet, resume)
+	}
+	// 08:30 in Tokyo
+	if quiet, _ = q.Quiet(ctx, 2, now); quiet {
+		t.Error("chat in Tokyo is quiet")
+	}
+	if quiet, _ = q.Quiet(ctx, 1, now.Add(10*time.Hour)); quiet {
+		t.Error("chat is quiet at 09:30")
+	}
+
+	if _, err = NewQuietHours(NewMemoryOutboxStore(), "25:00", "08:00"); err == nil {
+		t.Error("expected an invalid clock error")
+	}
+}
+
+func TestQuietHoursSend(t *testing.T) {
+	var sent atomic.Int32
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		sent.Add(1)
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":1,"chat":{"id":1}}}`))
+	})
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a quiet window around the current time
+	now := time.Now().UTC()
+	outbox := NewMemoryOutboxStore()
+	q, err := NewQuietHours(outbox, now.Add(-time.Hour).Format("15:04"), now.Add(time.Hour).Format("15:04"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if err = q.BroadcastSender(&Message{Text: "news"})(ctx, app.API(), 1); err != nil {
+		t.Fatal(err)
+	}
+	deferred, err := q.Send(WithUrgent(ctx), app.API(), 1, &Message{Text: "alert"})
+	if err != nil || deferred {
+		t.Errorf("urgent send deferred = %v, %v", deferred, err)
+	}
+	if sent.Load() != 1 {
+		t.Errorf("sent %d messages, want 1", sent.Load())
+	}
+	if due, _ := outbox.Claim(ctx, time.Now(), time.Minute, 0); len(due) != 0 {
+		t.Error("deferred message is due during the quiet window")
+	}
+	if due, _ := outbox.Claim(ctx, now.Add(2*time.Hour), time.Minute, 0); len(due) != 1 || due[0].Text != "news" {
+		t.Errorf("unexpected queued messages: %v", due)
+	}
+}
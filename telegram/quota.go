@@ -0,0 +1,363 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrQuotaExceeded is wrapped by the errors of Quotas.Consume when a quota is used up and the
+// user has not enough credits.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// QuotaPeriod is the window a quota applies to.
+type QuotaPeriod int
+
+// Quota periods, starting at midnight and on the first day of the month in the quota location.
+const (
+	QuotaDaily QuotaPeriod = iota
+	QuotaMonthly
+)
+
+func (p QuotaPeriod) String() string {
+	switch p {
+	case QuotaDaily:
+		return "daily"
+	case QuotaMonthly:
+		return "monthly"
+	default:
+		return fmt.Sprintf("QuotaPeriod(%d)", int(p))
+	}
+}
+
+// window returns the name of the window containing now and the time it ends.
+func (p QuotaPeriod) window(now time.Time, loc *time.Location) (string, time.Time) {
+	local := now.In(loc)
+	if p == QuotaMonthly {
+		start := time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, loc)
+		return start.Format("2006-01"), start.AddDate(0, 1, 0)
+	}
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	return start.Format(time.DateOnly), start.AddDate(0, 0, 1)
+}
+
+// Quota limits the usage of a route per user and period.
+type Quota struct {
+	Period QuotaPeriod
+	Limit  int64
+}
+
+// QuotaKey identifies the usage of a route by a user within a window, e.g. "2024-05-01" for
+// daily or "2024-05" for monthly quotas.
+type QuotaKey struct {
+	UserID int64
+	Route  string
+	Window string
+}
+
+// QuotaStore persists quota usage and credit balances. Implementations must apply
+// ConsumeQuota and UseCredits atomically, so concurrent requests can not exceed a limit.
+type QuotaStore interface {
+	// ConsumeQuota adds n to the usage of the key unless the result exceeds limit, and returns
+	// the resulting usage and whether n was added. A negative n gives usage back. The usage may
+	// be forgotten after expiresAt.
+	ConsumeQuota(ctx context.Context, key QuotaKey, n, limit int64, expiresAt time.Time) (used int64, ok bool, err error)
+	// QuotaUsage returns the usage of the key, zero for unknown keys.
+	QuotaUsage(ctx context.Context, key QuotaKey) (int64, error)
+	// AddCredits adds n credits to the balance of the user, a negative n revokes credits, and
+	// returns the new balance. The balance never drops below zero.
+	AddCredits(ctx context.Context, userID int64, n int64) (int64, error)
+	// UseCredits subtracts n credits from the balance of the user if it suffices, and reports
+	// whether it did.
+	UseCredits(ctx context.Context, userID int64, n int64) (bool, error)
+	// Credits returns the balance of the user, zero for unknown users.
+	Credits(ctx context.Context, userID int64) (int64, error)
+}
+
+type quotaUsage struct {
+	used      int64
+	expiresAt time.Time
+}
+
+// MemoryQuotaStore is an in-memory QuotaStore, suitable for tests and single instance bots.
+type MemoryQuotaStore struct {
+	mu      sync.Mutex
+	usage   map[QuotaKey]quotaUsage
+	credits map[int64]int64
+}
+
+// NewMemoryQuotaStore creates an empty in-memory quota store.
+func NewMemoryQuotaStore() *MemoryQuotaStore {
+	return &MemoryQuotaStore{usage: map[QuotaKey]quotaUsage{}, credits: map[int64]int64{}}
+}
+
+// ConsumeQuota implements QuotaStore. Expired usage is dropped on every call.
+func (s *MemoryQuotaStore) ConsumeQuota(ctx context.Context, key QuotaKey, n, limit int64, expiresAt time.Time) (int64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for k, usage := range s.usage {
+		if !usage.expiresAt.IsZero() && !now.Before(usage.expiresAt) {
+			delete(s.usage, k)
+		}
+	}
+	usage := s.usage[key]
+	if n > 0 && usage.used+n > limit {
+		return usage.used, false, nil
+	}
+	usage.used = max(usage.used+n, 0)
+	usage.expiresAt = expiresAt
+	s.usage[key] = usage
+	return usage.used, true, nil
+}
+
+// QuotaUsage implements QuotaStore.
+func (s *MemoryQuotaStore) QuotaUsage(ctx context.Context, key QuotaKey) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	usage := s.usage[key]
+	if !usage.expiresAt.IsZero() && !time.Now().Before(usage.expiresAt) {
+		return 0, nil
+	}
+	return usage.used, nil
+}
+
+// AddCredits implements QuotaStore.
+func (s *MemoryQuotaStore) AddCredits(ctx context.Context, userID int64, n int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	balance := max(s.credits[userID]+n, 0)
+	s.credits[userID] = balance
+	return balance, nil
+}
+
+// UseCredits implements QuotaStore.
+func (s *MemoryQuotaStore) UseCredits(ctx context.Context, userID int64, n int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.credits[userID] < n {
+		return false, nil
+	}
+	s.credits[userID] -= n
+	return true, nil
+}
+
+// Credits implements QuotaStore.
+func (s *MemoryQuotaStore) Credits(ctx context.Context, userID int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.credits[userID], nil
+}
+
+// DeleteUserData implements UserDataStore, deleting the usage and credits of the user.
+func (s *MemoryQuotaStore) DeleteUserData(ctx context.Context, userID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.usage {
+		if key.UserID == userID {
+			delete(s.usage, key)
+		}
+	}
+	delete(s.credits, userID)
+	return nil
+}
+
+// QuotaError reports a used up quota.
+type QuotaError struct {
+	Route   string
+	Quota   Quota
+	ResetAt time.Time // End of the quota window
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s %s quota of %d used up until %s", e.Route, e.Quota.Period, e.Quota.Limit, e.ResetAt.Format(time.DateTime))
+}
+
+func (e *QuotaError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// quotaOptions holds configuration for Quotas.
+type quotaOptions struct {
+	location *time.Location // Time zone of the quota windows
+}
+
+// QuotaOption defines a function type for configuring Quotas.
+type QuotaOption func(*quotaOptions)
+
+// WithQuotaLocation sets the time zone in which daily and monthly windows start. Defaults to UTC.
+func WithQuotaLocation(loc *time.Location) QuotaOption {
+	return func(o *quotaOptions) {
+		o.location = loc
+	}
+}
+
+// Quotas accounts the usage of costly routes, e.g. calls to a paid AI API, against per-user
+// daily and monthly quotas. Once a quota is used up, usage is paid with credits granted by
+// admins, see GrantCredits, and rejected without them.
+type Quotas struct {
+	store QuotaStore
+	opts  quotaOptions
+}
+
+// NewQuotas creates a quota accountant backed by the store.
+func NewQuotas(store QuotaStore, options ...QuotaOption) *Quotas {
+	q := &Quotas{store: store, opts: quotaOptions{location: time.UTC}}
+	for _, opt := range options {
+		opt(&q.opts)
+	}
+	return q
+}
+
+// Consume accounts n units of the route to the user. All quotas must have room for n units,
+// otherwise n credits are used instead; without enough credits it fails with a *QuotaError
+// of the first used up quota and nothing is accounted.
+func (q *Quotas) Consume(ctx context.Context, userID int64, route string, n int64, quotas ...Quota) error {
+	now := time.Now()
+	var done []consumedQuota
+	var exceeded *QuotaError
+	for _, quota := range quotas {
+		window, resetAt := quota.Period.window(now, q.opts.location)
+		key := QuotaKey{UserID: userID, Route: route, Window: window}
+		_, ok, err := q.store.ConsumeQuota(ctx, key, n, quota.Limit, resetAt)
+		if err != nil {
+			q.giveBack(ctx, done, n)
+			return err
+		}
+		if !ok {
+			exceeded = &QuotaError{Route: route, Quota: quota, ResetAt: resetAt}
+			break
+		}
+		done = append(done, consumedQuota{key: key, expiresAt: resetAt})
+	}
+	if exceeded == nil {
+		return nil
+	}
+	q.giveBack(ctx, done, n)
+	ok, err := q.store.UseCredits(ctx, userID, n)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return exceeded
+	}
+	return nil
+}
+
+// consumedQuota is a quota consumed by Quotas.Consume, given back when another quota is used up.
+type consumedQuota struct {
+	key       QuotaKey
+	expiresAt time.Time
+}
+
+func (q *Quotas) giveBack(ctx context.Context, done []consumedQuota, n int64) {
+	for _, c := range done {
+		_, _, _ = q.store.ConsumeQuota(ctx, c.key, -n, 0, c.expiresAt)
+	}
+}
+
+// Remaining returns the units of the route left to the user in the current window of the quota,
+// not counting credits.
+func (q *Quotas) Remaining(ctx context.Context, userID int64, route string, quota Quota) (int64, error) {
+	window, _ := quota.Period.window(time.Now(), q.opts.location)
+	used, err := q.store.QuotaUsage(ctx, QuotaKey{UserID: userID, Route: route, Window: window})
+	if err != nil {
+		return 0, err
+	}
+	return max(quota.Limit-used, 0), nil
+}
+
+// GrantCredits adds n credits to the user, a negative n revokes credits, and returns the balance.
+func (q *Quotas) GrantCredits(ctx context.Context, userID int64, n int64) (int64, error) {
+	return q.store.AddCredits(ctx, userID, n)
+}
+
+// Credits returns the credit balance of the user.
+func (q *Quotas) Credits(ctx context.Context, userID int64) (int64, error) {
+	return q.store.Credits(ctx, userID)
+}
+
+// Middleware returns a middleware accounting one unit of the route to the user of every update.
+// Updates of users whose quota and credits are used up fail with a UserError wrapping the
+// *QuotaError, replied with the "quota_exceeded" translation key. Updates without a user pass.
+func (q *Quotas) Middleware(route string, quotas ...Quota) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, update *Update) error {
+			user := UpdateUser(update)
+			if user == nil {
+				return next(ctx, update)
+			}
+			err := q.Consume(ctx, user.ID, route, 1, quotas...)
+			var quotaErr *QuotaError
+			if errors.As(err, &quotaErr) {
+				resetAt := quotaErr.ResetAt.Format(time.DateTime)
+				return WrapUserError(err, "Your "+quotaErr.Quota.Period.String()+" limit is used up, try again after "+resetAt+".").
+					WithKey("quota_exceeded", quotaErr.Quota.Period.String(), resetAt)
+			}
+			if err != nil {
+				return err
+			}
+			return next(ctx, update)
+		}
+	}
+}
+
+// BindQuotaCommands registers admin commands managing credits:
+//   - "/credits <id> <n>" grants n credits to a user, a negative n revokes them
+//   - "/credits <id>" shows the balance of a user
+//
+// Without an ID, the commands apply to the author of the replied-to message.
+// Only the given admins receive a reply, updates from other users are ignored silently.
+func (b *Bot) BindQuotaCommands(quotas *Quotas, admins []int64, middlewares ...MiddlewareFunc) {
+	middlewares = append([]MiddlewareFunc{newAdminOnlyMiddleware(admins)}, middlewares...)
+	b.BindCommand("credits", func(ctx context.Context, update *Update) error {
+		id, n, err := parseCreditArgs(update)
+		if err != nil {
+			return b.SendMessage(ctx, update, &Message{Text: fmt.Sprintf("usage: /credits <id> [n]: %v", err)})
+		}
+		var balance int64
+		if n != 0 {
+			balance, err = quotas.GrantCredits(ctx, id, n)
+		} else {
+			balance, err = quotas.Credits(ctx, id)
+		}
+		if err != nil {
+			return err
+		}
+		return b.SendMessage(ctx, update, &Message{Text: fmt.Sprintf("user %d has %d credits", id, balance)})
+	}, middlewares...)
+}
+
+// parseCreditArgs parses "<command> [id] [n]", falling back to the author of the replied-to
+// message when only n or nothing is given.
+func parseCreditArgs(update *Update) (int64, int64, error) {
+	var numbers []int64
+	for _, arg := range strings.Fields(update.Message.Text)[1:] {
+		n, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		numbers = append(numbers, n)
+	}
+	if len(numbers) == 2 {
+		return numbers[0], numbers[1], nil
+	}
+	if len(numbers) > 2 {
+		return 0, 0, errors.New("too many arguments")
+	}
+	reply := update.Message.ReplyToMessage
+	if reply == nil || reply.From == nil {
+		if len(numbers) == 1 {
+			return numbers[0], 0, nil
+		}
+		return 0, 0, errors.New("missing id")
+	}
+	if len(numbers) == 1 {
+		return reply.From.ID, numbers[0], nil
+	}
+	return reply.From.ID, 0, nil
+}
@@ -0,0 +1,83 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestQuotas(t *testing.T) {
+	ctx := context.Background()
+	quotas := NewQuotas(NewMemoryQuotaStore())
+	limits := []Quota{{Period: QuotaDaily, Limit: 2}, {Period: QuotaMonthly, Limit: 3}}
+
+	for i := 0; i < 2; i++ {
+		if err := quotas.Consume(ctx, 1, "ask", 1, limits...); err != nil {
+			t.Fatalf("consume %d: %v", i, err)
+		}
+	}
+	err := quotas.Consume(ctx, 1, "ask", 1, limits...)
+	var quotaErr *QuotaError
+	if !errors.As(err, &quotaErr) || !errors.Is(err, ErrQuotaExceeded) || quotaErr.Quota.Period != QuotaDaily {
+		t.Fatalf("expected daily quota error, got %v", err)
+	}
+	// the used up daily quota must not consume the monthly quota
+	if left, _ := quotas.Remaining(ctx, 1, "ask", limits[1]); left != 1 {
+		t.Errorf("monthly remaining = %d, want 1", left)
+	}
+	if err = quotas.Consume(ctx, 2, "ask", 1, limits...); err != nil {
+		t.Errorf("quota of another user is used up: %v", err)
+	}
+
+	if balance, _ := quotas.GrantCredits(ctx, 1, 1); balance != 1 {
+		t.Errorf("balance = %d, want 1", balance)
+	}
+	if err = quotas.Consume(ctx, 1, "ask", 1, limits...); err != nil {
+		t.Errorf("credits were not used: %v", err)
+	}
+	if balance, _ := quotas.Credits(ctx, 1); balance != 0 {
+		t.Errorf("balance = %d, want 0", balance)
+	}
+
+	handler := quotas.Middleware("ask", limits...)(func(ctx context.Context, update *Update) error { return nil })
+	err = handler(ctx, &Update{Message: &models.Message{From: &models.User{ID: 1}}})
+	var userErr *UserError
+	if !errors.As(err, &userErr) || userErr.Key != "quota_exceeded" {
+		t.Errorf("expected quota user error, got %v", err)
+	}
+}
+
+func TestQuotaPeriodWindow(t *testing.T) {
+	now := time.Date(2024, 12, 31, 23, 0, 0, 0, time.UTC)
+	if window, reset := QuotaDaily.window(now, time.UTC); window != "2024-12-31" || !reset.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("daily window = %s, %s", window, reset)
+	}
+	if window, reset := QuotaMonthly.window(now, time.UTC); window != "2024-12" || !reset.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("monthly window = %s, %s", window, reset)
+	}
+}
+
+func TestAdminAPICredits(t *testing.T) {
+	app, err := NewApp(Config{Token: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	quotas := NewQuotas(NewMemoryQuotaStore())
+	handler := app.AdminAPIHandler([]string{"key"}, WithAdminAPIQuotas(quotas))
+	req := httptest.NewRequest(http.MethodPost, "/users/7/credits", strings.NewReader(`{"credits":5}`))
+	req.Header.Set("X-API-Key", "key")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"credits":5`) {
+		t.Errorf("unexpected response %d %s", rec.Code, rec.Body)
+	}
+	if balance, _ := quotas.Credits(context.Background(), 7); balance != 5 {
+		t.Errorf("balance = %d, want 5", balance)
+	}
+}
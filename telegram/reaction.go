@@ -0,0 +1,101 @@
+package telegram
+
+import (
+	"context"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// NewEmojiReaction creates a reaction with a standard emoji, e.g. "👍".
+func NewEmojiReaction(emoji string) models.ReactionType {
+	return models.ReactionType{
+		Type: models.ReactionTypeTypeEmoji,
+		ReactionTypeEmoji: &models.ReactionTypeEmoji{
+			Type:  models.ReactionTypeTypeEmoji,
+			Emoji: emoji,
+		},
+	}
+}
+
+// SetReaction sets the bot's reaction on a message. An empty emoji removes the reaction.
+func SetReaction(ctx context.Context, b *bot.Bot, chatID int64, messageID int, emoji string) error {
+	params := &bot.SetMessageReactionParams{
+		ChatID:    chatID,
+		MessageID: messageID,
+		Reaction:  []models.ReactionType{},
+	}
+	if emoji != "" {
+		params.Reaction = append(params.Reaction, NewEmojiReaction(emoji))
+	}
+	_, err := b.SetMessageReaction(ctx, params)
+	return err
+}
+
+// ReactToUpdate sets the bot's reaction on the message that triggered the update.
+func ReactToUpdate(ctx context.Context, b *bot.Bot, update *Update, emoji string) error {
+	if update == nil || update.Message == nil {
+		return nil
+	}
+	return SetReaction(ctx, b, update.Message.Chat.ID, update.Message.ID, emoji)
+}
+
+func reactionEmojis(reactions []models.ReactionType) map[string]struct{} {
+	emojis := make(map[string]struct{}, len(reactions))
+	for _, r := range reactions {
+		switch {
+		case r.ReactionTypeEmoji != nil:
+			emojis[r.ReactionTypeEmoji.Emoji] = struct{}{}
+		case r.ReactionTypeCustomEmoji != nil:
+			emojis[r.ReactionTypeCustomEmoji.CustomEmojiID] = struct{}{}
+		}
+	}
+	return emojis
+}
+
+// AddedReactions returns the emojis (or custom emoji IDs) added by a message_reaction update.
+func AddedReactions(update *Update) []string {
+	if update == nil || update.MessageReaction == nil {
+		return nil
+	}
+	old := reactionEmojis(update.MessageReaction.OldReaction)
+	var added []string
+	for emoji := range reactionEmojis(update.MessageReaction.NewReaction) {
+		if _, ok := old[emoji]; !ok {
+			added = append(added, emoji)
+		}
+	}
+	return added
+}
+
+// RemovedReactions returns the emojis (or custom emoji IDs) removed by a message_reaction update.
+func RemovedReactions(update *Update) []string {
+	if update == nil || update.MessageReaction == nil {
+		return nil
+	}
+	current := reactionEmojis(update.MessageReaction.NewReaction)
+	var removed []string
+	for emoji := range reactionEmojis(update.MessageReaction.OldReaction) {
+		if _, ok := current[emoji]; !ok {
+			removed = append(removed, emoji)
+		}
+	}
+	return removed
+}
+
+// BindMessageReaction registers a handler for reactions changed by a user on a message.
+// The bot must be an administrator in the chat and "message_reaction" must be listed
+// in the allowed updates to receive these updates.
+func (b *Bot) BindMessageReaction(handlerFunc HandlerFunc, middlewares ...MiddlewareFunc) {
+	b.BindMatch(func(update *Update) bool {
+		return update.MessageReaction != nil
+	}, handlerFunc, middlewares...)
+}
+
+// BindMessageReactionCount registers a handler for anonymous reaction count changes on a message.
+// "message_reaction_count" must be listed in the allowed updates to receive these updates.
+func (b *Bot) BindMessageReactionCount(handlerFunc HandlerFunc, middlewares ...MiddlewareFunc) {
+	b.BindMatch(func(update *Update) bool {
+		return update.MessageReactionCount != nil
+	}, handlerFunc, middlewares...)
+}
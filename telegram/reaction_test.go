@@ -0,0 +1,83 @@
+package telegram
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSetReaction(t *testing.T) {
+	var requests []map[string]string
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/setMessageReaction") {
+			requests = append(requests, map[string]string{
+				"chat_id":    r.FormValue("chat_id"),
+				"message_id": r.FormValue("message_id"),
+				"reaction":   r.FormValue("reaction"),
+			})
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.BindCommand("like", func(ctx context.Context, update *Update) error {
+		return ReactToUpdate(ctx, app.API(), update, "👍")
+	})
+	body := `{"update_id":1,"message":{"message_id":5,"date":1,"chat":{"id":7,"type":"private"},"text":"/like","entities":[{"type":"bot_command","offset":0,"length":5}]}}`
+	if err = app.HandleUpdateJSON(context.Background(), []byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	if err = SetReaction(context.Background(), app.API(), 7, 5, ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %v", requests)
+	}
+	if requests[0]["chat_id"] != "7" || requests[0]["message_id"] != "5" ||
+		requests[0]["reaction"] != `[{"type":"emoji","emoji":"👍"}]` {
+		t.Errorf("unexpected reaction request: %v", requests[0])
+	}
+	if requests[1]["reaction"] != "[]" {
+		t.Errorf("removing a reaction should send an empty list, got %q", requests[1]["reaction"])
+	}
+}
+
+func TestMessageReaction(t *testing.T) {
+	app, err := NewApp(Config{Token: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var added, removed []string
+	var counted bool
+	app.BindMessageReaction(func(ctx context.Context, update *Update) error {
+		added, removed = AddedReactions(update), RemovedReactions(update)
+		return nil
+	})
+	app.BindMessageReactionCount(func(ctx context.Context, update *Update) error {
+		counted = true
+		return nil
+	})
+	reaction := `{"update_id":1,"message_reaction":{"chat":{"id":-1,"type":"supergroup"},"message_id":3,"date":1,` +
+		`"old_reaction":[{"type":"emoji","emoji":"👍"},{"type":"custom_emoji","custom_emoji_id":"42"}],` +
+		`"new_reaction":[{"type":"emoji","emoji":"🔥"},{"type":"custom_emoji","custom_emoji_id":"42"}]}}`
+	if err = app.HandleUpdateJSON(context.Background(), []byte(reaction)); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(added, []string{"🔥"}) || !slices.Equal(removed, []string{"👍"}) {
+		t.Errorf("added = %v, removed = %v", added, removed)
+	}
+	count := `{"update_id":2,"message_reaction_count":{"chat":{"id":-1,"type":"channel"},"message_id":3,"date":1,"reactions":[{"type":{"type":"emoji","emoji":"🔥"},"total_count":2}]}}`
+	if err = app.HandleUpdateJSON(context.Background(), []byte(count)); err != nil {
+		t.Fatal(err)
+	}
+	if !counted {
+		t.Error("message_reaction_count update was not routed")
+	}
+	if AddedReactions(&Update{}) != nil || RemovedReactions(nil) != nil {
+		t.Error("expected no reactions for updates without message_reaction")
+	}
+}
@@ -0,0 +1,243 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrReferralNotFound is returned by a ReferralStore when the user was not referred.
+var ErrReferralNotFound = errors.New("referral not found")
+
+// Referral records that a user started the bot through the referral link of another user.
+type Referral struct {
+	ReferrerID int64     `json:"referrer_id"`
+	UserID     int64     `json:"user_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ReferrerCount is the number of users referred by a referrer.
+type ReferrerCount struct {
+	ReferrerID int64 `json:"referrer_id"`
+	Count      int   `json:"count"`
+}
+
+// ReferralStore persists referrals. A user is attributed to the first referrer only.
+type ReferralStore interface {
+	// AddReferral records the referral unless the user was already referred, and reports
+	// whether it was recorded.
+	AddReferral(ctx context.Context, referral *Referral) (bool, error)
+	// GetReferral returns the referral of the user or ErrReferralNotFound.
+	GetReferral(ctx context.Context, userID int64) (*Referral, error)
+	// ListReferrals returns the users referred by the referrer, oldest first.
+	ListReferrals(ctx context.Context, referrerID int64) ([]*Referral, error)
+	// TopReferrers returns up to limit referrers with the most referrals, most first.
+	TopReferrers(ctx context.Context, limit int) ([]ReferrerCount, error)
+}
+
+// MemoryReferralStore is an in-memory ReferralStore, suitable for tests and single instance bots.
+type MemoryReferralStore struct {
+	mu        sync.RWMutex
+	referrals map[int64]Referral
+}
+
+// NewMemoryReferralStore creates an empty in-memory referral store.
+func NewMemoryReferralStore() *MemoryReferralStore {
+	return &MemoryReferralStore{referrals: map[int64]Referral{}}
+}
+
+// AddReferral implements ReferralStore.
+func (s *MemoryReferralStore) AddReferral(ctx context.Context, referral *Referral) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.referrals[referral.UserID]; ok {
+		return false, nil
+	}
+	s.referrals[referral.UserID] = *referral
+	return true, nil
+}
+
+// GetReferral implements ReferralStore.
+func (s *MemoryReferralStore) GetReferral(ctx context.Context, userID int64) (*Referral, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	referral, ok := s.referrals[userID]
+	if !ok {
+		return nil, ErrReferralNotFound
+	}
+	return &referral, nil
+}
+
+// ListReferrals implements ReferralStore.
+func (s *MemoryReferralStore) ListReferrals(ctx context.Context, referrerID int64) ([]*Referral, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var referrals []*Referral
+	for _, referral := range s.referrals {
+		if referral.ReferrerID == referrerID {
+			referrals = append(referrals, &referral)
+		}
+	}
+	sort.Slice(referrals, func(i, j int) bool {
+		return referrals[i].CreatedAt.Before(referrals[j].CreatedAt)
+	})
+	return referrals, nil
+}
+
+// TopReferrers implements ReferralStore.
+func (s *MemoryReferralStore) TopReferrers(ctx context.Context, limit int) ([]ReferrerCount, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	counts := map[int64]int{}
+	for _, referral := range s.referrals {
+		counts[referral.ReferrerID]++
+	}
+	top := make([]ReferrerCount, 0, len(counts))
+	for id, count := range counts {
+		top = append(top, ReferrerCount{ReferrerID: id, Count: count})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].ReferrerID < top[j].ReferrerID
+	})
+	if limit > 0 && len(top) > limit {
+		top = top[:limit]
+	}
+	return top, nil
+}
+
+// DeleteUserData implements UserDataStore, deleting the referral of the user and the referrals
+// naming the user as referrer.
+func (s *MemoryReferralStore) DeleteUserData(ctx context.Context, userID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, referral := range s.referrals {
+		if id == userID || referral.ReferrerID == userID {
+			delete(s.referrals, id)
+		}
+	}
+	return nil
+}
+
+// ReferralHook is notified about every recorded referral, e.g. to reward the referrer.
+type ReferralHook = func(ctx context.Context, referral *Referral)
+
+// referralOptions holds configuration for the ReferralTracker.
+type referralOptions struct {
+	route string         // Deep-link route of referral links
+	hooks []ReferralHook // Hooks notified about recorded referrals
+}
+
+// ReferralOption defines a function type for configuring the ReferralTracker.
+type ReferralOption func(*referralOptions)
+
+// WithReferralRoute sets the deep-link route of referral links. Defaults to "ref".
+func WithReferralRoute(route string) ReferralOption {
+	return func(o *referralOptions) {
+		o.route = route
+	}
+}
+
+// WithReferralHook adds a hook notified about every recorded referral. Multiple calls append hooks.
+func WithReferralHook(hook ReferralHook) ReferralOption {
+	return func(o *referralOptions) {
+		o.hooks = append(o.hooks, hook)
+	}
+}
+
+// ReferralTracker attributes new users to the users whose referral link they started the bot
+// with, built on the deep-link routing of BindStart.
+type ReferralTracker struct {
+	app   *Bot
+	store ReferralStore
+	opts  referralOptions
+}
+
+// NewReferralTracker creates a referral tracker backed by the store.
+func NewReferralTracker(app *Bot, store ReferralStore, options ...ReferralOption) *ReferralTracker {
+	t := &ReferralTracker{app: app, store: store, opts: referralOptions{route: "ref"}}
+	for _, opt := range options {
+		opt(&t.opts)
+	}
+	return t
+}
+
+// Link returns the referral link of the user.
+func (t *ReferralTracker) Link(ctx context.Context, userID int64) (string, error) {
+	return BotStartLink(ctx, t.app, t.opts.route, userID)
+}
+
+// Record attributes the user to the referrer. Self-referrals and users who were already
+// referred are ignored, the result reports whether the referral was recorded.
+func (t *ReferralTracker) Record(ctx context.Context, referrerID, userID int64) (bool, error) {
+	if referrerID == 0 || referrerID == userID {
+		return false, nil
+	}
+	referral := &Referral{ReferrerID: referrerID, UserID: userID, CreatedAt: time.Now()}
+	added, err := t.store.AddReferral(ctx, referral)
+	if err != nil || !added {
+		return false, err
+	}
+	for _, hook := range t.opts.hooks {
+		hook(ctx, referral)
+	}
+	return true, nil
+}
+
+// Referrals returns the users referred by the user, oldest first.
+func (t *ReferralTracker) Referrals(ctx context.Context, userID int64) ([]*Referral, error) {
+	return t.store.ListReferrals(ctx, userID)
+}
+
+// Referrer returns the referral of the user or ErrReferralNotFound.
+func (t *ReferralTracker) Referrer(ctx context.Context, userID int64) (*Referral, error) {
+	return t.store.GetReferral(ctx, userID)
+}
+
+// TopReferrers returns up to limit referrers with the most referrals.
+func (t *ReferralTracker) TopReferrers(ctx context.Context, limit int) ([]ReferrerCount, error) {
+	return t.store.TopReferrers(ctx, limit)
+}
+
+// Bind registers the handler of referral deep links. The referral is recorded before start
+// runs, e.g. the regular welcome of the bot, nil for none. Links with an undecodable payload
+// are not recorded but still reach start.
+func (t *ReferralTracker) Bind(start HandlerFunc, middlewares ...MiddlewareFunc) {
+	t.app.BindStart(t.opts.route, func(ctx context.Context, update *Update) error {
+		if _, referrerID, err := UnmarshalStartData[int64](StartParam(update)); err == nil && update.Message.From != nil {
+			if _, err = t.Record(ctx, *referrerID, update.Message.From.ID); err != nil {
+				return err
+			}
+		}
+		if start == nil {
+			return nil
+		}
+		return start(ctx, update)
+	}, middlewares...)
+}
+
+// BindInviteCommand registers the "/invite" command replying with the referral link of the
+// user and the number of users it referred.
+func (t *ReferralTracker) BindInviteCommand(middlewares ...MiddlewareFunc) {
+	t.app.BindCommand("invite", func(ctx context.Context, update *Update) error {
+		user := UpdateUser(update)
+		if user == nil {
+			return nil
+		}
+		link, err := t.Link(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		referrals, err := t.Referrals(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		text := fmt.Sprintf("Invite friends with your link:\n%s\n\nInvited so far: %d", link, len(referrals))
+		return t.app.SendMessage(ctx, update, &Message{Text: text})
+	}, middlewares...)
+}
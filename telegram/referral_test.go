@@ -0,0 +1,63 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestReferralTracker(t *testing.T) {
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if path.Base(r.URL.Path) == "getMe" {
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"id":1,"is_bot":true,"username":"demo_bot"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":1,"chat":{"id":1}}}`))
+	})
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rewarded []int64
+	tracker := NewReferralTracker(app, NewMemoryReferralStore(), WithReferralHook(func(ctx context.Context, referral *Referral) {
+		rewarded = append(rewarded, referral.ReferrerID)
+	}))
+	started := 0
+	tracker.Bind(func(ctx context.Context, update *Update) error {
+		started++
+		return nil
+	})
+
+	ctx := context.Background()
+	link, err := tracker.Link(ctx, 10)
+	if err != nil || !strings.HasPrefix(link, "https://t.me/demo_bot?start=ref_") {
+		t.Fatalf("unexpected link %q, %v", link, err)
+	}
+	param := strings.TrimPrefix(link, "https://t.me/demo_bot?start=")
+	start := func(userID int64, param string) {
+		body := fmt.Sprintf(`{"update_id":%d,"message":{"message_id":1,"from":{"id":%d},"chat":{"id":%d},"text":"/start %s"}}`, userID, userID, userID, param)
+		if err := app.HandleUpdateJSON(ctx, []byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	start(10, param) // self-referral
+	start(11, param)
+	start(11, MarshalStartData("ref", int64(12))) // already referred
+	start(12, param)
+
+	if started != 4 {
+		t.Errorf("start handler ran %d times, want 4", started)
+	}
+	if fmt.Sprint(rewarded) != "[10 10]" {
+		t.Errorf("unexpected rewarded referrers: %v", rewarded)
+	}
+	if referral, err := tracker.Referrer(ctx, 11); err != nil || referral.ReferrerID != 10 {
+		t.Errorf("unexpected referral of 11: %v, %v", referral, err)
+	}
+	if top, _ := tracker.TopReferrers(ctx, 1); len(top) != 1 || top[0] != (ReferrerCount{ReferrerID: 10, Count: 2}) {
+		t.Errorf("unexpected top referrers: %v", top)
+	}
+}
@@ -0,0 +1,85 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-telegram/bot"
+)
+
+// ErrorReport describes a handler error or panic together with sanitized update metadata.
+// Message text, captions and callback payloads are deliberately excluded so reports
+// can be shipped to third-party services without leaking user content.
+type ErrorReport struct {
+	Err        error     // The handler error, or *PanicError for recovered panics
+	Stack      []byte    // Stack trace of the panicking goroutine, empty for regular errors
+	UpdateID   int64     // ID of the update being processed
+	UpdateType string    // Payload type of the update, see UpdateType
+	ChatID     int64     // Chat the update originates from, 0 if unknown
+	UserID     int64     // User who triggered the update, 0 if unknown
+	Time       time.Time // Time the error was reported
+}
+
+// IsPanic reports whether the error was produced by a recovered panic.
+func (r *ErrorReport) IsPanic() bool {
+	var panicErr *PanicError
+	return errors.As(r.Err, &panicErr)
+}
+
+// ErrorReporter receives every handler error and recovered panic, e.g. to forward them
+// to a centralized error tracking service.
+type ErrorReporter interface {
+	Report(ctx context.Context, report *ErrorReport)
+}
+
+// ErrorReporterFunc is a function type that implements the ErrorReporter interface.
+type ErrorReporterFunc func(ctx context.Context, report *ErrorReport)
+
+// Report implements the ErrorReporter interface by calling the function.
+func (f ErrorReporterFunc) Report(ctx context.Context, report *ErrorReport) {
+	f(ctx, report)
+}
+
+// NewErrorReport builds a sanitized report for an error raised while processing the update.
+func NewErrorReport(update *Update, err error) *ErrorReport {
+	report := &ErrorReport{
+		Err:        err,
+		UpdateType: UpdateType(update),
+		Time:       time.Now(),
+	}
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		report.Stack = panicErr.Stack
+	}
+	if update == nil {
+		return report
+	}
+	report.UpdateID = update.ID
+	switch {
+	case update.Message != nil:
+		report.ChatID = update.Message.Chat.ID
+		if update.Message.From != nil {
+			report.UserID = update.Message.From.ID
+		}
+	case update.CallbackQuery != nil:
+		report.UserID = update.CallbackQuery.From.ID
+		if origin := update.CallbackQuery.Message.Message; origin != nil {
+			report.ChatID = origin.Chat.ID
+		}
+	}
+	return report
+}
+
+// withErrorReporter wraps an error handler so every error is reported before being handled.
+// Reports are masked with the privacy policy of the context, see WithPrivacyPolicy.
+func withErrorReporter(reporter ErrorReporter, next ErrorHandlerFunc) ErrorHandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *Update, err error) {
+		report := NewErrorReport(update, err)
+		privacyPolicyFrom(ctx).redactReport(report)
+		reporter.Report(ctx, report)
+		if next != nil {
+			next(ctx, b, update, err)
+		}
+	}
+}
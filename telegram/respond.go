@@ -0,0 +1,80 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// ErrNoResponder is returned by Respond when the context was not created for update processing.
+var ErrNoResponder = errors.New("no responder in context")
+
+// Responder replies to the update being processed. It is injected into the handler context,
+// so service code can reply without access to the client and the update.
+type Responder interface {
+	// Update returns the update being processed.
+	Update() *Update
+	// Reply responds like SendMessage: callback queries edit the message carrying the keyboard,
+	// other updates are answered with a new message.
+	Reply(ctx context.Context, m *Message) error
+	// Send always sends the message as a new message to the chat of the update.
+	Send(ctx context.Context, m *Message) error
+	// AnswerCallback answers the callback query of the update, shown as an alert popup
+	// if alert is true. It does nothing for other updates.
+	AnswerCallback(ctx context.Context, text string, alert bool) error
+}
+
+type responderKey struct{}
+
+// ResponderFromContext returns the responder of the update being processed,
+// or nil if the context was not created for update processing.
+func ResponderFromContext(ctx context.Context) Responder {
+	responder, _ := ctx.Value(responderKey{}).(Responder)
+	return responder
+}
+
+// Respond replies to the update being processed with the message, see Responder.Reply.
+func Respond(ctx context.Context, m *Message) error {
+	responder := ResponderFromContext(ctx)
+	if responder == nil {
+		return ErrNoResponder
+	}
+	return responder.Reply(ctx, m)
+}
+
+func contextWithResponder(ctx context.Context, client *bot.Bot, update *Update) context.Context {
+	return context.WithValue(ctx, responderKey{}, &updateResponder{client: client, update: update})
+}
+
+// updateResponder is the Responder of an update processed by a client.
+type updateResponder struct {
+	client *bot.Bot
+	update *Update
+}
+
+func (r *updateResponder) Update() *Update {
+	return r.update
+}
+
+func (r *updateResponder) Reply(ctx context.Context, m *Message) error {
+	return SendMessage(ctx, r.client, r.update, m)
+}
+
+func (r *updateResponder) Send(ctx context.Context, m *Message) error {
+	if m == nil {
+		return nil
+	}
+	ref, err := UpdateMessageRef(r.update)
+	if err != nil {
+		return err
+	}
+	return runSendHooks(ctx, m, func() (*models.Message, error) {
+		return sendBusinessMessage(ctx, r.client, BusinessConnectionID(r.update), ref.ChatID, m)
+	})
+}
+
+func (r *updateResponder) AnswerCallback(ctx context.Context, text string, alert bool) error {
+	return AnswerCallback(ctx, r.client, r.update, WithAnswerText(text), WithShowAlert(alert))
+}
@@ -0,0 +1,28 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+func TestRespondContext(t *testing.T) {
+	if err := Respond(context.Background(), &Message{Text: "hi"}); !errors.Is(err, ErrNoResponder) {
+		t.Errorf("expected ErrNoResponder, got %v", err)
+	}
+	update := &Update{ID: 42}
+	var responder Responder
+	handler := newUpdateContextMiddleware(nil, 0)(func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		responder = ResponderFromContext(ctx)
+	})
+	handler(context.Background(), nil, update)
+	if responder == nil || responder.Update() != update {
+		t.Fatal("responder of the update is not in context")
+	}
+	if err := responder.AnswerCallback(context.Background(), "ok", false); err != nil {
+		t.Errorf("answering a non callback update must be a no-op: %v", err)
+	}
+}
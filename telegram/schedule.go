@@ -0,0 +1,221 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ParseClock parses a wall clock time in the "15:04" format into hour and minute.
+func ParseClock(clock string) (hour, minute int, err error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parse clock %q: %w", clock, err)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// NextDailyAt returns the first time after now at which the wall clock in loc shows hour and
+// minute. It counts calendar days rather than 24 hour periods, so the result stays at the same
+// local time across daylight saving transitions. A wall clock time skipped by a transition is
+// normalized by time.Date, e.g. 02:30 on a spring forward night becomes 03:30.
+func NextDailyAt(now time.Time, hour, minute int, loc *time.Location) time.Time {
+	local := now.In(loc)
+	next := time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, loc)
+	for !next.After(now) {
+		local = local.AddDate(0, 0, 1)
+		next = time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, loc)
+	}
+	return next
+}
+
+// ScheduledJob is run by the Scheduler for the chat it was scheduled for.
+type ScheduledJob = func(ctx context.Context, chatID int64) error
+
+// scheduleOptions holds configuration for the Scheduler.
+type scheduleOptions struct {
+	settings SettingsStore // Store providing the time zone of every chat
+	location *time.Location
+}
+
+// ScheduleOption defines a function type for configuring the Scheduler.
+type ScheduleOption func(*scheduleOptions)
+
+// WithScheduleSettings resolves the time zone of every chat from its ChatSettings, so daily
+// jobs follow the chat's local time. Changes of the time zone apply from the next run.
+func WithScheduleSettings(store SettingsStore) ScheduleOption {
+	return func(o *scheduleOptions) {
+		o.settings = store
+	}
+}
+
+// WithScheduleLocation sets the time zone of chats without one. Defaults to UTC.
+func WithScheduleLocation(loc *time.Location) ScheduleOption {
+	return func(o *scheduleOptions) {
+		o.location = loc
+	}
+}
+
+type scheduledJob struct {
+	chatID       int64
+	hour, minute int
+	once         bool // Run once at next instead of daily
+	job          ScheduledJob
+	next         time.Time
+}
+
+// Scheduler runs jobs at a daily wall clock time in the time zone of each chat, e.g. for
+// reminder or digest bots serving users around the world, and one-off jobs at a given time.
+// Jobs run one after another in Run.
+type Scheduler struct {
+	opts *scheduleOptions
+
+	mu     sync.Mutex
+	jobs   map[int]*scheduledJob
+	nextID int
+	wake   chan struct{}
+}
+
+// NewScheduler creates a scheduler, jobs are run once Run is called.
+func NewScheduler(options ...ScheduleOption) *Scheduler {
+	opts := &scheduleOptions{location: time.UTC}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return &Scheduler{
+		opts: opts,
+		jobs: map[int]*scheduledJob{},
+		wake: make(chan struct{}, 1),
+	}
+}
+
+// location returns the time zone of the chat.
+func (s *Scheduler) location(ctx context.Context, chatID int64) *time.Location {
+	if s.opts.settings == nil {
+		return s.opts.location
+	}
+	settings, err := LoadChatSettings(ctx, s.opts.settings, chatID, ChatSettings{})
+	if err != nil || settings.Timezone == "" {
+		return s.opts.location
+	}
+	return settings.Location()
+}
+
+// ScheduleDailyAt runs the job every day when the wall clock of the chat shows clock, in the
+// "15:04" format. The returned function cancels the job.
+func (s *Scheduler) ScheduleDailyAt(ctx context.Context, chatID int64, clock string, job ScheduledJob) (cancel func(), err error) {
+	hour, minute, err := ParseClock(clock)
+	if err != nil {
+		return nil, err
+	}
+	daily := &scheduledJob{chatID: chatID, hour: hour, minute: minute, job: job}
+	daily.next = NextDailyAt(time.Now(), hour, minute, s.location(ctx, chatID))
+	return s.add(daily), nil
+}
+
+// ScheduleAt runs the job once at the given time, right away if it is in the past.
+// The returned function cancels the job.
+func (s *Scheduler) ScheduleAt(chatID int64, at time.Time, job ScheduledJob) (cancel func()) {
+	return s.add(&scheduledJob{chatID: chatID, once: true, job: job, next: at})
+}
+
+func (s *Scheduler) add(job *scheduledJob) (cancel func()) {
+	s.mu.Lock()
+	id := s.nextID
+	s.nextID++
+	s.jobs[id] = job
+	s.mu.Unlock()
+	s.notify()
+	return func() {
+		s.mu.Lock()
+		delete(s.jobs, id)
+		s.mu.Unlock()
+		s.notify()
+	}
+}
+
+// DeleteUserData implements UserDataStore, cancelling the jobs of the private chat with the user.
+func (s *Scheduler) DeleteUserData(ctx context.Context, userID int64) error {
+	s.mu.Lock()
+	for id, job := range s.jobs {
+		if job.chatID == userID {
+			delete(s.jobs, id)
+		}
+	}
+	s.mu.Unlock()
+	s.notify()
+	return nil
+}
+
+// notify wakes Run up to recompute the next due job.
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run runs the due jobs until ctx is done. Job errors are logged.
+func (s *Scheduler) Run(ctx context.Context) error {
+	for {
+		var timer *time.Timer
+		var fire <-chan time.Time
+		if next, ok := s.nextDue(); ok {
+			timer = time.NewTimer(time.Until(next))
+			fire = timer.C
+		}
+		select {
+		case <-ctx.Done():
+		case <-s.wake:
+		case <-fire:
+			s.runDue(ctx, time.Now())
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+func (s *Scheduler) nextDue() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var next time.Time
+	for _, job := range s.jobs {
+		if next.IsZero() || job.next.Before(next) {
+			next = job.next
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// runDue runs the jobs due at now and schedules their next run.
+func (s *Scheduler) runDue(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	var due []*scheduledJob
+	for id, job := range s.jobs {
+		if !job.next.After(now) {
+			due = append(due, job)
+			if job.once {
+				delete(s.jobs, id)
+			}
+		}
+	}
+	s.mu.Unlock()
+	for _, job := range due {
+		if err := job.job(ctx, job.chatID); err != nil {
+			slog.ErrorContext(ctx, "scheduled job error", slog.Int64("chat_id", job.chatID), slog.Any("error", err))
+		}
+		if job.once {
+			continue
+		}
+		next := NextDailyAt(now, job.hour, job.minute, s.location(ctx, job.chatID))
+		s.mu.Lock()
+		job.next = next
+		s.mu.Unlock()
+	}
+}
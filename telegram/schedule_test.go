@@ -0,0 +1,76 @@
+package telegram
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNextDailyAt(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database unavailable")
+	}
+	cases := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"later today", time.Date(2024, 3, 1, 8, 0, 0, 0, berlin), time.Date(2024, 3, 1, 9, 0, 0, 0, berlin)},
+		{"tomorrow", time.Date(2024, 3, 1, 9, 0, 0, 0, berlin), time.Date(2024, 3, 2, 9, 0, 0, 0, berlin)},
+		// the night of 31 March 2024 is one hour shorter, the run stays at 09:00 local time
+		{"spring forward", time.Date(2024, 3, 30, 10, 0, 0, 0, berlin), time.Date(2024, 3, 31, 9, 0, 0, 0, berlin)},
+		{"fall back", time.Date(2024, 10, 26, 10, 0, 0, 0, berlin), time.Date(2024, 10, 27, 9, 0, 0, 0, berlin)},
+	}
+	for _, c := range cases {
+		got := NextDailyAt(c.now, 9, 0, berlin)
+		if !got.Equal(c.want) || got.In(berlin).Hour() != 9 {
+			t.Errorf("%s: got %s, want %s", c.name, got, c.want)
+		}
+	}
+	if d := NextDailyAt(time.Date(2024, 3, 30, 10, 0, 0, 0, berlin), 9, 0, berlin).Sub(time.Date(2024, 3, 30, 9, 0, 0, 0, berlin)); d != 23*time.Hour {
+		t.Errorf("expected a 23 hour day, got %s", d)
+	}
+}
+
+func TestSchedulerTimezone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("time zone database unavailable")
+	}
+	ctx := context.Background()
+	store := NewMemorySettingsStore()
+	_ = store.SaveSettings(ctx, &ChatSettings{ChatID: 1, Timezone: "Asia/Tokyo"})
+	s := NewScheduler(WithScheduleSettings(store))
+
+	var ran []int64
+	job := func(ctx context.Context, chatID int64) error {
+		ran = append(ran, chatID)
+		return nil
+	}
+	if _, err = s.ScheduleDailyAt(ctx, 1, "9:00", job); err != nil {
+		t.Fatal(err)
+	}
+	cancel, err := s.ScheduleDailyAt(ctx, 2, "09:00", job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.ScheduleDailyAt(ctx, 3, "25:00", job); err == nil {
+		t.Error("expected an invalid clock error")
+	}
+	for _, daily := range s.jobs {
+		want := time.UTC
+		if daily.chatID == 1 {
+			want = tokyo
+		}
+		if daily.next.In(want).Hour() != 9 {
+			t.Errorf("chat %d scheduled at %s", daily.chatID, daily.next.In(want))
+		}
+	}
+
+	cancel()
+	s.runDue(ctx, time.Now().Add(48*time.Hour))
+	if len(ran) != 1 || ran[0] != 1 {
+		t.Errorf("unexpected runs: %v", ran)
+	}
+}
@@ -3,68 +3,181 @@ package telegram
 import (
 	"context"
 	"errors"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
 	"golang.org/x/time/rate"
 )
 
 // SendMessage sends or edits a message based on the update type and content.
 // For callback queries, it edits the original message. For regular messages, it sends a new message.
-// The function automatically chooses between text and photo messages based on media presence.
+// Message.Strategy controls whether callback queries are answered with a new message instead,
+// or fall back to one when the original message can not be edited anymore.
+// Edits that would not change the message are skipped, see MessageUnchanged.
+// Once sent, callback queries are answered with Message.CallbackAnswer if set.
+// For business messages, the reply is sent on behalf of the connected business account.
+// The function automatically chooses between text, photo, voice and video note messages based on
+// the media, voice and video note messages are always sent as new messages.
 func SendMessage(ctx context.Context, b *bot.Bot, update *Update, m *Message) error {
 	if m == nil || update == nil {
 		return nil
 	}
+	err := runSendHooks(ctx, m, func() (*models.Message, error) {
+		return sendMessage(ctx, b, update, m)
+	})
+	if err != nil || m.CallbackAnswer == nil {
+		return err
+	}
+	return answerCallback(ctx, b, update, m.CallbackAnswer)
+}
+
+func sendMessage(ctx context.Context, b *bot.Bot, update *Update, m *Message) (*models.Message, error) {
 	if update.CallbackQuery != nil {
-		origin := update.CallbackQuery.Message.Message
-		if len(origin.Photo) == 0 {
-			param := m.toEditMessageTextParams(origin.Chat.ID, origin.ID)
-			_, err := b.EditMessageText(ctx, param)
-			return err
-		} else {
-			if m.Media == nil {
-				param := m.toEditMessageCaptionParams(origin.Chat.ID, origin.ID)
-				_, err := b.EditMessageCaption(ctx, param)
-				return err
-			} else {
-				param := m.toEditMessageMediaParams(origin.Chat.ID, origin.ID)
-				_, err := b.EditMessageMedia(ctx, param)
-				return err
+		if m.Strategy != SendOnly && m.MediaKind == MediaPhoto {
+			msg, err := editCallbackMessage(ctx, b, update.CallbackQuery, m)
+			if m.Strategy == EditOnly {
+				return msg, err
+			}
+			if !editFailed(err) {
+				return msg, err
 			}
 		}
+		ref, err := UpdateMessageRef(update)
+		if err != nil {
+			return nil, err
+		}
+		return sendBusinessMessage(ctx, b, BusinessConnectionID(update), ref.ChatID, m)
+	}
+	if update.BusinessMessage != nil {
+		return sendBusinessMessage(ctx, b, update.BusinessMessage.BusinessConnectionID, update.BusinessMessage.Chat.ID, m)
 	}
 	if update.Message != nil {
+		return sendBusinessMessage(ctx, b, "", update.Message.Chat.ID, m)
+	}
+	return nil, nil
+}
+
+// SendTo sends the message to the chat without an update to reply to, e.g. from a scheduler,
+// and returns a reference to the sent message for later EditTo or DeleteTo calls.
+// Send hooks run only when the context carries them, see WithSendHooks and Bot.SendTo.
+func SendTo(ctx context.Context, b *bot.Bot, chatID int64, m *Message) (MessageRef, error) {
+	var msg *models.Message
+	err := runSendHooks(ctx, m, func() (*models.Message, error) {
+		sent, err := sendBusinessMessage(ctx, b, "", chatID, m)
+		msg = sent
+		return sent, err
+	})
+	if err != nil {
+		return MessageRef{}, err
+	}
+	return MessageRef{ChatID: msg.Chat.ID, MessageID: msg.ID}, nil
+}
+
+// EditTo replaces the content of a previously sent message identified by chat and message ID.
+// Messages with media replace the media, otherwise the text is edited, or the caption if the
+// message has no text. Edits that would not change the message are not reported as errors.
+// Send hooks run only when the context carries them, see WithSendHooks and Bot.EditTo.
+func EditTo(ctx context.Context, b *bot.Bot, chatID int64, messageID int, m *Message) error {
+	ref := MessageRef{ChatID: chatID, MessageID: messageID}
+	err := runSendHooks(ctx, m, func() (*models.Message, error) {
+		msg, err := editMessage(ctx, b, chatID, messageID, m)
+		if err != nil && strings.Contains(err.Error(), "message is not modified") {
+			return msg, nil
+		}
+		return msg, err
+	})
+	return messageError("edit", ref, err)
+}
+
+func editMessage(ctx context.Context, b *bot.Bot, chatID int64, messageID int, m *Message) (*models.Message, error) {
+	if m.Media != nil {
+		return b.EditMessageMedia(ctx, m.toEditMessageMediaParams(chatID, messageID))
+	}
+	msg, err := b.EditMessageText(ctx, m.toEditMessageTextParams(chatID, messageID))
+	if err != nil && strings.Contains(err.Error(), "no text in the message") {
+		return b.EditMessageCaption(ctx, m.toEditMessageCaptionParams(chatID, messageID))
+	}
+	return msg, err
+}
+
+// DeleteTo deletes a previously sent message identified by chat and message ID.
+func DeleteTo(ctx context.Context, b *bot.Bot, chatID int64, messageID int) error {
+	return DeleteMessage(ctx, b, MessageRef{ChatID: chatID, MessageID: messageID})
+}
+
+// ErrMessageInaccessible is returned when the message of a callback query can no longer be
+// edited and the EditOnly strategy does not allow sending a new message instead.
+var ErrMessageInaccessible = errors.New("callback message is inaccessible")
+
+func editCallbackMessage(ctx context.Context, b *bot.Bot, query *models.CallbackQuery, m *Message) (*models.Message, error) {
+	origin := query.Message.Message
+	if origin == nil {
+		if query.Message.InaccessibleMessage != nil {
+			return nil, ErrMessageInaccessible
+		}
+		return nil, nil
+	}
+	if MessageUnchanged(origin, m) {
+		return origin, nil
+	}
+	if len(origin.Photo) == 0 {
+		param := m.toEditMessageTextParams(origin.Chat.ID, origin.ID)
+		param.BusinessConnectionID = origin.BusinessConnectionID
+		return b.EditMessageText(ctx, param)
+	} else {
 		if m.Media == nil {
-			param := m.toSendMessageParams(update.Message.Chat.ID)
-			_, err := b.SendMessage(ctx, param)
-			return err
+			param := m.toEditMessageCaptionParams(origin.Chat.ID, origin.ID)
+			param.BusinessConnectionID = origin.BusinessConnectionID
+			return b.EditMessageCaption(ctx, param)
 		} else {
-			param := m.toSendPhotoParams(update.Message.Chat.ID)
-			_, err := b.SendPhoto(ctx, param)
-			return err
+			param := m.toEditMessageMediaParams(origin.Chat.ID, origin.ID)
+			param.BusinessConnectionID = origin.BusinessConnectionID
+			return b.EditMessageMedia(ctx, param)
 		}
 	}
-	return nil
+}
+
+// editFailed reports whether the edit failed because the message can not be edited anymore,
+// e.g. it is too old or was deleted. Unchanged content is not a failure.
+func editFailed(err error) bool {
+	if errors.Is(err, ErrMessageInaccessible) {
+		return true
+	}
+	return errors.Is(err, bot.ErrorBadRequest) && !strings.Contains(err.Error(), "message is not modified")
 }
 
 // SendErrorMessage sends an error message to the user based on the update type.
 // For regular messages, it sends a new message with the error text.
 // For callback queries, it shows the error in a popup using AnswerCallbackQuery.
+// Only the message of a UserError is shown, other errors are replaced with a generic text,
+// see NewUserErrorFormatter.
 func SendErrorMessage(ctx context.Context, b *bot.Bot, update *Update, err error) {
 	if err == nil {
 		return
 	}
+	if text := NewUserErrorFormatter().FormatError(ctx, update, err); text != "" {
+		sendErrorText(ctx, b, update, text, errorAlert(err))
+	}
+}
+
+func sendErrorText(ctx context.Context, b *bot.Bot, update *Update, text string, alert bool) {
+	if update == nil {
+		return
+	}
 	if update.Message != nil {
 		_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID: update.Message.Chat.ID,
-			Text:   err.Error(),
+			Text:   text,
 		})
 	}
 	if update.CallbackQuery != nil {
 		_, _ = b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
 			CallbackQueryID: update.CallbackQuery.ID,
-			Text:            err.Error(),
+			Text:            text,
+			ShowAlert:       alert,
 		})
 	}
 }
@@ -73,6 +186,16 @@ func SendErrorMessage(ctx context.Context, b *bot.Bot, update *Update, err error
 type broadcastOptions struct {
 	progress            func(int, int, int) // Progress callback: (current, errors, total)
 	terminalOnSendError bool                // Whether to stop on first send error
+	auditor             *Auditor            // Optional auditor recording the broadcast
+	auditName           string              // Name of the broadcast in audit events
+	metrics             MetricsRecorder     // Optional recorder receiving progress and rate limit waits
+	metricsName         string              // Name of the broadcast in metrics
+	concurrency         int                 // Number of workers sending in parallel
+	chatLimit           rate.Limit          // Rate limit per chat, 0 disables it
+	chatBurst           int                 // Burst of the per-chat rate limit
+	chatID              func(any) int64     // Resolves the chat of a recipient for the per-chat limit
+	report              *BroadcastReport    // Optional report receiving the result of every recipient
+	retries             int                 // Retries of sends failing with a rate limit error
 }
 
 // BroadcastOption defines a function type for configuring broadcast operations.
@@ -105,6 +228,65 @@ func WithTerminalOnSendError(terminalOnSendError bool) BroadcastOption {
 	}
 }
 
+// WithBroadcastAuditor records the start and the outcome of the broadcast as audit events.
+func WithBroadcastAuditor(auditor *Auditor, name string) BroadcastOption {
+	return func(o *broadcastOptions) {
+		o.auditor = auditor
+		o.auditName = name
+	}
+}
+
+// WithBroadcastMetrics reports the broadcast progress and rate limiter waits to the recorder.
+func WithBroadcastMetrics(recorder MetricsRecorder, name string) BroadcastOption {
+	return func(o *broadcastOptions) {
+		o.metrics = recorder
+		o.metricsName = name
+	}
+}
+
+// WithConcurrency sends from n workers in parallel. The shared rate limiter and per-chat limits
+// still apply to all workers, and progress is reported after every completed send with
+// monotonically increasing counts.
+func WithConcurrency(n int) BroadcastOption {
+	return func(o *broadcastOptions) {
+		o.concurrency = n
+	}
+}
+
+// WithPerChatRateLimit limits how often a single chat receives a message, e.g. the
+// 20 messages per minute Telegram allows in groups, without slowing down other chats.
+// The chat of a recipient is resolved with chatID, recipients of type int64 are used
+// as chat IDs when it is nil.
+func WithPerChatRateLimit(limit rate.Limit, burst int, chatID func(item any) int64) BroadcastOption {
+	return func(o *broadcastOptions) {
+		o.chatLimit = limit
+		o.chatBurst = burst
+		o.chatID = chatID
+		if chatID == nil {
+			o.chatID = func(item any) int64 {
+				id, _ := item.(int64)
+				return id
+			}
+		}
+	}
+}
+
+// WithBroadcastReport records the result of every recipient into the report, so it can be
+// exported with BroadcastReport.WriteJSON or WriteCSV after the campaign.
+func WithBroadcastReport(report *BroadcastReport) BroadcastOption {
+	return func(o *broadcastOptions) {
+		o.report = report
+	}
+}
+
+// WithBroadcastRetries retries sends failing with a rate limit error up to n times, waiting
+// for the duration requested by Telegram. Retries are counted in the BroadcastReport.
+func WithBroadcastRetries(n int) BroadcastOption {
+	return func(o *broadcastOptions) {
+		o.retries = n
+	}
+}
+
 // BroadcastMessage sends messages to multiple recipients with rate limiting and error handling.
 // It processes each item in the data slice through the provided send function, respecting
 // the rate limiter and reporting progress through optional callbacks.
@@ -119,34 +301,219 @@ func WithTerminalOnSendError(terminalOnSendError bool) BroadcastOption {
 //   - send: Function to send message for each data item
 //   - options: Optional configuration for progress tracking and error handling
 func BroadcastMessage[T any](ctx context.Context, b *bot.Bot, data []T, rateLimiter *rate.Limiter, send func(context.Context, *bot.Bot, T) error, options ...BroadcastOption) error {
+	i := 0
+	next := func() (T, bool) {
+		if i == len(data) {
+			var zero T
+			return zero, false
+		}
+		i++
+		return data[i-1], true
+	}
+	return broadcast(ctx, b, next, len(data), rateLimiter, send, options...)
+}
+
+// BroadcastFrom works like BroadcastMessage but pulls the recipients from next until it
+// reports false, so they can be streamed from a database cursor instead of being loaded into
+// memory. The total is unknown, so progress callbacks and metrics receive -1 as total until
+// the source is exhausted.
+func BroadcastFrom[T any](ctx context.Context, b *bot.Bot, next func() (T, bool), rateLimiter *rate.Limiter, send func(context.Context, *bot.Bot, T) error, options ...BroadcastOption) error {
+	return broadcast(ctx, b, next, -1, rateLimiter, send, options...)
+}
+
+func broadcast[T any](ctx context.Context, b *bot.Bot, next func() (T, bool), total int, rateLimiter *rate.Limiter, send func(context.Context, *bot.Bot, T) error, options ...BroadcastOption) error {
 	opts := newBroadcastOptions(options...)
-	total := len(data)
-	errCount := 0
-	for i, d := range data {
+	p := &broadcastProgress{opts: opts, total: total}
+	if opts.auditor != nil {
+		opts.auditor.Record(ctx, &AuditEvent{
+			Kind:   AuditKindBroadcast,
+			Action: opts.auditName,
+			Attrs:  map[string]any{"stage": "start", "total": total},
+		})
+		defer func() {
+			finished := total
+			if finished < 0 {
+				finished = p.sent
+			}
+			opts.auditor.Record(ctx, &AuditEvent{
+				Kind:   AuditKindBroadcast,
+				Action: opts.auditName,
+				Attrs:  map[string]any{"stage": "finish", "total": finished, "errors": p.failed},
+			})
+		}()
+	}
+	limiter := &broadcastLimiter{global: rateLimiter, opts: opts}
+	send = reportingSend(opts, send)
+	var err error
+	if opts.concurrency > 1 {
+		err = broadcastConcurrent(ctx, b, next, limiter, send, p)
+	} else {
+		err = broadcastSequential(ctx, b, next, limiter, send, p)
+	}
+	if err != nil {
+		return err
+	}
+	p.finish()
+	return nil
+}
+
+func broadcastSequential[T any](ctx context.Context, b *bot.Bot, next func() (T, bool), limiter *broadcastLimiter, send func(context.Context, *bot.Bot, T) error, p *broadcastProgress) error {
+	for {
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
-		if opts.progress != nil {
-			opts.progress(i, errCount, total)
+		d, ok := next()
+		if !ok {
+			return nil
 		}
-		err := rateLimiter.Wait(ctx)
-		if err != nil {
+		p.report()
+		if err := limiter.wait(ctx, d); err != nil {
 			return err
 		}
-		err = send(ctx, b, d)
-		if err != nil {
-			errCount++
-			if opts.terminalOnSendError {
-				return err
+		err := send(ctx, b, d)
+		if p.done(err) && p.opts.terminalOnSendError {
+			return err
+		}
+	}
+}
+
+// broadcastConcurrent sends from several workers. Progress is reported after every completed
+// send, serialized and with monotonically increasing counts.
+func broadcastConcurrent[T any](ctx context.Context, b *bot.Bot, next func() (T, bool), limiter *broadcastLimiter, send func(context.Context, *bot.Bot, T) error, p *broadcastProgress) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	items := make(chan T)
+	var wg sync.WaitGroup
+	for range p.opts.concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range items {
+				if err := limiter.wait(ctx, d); err != nil {
+					cancel(err)
+					continue
+				}
+				err := send(ctx, b, d)
+				if p.done(err) && p.opts.terminalOnSendError {
+					cancel(err)
+				}
+				p.report()
 			}
+		}()
+	}
+	for ctx.Err() == nil {
+		d, ok := next()
+		if !ok {
+			break
+		}
+		select {
+		case items <- d:
+		case <-ctx.Done():
 		}
 	}
-	if opts.progress != nil {
-		opts.progress(total, errCount, total)
+	close(items)
+	wg.Wait()
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
 	}
 	return nil
 }
 
+// broadcastProgress counts the outcome of a broadcast and reports it to the callbacks.
+type broadcastProgress struct {
+	mu     sync.Mutex
+	opts   *broadcastOptions
+	total  int
+	sent   int
+	failed int
+}
+
+// done records the outcome of a send and reports whether it failed.
+func (p *broadcastProgress) done(err error) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent++
+	if err != nil {
+		p.failed++
+	}
+	return err != nil
+}
+
+func (p *broadcastProgress) report() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.opts.progress != nil {
+		p.opts.progress(p.sent, p.failed, p.total)
+	}
+	if p.opts.metrics != nil {
+		p.opts.metrics.ObserveBroadcast(p.opts.metricsName, p.sent-p.failed, p.failed, p.total)
+	}
+}
+
+// finish reports the final progress, the total is known once the recipients are exhausted.
+func (p *broadcastProgress) finish() {
+	p.mu.Lock()
+	p.total = p.sent
+	p.mu.Unlock()
+	p.report()
+}
+
+// broadcastLimiter applies the shared rate limiter and the per-chat limits.
+type broadcastLimiter struct {
+	global *rate.Limiter
+	opts   *broadcastOptions
+
+	mu    sync.Mutex
+	chats map[int64]*rate.Limiter
+}
+
+// wait blocks until the recipient may be sent to. The per-chat limit is waited for first,
+// so a busy chat does not hold a token of the shared limiter other chats could use.
+func (l *broadcastLimiter) wait(ctx context.Context, item any) error {
+	waitStart := time.Now()
+	if chat := l.chat(item); chat != nil {
+		if err := chat.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	if err := l.global.Wait(ctx); err != nil {
+		return err
+	}
+	if l.opts.metrics != nil {
+		l.opts.metrics.ObserveRateLimitWait(time.Since(waitStart))
+	}
+	return nil
+}
+
+// broadcastChatLimiters is the number of per-chat limiters above which idle ones are dropped.
+const broadcastChatLimiters = 1024
+
+func (l *broadcastLimiter) chat(item any) *rate.Limiter {
+	if l.opts.chatLimit == 0 || l.opts.chatID == nil {
+		return nil
+	}
+	id := l.opts.chatID(item)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.chats == nil {
+		l.chats = map[int64]*rate.Limiter{}
+	}
+	limiter, ok := l.chats[id]
+	if !ok {
+		if len(l.chats) >= broadcastChatLimiters {
+			// a limiter with a full bucket behaves like a new one and can be dropped
+			for chatID, chat := range l.chats {
+				if chat.Tokens() >= float64(l.opts.chatBurst) {
+					delete(l.chats, chatID)
+				}
+			}
+		}
+		limiter = rate.NewLimiter(l.opts.chatLimit, l.opts.chatBurst)
+		l.chats[id] = limiter
+	}
+	return limiter
+}
+
 // RetryOnTooManyRequestsError implements automatic retry logic for Telegram rate limit errors.
 // It respects the RetryAfter duration from Telegram's error response and retries up to maxRetries times.
 // Returns an error if max retries are exceeded or if a non-rate-limit error occurs.
@@ -0,0 +1,209 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/time/rate"
+)
+
+func TestEditFailed(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{ErrMessageInaccessible, true},
+		{fmt.Errorf("%w, Bad Request: message to edit not found", bot.ErrorBadRequest), true},
+		{fmt.Errorf("%w, Bad Request: message can't be edited", bot.ErrorBadRequest), true},
+		{fmt.Errorf("%w, Bad Request: message is not modified", bot.ErrorBadRequest), false},
+		{errors.New("connection reset"), false},
+	}
+	for _, c := range cases {
+		if got := editFailed(c.err); got != c.want {
+			t.Errorf("editFailed(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
+
+func TestSendMessageBusinessCallback(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		connections = map[string]string{}
+	)
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		mu.Lock()
+		connections[method] = r.FormValue("business_connection_id")
+		mu.Unlock()
+		if method == "editMessageText" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: message can't be edited"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":2,"date":1,"chat":{"id":1,"type":"private"}}}`))
+	})
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	update := &Update{CallbackQuery: &models.CallbackQuery{ID: "q", Message: models.MaybeInaccessibleMessage{
+		Message: &models.Message{ID: 1, Chat: models.Chat{ID: 1, Type: models.ChatTypePrivate}, Text: "old", BusinessConnectionID: "conn"},
+	}}}
+	if err = SendMessage(ctx, app.API(), update, &Message{Text: "new", Strategy: EditOrSend}); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	if connections["editMessageText"] != "conn" || connections["sendMessage"] != "conn" {
+		t.Errorf("business connection was dropped: %v", connections)
+	}
+	mu.Unlock()
+
+	inaccessible := &Update{CallbackQuery: &models.CallbackQuery{ID: "q", Message: models.MaybeInaccessibleMessage{
+		InaccessibleMessage: &models.InaccessibleMessage{Chat: models.Chat{ID: 1}, MessageID: 1},
+	}}}
+	if err = SendMessage(ctx, app.API(), inaccessible, &Message{Text: "new"}); !errors.Is(err, ErrMessageInaccessible) {
+		t.Errorf("expected ErrMessageInaccessible, got %v", err)
+	}
+}
+
+func TestAnswerCallbackOptions(t *testing.T) {
+	answer := &CallbackAnswer{}
+	for _, opt := range []CallbackAnswerOption{WithAnswerText("saved"), WithShowAlert(true), WithCacheTime(30 * time.Second)} {
+		opt(answer)
+	}
+	if answer.Text != "saved" || !answer.ShowAlert || answer.CacheTime != 30*time.Second {
+		t.Errorf("unexpected answer: %+v", answer)
+	}
+	if err := AnswerCallback(context.Background(), nil, &Update{}, WithAnswerText("ignored")); err != nil {
+		t.Errorf("answering a non callback update must be a no-op: %v", err)
+	}
+}
+
+func TestVoiceMessageParams(t *testing.T) {
+	m := NewVoiceMessage([]byte("ogg"), 3500*time.Millisecond)
+	m.Text = "reply"
+	params := m.toSendVoiceParams(1)
+	if params.Duration != 3 || params.Caption != "reply" || params.Voice == nil || params.ReplyMarkup != nil {
+		t.Errorf("unexpected voice params: %+v", params)
+	}
+	note := NewVideoNoteMessage([]byte("mp4"), time.Minute, 240).toSendVideoNoteParams(1)
+	if note.Duration != 60 || note.Length != 240 {
+		t.Errorf("unexpected video note params: %+v", note)
+	}
+}
+
+func TestEditToFallsBackToCaption(t *testing.T) {
+	var methods []string
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		methods = append(methods, method)
+		if method == "editMessageText" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: there is no text in the message to edit"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":5,"chat":{"id":1}}}`))
+	})
+	b, err := bot.New("token", bot.WithServerURL(server.URL), bot.WithSkipGetMe())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = EditTo(context.Background(), b, 1, 5, &Message{Text: "caption"}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(methods, ",") != "editMessageText,editMessageCaption" {
+		t.Errorf("unexpected calls: %v", methods)
+	}
+}
+
+func TestBroadcastFrom(t *testing.T) {
+	i := 0
+	next := func() (int, bool) {
+		i++
+		return i, i <= 5
+	}
+	var got []int
+	var last [3]int
+	err := BroadcastFrom(context.Background(), nil, next, rate.NewLimiter(rate.Inf, 1), func(ctx context.Context, b *bot.Bot, n int) error {
+		got = append(got, n)
+		if n == 3 {
+			return errors.New("blocked")
+		}
+		return nil
+	}, WithProgress(func(current, errs, total int) {
+		last = [3]int{current, errs, total}
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 5 {
+		t.Errorf("expected 5 recipients, got %v", got)
+	}
+	if last != [3]int{5, 1, 5} {
+		t.Errorf("unexpected final progress: %v", last)
+	}
+}
+
+func TestBroadcastConcurrency(t *testing.T) {
+	data := make([]int64, 50)
+	for i := range data {
+		data[i] = int64(i)
+	}
+	var mu sync.Mutex
+	seen := map[int64]bool{}
+	last := -1
+	err := BroadcastMessage(context.Background(), nil, data, rate.NewLimiter(rate.Inf, 1), func(ctx context.Context, b *bot.Bot, id int64) error {
+		mu.Lock()
+		defer mu.Unlock()
+		seen[id] = true
+		if id%10 == 0 {
+			return errors.New("blocked")
+		}
+		return nil
+	}, WithConcurrency(4), WithProgress(func(current, errs, total int) {
+		if current < last {
+			t.Errorf("progress went backwards: %d after %d", current, last)
+		}
+		last = current
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != len(data) || last != len(data) {
+		t.Errorf("expected all %d recipients, got %d (progress %d)", len(data), len(seen), last)
+	}
+
+	stop := errors.New("stop")
+	err = BroadcastMessage(context.Background(), nil, data, rate.NewLimiter(rate.Inf, 1), func(ctx context.Context, b *bot.Bot, id int64) error {
+		return stop
+	}, WithConcurrency(4), WithTerminalOnSendError(true))
+	if !errors.Is(err, stop) {
+		t.Errorf("expected the send error, got %v", err)
+	}
+}
+
+func TestBroadcastPerChatRateLimit(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var sent []int64
+	err := BroadcastMessage(ctx, nil, []int64{1, 2, 1}, rate.NewLimiter(rate.Inf, 1), func(ctx context.Context, b *bot.Bot, id int64) error {
+		sent = append(sent, id)
+		return nil
+	}, WithPerChatRateLimit(rate.Every(time.Hour), 1, nil))
+	if err == nil {
+		t.Fatal("expected the second message to chat 1 to exceed the deadline")
+	}
+	if len(sent) != 2 {
+		t.Errorf("other chats must not be limited: %v", sent)
+	}
+}
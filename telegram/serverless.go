@@ -0,0 +1,78 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// updateResult collects the outcome of an update processed by HandleUpdate.
+type updateResult struct {
+	done chan struct{}
+	mu   sync.Mutex
+	err  error
+}
+
+type updateResultKey struct{}
+
+// newUpdateResultMiddleware creates the outermost middleware signaling HandleUpdate that the
+// update was processed, including the recovery of panics.
+func newUpdateResultMiddleware() bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			if result, ok := ctx.Value(updateResultKey{}).(*updateResult); ok {
+				defer close(result.done)
+			}
+			next(ctx, b, update)
+		}
+	}
+}
+
+// withUpdateResult wraps an error handler so errors of updates processed by HandleUpdate are
+// also returned to its caller.
+func withUpdateResult(next ErrorHandlerFunc) ErrorHandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *Update, err error) {
+		if result, ok := ctx.Value(updateResultKey{}).(*updateResult); ok {
+			result.mu.Lock()
+			result.err = errors.Join(result.err, err)
+			result.mu.Unlock()
+		}
+		if next != nil {
+			next(ctx, b, update, err)
+		}
+	}
+}
+
+// HandleUpdate runs the update through the middlewares and the router and waits until it was
+// processed, also with asynchronous handlers. Handler errors and panics are passed to the
+// error handler as usual and returned as well.
+func (b *Bot) HandleUpdate(ctx context.Context, update *Update) error {
+	result := &updateResult{done: make(chan struct{})}
+	b.API().ProcessUpdate(context.WithValue(ctx, updateResultKey{}, result), update)
+	select {
+	case <-result.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	result.mu.Lock()
+	defer result.mu.Unlock()
+	return result.err
+}
+
+// HandleUpdateJSON parses a single update, e.g. the body of a webhook request, and processes
+// it with HandleUpdate. It is meant for serverless functions like AWS Lambda or Cloud
+// Functions receiving the webhook without a long-running listener: neither Start nor
+// StartWebhook is called, so the lifecycle hooks and background components started by them,
+// like the admin notifications or AutoDelete, do not run.
+func (b *Bot) HandleUpdateJSON(ctx context.Context, body []byte) error {
+	var update Update
+	if err := json.Unmarshal(body, &update); err != nil {
+		return fmt.Errorf("decode update: %w", err)
+	}
+	return b.HandleUpdate(ctx, &update)
+}
@@ -0,0 +1,53 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram/bot"
+)
+
+func TestHandleUpdateJSON(t *testing.T) {
+	var handled []error
+	app, err := NewApp(Config{Token: "token"}, WithErrorHandler(func(ctx context.Context, b *bot.Bot, update *Update, err error) {
+		handled = append(handled, err)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed := errors.New("failed")
+	var processed []string
+	app.BindCommand("/ok", func(ctx context.Context, update *Update) error {
+		processed = append(processed, update.Message.Text)
+		return nil
+	})
+	app.BindCommand("/fail", func(ctx context.Context, update *Update) error {
+		return failed
+	})
+	app.BindCommand("/panic", func(ctx context.Context, update *Update) error {
+		panic("boom")
+	})
+	ctx := context.Background()
+
+	// handlers run asynchronously by default, the call still waits for them
+	if err = app.HandleUpdateJSON(ctx, []byte(`{"update_id":1,"message":{"message_id":1,"chat":{"id":1},"text":"/ok"}}`)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(processed) != 1 {
+		t.Errorf("update was not processed before returning: %v", processed)
+	}
+	if err = app.HandleUpdateJSON(ctx, []byte(`{"update_id":2,"message":{"message_id":2,"chat":{"id":1},"text":"/fail"}}`)); !errors.Is(err, failed) {
+		t.Errorf("expected handler error, got %v", err)
+	}
+	if len(handled) != 1 {
+		t.Errorf("error handler was not called: %v", handled)
+	}
+	if err = app.HandleUpdateJSON(ctx, []byte(`{"update_id":3,"message":{"message_id":3,"chat":{"id":1},"text":"/panic"}}`)); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("expected recovered panic, got %v", err)
+	}
+	if err = app.HandleUpdateJSON(ctx, []byte(`{`)); err == nil {
+		t.Error("expected decode error")
+	}
+}
@@ -0,0 +1,110 @@
+package telegram
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// ServiceKind is a set of kinds of service messages, combined with "|".
+type ServiceKind int
+
+// Kinds of service messages.
+const (
+	ServicePin   ServiceKind = 1 << iota // A message was pinned
+	ServiceJoin                          // Members joined the chat
+	ServiceLeave                         // A member left the chat
+	ServiceTitle                         // The chat title changed
+	ServicePhoto                         // The chat photo changed or was deleted
+
+	// ServiceAll matches every kind of service message.
+	ServiceAll = ServicePin | ServiceJoin | ServiceLeave | ServiceTitle | ServicePhoto
+)
+
+// ServiceMessageKind returns the kind of the service message, zero for regular messages.
+func ServiceMessageKind(m *models.Message) ServiceKind {
+	switch {
+	case m == nil:
+		return 0
+	case m.PinnedMessage != nil:
+		return ServicePin
+	case len(m.NewChatMembers) > 0:
+		return ServiceJoin
+	case m.LeftChatMember != nil:
+		return ServiceLeave
+	case m.NewChatTitle != "":
+		return ServiceTitle
+	case len(m.NewChatPhoto) > 0 || m.DeleteChatPhoto:
+		return ServicePhoto
+	default:
+		return 0
+	}
+}
+
+// IsServiceMessage returns a match function for BindMatch accepting service messages of the kinds.
+func IsServiceMessage(kinds ServiceKind) func(update *Update) bool {
+	return func(update *Update) bool {
+		return ServiceMessageKind(update.Message)&kinds != 0
+	}
+}
+
+// serviceCleanupOptions holds configuration for the service message cleanup middleware.
+type serviceCleanupOptions struct {
+	chats []int64       // Chats cleaned up, empty for every chat
+	delay time.Duration // Delay before the message is deleted
+}
+
+// ServiceCleanupOption defines a function type for configuring the service message cleanup middleware.
+type ServiceCleanupOption func(*serviceCleanupOptions)
+
+// WithServiceCleanupChats restricts the cleanup to the chats. By default every chat is cleaned up.
+func WithServiceCleanupChats(chatIDs ...int64) ServiceCleanupOption {
+	return func(o *serviceCleanupOptions) {
+		o.chats = append(o.chats, chatIDs...)
+	}
+}
+
+// WithServiceCleanupDelay deletes service messages after the delay instead of right away.
+func WithServiceCleanupDelay(delay time.Duration) ServiceCleanupOption {
+	return func(o *serviceCleanupOptions) {
+		o.delay = delay
+	}
+}
+
+// NewServiceCleanupMiddleware creates a middleware deleting service messages of the kinds.
+// The update is still passed on, so handlers like Greetings or Captcha keep working.
+// Add it with AppendUpdateMiddlewares to cover service messages without a route.
+// The bot must be an administrator allowed to delete messages.
+func NewServiceCleanupMiddleware(kinds ServiceKind, options ...ServiceCleanupOption) MiddlewareFunc {
+	opts := &serviceCleanupOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, update *Update) error {
+			client := ClientFromContext(ctx)
+			if client == nil || ServiceMessageKind(update.Message)&kinds == 0 {
+				return next(ctx, update)
+			}
+			chatID, messageID := update.Message.Chat.ID, update.Message.ID
+			if len(opts.chats) > 0 && !slices.Contains(opts.chats, chatID) {
+				return next(ctx, update)
+			}
+			remove := func(ctx context.Context) {
+				if err := DeleteTo(ctx, client, chatID, messageID); err != nil {
+					slog.ErrorContext(ctx, "delete service message", slog.Int64("chat_id", chatID), slog.Any("error", err))
+				}
+			}
+			if opts.delay > 0 {
+				expired := context.WithoutCancel(ctx)
+				time.AfterFunc(opts.delay, func() { remove(expired) })
+			} else {
+				remove(ctx)
+			}
+			return next(ctx, update)
+		}
+	}
+}
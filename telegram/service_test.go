@@ -0,0 +1,35 @@
+package telegram
+
+import (
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestServiceMessageKind(t *testing.T) {
+	cases := []struct {
+		message *models.Message
+		want    ServiceKind
+	}{
+		{nil, 0},
+		{&models.Message{Text: "hello"}, 0},
+		{&models.Message{PinnedMessage: &models.MaybeInaccessibleMessage{}}, ServicePin},
+		{&models.Message{NewChatMembers: []models.User{{ID: 1}}}, ServiceJoin},
+		{&models.Message{LeftChatMember: &models.User{ID: 1}}, ServiceLeave},
+		{&models.Message{NewChatTitle: "Go"}, ServiceTitle},
+		{&models.Message{DeleteChatPhoto: true}, ServicePhoto},
+	}
+	for i, c := range cases {
+		if got := ServiceMessageKind(c.message); got != c.want {
+			t.Errorf("case %d: got %d, want %d", i, got, c.want)
+		}
+	}
+
+	match := IsServiceMessage(ServiceJoin | ServiceLeave)
+	if !match(&Update{Message: &models.Message{LeftChatMember: &models.User{}}}) {
+		t.Error("leave message must match")
+	}
+	if match(&Update{Message: &models.Message{NewChatTitle: "Go"}}) || match(&Update{}) {
+		t.Error("title change and non-message updates must not match")
+	}
+}
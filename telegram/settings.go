@@ -0,0 +1,132 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"sync"
+	"time"
+)
+
+// ErrSettingsNotFound is returned by a SettingsStore when the chat has no saved settings.
+var ErrSettingsNotFound = errors.New("chat settings not found")
+
+// ChatSettings are the preferences of a chat.
+type ChatSettings struct {
+	ChatID        int64           `json:"chat_id"`
+	Language      string          `json:"language,omitempty"`      // Language code, e.g. "en"
+	Timezone      string          `json:"timezone,omitempty"`      // IANA time zone, e.g. "Europe/Berlin"
+	Notifications map[string]bool `json:"notifications,omitempty"` // Notification preferences by name
+	Welcome       *Greeting       `json:"welcome,omitempty"`       // Message greeting new members, see Greetings
+	Goodbye       *Greeting       `json:"goodbye,omitempty"`       // Message sent when a member leaves, see Greetings
+}
+
+// Location returns the time zone of the chat, UTC if it is unset or unknown.
+func (s *ChatSettings) Location() *time.Location {
+	if s == nil || s.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Notify reports whether the notification is enabled for the chat.
+func (s *ChatSettings) Notify(name string) bool {
+	return s != nil && s.Notifications[name]
+}
+
+// clone returns a deep copy of the settings.
+func (s *ChatSettings) clone() *ChatSettings {
+	c := *s
+	c.Notifications = maps.Clone(s.Notifications)
+	c.Welcome = s.Welcome.clone()
+	c.Goodbye = s.Goodbye.clone()
+	return &c
+}
+
+// SettingsStore persists chat settings.
+type SettingsStore interface {
+	// GetSettings returns the settings of the chat or ErrSettingsNotFound.
+	GetSettings(ctx context.Context, chatID int64) (*ChatSettings, error)
+	// SaveSettings inserts or replaces the settings of the chat.
+	SaveSettings(ctx context.Context, settings *ChatSettings) error
+}
+
+// MemorySettingsStore is an in-memory SettingsStore, suitable for tests and single instance bots.
+type MemorySettingsStore struct {
+	mu       sync.RWMutex
+	settings map[int64]*ChatSettings
+}
+
+// NewMemorySettingsStore creates an empty in-memory settings store.
+func NewMemorySettingsStore() *MemorySettingsStore {
+	return &MemorySettingsStore{settings: map[int64]*ChatSettings{}}
+}
+
+// GetSettings implements SettingsStore.
+func (s *MemorySettingsStore) GetSettings(ctx context.Context, chatID int64) (*ChatSettings, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	settings, ok := s.settings[chatID]
+	if !ok {
+		return nil, ErrSettingsNotFound
+	}
+	return settings.clone(), nil
+}
+
+// SaveSettings implements SettingsStore.
+func (s *MemorySettingsStore) SaveSettings(ctx context.Context, settings *ChatSettings) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settings[settings.ChatID] = settings.clone()
+	return nil
+}
+
+// DeleteUserData implements UserDataStore, deleting the settings of the private chat with the user.
+func (s *MemorySettingsStore) DeleteUserData(ctx context.Context, userID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.settings, userID)
+	return nil
+}
+
+// LoadChatSettings returns the settings of the chat, or a copy of the defaults for chats
+// without saved settings.
+func LoadChatSettings(ctx context.Context, store SettingsStore, chatID int64, defaults ChatSettings) (*ChatSettings, error) {
+	settings, err := store.GetSettings(ctx, chatID)
+	if errors.Is(err, ErrSettingsNotFound) {
+		settings = defaults.clone()
+		settings.ChatID = chatID
+		return settings, nil
+	}
+	return settings, err
+}
+
+var chatSettingsKey = NewKey[*ChatSettings]("chat_settings")
+
+// NewSettingsMiddleware creates a middleware loading the settings of the update's chat, so
+// handlers can read them with ChatSettingsFromContext. Updates without a chat pass unchanged.
+func NewSettingsMiddleware(store SettingsStore, defaults ChatSettings) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, update *Update) error {
+			ref, err := UpdateMessageRef(update)
+			if err != nil {
+				return next(ctx, update)
+			}
+			settings, err := LoadChatSettings(ctx, store, ref.ChatID, defaults)
+			if err != nil {
+				return err
+			}
+			return next(WithValue(ctx, chatSettingsKey, settings), update)
+		}
+	}
+}
+
+// ChatSettingsFromContext returns the chat settings loaded by NewSettingsMiddleware, or nil.
+func ChatSettingsFromContext(ctx context.Context) *ChatSettings {
+	settings, _ := Value(ctx, chatSettingsKey)
+	return settings
+}
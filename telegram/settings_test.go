@@ -0,0 +1,69 @@
+package telegram
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestSettingsMenu(t *testing.T) {
+	menu := &SettingsMenu{
+		Title:         "Settings",
+		Languages:     []string{"en", "de"},
+		Timezones:     []string{"UTC", "Europe/Berlin"},
+		Notifications: []string{"digest"},
+		Defaults:      ChatSettings{Language: "en"},
+	}
+	settings := &ChatSettings{ChatID: 1, Language: "en"}
+
+	m := menu.Message(settings)
+	if len(m.Button) != 3 || m.Button[0][0].Text != "✓ en" || m.Button[2][0].Text != "digest: off" {
+		t.Fatalf("unexpected keyboard: %+v", m.Button)
+	}
+	_, data, err := UnmarshalData[SettingsData](m.Button[1][1].CallbackData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !menu.Apply(settings, *data) || settings.Timezone != "Europe/Berlin" {
+		t.Errorf("time zone not applied: %+v", settings)
+	}
+	if settings.Location().String() != "Europe/Berlin" {
+		t.Errorf("unexpected location: %s", settings.Location())
+	}
+	if !menu.Apply(settings, SettingsData{Field: "notify", Index: 0}) || !settings.Notify("digest") {
+		t.Error("notification not toggled")
+	}
+	if menu.Apply(settings, SettingsData{Field: "lang", Index: 5}) {
+		t.Error("out of range option must be rejected")
+	}
+	for _, row := range m.Button {
+		for _, button := range row {
+			if len(button.CallbackData) > 64 {
+				t.Errorf("callback data too long: %q", button.CallbackData)
+			}
+		}
+	}
+}
+
+func TestSettingsMiddleware(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemorySettingsStore()
+	_ = store.SaveSettings(ctx, &ChatSettings{ChatID: 1, Timezone: "Asia/Tokyo"})
+	mw := NewSettingsMiddleware(store, ChatSettings{Language: "en"})
+
+	var got *ChatSettings
+	h := mw(func(ctx context.Context, update *Update) error {
+		got = ChatSettingsFromContext(ctx)
+		return nil
+	})
+	_ = h(ctx, &Update{Message: &models.Message{Chat: models.Chat{ID: 1}}})
+	if got == nil || got.Timezone != "Asia/Tokyo" {
+		t.Errorf("unexpected saved settings: %+v", got)
+	}
+	_ = h(ctx, &Update{Message: &models.Message{Chat: models.Chat{ID: 2}}})
+	if got == nil || got.ChatID != 2 || got.Language != "en" || got.Location() != time.UTC {
+		t.Errorf("unexpected default settings: %+v", got)
+	}
+}
@@ -0,0 +1,129 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+)
+
+// SettingsMenu is an inline keyboard for changing chat settings, see Bot.BindSettingsMenu.
+// Options are referenced by index in the callback data, so their order must be stable.
+type SettingsMenu struct {
+	Route         string       // Callback route of the menu buttons, defaults to "settings"
+	Title         string       // Text of the menu message
+	Languages     []string     // Selectable language codes, the row is hidden if empty
+	Timezones     []string     // Selectable IANA time zones, the rows are hidden if empty
+	Notifications []string     // Names of the notifications that can be toggled
+	Defaults      ChatSettings // Settings of chats that did not change anything yet
+}
+
+// SettingsData is the compact callback data of the settings menu buttons.
+type SettingsData struct {
+	Field string `json:"f"` // "lang", "tz" or "notify"
+	Index int    `json:"i"` // Index of the option within the menu list
+}
+
+const (
+	settingsLanguage     = "lang"
+	settingsTimezone     = "tz"
+	settingsNotification = "notify"
+)
+
+func (m *SettingsMenu) route() string {
+	if m.Route == "" {
+		return "settings"
+	}
+	return m.Route
+}
+
+// Message renders the menu for the settings, marking the selected options.
+func (m *SettingsMenu) Message(settings *ChatSettings, options ...DataOption) *Message {
+	button := func(text, field string, index int, selected bool) Button {
+		if selected {
+			text = "✓ " + text
+		}
+		return NewButton(text, m.route(), SettingsData{Field: field, Index: index}, options...)
+	}
+	var rows [][]Button
+	if len(m.Languages) > 0 {
+		buttons := make([]Button, len(m.Languages))
+		for i, language := range m.Languages {
+			buttons[i] = button(language, settingsLanguage, i, language == settings.Language)
+		}
+		rows = append(rows, ChunkButtons(buttons, 4)...)
+	}
+	if len(m.Timezones) > 0 {
+		buttons := make([]Button, len(m.Timezones))
+		for i, timezone := range m.Timezones {
+			buttons[i] = button(timezone, settingsTimezone, i, timezone == settings.Timezone)
+		}
+		rows = append(rows, ChunkButtons(buttons, 2)...)
+	}
+	for i, name := range m.Notifications {
+		state := "off"
+		if settings.Notify(name) {
+			state = "on"
+		}
+		rows = append(rows, []Button{button(fmt.Sprintf("%s: %s", name, state), settingsNotification, i, false)})
+	}
+	return &Message{Text: m.Title, Button: rows}
+}
+
+// Apply changes the settings according to the pressed button. It reports false if the data
+// does not reference an option of the menu.
+func (m *SettingsMenu) Apply(settings *ChatSettings, data SettingsData) bool {
+	valid := func(list []string) bool { return data.Index >= 0 && data.Index < len(list) }
+	switch {
+	case data.Field == settingsLanguage && valid(m.Languages):
+		settings.Language = m.Languages[data.Index]
+	case data.Field == settingsTimezone && valid(m.Timezones):
+		settings.Timezone = m.Timezones[data.Index]
+	case data.Field == settingsNotification && valid(m.Notifications):
+		if settings.Notifications == nil {
+			settings.Notifications = map[string]bool{}
+		}
+		name := m.Notifications[data.Index]
+		settings.Notifications[name] = !settings.Notifications[name]
+	default:
+		return false
+	}
+	return true
+}
+
+// BindSettingsMenu registers a command showing the settings menu of the chat and the callback
+// route of its buttons, which saves the change to the store and updates the menu in place.
+func (b *Bot) BindSettingsMenu(command string, store SettingsStore, menu *SettingsMenu, middlewares ...MiddlewareFunc) {
+	show := func(ctx context.Context, update *Update) error {
+		ref, err := UpdateMessageRef(update)
+		if err != nil {
+			return err
+		}
+		settings, err := LoadChatSettings(ctx, store, ref.ChatID, menu.Defaults)
+		if err != nil {
+			return err
+		}
+		return b.SendMessage(ctx, update, menu.Message(settings, b.DataOptions()...))
+	}
+	b.BindCommand(command, show, middlewares...)
+	b.BindCallback(menu.route(), func(ctx context.Context, update *Update) error {
+		_, data, err := UnmarshalData[SettingsData](update.CallbackQuery.Data, b.DataOptions()...)
+		if err != nil {
+			return err
+		}
+		ref, err := UpdateMessageRef(update)
+		if err != nil {
+			return err
+		}
+		settings, err := LoadChatSettings(ctx, store, ref.ChatID, menu.Defaults)
+		if err != nil {
+			return err
+		}
+		if menu.Apply(settings, *data) {
+			if err = store.SaveSettings(ctx, settings); err != nil {
+				return err
+			}
+		}
+		m := menu.Message(settings, b.DataOptions()...)
+		m.CallbackAnswer = &CallbackAnswer{}
+		return b.SendMessage(ctx, update, m)
+	}, middlewares...)
+}
@@ -0,0 +1,134 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrStateNotFound is returned by a StateStore when no state is stored for the key.
+var ErrStateNotFound = errors.New("conversation state not found")
+
+// StateKey identifies the conversation of a user in a chat.
+type StateKey struct {
+	ChatID int64
+	UserID int64
+}
+
+// UpdateStateKey returns the conversation the update belongs to.
+// The second return value is false if the update has no chat or user.
+func UpdateStateKey(update *Update) (StateKey, bool) {
+	user := UpdateUser(update)
+	if user == nil {
+		return StateKey{}, false
+	}
+	ref, err := UpdateMessageRef(update)
+	if err != nil {
+		return StateKey{}, false
+	}
+	return StateKey{ChatID: ref.ChatID, UserID: user.ID}, true
+}
+
+// ConversationState is the persisted state of a multi-step dialog.
+type ConversationState struct {
+	Key       StateKey
+	Step      string          // Current step of the dialog
+	Data      json.RawMessage // Data collected so far, encoded as JSON
+	UpdatedAt time.Time       // Time the state was last saved
+}
+
+// StateStore persists conversation states, so dialogs survive restarts and can be
+// continued by any replica of a horizontally scaled bot.
+type StateStore interface {
+	// GetState returns the state of the conversation or ErrStateNotFound.
+	GetState(ctx context.Context, key StateKey) (*ConversationState, error)
+	// SetState saves the state, replacing the previous state of the conversation.
+	SetState(ctx context.Context, state *ConversationState) error
+	// DeleteState removes the state of the conversation, e.g. when the dialog ends.
+	DeleteState(ctx context.Context, key StateKey) error
+}
+
+// MemoryStateStore is an in-memory StateStore, suitable for tests and single instance bots.
+type MemoryStateStore struct {
+	mu     sync.RWMutex
+	states map[StateKey]*ConversationState
+}
+
+// NewMemoryStateStore creates an empty in-memory state store.
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{states: map[StateKey]*ConversationState{}}
+}
+
+// GetState implements StateStore.
+func (s *MemoryStateStore) GetState(ctx context.Context, key StateKey) (*ConversationState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	state, ok := s.states[key]
+	if !ok {
+		return nil, ErrStateNotFound
+	}
+	record := *state
+	return &record, nil
+}
+
+// SetState implements StateStore.
+func (s *MemoryStateStore) SetState(ctx context.Context, state *ConversationState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record := *state
+	s.states[state.Key] = &record
+	return nil
+}
+
+// DeleteState implements StateStore.
+func (s *MemoryStateStore) DeleteState(ctx context.Context, key StateKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.states, key)
+	return nil
+}
+
+// DeleteUserData implements UserDataStore, deleting the conversations of the user and of
+// the private chat with the user.
+func (s *MemoryStateStore) DeleteUserData(ctx context.Context, userID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.states {
+		if key.UserID == userID || key.ChatID == userID {
+			delete(s.states, key)
+		}
+	}
+	return nil
+}
+
+// LoadState reads the data of the conversation into a value of type T.
+// It returns ErrStateNotFound if the conversation has no state.
+func LoadState[T any](ctx context.Context, store StateStore, key StateKey) (string, *T, error) {
+	state, err := store.GetState(ctx, key)
+	if err != nil {
+		return "", nil, err
+	}
+	var data T
+	if len(state.Data) > 0 {
+		if err = json.Unmarshal(state.Data, &data); err != nil {
+			return "", nil, err
+		}
+	}
+	return state.Step, &data, nil
+}
+
+// SaveState stores the step and data of the conversation.
+func SaveState[T any](ctx context.Context, store StateStore, key StateKey, step string, data T) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return store.SetState(ctx, &ConversationState{
+		Key:       key,
+		Step:      step,
+		Data:      raw,
+		UpdatedAt: time.Now(),
+	})
+}
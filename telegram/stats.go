@@ -0,0 +1,245 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LatencyStats summarizes handler latency over the most recent samples.
+type LatencyStats struct {
+	Count int           // Number of samples the percentiles are computed from
+	P50   time.Duration // Median latency
+	P90   time.Duration // 90th percentile latency
+	P99   time.Duration // 99th percentile latency
+	Max   time.Duration // Maximum latency
+}
+
+// StatsSnapshot is a point-in-time copy of the collected statistics.
+type StatsSnapshot struct {
+	Since       time.Time        // Time the collector was created
+	Updates     int64            // Total number of processed updates
+	Errors      int64            // Number of updates whose handler returned an error
+	Commands    map[string]int64 // Updates per command, e.g. "/start"
+	ChatTypes   map[string]int64 // Updates per chat type, e.g. "private" or "supergroup"
+	DailyUsers  int              // Unique users seen during the last 24 hours
+	WeeklyUsers int              // Unique users seen during the last 7 days
+	Latency     LatencyStats     // Handler latency percentiles
+}
+
+// statsOptions holds configuration for the StatsCollector.
+type statsOptions struct {
+	latencySamples int // Number of latency samples kept for percentile computation
+}
+
+// StatsOption defines a function type for configuring the StatsCollector.
+type StatsOption func(*statsOptions)
+
+// WithStatsLatencySamples sets how many of the most recent latency samples are kept
+// to compute percentiles. Defaults to 1024.
+func WithStatsLatencySamples(n int) StatsOption {
+	return func(o *statsOptions) {
+		if n > 0 {
+			o.latencySamples = n
+		}
+	}
+}
+
+// StatsCollector counts updates per command and chat type, tracks unique daily and weekly
+// users and measures handler latency. It is safe for concurrent use. To expose the
+// statistics to Prometheus, pass it to metrics.WithStatsCollector.
+type StatsCollector struct {
+	mu        sync.Mutex
+	since     time.Time
+	updates   int64
+	errors    int64
+	commands  map[string]int64
+	chatTypes map[string]int64
+	users     map[int64]time.Time
+	latencies []time.Duration
+	next      int
+	full      bool
+}
+
+// NewStatsCollector creates an empty StatsCollector.
+func NewStatsCollector(options ...StatsOption) *StatsCollector {
+	opts := &statsOptions{latencySamples: 1024}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return &StatsCollector{
+		since:     time.Now(),
+		commands:  map[string]int64{},
+		chatTypes: map[string]int64{},
+		users:     map[int64]time.Time{},
+		latencies: make([]time.Duration, opts.latencySamples),
+	}
+}
+
+// Middleware returns a middleware recording every update passing through it.
+func (s *StatsCollector) Middleware() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, update *Update) error {
+			start := time.Now()
+			err := next(ctx, update)
+			s.observe(update, time.Since(start), err)
+			return err
+		}
+	}
+}
+
+func (s *StatsCollector) observe(update *Update, latency time.Duration, err error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updates++
+	if err != nil {
+		s.errors++
+	}
+	if command := updateCommand(update); command != "" {
+		s.commands[command]++
+	}
+	if chatType := updateChatType(update); chatType != "" {
+		s.chatTypes[chatType]++
+	}
+	if user := UpdateUser(update); user != nil {
+		s.users[user.ID] = now
+	}
+	s.latencies[s.next] = latency
+	s.next = (s.next + 1) % len(s.latencies)
+	if s.next == 0 {
+		s.full = true
+	}
+}
+
+// Stats returns a snapshot of the collected statistics.
+func (s *StatsCollector) Stats() StatsSnapshot {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshot := StatsSnapshot{
+		Since:     s.since,
+		Updates:   s.updates,
+		Errors:    s.errors,
+		Commands:  make(map[string]int64, len(s.commands)),
+		ChatTypes: make(map[string]int64, len(s.chatTypes)),
+	}
+	for k, v := range s.commands {
+		snapshot.Commands[k] = v
+	}
+	for k, v := range s.chatTypes {
+		snapshot.ChatTypes[k] = v
+	}
+	for id, seen := range s.users {
+		switch age := now.Sub(seen); {
+		case age > 7*24*time.Hour:
+			delete(s.users, id) // users inactive for a week are forgotten
+		case age > 24*time.Hour:
+			snapshot.WeeklyUsers++
+		default:
+			snapshot.WeeklyUsers++
+			snapshot.DailyUsers++
+		}
+	}
+	samples := s.latencies[:s.next]
+	if s.full {
+		samples = s.latencies
+	}
+	snapshot.Latency = latencyStats(slices.Clone(samples))
+	return snapshot
+}
+
+func latencyStats(samples []time.Duration) LatencyStats {
+	if len(samples) == 0 {
+		return LatencyStats{}
+	}
+	slices.Sort(samples)
+	percentile := func(p float64) time.Duration {
+		return samples[int(p*float64(len(samples)-1))]
+	}
+	return LatencyStats{
+		Count: len(samples),
+		P50:   percentile(0.50),
+		P90:   percentile(0.90),
+		P99:   percentile(0.99),
+		Max:   samples[len(samples)-1],
+	}
+}
+
+// String formats the statistics as a human readable report.
+func (s StatsSnapshot) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Since: %s\n", s.Since.Format(time.DateTime))
+	fmt.Fprintf(&b, "Updates: %d (errors: %d)\n", s.Updates, s.Errors)
+	fmt.Fprintf(&b, "Active users: %d today, %d this week\n", s.DailyUsers, s.WeeklyUsers)
+	fmt.Fprintf(&b, "Latency: p50 %s, p90 %s, p99 %s, max %s\n",
+		s.Latency.P50.Round(time.Millisecond), s.Latency.P90.Round(time.Millisecond),
+		s.Latency.P99.Round(time.Millisecond), s.Latency.Max.Round(time.Millisecond))
+	if len(s.Commands) > 0 {
+		b.WriteString("Commands:\n")
+		for _, k := range sortedKeys(s.Commands) {
+			fmt.Fprintf(&b, "  %s: %d\n", k, s.Commands[k])
+		}
+	}
+	if len(s.ChatTypes) > 0 {
+		b.WriteString("Chat types:\n")
+		for _, k := range sortedKeys(s.ChatTypes) {
+			fmt.Fprintf(&b, "  %s: %d\n", k, s.ChatTypes[k])
+		}
+	}
+	return b.String()
+}
+
+// BindStatsCommand registers a command replying with the collected statistics.
+// When admins are given, only these users receive a reply.
+func (b *Bot) BindStatsCommand(command string, collector *StatsCollector, admins []int64, middlewares ...MiddlewareFunc) {
+	b.BindCommand(command, func(ctx context.Context, update *Update) error {
+		if len(admins) > 0 {
+			user := UpdateUser(update)
+			if user == nil || !slices.Contains(admins, user.ID) {
+				return nil
+			}
+		}
+		return b.SendMessage(ctx, update, &Message{Text: collector.Stats().String()})
+	}, middlewares...)
+}
+
+func updateCommand(update *Update) string {
+	if update == nil || update.Message == nil || !strings.HasPrefix(update.Message.Text, "/") {
+		return ""
+	}
+	command, _, _ := strings.Cut(strings.Fields(update.Message.Text)[0], "@")
+	return command
+}
+
+func updateChatType(update *Update) string {
+	switch {
+	case update == nil:
+		return ""
+	case update.Message != nil:
+		return string(update.Message.Chat.Type)
+	case update.CallbackQuery != nil && update.CallbackQuery.Message.Message != nil:
+		return string(update.CallbackQuery.Message.Message.Chat.Type)
+	case update.EditedMessage != nil:
+		return string(update.EditedMessage.Chat.Type)
+	case update.ChannelPost != nil:
+		return string(update.ChannelPost.Chat.Type)
+	case update.BusinessMessage != nil:
+		return string(update.BusinessMessage.Chat.Type)
+	default:
+		return ""
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
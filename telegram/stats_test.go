@@ -0,0 +1,48 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestStatsCollector(t *testing.T) {
+	collector := NewStatsCollector()
+	handler := collector.Middleware()(func(ctx context.Context, update *Update) error {
+		if update.Message != nil && update.Message.Text == "/fail" {
+			return errors.New("fail")
+		}
+		return nil
+	})
+	newMessage := func(userID int64, text string) *Update {
+		return &Update{Message: &models.Message{
+			Text: text,
+			From: &models.User{ID: userID},
+			Chat: models.Chat{ID: userID, Type: models.ChatTypePrivate},
+		}}
+	}
+	ctx := context.Background()
+	_ = handler(ctx, newMessage(1, "/start"))
+	_ = handler(ctx, newMessage(2, "/start@test_bot arg"))
+	_ = handler(ctx, newMessage(2, "/fail"))
+	_ = handler(ctx, newCallbackUpdate(3, 10, "menu:a"))
+
+	stats := collector.Stats()
+	if stats.Updates != 4 || stats.Errors != 1 {
+		t.Errorf("unexpected counters: %d updates, %d errors", stats.Updates, stats.Errors)
+	}
+	if stats.Commands["/start"] != 2 || stats.Commands["/fail"] != 1 {
+		t.Errorf("unexpected commands: %v", stats.Commands)
+	}
+	if stats.ChatTypes["private"] != 3 {
+		t.Errorf("unexpected chat types: %v", stats.ChatTypes)
+	}
+	if stats.DailyUsers != 3 || stats.WeeklyUsers != 3 {
+		t.Errorf("unexpected active users: %d daily, %d weekly", stats.DailyUsers, stats.WeeklyUsers)
+	}
+	if stats.Latency.Count != 4 {
+		t.Errorf("unexpected latency samples: %d", stats.Latency.Count)
+	}
+}
@@ -0,0 +1,131 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// Sticker formats accepted by sticker set management calls.
+const (
+	StickerFormatStatic   = "static"
+	StickerFormatAnimated = "animated"
+	StickerFormatVideo    = "video"
+)
+
+// SendSticker sends a sticker, referenced by file_id or URL with NewStringInputFile
+// or uploaded with NewBytesInputFile, and returns the sent message.
+func SendSticker(ctx context.Context, b *bot.Bot, chatID int64, sticker models.InputFile) (MessageRef, error) {
+	msg, err := b.SendSticker(ctx, &bot.SendStickerParams{
+		ChatID:  chatID,
+		Sticker: sticker,
+	})
+	if err != nil {
+		return MessageRef{}, err
+	}
+	return MessageRef{ChatID: msg.Chat.ID, MessageID: msg.ID}, nil
+}
+
+// SendSticker replies to the update with a sticker.
+func (b *Bot) SendSticker(ctx context.Context, update *Update, sticker models.InputFile) (MessageRef, error) {
+	ref, err := UpdateMessageRef(update)
+	if err != nil {
+		return MessageRef{}, err
+	}
+	return SendSticker(ctx, b.API(), ref.ChatID, sticker)
+}
+
+// StickerFilter selects sticker messages. Empty fields match every sticker.
+type StickerFilter struct {
+	SetName string // Name of the sticker set
+	Emoji   string // Emoji associated with the sticker
+	Type    string // Sticker type: "regular", "mask" or "custom_emoji"
+}
+
+// Match reports whether the sticker satisfies the filter.
+func (f StickerFilter) Match(sticker *models.Sticker) bool {
+	if sticker == nil {
+		return false
+	}
+	if f.SetName != "" && sticker.SetName != f.SetName {
+		return false
+	}
+	if f.Emoji != "" && sticker.Emoji != f.Emoji {
+		return false
+	}
+	if f.Type != "" && sticker.Type != f.Type {
+		return false
+	}
+	return true
+}
+
+// BindSticker registers a handler for sticker messages accepted by the filter.
+func (b *Bot) BindSticker(filter StickerFilter, handlerFunc HandlerFunc, middlewares ...MiddlewareFunc) {
+	b.BindMatch(func(update *Update) bool {
+		return update.Message != nil && filter.Match(update.Message.Sticker)
+	}, handlerFunc, middlewares...)
+}
+
+// StickerSetName returns the name of a sticker set owned by the bot. Telegram requires set
+// names to end with "_by_<bot username>".
+func StickerSetName(name, botUsername string) string {
+	suffix := "_by_" + strings.TrimPrefix(botUsername, "@")
+	if strings.HasSuffix(strings.ToLower(name), strings.ToLower(suffix)) {
+		return name
+	}
+	return name + suffix
+}
+
+// NewInputSticker references an existing sticker file by file_id or URL for sticker set calls.
+func NewInputSticker(sticker, format string, emojis ...string) models.InputSticker {
+	return models.InputSticker{
+		Sticker:   sticker,
+		Format:    format,
+		EmojiList: emojis,
+	}
+}
+
+// NewUploadInputSticker uploads the sticker file with the sticker set call.
+func NewUploadInputSticker(filename string, data []byte, format string, emojis ...string) models.InputSticker {
+	return models.InputSticker{
+		Sticker:           "attach://" + filename,
+		Format:            format,
+		EmojiList:         emojis,
+		StickerAttachment: bytes.NewReader(data),
+	}
+}
+
+// CreateStickerSet creates a regular sticker set owned by the user with the initial stickers.
+func CreateStickerSet(ctx context.Context, b *bot.Bot, userID int64, name, title string, stickers ...models.InputSticker) error {
+	_, err := b.CreateNewStickerSet(ctx, &bot.CreateNewStickerSetParams{
+		UserID:   userID,
+		Name:     name,
+		Title:    title,
+		Stickers: stickers,
+	})
+	return err
+}
+
+// AddStickerToSet adds a sticker to a set created by the bot.
+func AddStickerToSet(ctx context.Context, b *bot.Bot, userID int64, name string, sticker models.InputSticker) error {
+	_, err := b.AddStickerToSet(ctx, &bot.AddStickerToSetParams{
+		UserID:  userID,
+		Name:    name,
+		Sticker: sticker,
+	})
+	return err
+}
+
+// DeleteStickerFromSet deletes a sticker, referenced by file_id, from a set created by the bot.
+func DeleteStickerFromSet(ctx context.Context, b *bot.Bot, fileID string) error {
+	_, err := b.DeleteStickerFromSet(ctx, &bot.DeleteStickerFromSetParams{Sticker: fileID})
+	return err
+}
+
+// GetStickerSet returns the sticker set with the name.
+func GetStickerSet(ctx context.Context, b *bot.Bot, name string) (*models.StickerSet, error) {
+	return b.GetStickerSet(ctx, &bot.GetStickerSetParams{Name: name})
+}
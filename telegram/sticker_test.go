@@ -0,0 +1,29 @@
+package telegram
+
+import (
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestStickerFilter(t *testing.T) {
+	sticker := &models.Sticker{SetName: "cats_by_demo_bot", Emoji: "😺", Type: "regular"}
+	if !(StickerFilter{}).Match(sticker) {
+		t.Error("empty filter must match every sticker")
+	}
+	if !(StickerFilter{SetName: "cats_by_demo_bot", Emoji: "😺"}).Match(sticker) {
+		t.Error("matching filter rejected the sticker")
+	}
+	if (StickerFilter{Emoji: "🐶"}).Match(sticker) || (StickerFilter{}).Match(nil) {
+		t.Error("filter accepted a wrong sticker")
+	}
+}
+
+func TestStickerSetName(t *testing.T) {
+	if got := StickerSetName("cats", "@demo_bot"); got != "cats_by_demo_bot" {
+		t.Errorf("unexpected name %q", got)
+	}
+	if got := StickerSetName("cats_by_Demo_Bot", "demo_bot"); got != "cats_by_Demo_Bot" {
+		t.Errorf("suffix added twice: %q", got)
+	}
+}
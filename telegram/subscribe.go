@@ -0,0 +1,158 @@
+package telegram
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// DropPolicy decides what happens when a subscriber's buffer is full.
+type DropPolicy int
+
+const (
+	// DropNewest discards the incoming update.
+	DropNewest DropPolicy = iota
+	// DropOldest discards the oldest buffered update to make room for the incoming one.
+	DropOldest
+	// Block waits until the subscriber has room, delaying update processing.
+	Block
+)
+
+// subscribeOptions holds configuration for a subscription.
+type subscribeOptions struct {
+	buffer int        // Channel capacity
+	policy DropPolicy // Behavior when the channel is full
+}
+
+// SubscribeOption defines a function type for configuring subscriptions.
+type SubscribeOption func(*subscribeOptions)
+
+// WithSubscriptionBuffer sets the capacity of the subscription channel. Defaults to 100.
+func WithSubscriptionBuffer(size int) SubscribeOption {
+	return func(o *subscribeOptions) {
+		if size > 0 {
+			o.buffer = size
+		}
+	}
+}
+
+// WithDropPolicy sets the behavior when the subscription channel is full. Defaults to DropNewest.
+func WithDropPolicy(policy DropPolicy) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.policy = policy
+	}
+}
+
+type subscriber struct {
+	ch     chan *Update
+	done   chan struct{}
+	filter func(update *Update) bool
+	policy DropPolicy
+	mu     sync.Mutex // Serializes DropOldest sends
+	stop   sync.Once
+}
+
+func (s *subscriber) send(ctx context.Context, update *Update) {
+	switch s.policy {
+	case Block:
+		select {
+		case s.ch <- update:
+		case <-s.done:
+		case <-ctx.Done():
+		}
+	case DropOldest:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for {
+			select {
+			case s.ch <- update:
+				return
+			default:
+			}
+			select {
+			case <-s.ch:
+				slog.Debug("subscription buffer full, drop oldest update")
+			default:
+			}
+		}
+	default:
+		select {
+		case s.ch <- update:
+		default:
+			slog.Debug("subscription buffer full, drop update", slog.Int64("update_id", update.ID))
+		}
+	}
+}
+
+// eventBus fans out every received update to the subscribers.
+type eventBus struct {
+	mu          sync.RWMutex
+	subscribers map[<-chan *Update]*subscriber
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{subscribers: map[<-chan *Update]*subscriber{}}
+}
+
+func (e *eventBus) publish(ctx context.Context, update *Update) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, s := range e.subscribers {
+		if s.filter == nil || s.filter(update) {
+			s.send(ctx, update)
+		}
+	}
+}
+
+// middleware publishes every update before it is routed to the handlers.
+func (e *eventBus) middleware() bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			e.publish(ctx, update)
+			next(ctx, b, update)
+		}
+	}
+}
+
+// Subscribe returns a channel receiving every update accepted by the filter, or every update
+// if the filter is nil. Subscribers observe updates outside the handler chain and must not
+// modify them. Call Unsubscribe to release the subscription and close the channel.
+func (b *Bot) Subscribe(filter func(update *Update) bool, options ...SubscribeOption) <-chan *Update {
+	opts := &subscribeOptions{buffer: 100, policy: DropNewest}
+	for _, opt := range options {
+		opt(opts)
+	}
+	s := &subscriber{
+		ch:     make(chan *Update, opts.buffer),
+		done:   make(chan struct{}),
+		filter: filter,
+		policy: opts.policy,
+	}
+	b.events.mu.Lock()
+	defer b.events.mu.Unlock()
+	b.events.subscribers[s.ch] = s
+	return s.ch
+}
+
+// Unsubscribe removes the subscription and closes its channel.
+func (b *Bot) Unsubscribe(ch <-chan *Update) {
+	b.events.mu.RLock()
+	s, ok := b.events.subscribers[ch]
+	b.events.mu.RUnlock()
+	if !ok {
+		return
+	}
+	// release a publisher blocked on this subscriber before waiting for the write lock
+	s.stop.Do(func() {
+		close(s.done)
+	})
+	b.events.mu.Lock()
+	defer b.events.mu.Unlock()
+	if _, ok = b.events.subscribers[ch]; ok {
+		delete(b.events.subscribers, ch)
+		close(s.ch)
+	}
+}
@@ -0,0 +1,36 @@
+package telegram
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestSubscribe(t *testing.T) {
+	b := &Bot{events: newEventBus()}
+	ctx := context.Background()
+	messages := b.Subscribe(func(update *Update) bool {
+		return update.Message != nil
+	}, WithSubscriptionBuffer(2), WithDropPolicy(DropOldest))
+	all := b.Subscribe(nil, WithSubscriptionBuffer(1))
+
+	for i := int64(1); i <= 3; i++ {
+		b.events.publish(ctx, &Update{ID: i, Message: &models.Message{}})
+	}
+	b.events.publish(ctx, &Update{ID: 4, CallbackQuery: &models.CallbackQuery{}})
+
+	if got := (<-messages).ID; got != 2 {
+		t.Errorf("expected oldest update to be dropped, got %d", got)
+	}
+	if got := (<-messages).ID; got != 3 {
+		t.Errorf("expected update 3, got %d", got)
+	}
+	if got := (<-all).ID; got != 1 {
+		t.Errorf("expected newest updates to be dropped, got %d", got)
+	}
+	b.Unsubscribe(messages)
+	if _, ok := <-messages; ok {
+		t.Error("channel is not closed")
+	}
+}
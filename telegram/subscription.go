@@ -0,0 +1,389 @@
+package telegram
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// ErrSubscriptionNotFound is returned by a SubscriptionStore when the user never subscribed.
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+// ErrPlanRequired is wrapped by the errors of Subscriptions.RequirePlan for users without an
+// active subscription to one of the required plans.
+var ErrPlanRequired = errors.New("subscription plan required")
+
+// subscriptionPayloadPrefix prefixes the invoice payload of subscription invoices.
+const subscriptionPayloadPrefix = "plan:"
+
+// Plan is a paid subscription plan.
+type Plan struct {
+	ID          string
+	Title       string
+	Description string
+	Price       int           // Price in the smallest units of the currency, e.g. Telegram Stars
+	Duration    time.Duration // Time a payment extends the subscription by
+	// Quotas of the plan by route, replacing the free quotas in Subscriptions.QuotaMiddleware.
+	Quotas map[string][]Quota
+}
+
+// Subscription is the paid plan of a user.
+type Subscription struct {
+	UserID    int64     `json:"user_id"`
+	PlanID    string    `json:"plan_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	ChargeID  string    `json:"charge_id,omitempty"` // Telegram payment charge ID of the last payment
+}
+
+// Active reports whether the subscription has not expired at the given time.
+func (s *Subscription) Active(now time.Time) bool {
+	return now.Before(s.ExpiresAt)
+}
+
+// SubscriptionStore persists the subscriptions of users.
+type SubscriptionStore interface {
+	// SetSubscription inserts or replaces the subscription of the user.
+	SetSubscription(ctx context.Context, subscription *Subscription) error
+	// GetSubscription returns the subscription of the user or ErrSubscriptionNotFound.
+	GetSubscription(ctx context.Context, userID int64) (*Subscription, error)
+	// ListSubscriptions returns the subscriptions active at the given time ordered by user ID.
+	ListSubscriptions(ctx context.Context, activeAt time.Time) ([]*Subscription, error)
+}
+
+// MemorySubscriptionStore is an in-memory SubscriptionStore, suitable for tests and single instance bots.
+type MemorySubscriptionStore struct {
+	mu            sync.RWMutex
+	subscriptions map[int64]Subscription
+}
+
+// NewMemorySubscriptionStore creates an empty in-memory subscription store.
+func NewMemorySubscriptionStore() *MemorySubscriptionStore {
+	return &MemorySubscriptionStore{subscriptions: map[int64]Subscription{}}
+}
+
+// SetSubscription implements SubscriptionStore.
+func (s *MemorySubscriptionStore) SetSubscription(ctx context.Context, subscription *Subscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscriptions[subscription.UserID] = *subscription
+	return nil
+}
+
+// GetSubscription implements SubscriptionStore.
+func (s *MemorySubscriptionStore) GetSubscription(ctx context.Context, userID int64) (*Subscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	subscription, ok := s.subscriptions[userID]
+	if !ok {
+		return nil, ErrSubscriptionNotFound
+	}
+	return &subscription, nil
+}
+
+// ListSubscriptions implements SubscriptionStore.
+func (s *MemorySubscriptionStore) ListSubscriptions(ctx context.Context, activeAt time.Time) ([]*Subscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var subscriptions []*Subscription
+	for _, subscription := range s.subscriptions {
+		if subscription.Active(activeAt) {
+			subscriptions = append(subscriptions, &subscription)
+		}
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].UserID < subscriptions[j].UserID
+	})
+	return subscriptions, nil
+}
+
+// DeleteUserData implements UserDataStore.
+func (s *MemorySubscriptionStore) DeleteUserData(ctx context.Context, userID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscriptions, userID)
+	return nil
+}
+
+// subscriptionOptions holds configuration for Subscriptions.
+type subscriptionOptions struct {
+	currency      string                                     // Invoice currency
+	providerToken string                                     // Payment provider token, empty for Telegram Stars
+	scheduler     *Scheduler                                 // Scheduler sending renewal reminders
+	remindBefore  time.Duration                              // Time before the expiry reminders are sent
+	reminder      func(plan *Plan, sub *Subscription) string // Text of the renewal reminder
+}
+
+// SubscriptionOption defines a function type for configuring Subscriptions.
+type SubscriptionOption func(*subscriptionOptions)
+
+// WithSubscriptionCurrency sets the currency and payment provider token of invoices. Defaults
+// to Telegram Stars ("XTR"), which need no provider token.
+func WithSubscriptionCurrency(currency, providerToken string) SubscriptionOption {
+	return func(o *subscriptionOptions) {
+		o.currency = currency
+		o.providerToken = providerToken
+	}
+}
+
+// WithRenewalReminders sends the user a reminder with a renewal invoice before the subscription
+// expires, scheduled on the scheduler which must be run by the caller. The text is built by
+// reminder, nil for a default text.
+func WithRenewalReminders(scheduler *Scheduler, before time.Duration, reminder func(plan *Plan, sub *Subscription) string) SubscriptionOption {
+	return func(o *subscriptionOptions) {
+		o.scheduler = scheduler
+		o.remindBefore = before
+		if reminder != nil {
+			o.reminder = reminder
+		}
+	}
+}
+
+// Subscriptions sells time limited plans through Telegram payments, gates routes by plan and
+// grants plan specific quotas. Payments are handled once Bind was called.
+type Subscriptions struct {
+	app   *Bot
+	store SubscriptionStore
+	plans map[string]*Plan
+	opts  subscriptionOptions
+
+	mu        sync.Mutex
+	reminders map[int64]func() // Cancels the scheduled reminder of a user
+}
+
+// NewSubscriptions creates a subscription component selling the plans.
+func NewSubscriptions(app *Bot, store SubscriptionStore, plans []Plan, options ...SubscriptionOption) *Subscriptions {
+	s := &Subscriptions{
+		app:   app,
+		store: store,
+		plans: make(map[string]*Plan, len(plans)),
+		opts: subscriptionOptions{
+			currency: "XTR",
+			reminder: func(plan *Plan, sub *Subscription) string {
+				return fmt.Sprintf("Your %s subscription expires on %s.", plan.Title, sub.ExpiresAt.Format(time.DateTime))
+			},
+		},
+		reminders: map[int64]func(){},
+	}
+	for _, plan := range plans {
+		s.plans[plan.ID] = &plan
+	}
+	for _, opt := range options {
+		opt(&s.opts)
+	}
+	if s.opts.scheduler != nil {
+		app.OnStart(s.scheduleReminders)
+	}
+	return s
+}
+
+// Plan returns the plan with the ID.
+func (s *Subscriptions) Plan(id string) (*Plan, bool) {
+	plan, ok := s.plans[id]
+	return plan, ok
+}
+
+// Bind registers the handlers of pre-checkout queries and successful payments of subscription
+// invoices. Allow the "pre_checkout_query" update type to receive the former.
+func (s *Subscriptions) Bind(middlewares ...MiddlewareFunc) {
+	s.app.BindMatch(func(update *Update) bool {
+		return update.PreCheckoutQuery != nil && strings.HasPrefix(update.PreCheckoutQuery.InvoicePayload, subscriptionPayloadPrefix)
+	}, s.handlePreCheckout, middlewares...)
+	s.app.BindMatch(func(update *Update) bool {
+		return update.Message != nil && update.Message.SuccessfulPayment != nil &&
+			strings.HasPrefix(update.Message.SuccessfulPayment.InvoicePayload, subscriptionPayloadPrefix)
+	}, s.handlePayment, middlewares...)
+}
+
+// SendInvoice sends an invoice for the plan to the chat.
+func (s *Subscriptions) SendInvoice(ctx context.Context, chatID int64, planID string) error {
+	plan, ok := s.plans[planID]
+	if !ok {
+		return fmt.Errorf("unknown plan %q", planID)
+	}
+	_, err := s.app.API().SendInvoice(ctx, &bot.SendInvoiceParams{
+		ChatID:        chatID,
+		Title:         plan.Title,
+		Description:   cmp.Or(plan.Description, plan.Title),
+		Payload:       subscriptionPayloadPrefix + plan.ID,
+		ProviderToken: s.opts.providerToken,
+		Currency:      s.opts.currency,
+		Prices:        []models.LabeledPrice{{Label: plan.Title, Amount: plan.Price}},
+	})
+	return err
+}
+
+func (s *Subscriptions) handlePreCheckout(ctx context.Context, update *Update) error {
+	query := update.PreCheckoutQuery
+	params := &bot.AnswerPreCheckoutQueryParams{PreCheckoutQueryID: query.ID, OK: true}
+	plan, ok := s.plans[strings.TrimPrefix(query.InvoicePayload, subscriptionPayloadPrefix)]
+	if !ok || query.Currency != s.opts.currency || query.TotalAmount != plan.Price {
+		params.OK, params.ErrorMessage = false, "This plan is no longer available."
+	}
+	_, err := s.app.API().AnswerPreCheckoutQuery(ctx, params)
+	return err
+}
+
+func (s *Subscriptions) handlePayment(ctx context.Context, update *Update) error {
+	payment := update.Message.SuccessfulPayment
+	if update.Message.From == nil {
+		return errors.New("successful payment without sender")
+	}
+	planID := strings.TrimPrefix(payment.InvoicePayload, subscriptionPayloadPrefix)
+	sub, err := s.Activate(ctx, update.Message.From.ID, planID, payment.TelegramPaymentChargeID)
+	if err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "subscription paid", slog.Int64("user_id", sub.UserID), slog.String("plan", planID),
+		slog.Time("expires_at", sub.ExpiresAt))
+	return nil
+}
+
+// Activate extends the subscription of the user to the plan by the plan duration, e.g. after a
+// payment handled outside of Bind or to grant a plan for free. Renewing the active plan extends
+// it from its expiry, switching plans starts the new plan now. Activating again with the charge
+// ID of the current subscription, e.g. for a redelivered payment update, returns it unchanged.
+func (s *Subscriptions) Activate(ctx context.Context, userID int64, planID, chargeID string) (*Subscription, error) {
+	plan, ok := s.plans[planID]
+	if !ok {
+		return nil, fmt.Errorf("unknown plan %q", planID)
+	}
+	now := time.Now()
+	start := now
+	current, err := s.store.GetSubscription(ctx, userID)
+	if err != nil && !errors.Is(err, ErrSubscriptionNotFound) {
+		return nil, err
+	}
+	if err == nil && chargeID != "" && current.ChargeID == chargeID {
+		return current, nil
+	}
+	if err == nil && current.PlanID == planID && current.Active(now) {
+		start = current.ExpiresAt
+	}
+	sub := &Subscription{UserID: userID, PlanID: planID, ExpiresAt: start.Add(plan.Duration), ChargeID: chargeID}
+	if err = s.store.SetSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	s.scheduleReminder(sub)
+	return sub, nil
+}
+
+// Active returns the plan of the user's active subscription, false without one.
+func (s *Subscriptions) Active(ctx context.Context, userID int64) (*Plan, bool, error) {
+	sub, err := s.store.GetSubscription(ctx, userID)
+	if errors.Is(err, ErrSubscriptionNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	plan, ok := s.plans[sub.PlanID]
+	if !ok || !sub.Active(time.Now()) {
+		return nil, false, nil
+	}
+	return plan, true, nil
+}
+
+// RequirePlan returns a middleware only running the handler for users with an active
+// subscription to one of the plans, other updates fail with a UserError wrapping
+// ErrPlanRequired, replied with the "plan_required" translation key.
+func (s *Subscriptions) RequirePlan(planIDs ...string) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, update *Update) error {
+			user := UpdateUser(update)
+			if user == nil {
+				return ErrPlanRequired
+			}
+			plan, ok, err := s.Active(ctx, user.ID)
+			if err != nil {
+				return err
+			}
+			if !ok || !slices.Contains(planIDs, plan.ID) {
+				return WrapUserError(ErrPlanRequired, "This feature requires a subscription.").WithKey("plan_required")
+			}
+			return next(ctx, update)
+		}
+	}
+}
+
+// QuotaMiddleware works like Quotas.Middleware for the route, using the quotas of the user's
+// active plan for the route, or the free quotas for users without a subscription.
+func (s *Subscriptions) QuotaMiddleware(quotas *Quotas, route string, free ...Quota) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, update *Update) error {
+			user := UpdateUser(update)
+			if user == nil {
+				return next(ctx, update)
+			}
+			limits := free
+			plan, ok, err := s.Active(ctx, user.ID)
+			if err != nil {
+				return err
+			}
+			if planLimits, found := plan.quotas(route); ok && found {
+				limits = planLimits
+			}
+			return quotas.Middleware(route, limits...)(next)(ctx, update)
+		}
+	}
+}
+
+func (p *Plan) quotas(route string) ([]Quota, bool) {
+	if p == nil {
+		return nil, false
+	}
+	quotas, ok := p.Quotas[route]
+	return quotas, ok
+}
+
+// scheduleReminders schedules the reminders of the active subscriptions on start.
+func (s *Subscriptions) scheduleReminders(ctx context.Context) error {
+	subs, err := s.store.ListSubscriptions(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		s.scheduleReminder(sub)
+	}
+	return nil
+}
+
+// scheduleReminder replaces the reminder of the user with one for the subscription. Reminders
+// whose time passed, e.g. while the bot was stopped, are not sent.
+func (s *Subscriptions) scheduleReminder(sub *Subscription) {
+	if s.opts.scheduler == nil {
+		return
+	}
+	plan, ok := s.plans[sub.PlanID]
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cancel, ok := s.reminders[sub.UserID]; ok {
+		cancel()
+		delete(s.reminders, sub.UserID)
+	}
+	at := sub.ExpiresAt.Add(-s.opts.remindBefore)
+	if at.Before(time.Now()) {
+		return
+	}
+	s.reminders[sub.UserID] = s.opts.scheduler.ScheduleAt(sub.UserID, at, func(ctx context.Context, chatID int64) error {
+		s.mu.Lock()
+		delete(s.reminders, chatID)
+		s.mu.Unlock()
+		if _, err := SendTo(ctx, s.app.API(), chatID, &Message{Text: s.opts.reminder(plan, sub)}); err != nil {
+			return err
+		}
+		return s.SendInvoice(ctx, chatID, plan.ID)
+	})
+}
@@ -0,0 +1,93 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestSubscriptions(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		answers []string
+	)
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if path.Base(r.URL.Path) == "answerPreCheckoutQuery" {
+			_ = r.ParseMultipartForm(1 << 20)
+			mu.Lock()
+			answers = append(answers, url.Values(r.MultipartForm.Value).Get("ok"))
+			mu.Unlock()
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plans := []Plan{{
+		ID: "pro", Title: "Pro", Price: 100, Duration: 30 * 24 * time.Hour,
+		Quotas: map[string][]Quota{"ask": {{Period: QuotaDaily, Limit: 100}}},
+	}}
+	scheduler := NewScheduler()
+	subs := NewSubscriptions(app, NewMemorySubscriptionStore(), plans, WithRenewalReminders(scheduler, 72*time.Hour, nil))
+	subs.Bind()
+
+	ctx := context.Background()
+	for _, body := range []string{
+		`{"update_id":1,"pre_checkout_query":{"id":"q1","from":{"id":7},"currency":"XTR","total_amount":100,"invoice_payload":"plan:pro"}}`,
+		`{"update_id":2,"pre_checkout_query":{"id":"q2","from":{"id":7},"currency":"XTR","total_amount":1,"invoice_payload":"plan:pro"}}`,
+		`{"update_id":3,"message":{"message_id":1,"from":{"id":7},"chat":{"id":7},"successful_payment":{"currency":"XTR","total_amount":100,"invoice_payload":"plan:pro","telegram_payment_charge_id":"c1"}}}`,
+	} {
+		if err = app.HandleUpdateJSON(ctx, []byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(answers) != 2 || answers[0] != "true" || answers[1] != "false" {
+		t.Errorf("unexpected pre-checkout answers: %v", answers)
+	}
+	plan, ok, err := subs.Active(ctx, 7)
+	if err != nil || !ok || plan.ID != "pro" {
+		t.Fatalf("Active = %v, %v, %v", plan, ok, err)
+	}
+	if len(subs.reminders) != 1 {
+		t.Errorf("renewal reminder was not scheduled")
+	}
+	// renewing extends from the current expiry
+	sub, err := subs.Activate(ctx, 7, "pro", "c2")
+	if err != nil || time.Until(sub.ExpiresAt) < 59*24*time.Hour {
+		t.Errorf("renewal did not extend the subscription: %v, %v", sub, err)
+	}
+	// a redelivered payment does not extend it again
+	again, err := subs.Activate(ctx, 7, "pro", "c2")
+	if err != nil || !again.ExpiresAt.Equal(sub.ExpiresAt) {
+		t.Errorf("repeated charge extended the subscription: %v, %v", again, err)
+	}
+
+	next := func(ctx context.Context, update *Update) error { return nil }
+	gated := subs.RequirePlan("pro")(next)
+	if err = gated(ctx, &Update{Message: &models.Message{From: &models.User{ID: 7}}}); err != nil {
+		t.Errorf("subscriber was rejected: %v", err)
+	}
+	if err = gated(ctx, &Update{Message: &models.Message{From: &models.User{ID: 8}}}); !errors.Is(err, ErrPlanRequired) {
+		t.Errorf("expected ErrPlanRequired, got %v", err)
+	}
+
+	quotas := NewQuotas(NewMemoryQuotaStore())
+	limited := subs.QuotaMiddleware(quotas, "ask", Quota{Period: QuotaDaily, Limit: 1})(next)
+	for i := 0; i < 2; i++ {
+		if err = limited(ctx, &Update{Message: &models.Message{From: &models.User{ID: 7}}}); err != nil {
+			t.Errorf("plan quota was not applied: %v", err)
+		}
+	}
+	_ = limited(ctx, &Update{Message: &models.Message{From: &models.User{ID: 8}}})
+	if err = limited(ctx, &Update{Message: &models.Message{From: &models.User{ID: 8}}}); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("free quota was not applied: %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package telegram
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// Recipient describes who a templated broadcast message is sent to.
+type Recipient struct {
+	ChatID       int64             // Chat the message is sent to
+	LanguageCode string            // Language passed to the translator, see WithTemplateTranslator
+	Vars         map[string]string // Values substituted for "{name}" placeholders in the text
+	StartData    any               // Payload of the deep link substituted for "{link}", see WithTemplateStartLink
+}
+
+// templateOptions holds configuration for templated broadcast messages.
+type templateOptions struct {
+	translator  Translator // Optional i18n hook resolving the template text as a key
+	botUsername string     // Username of the bot deep links point to
+	startRoute  string     // Route of the deep links
+}
+
+// TemplateOption defines a function type for configuring templated broadcast messages.
+type TemplateOption func(*templateOptions)
+
+// WithTemplateTranslator resolves the template text as a translation key in the language of
+// every recipient before placeholders are substituted.
+func WithTemplateTranslator(translator Translator) TemplateOption {
+	return func(o *templateOptions) {
+		o.translator = translator
+	}
+}
+
+// WithTemplateStartLink substitutes "{link}" with a deep link to the bot carrying the
+// recipient's StartData for the route, see StartLink.
+func WithTemplateStartLink(botUsername, route string) TemplateOption {
+	return func(o *templateOptions) {
+		o.botUsername = botUsername
+		o.startRoute = route
+	}
+}
+
+// NewTemplateSender returns a send function for BroadcastMessage and BroadcastFrom that
+// renders the template for every recipient: the text is translated, placeholders and the
+// deep link are substituted, then personalize, if not nil, may adjust a copy of the message.
+// Recipients for which personalize returns nil are skipped.
+func NewTemplateSender[T any](template *Message, recipient func(T) Recipient, personalize func(T, *Message) *Message, options ...TemplateOption) func(context.Context, *bot.Bot, T) error {
+	opts := &templateOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return func(ctx context.Context, b *bot.Bot, item T) error {
+		to := recipient(item)
+		m := opts.render(ctx, template, to)
+		if personalize != nil {
+			if m = personalize(item, m); m == nil {
+				return nil
+			}
+		}
+		_, err := sendBusinessMessage(ctx, b, "", to.ChatID, m)
+		return err
+	}
+}
+
+// render returns a copy of the template rendered for the recipient.
+func (o *templateOptions) render(ctx context.Context, template *Message, to Recipient) *Message {
+	m := *template
+	m.Button = make([][]models.InlineKeyboardButton, len(template.Button))
+	for i, row := range template.Button {
+		m.Button[i] = slices.Clone(row)
+	}
+	m.Entities = slices.Clone(template.Entities)
+	if o.translator != nil && m.Text != "" {
+		// the translator resolves the language from the user of an update
+		update := &Update{Message: &models.Message{
+			Chat: models.Chat{ID: to.ChatID},
+			From: &models.User{ID: to.ChatID, LanguageCode: to.LanguageCode},
+		}}
+		if translated := o.translator(ctx, update, m.Text); translated != "" {
+			m.Text = translated
+		}
+	}
+	pairs := make([]string, 0, 2*len(to.Vars)+2)
+	for name, value := range to.Vars {
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+	if o.botUsername != "" && to.StartData != nil {
+		pairs = append(pairs, "{link}", StartLink(o.botUsername, o.startRoute, to.StartData))
+	}
+	if len(pairs) > 0 {
+		replacer := strings.NewReplacer(pairs...)
+		m.Text = replacer.Replace(m.Text)
+		for _, row := range m.Button {
+			for i := range row {
+				row[i].Text = replacer.Replace(row[i].Text)
+				row[i].URL = replacer.Replace(row[i].URL)
+			}
+		}
+	}
+	return &m
+}
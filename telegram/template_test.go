@@ -0,0 +1,44 @@
+package telegram
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestTemplateRender(t *testing.T) {
+	opts := &templateOptions{}
+	WithTemplateTranslator(func(ctx context.Context, update *Update, key string, args ...any) string {
+		if UpdateUser(update).LanguageCode == "de" && key == "greeting" {
+			return "Hallo {name}, {link}"
+		}
+		return ""
+	})(opts)
+	WithTemplateStartLink("demo_bot", "ref")(opts)
+	template := &Message{
+		Text:   "greeting",
+		Button: [][]models.InlineKeyboardButton{{{Text: "Join {name}", URL: "{link}"}}},
+	}
+
+	m := opts.render(context.Background(), template, Recipient{
+		ChatID:       1,
+		LanguageCode: "de",
+		Vars:         map[string]string{"name": "Ada"},
+		StartData:    "x",
+	})
+	if !strings.HasPrefix(m.Text, "Hallo Ada, https://t.me/demo_bot?start=ref") {
+		t.Errorf("unexpected text: %q", m.Text)
+	}
+	if m.Button[0][0].Text != "Join Ada" || !strings.HasPrefix(m.Button[0][0].URL, "https://t.me/demo_bot") {
+		t.Errorf("unexpected button: %+v", m.Button[0][0])
+	}
+	if template.Button[0][0].Text != "Join {name}" {
+		t.Error("rendering modified the template")
+	}
+
+	if m = opts.render(context.Background(), template, Recipient{ChatID: 2, LanguageCode: "en"}); m.Text != "greeting" {
+		t.Errorf("untranslated text must be kept: %q", m.Text)
+	}
+}
@@ -0,0 +1,88 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/go-telegram/bot"
+)
+
+// redactedToken replaces the bot token in errors, logs and strings produced by the package.
+const redactedToken = "***"
+
+// RedactToken replaces every occurrence of the bot token in s, also in its URL-escaped form,
+// with "***", e.g. before logging a link returned by FileDownloadLink.
+func RedactToken(s, token string) string {
+	if token == "" {
+		return s
+	}
+	s = strings.ReplaceAll(s, token, redactedToken)
+	if escaped := url.QueryEscape(token); escaped != token {
+		s = strings.ReplaceAll(s, escaped, redactedToken)
+	}
+	return s
+}
+
+// RedactToken replaces the current token of the bot in s with "***", see the RedactToken function.
+func (b *Bot) RedactToken(s string) string {
+	return RedactToken(s, b.currentConfig().Token)
+}
+
+// redactTokenError returns the error with the token removed from its message. The *url.Error of
+// the HTTP client, which contains the request URL, is scrubbed in place, other errors are wrapped
+// so errors.Is and errors.As keep working.
+func redactTokenError(err error, token string) error {
+	if err == nil || token == "" {
+		return err
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = RedactToken(urlErr.URL, token)
+	}
+	msg := err.Error()
+	if redacted := RedactToken(msg, token); redacted != msg {
+		return &redactedError{err: err, msg: redacted}
+	}
+	return err
+}
+
+// withTokenRedaction wraps an error handler so errors carrying the current token, e.g. the URL
+// of a failed file download, are scrubbed before they are logged, reported or returned by
+// HandleUpdate.
+func (b *Bot) withTokenRedaction(next ErrorHandlerFunc) ErrorHandlerFunc {
+	return func(ctx context.Context, client *bot.Bot, update *Update, err error) {
+		if next != nil {
+			next(ctx, client, update, redactTokenError(err, b.currentConfig().Token))
+		}
+	}
+}
+
+// redactedConfig has the fields of Config without its methods, so it can be formatted without
+// recursing into String and LogValue.
+type redactedConfig Config
+
+// Redacted returns a copy of the configuration with the token and the webhook secret replaced
+// by "***".
+func (c Config) Redacted() Config {
+	if c.Token != "" {
+		c.Token = redactedToken
+	}
+	if c.Webhook.SecretToken != "" {
+		c.Webhook.SecretToken = redactedToken
+	}
+	return c
+}
+
+// String formats the configuration with its secrets redacted.
+func (c Config) String() string {
+	return fmt.Sprintf("%+v", redactedConfig(c.Redacted()))
+}
+
+// LogValue implements slog.LogValuer, logging the configuration with its secrets redacted.
+func (c Config) LogValue() slog.Value {
+	return slog.AnyValue(redactedConfig(c.Redacted()))
+}
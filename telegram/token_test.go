@@ -0,0 +1,62 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+)
+
+const testToken = "123456:SECRET-token"
+
+func TestRedactToken(t *testing.T) {
+	got := RedactToken("https://api.telegram.org/file/bot123456:SECRET-token/a.jpg?t=123456%3ASECRET-token", testToken)
+	if got != "https://api.telegram.org/file/bot***/a.jpg?t=***" {
+		t.Errorf("unexpected redaction %q", got)
+	}
+
+	config := Config{Token: testToken, Webhook: WebhookConfig{SecretToken: "hook-secret"}}
+	var logs bytes.Buffer
+	slog.New(slog.NewTextHandler(&logs, nil)).Info("config", slog.Any("config", config))
+	for _, s := range []string{config.String(), fmt.Sprint(config), logs.String()} {
+		if strings.Contains(s, "SECRET") || strings.Contains(s, "hook-secret") {
+			t.Errorf("config leaked secrets: %s", s)
+		}
+	}
+}
+
+func TestTokenRedactionInErrors(t *testing.T) {
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/file/") {
+			// drop the connection, so the HTTP client fails with the download URL
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			_ = conn.Close()
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"file_id":"f","file_unique_id":"u","file_path":"photos/a.jpg"}}`))
+	})
+	app, err := NewApp(Config{Token: testToken}, WithAPIServer(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err = app.DownloadFile(ctx, "f"); err == nil || strings.Contains(err.Error(), "SECRET") {
+		t.Errorf("download error leaked the token: %v", err)
+	}
+
+	errLeak := errors.New("leak")
+	app.BindCommand("leak", func(ctx context.Context, update *Update) error {
+		return fmt.Errorf("%w: %s", errLeak, app.API().FileDownloadLink(&models.File{FilePath: "a.jpg"}))
+	})
+	body := `{"update_id":1,"message":{"message_id":1,"from":{"id":7},"chat":{"id":7},"text":"/leak"}}`
+	err = app.HandleUpdateJSON(ctx, []byte(body))
+	if err == nil || strings.Contains(err.Error(), "SECRET") || !errors.Is(err, errLeak) {
+		t.Errorf("handler error was not redacted: %v", err)
+	}
+}
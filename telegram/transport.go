@@ -0,0 +1,50 @@
+package telegram
+
+import (
+	"cmp"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-telegram/bot"
+)
+
+// transportOptions holds the HTTP client configuration of the bot.
+type transportOptions struct {
+	client    *http.Client  // Caller supplied client, takes precedence over the other settings
+	proxy     string        // Proxy URL, e.g. "socks5://127.0.0.1:1080" or "http://proxy:3128"
+	tlsConfig *tls.Config   // TLS configuration of the default transport
+	timeout   time.Duration // Long polling and request timeout
+}
+
+// newHTTPClient builds the HTTP client shared by the underlying bot client and the long
+// polling of Start, and the poll timeout passed along with it.
+func newHTTPClient(o *transportOptions, proxy string, recorder MetricsRecorder) (bot.HttpClient, time.Duration, error) {
+	o.proxy = cmp.Or(o.proxy, proxy)
+	timeout := cmp.Or(o.timeout, defaultPollTimeout)
+	client := o.client
+	if client == nil && o.proxy == "" && o.tlsConfig == nil {
+		client = &http.Client{Timeout: timeout}
+	}
+	if client == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if o.proxy != "" {
+			proxyURL, err := url.Parse(o.proxy)
+			if err != nil {
+				return nil, 0, fmt.Errorf("invalid proxy url: %w", err)
+			}
+			// socks5 proxies are supported natively by net/http
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+		if o.tlsConfig != nil {
+			transport.TLSClientConfig = o.tlsConfig
+		}
+		client = &http.Client{Timeout: timeout, Transport: transport}
+	}
+	if recorder != nil {
+		return &metricsHTTPClient{client: client, recorder: recorder}, timeout, nil
+	}
+	return client, timeout, nil
+}
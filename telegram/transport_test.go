@@ -0,0 +1,94 @@
+package telegram
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	custom := &http.Client{Timeout: 3 * time.Minute}
+	tlsConfig := &tls.Config{ServerName: "api.example.com"}
+	tests := []struct {
+		name        string
+		opts        transportOptions
+		configProxy string
+		wantProxy   string
+		wantTLS     bool
+		wantTimeout time.Duration
+		wantErr     bool
+	}{
+		{name: "default", wantTimeout: defaultPollTimeout},
+		{name: "config proxy", configProxy: "http://config:3128", wantProxy: "http://config:3128", wantTimeout: defaultPollTimeout},
+		{name: "option proxy wins", opts: transportOptions{proxy: "socks5://option:1080"}, configProxy: "http://config:3128", wantProxy: "socks5://option:1080", wantTimeout: defaultPollTimeout},
+		{name: "tls and timeout", opts: transportOptions{tlsConfig: tlsConfig, timeout: 2 * time.Minute}, wantTLS: true, wantTimeout: 2 * time.Minute},
+		{name: "invalid proxy", opts: transportOptions{proxy: "http://[::1"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, timeout, err := newHTTPClient(&tt.opts, tt.configProxy, nil)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			httpClient := client.(*http.Client)
+			if timeout != tt.wantTimeout || httpClient.Timeout != tt.wantTimeout {
+				t.Errorf("timeout = %s, client timeout = %s, want %s", timeout, httpClient.Timeout, tt.wantTimeout)
+			}
+			transport, _ := httpClient.Transport.(*http.Transport)
+			if tt.wantProxy == "" && !tt.wantTLS {
+				if transport != nil {
+					t.Error("expected the default transport")
+				}
+				return
+			}
+			if tt.wantTLS && transport.TLSClientConfig != tlsConfig {
+				t.Error("TLS configuration was not applied")
+			}
+			if tt.wantProxy != "" {
+				proxy, err := transport.Proxy(httptest.NewRequest("GET", "https://api.telegram.org", nil))
+				if err != nil || proxy.String() != tt.wantProxy {
+					t.Errorf("proxy = %v, %v, want %s", proxy, err, tt.wantProxy)
+				}
+			}
+		})
+	}
+
+	// a caller supplied client takes precedence and is only wrapped for metrics
+	opts := transportOptions{client: custom, proxy: "http://ignored:3128"}
+	client, timeout, err := newHTTPClient(&opts, "", nil)
+	if err != nil || client != custom || timeout != defaultPollTimeout {
+		t.Errorf("custom client was not used: %v, %s, %v", client, timeout, err)
+	}
+	client, _, _ = newHTTPClient(&opts, "", &recordingMetrics{})
+	if wrapped, ok := client.(*metricsHTTPClient); !ok || wrapped.client != custom {
+		t.Errorf("custom client was not wrapped: %T", client)
+	}
+}
+
+func TestProxy(t *testing.T) {
+	hosts := make(chan string, 1)
+	// an HTTP proxy receives the absolute URL of the Bot API request
+	proxy := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		hosts <- r.URL.Host
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":1,"date":1,"chat":{"id":1,"type":"private"}}}`))
+	})
+	app, err := NewApp(Config{Token: "token", Proxy: proxy.URL}, WithAPIServer("http://api.invalid"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = app.SendTo(context.Background(), 1, &Message{Text: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	if host := <-hosts; host != "api.invalid" {
+		t.Errorf("request was not sent through the proxy: %q", host)
+	}
+}
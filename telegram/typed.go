@@ -0,0 +1,48 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+)
+
+// NewTypedHandler adapts a service method to a HandlerFunc. The update is decoded into the
+// request, the method is called, and the response is encoded into the reply sent with
+// SendMessage through the client processing the update. Decode failures are wrapped, service
+// errors are returned unchanged so the error handler can map them. A nil response or a nil
+// encoded message sends nothing.
+func NewTypedHandler[Req, Resp any](decode func(*Update) (*Req, error), call func(context.Context, *Req) (*Resp, error), encode func(*Resp) *Message) HandlerFunc {
+	return func(ctx context.Context, update *Update) error {
+		req, err := decode(update)
+		if err != nil {
+			return fmt.Errorf("decode request: %w", err)
+		}
+		resp, err := call(ctx, req)
+		if err != nil {
+			return err
+		}
+		if resp == nil {
+			return nil
+		}
+		msg := encode(resp)
+		if msg == nil {
+			return nil
+		}
+		client := ClientFromContext(ctx)
+		if client == nil {
+			return ErrNoClient
+		}
+		return SendMessage(ctx, client, update, msg)
+	}
+}
+
+// DecodeCallbackData returns a decoder for NewTypedHandler reading the request from the
+// callback query data, see UnmarshalData.
+func DecodeCallbackData[Req any](options ...DataOption) func(*Update) (*Req, error) {
+	return func(update *Update) (*Req, error) {
+		if update.CallbackQuery == nil {
+			return nil, fmt.Errorf("update is not a callback query")
+		}
+		_, req, err := UnmarshalData[Req](update.CallbackQuery.Data, options...)
+		return req, err
+	}
+}
@@ -0,0 +1,41 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type typedRequest struct {
+	ID int `json:"id"`
+}
+
+type typedResponse struct {
+	Name string
+}
+
+func TestTypedHandler(t *testing.T) {
+	serviceErr := errors.New("service failed")
+	var got *typedRequest
+	handler := NewTypedHandler(DecodeCallbackData[typedRequest](), func(ctx context.Context, req *typedRequest) (*typedResponse, error) {
+		got = req
+		if req.ID == 0 {
+			return nil, serviceErr
+		}
+		return nil, nil
+	}, func(resp *typedResponse) *Message {
+		return &Message{Text: resp.Name}
+	})
+	if err := handler(context.Background(), newCallbackUpdate(1, 1, MarshalData("item", typedRequest{ID: 7}))); err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.ID != 7 {
+		t.Errorf("unexpected request: %+v", got)
+	}
+	if err := handler(context.Background(), newCallbackUpdate(1, 1, MarshalData("item", typedRequest{}))); !errors.Is(err, serviceErr) {
+		t.Errorf("expected service error, got %v", err)
+	}
+	if err := handler(context.Background(), &Update{}); err == nil {
+		t.Error("expected decode error")
+	}
+}
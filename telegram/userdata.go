@@ -0,0 +1,66 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// UserDataStore is implemented by stores keeping personal data of users, so Bot.ForgetUser
+// can delete it. The memory stores of the package and the sqlstore implement it.
+type UserDataStore interface {
+	// DeleteUserData deletes the data of the user, deleting an unknown user is not an error.
+	// Data of the private chat with the user, whose ID equals the user ID, is deleted too.
+	DeleteUserData(ctx context.Context, userID int64) error
+}
+
+// UserDataStoreFunc is a function type that implements the UserDataStore interface.
+type UserDataStoreFunc func(ctx context.Context, userID int64) error
+
+// DeleteUserData implements the UserDataStore interface by calling the function.
+func (f UserDataStoreFunc) DeleteUserData(ctx context.Context, userID int64) error {
+	return f(ctx, userID)
+}
+
+// userDataStores is the registry of the stores cleared by ForgetUser.
+type userDataStores struct {
+	mu     sync.RWMutex
+	stores []UserDataStore
+}
+
+func (r *userDataStores) add(stores ...UserDataStore) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, store := range stores {
+		if store != nil {
+			r.stores = append(r.stores, store)
+		}
+	}
+}
+
+func (r *userDataStores) list() []UserDataStore {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]UserDataStore(nil), r.stores...)
+}
+
+// RegisterUserData adds stores whose data of a user is deleted by ForgetUser, e.g. the stores
+// of sessions, settings and the user registry, an Archiver store or a Scheduler. Stores can
+// also be registered at construction with WithUserDataStores.
+func (b *Bot) RegisterUserData(stores ...UserDataStore) {
+	b.userData.add(stores...)
+}
+
+// ForgetUser deletes the data of the user from every registered UserDataStore, e.g. to comply
+// with a deletion request. All stores are cleared even if some fail, the failures are joined
+// into the returned error.
+func (b *Bot) ForgetUser(ctx context.Context, userID int64) error {
+	var errs []error
+	for _, store := range b.userData.list() {
+		if err := store.DeleteUserData(ctx, userID); err != nil {
+			errs = append(errs, fmt.Errorf("delete data of user %d: %w", userID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
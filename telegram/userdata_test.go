@@ -0,0 +1,56 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestForgetUser(t *testing.T) {
+	ctx := context.Background()
+	states := NewMemoryStateStore()
+	users := NewMemoryUserStore()
+	settings := NewMemorySettingsStore()
+	archive := NewMemoryArchiveStore()
+	scheduler := NewScheduler()
+	failing := UserDataStoreFunc(func(ctx context.Context, userID int64) error {
+		return errors.New("unavailable")
+	})
+
+	app, err := NewApp(Config{Token: "token"}, WithUserDataStores(states, users, failing))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.RegisterUserData(settings, archive, scheduler)
+
+	_ = states.SetState(ctx, &ConversationState{Key: StateKey{ChatID: -100, UserID: 7}})
+	_ = states.SetState(ctx, &ConversationState{Key: StateKey{ChatID: -100, UserID: 8}})
+	_ = users.UpsertUser(ctx, &UserRecord{ID: 7})
+	_ = settings.SaveSettings(ctx, &ChatSettings{ChatID: 7, Language: "en"})
+	_ = archive.AppendArchive(ctx, &ArchiveRecord{ChatID: 7, UserID: 1, Time: time.Now()})
+	_ = archive.AppendArchive(ctx, &ArchiveRecord{ChatID: -100, UserID: 8, Time: time.Now()})
+	scheduler.ScheduleAt(7, time.Now().Add(time.Hour), func(ctx context.Context, chatID int64) error { return nil })
+
+	if err = app.ForgetUser(ctx, 7); err == nil {
+		t.Error("the failing store did not surface its error")
+	}
+	if _, err = states.GetState(ctx, StateKey{ChatID: -100, UserID: 7}); !errors.Is(err, ErrStateNotFound) {
+		t.Errorf("state of the user was kept: %v", err)
+	}
+	if _, err = states.GetState(ctx, StateKey{ChatID: -100, UserID: 8}); err != nil {
+		t.Errorf("state of another user was deleted: %v", err)
+	}
+	if _, err = users.GetUser(ctx, 7); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("user record was kept: %v", err)
+	}
+	if _, err = settings.GetSettings(ctx, 7); !errors.Is(err, ErrSettingsNotFound) {
+		t.Errorf("settings of the private chat were kept: %v", err)
+	}
+	if records, _ := archive.QueryArchive(ctx, ArchiveQuery{}); len(records) != 1 || records[0].UserID != 8 {
+		t.Errorf("unexpected archive: %+v", records)
+	}
+	if _, ok := scheduler.nextDue(); ok {
+		t.Error("job of the private chat was kept")
+	}
+}
@@ -0,0 +1,186 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+)
+
+// ErrUserNotFound is returned by a UserStore when the user is unknown.
+var ErrUserNotFound = errors.New("user not found")
+
+// UserRecord is the persisted profile of a user who interacted with the bot.
+type UserRecord struct {
+	ID           int64     `json:"id"`
+	Username     string    `json:"username,omitempty"`
+	FirstName    string    `json:"first_name,omitempty"`
+	LastName     string    `json:"last_name,omitempty"`
+	LanguageCode string    `json:"language_code,omitempty"`
+	FirstSeen    time.Time `json:"first_seen"`
+	LastSeen     time.Time `json:"last_seen"`
+	Blocked      bool      `json:"blocked"` // The user blocked the bot
+}
+
+// UserFilter selects users from a UserStore.
+type UserFilter struct {
+	ActiveSince    time.Time // Only users seen at or after this time, zero for all
+	LanguageCode   string    // Only users with this language, empty for all
+	IncludeBlocked bool      // Whether users who blocked the bot are included
+}
+
+// Match reports whether the user satisfies the filter.
+func (f UserFilter) Match(user *UserRecord) bool {
+	if user.Blocked && !f.IncludeBlocked {
+		return false
+	}
+	if !f.ActiveSince.IsZero() && user.LastSeen.Before(f.ActiveSince) {
+		return false
+	}
+	if f.LanguageCode != "" && user.LanguageCode != f.LanguageCode {
+		return false
+	}
+	return true
+}
+
+// UserStore persists users who interacted with the bot.
+type UserStore interface {
+	// UpsertUser inserts or updates the user, keeping the original FirstSeen of known users.
+	UpsertUser(ctx context.Context, user *UserRecord) error
+	// GetUser returns the user or ErrUserNotFound.
+	GetUser(ctx context.Context, id int64) (*UserRecord, error)
+	// SetBlocked marks whether the user blocked the bot.
+	SetBlocked(ctx context.Context, id int64, blocked bool) error
+	// ListUsers returns the users matching the filter ordered by ID.
+	ListUsers(ctx context.Context, filter UserFilter) ([]*UserRecord, error)
+}
+
+// MemoryUserStore is an in-memory UserStore, suitable for tests and single instance bots.
+type MemoryUserStore struct {
+	mu    sync.RWMutex
+	users map[int64]*UserRecord
+}
+
+// NewMemoryUserStore creates an empty in-memory user store.
+func NewMemoryUserStore() *MemoryUserStore {
+	return &MemoryUserStore{users: map[int64]*UserRecord{}}
+}
+
+// UpsertUser implements UserStore.
+func (s *MemoryUserStore) UpsertUser(ctx context.Context, user *UserRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record := *user
+	if old, ok := s.users[user.ID]; ok && !old.FirstSeen.IsZero() {
+		record.FirstSeen = old.FirstSeen
+	}
+	s.users[user.ID] = &record
+	return nil
+}
+
+// GetUser implements UserStore.
+func (s *MemoryUserStore) GetUser(ctx context.Context, id int64) (*UserRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	user, ok := s.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	record := *user
+	return &record, nil
+}
+
+// SetBlocked implements UserStore.
+func (s *MemoryUserStore) SetBlocked(ctx context.Context, id int64, blocked bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.users[id]
+	if !ok {
+		return ErrUserNotFound
+	}
+	user.Blocked = blocked
+	return nil
+}
+
+// ListUsers implements UserStore.
+func (s *MemoryUserStore) ListUsers(ctx context.Context, filter UserFilter) ([]*UserRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	users := make([]*UserRecord, 0, len(s.users))
+	for _, user := range s.users {
+		if filter.Match(user) {
+			record := *user
+			users = append(users, &record)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID < users[j].ID
+	})
+	return users, nil
+}
+
+// DeleteUserData implements UserDataStore.
+func (s *MemoryUserStore) DeleteUserData(ctx context.Context, userID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.users, userID)
+	return nil
+}
+
+// NewUserRegistryMiddleware creates a middleware that upserts every interacting user into the store.
+// Interacting with the bot clears a previous blocked status. Store failures are logged and do not
+// interrupt update processing.
+func NewUserRegistryMiddleware(store UserStore) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, update *Update) error {
+			if user := UpdateUser(update); user != nil {
+				now := time.Now()
+				err := store.UpsertUser(ctx, &UserRecord{
+					ID:           user.ID,
+					Username:     user.Username,
+					FirstName:    user.FirstName,
+					LastName:     user.LastName,
+					LanguageCode: user.LanguageCode,
+					FirstSeen:    now,
+					LastSeen:     now,
+				})
+				if err != nil {
+					slog.Error("upsert user error", slog.Int64("user_id", user.ID), slog.String("error", err.Error()))
+				}
+			}
+			return next(ctx, update)
+		}
+	}
+}
+
+// RecipientIDs returns the IDs of the users matching the filter, ready to be used as
+// broadcast targets with BroadcastMessage.
+func RecipientIDs(ctx context.Context, store UserStore, filter UserFilter) ([]int64, error) {
+	users, err := store.ListUsers(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	return ids, nil
+}
+
+// MarkBlockedOnForbidden wraps a broadcast send function so users who blocked the bot
+// are marked as blocked in the store, excluding them from future recipient lists.
+func MarkBlockedOnForbidden(store UserStore, send func(context.Context, *bot.Bot, int64) error) func(context.Context, *bot.Bot, int64) error {
+	return func(ctx context.Context, b *bot.Bot, userID int64) error {
+		err := send(ctx, b, userID)
+		if errors.Is(err, bot.ErrorForbidden) {
+			if e := store.SetBlocked(ctx, userID, true); e != nil {
+				slog.Error("mark user blocked error", slog.Int64("user_id", userID), slog.String("error", e.Error()))
+			}
+		}
+		return err
+	}
+}
@@ -0,0 +1,90 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// ReplyButton is an alias for Telegram's reply keyboard button.
+type ReplyButton = models.KeyboardButton
+
+// NewWebAppButton creates an inline keyboard button that opens a Mini App at the given URL.
+func NewWebAppButton(text, url string) Button {
+	return Button{
+		Text:   text,
+		WebApp: &models.WebAppInfo{URL: url},
+	}
+}
+
+// NewReplyWebAppButton creates a reply keyboard button that opens a Mini App at the given URL.
+// Only Mini Apps launched from a reply keyboard button can send data back with Telegram.WebApp.sendData,
+// which arrives as a web_app_data message handled by BindWebAppData.
+func NewReplyWebAppButton(text, url string) ReplyButton {
+	return ReplyButton{
+		Text:   text,
+		WebApp: &models.WebAppInfo{URL: url},
+	}
+}
+
+// UnmarshalWebAppData decodes the JSON payload sent by a Mini App through Telegram.WebApp.sendData.
+// Returns an error if the update does not carry web_app_data.
+func UnmarshalWebAppData[T any](update *Update) (*T, error) {
+	if update == nil || update.Message == nil || update.Message.WebAppData == nil {
+		return nil, errors.New("update has no web app data")
+	}
+	var v T
+	err := json.Unmarshal([]byte(update.Message.WebAppData.Data), &v)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// AnswerWebAppQuery sets the result of an interaction with a Mini App opened from an inline button
+// and sends a message on behalf of the user. The queryID is the query_id field of the validated
+// init data, available as tmaauth.Claims.QueryID on the Mini App backend.
+func AnswerWebAppQuery(ctx context.Context, b *bot.Bot, queryID string, result models.InlineQueryResult) (string, error) {
+	msg, err := b.AnswerWebAppQuery(ctx, &bot.AnswerWebAppQueryParams{
+		WebAppQueryID: queryID,
+		Result:        result,
+	})
+	if err != nil {
+		return "", err
+	}
+	return msg.InlineMessageID, nil
+}
+
+// NewWebAppArticleResult creates a text article result suitable for AnswerWebAppQuery.
+func NewWebAppArticleResult(id, title string, m *Message) models.InlineQueryResult {
+	result := &models.InlineQueryResultArticle{
+		ID:    id,
+		Title: title,
+		InputMessageContent: &models.InputTextMessageContent{
+			MessageText: m.Text,
+			ParseMode:   m.ParseMode,
+		},
+	}
+	if len(m.Button) > 0 {
+		result.ReplyMarkup = &models.InlineKeyboardMarkup{
+			InlineKeyboard: m.Button,
+		}
+	}
+	return result
+}
+
+// BindWebAppData registers a handler for web_app_data service messages sent by Mini Apps
+// launched from a reply keyboard button. Use UnmarshalWebAppData to decode the payload.
+func (b *Bot) BindWebAppData(handlerFunc HandlerFunc, middlewares ...MiddlewareFunc) {
+	b.BindMatch(func(update *Update) bool {
+		return update.Message != nil && update.Message.WebAppData != nil
+	}, handlerFunc, middlewares...)
+}
+
+// AnswerWebAppQuery answers a Mini App query using the bot's client.
+func (b *Bot) AnswerWebAppQuery(ctx context.Context, queryID string, result models.InlineQueryResult) (string, error) {
+	return AnswerWebAppQuery(ctx, b.API(), queryID, result)
+}
@@ -0,0 +1,79 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestWebAppData(t *testing.T) {
+	app, err := NewApp(Config{Token: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	type order struct {
+		Item  string `json:"item"`
+		Count int    `json:"count"`
+	}
+	var got *order
+	app.BindWebAppData(func(ctx context.Context, update *Update) error {
+		got, err = UnmarshalWebAppData[order](update)
+		return err
+	})
+	body := `{"update_id":1,"message":{"message_id":1,"date":1,"chat":{"id":1,"type":"private"},"web_app_data":{"data":"{\"item\":\"tea\",\"count\":2}","button_text":"Order"}}}`
+	if err = app.HandleUpdateJSON(context.Background(), []byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.Item != "tea" || got.Count != 2 {
+		t.Errorf("unexpected web app data: %+v", got)
+	}
+	if _, err = UnmarshalWebAppData[order](&Update{Message: &models.Message{Text: "hi"}}); err == nil {
+		t.Error("expected an error for a message without web app data")
+	}
+}
+
+func TestAnswerWebAppQuery(t *testing.T) {
+	var params struct {
+		QueryID string
+		Result  map[string]any
+	}
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/answerWebAppQuery") {
+			params.QueryID = r.FormValue("web_app_query_id")
+			_ = json.Unmarshal([]byte(r.FormValue("result")), &params.Result)
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"inline_message_id":"inline-1"}}`))
+	})
+	app, err := NewApp(Config{Token: "token"}, WithAPIServer(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := NewWebAppArticleResult("r1", "Receipt", &Message{
+		Text:   "Paid",
+		Button: [][]Button{{NewWebAppButton("Open", "https://example.com/app")}},
+	})
+	id, err := app.AnswerWebAppQuery(context.Background(), "query-1", result)
+	if err != nil || id != "inline-1" {
+		t.Fatalf("AnswerWebAppQuery = %q, %v", id, err)
+	}
+	if params.QueryID != "query-1" || params.Result["type"] != "article" || params.Result["title"] != "Receipt" {
+		t.Errorf("unexpected request: %+v", params)
+	}
+	markup, _ := params.Result["reply_markup"].(map[string]any)
+	if !strings.Contains(toJSON(t, markup), `"web_app":{"url":"https://example.com/app"}`) {
+		t.Errorf("web app button was not sent: %v", markup)
+	}
+}
+
+func toJSON(t *testing.T, v any) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
@@ -0,0 +1,205 @@
+package telegram
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// telegramNetworks are the networks Telegram sends webhook requests from, see
+// https://core.telegram.org/bots/webhooks.
+var telegramNetworks = []netip.Prefix{
+	netip.MustParsePrefix("149.154.160.0/20"),
+	netip.MustParsePrefix("91.108.4.0/22"),
+}
+
+// TelegramNetworks returns the published networks Telegram sends webhook requests from.
+func TelegramNetworks() []netip.Prefix {
+	return slices.Clone(telegramNetworks)
+}
+
+// webhookOptions holds the verification of webhook requests.
+type webhookOptions struct {
+	networks    []netip.Prefix // Networks requests must originate from, empty to accept any
+	forwardedIP string         // Header carrying the client IP set by a trusted reverse proxy
+}
+
+// WithWebhookIPAllowlist makes WebhookHandler reject requests from outside the networks with
+// 403 Forbidden. Without networks Telegram's published networks are used, see TelegramNetworks.
+func WithWebhookIPAllowlist(networks ...netip.Prefix) Option {
+	return func(o *options) {
+		if len(networks) == 0 {
+			networks = TelegramNetworks()
+		}
+		o.webhook.networks = networks
+	}
+}
+
+// WithWebhookForwardedIP reads the client IP checked by WithWebhookIPAllowlist from the header,
+// e.g. "X-Real-IP" or "X-Forwarded-For", instead of the remote address. Only use it behind a
+// reverse proxy that sets the header, otherwise clients can spoof their address.
+// For "X-Forwarded-For" the last address, added by the proxy, is used.
+func WithWebhookForwardedIP(header string) Option {
+	return func(o *options) {
+		o.webhook.forwardedIP = header
+	}
+}
+
+// allowed reports whether the request comes from an allowed network.
+func (o *webhookOptions) allowed(r *http.Request) bool {
+	if len(o.networks) == 0 {
+		return true
+	}
+	addr, ok := o.clientIP(r)
+	if !ok {
+		return false
+	}
+	for _, network := range o.networks {
+		if network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *webhookOptions) clientIP(r *http.Request) (netip.Addr, bool) {
+	host := r.RemoteAddr
+	if o.forwardedIP != "" {
+		values := strings.Split(r.Header.Get(o.forwardedIP), ",")
+		host = strings.TrimSpace(values[len(values)-1])
+	} else if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// WebhookHandler returns the http.Handler receiving updates from Telegram in webhook mode.
+// It always delivers to the current client, also after the token is rotated.
+// Requests without the Config.Webhook.SecretToken in the X-Telegram-Bot-Api-Secret-Token
+// header, or from outside the networks set with WithWebhookIPAllowlist, are rejected with
+// 403 Forbidden.
+func (b *Bot) WebhookHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !b.webhook.allowed(r) {
+			slog.WarnContext(r.Context(), "webhook request from disallowed address", slog.String("remote_addr", r.RemoteAddr))
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		secret := b.currentConfig().Webhook.SecretToken
+		token := r.Header.Get("X-Telegram-Bot-Api-Secret-Token")
+		if secret != "" && subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+			slog.WarnContext(r.Context(), "webhook request with invalid secret token", slog.String("remote_addr", r.RemoteAddr))
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		b.API().WebhookHandler()(w, r)
+	})
+}
+
+// webhookShutdownTimeout bounds the graceful shutdown of the server of StartWebhookTLS.
+const webhookShutdownTimeout = 10 * time.Second
+
+// StartWebhookTLS serves WebhookHandler over TLS on Config.Webhook.Listen, ":8443" by default,
+// at the path of Config.Webhook.URL and processes the updates like StartWebhook, so no reverse
+// proxy is needed. Telegram accepts webhooks on the ports 443, 80, 88 and 8443. For a
+// self-signed certificate set Config.Webhook.SelfSigned, certFile is then uploaded when the
+// webhook is registered, see WriteSelfSignedCertificate. The server is shut down with ctx.
+func (b *Bot) StartWebhookTLS(ctx context.Context, certFile, keyFile string) error {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return fmt.Errorf("load webhook certificate: %w", err)
+	}
+	config := b.currentConfig().Webhook
+	path := "/"
+	if u, err := url.Parse(config.URL); err == nil && u.Path != "" {
+		path = u.Path
+	}
+	mux := http.NewServeMux()
+	mux.Handle(path, b.WebhookHandler())
+	server := &http.Server{
+		Handler:           mux,
+		TLSConfig:         &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12},
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	listener, err := net.Listen("tcp", cmp.Or(config.Listen, ":8443"))
+	if err != nil {
+		return fmt.Errorf("listen webhook: %w", err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	serveErr := make(chan error, 1)
+	go func() {
+		err := server.ServeTLS(listener, "", "")
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		}
+		// a failing server ends the update loop
+		cancel()
+		serveErr <- err
+	}()
+	err = b.startWebhook(ctx, certFile)
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.WithoutCancel(ctx), webhookShutdownTimeout)
+	defer cancelShutdown()
+	return errors.Join(err, server.Shutdown(shutdownCtx), <-serveErr)
+}
+
+// WriteSelfSignedCertificate generates a self-signed certificate for the host, a domain name
+// or the IP address of the server, valid for the duration, and writes it with its private key
+// as PEM files, for StartWebhookTLS with Config.Webhook.SelfSigned.
+func WriteSelfSignedCertificate(certFile, keyFile, host string, validFor time.Duration) error {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: host},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validFor),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	} else {
+		template.DNSNames = []string{host}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err = os.WriteFile(certFile, certPEM, 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(keyFile, keyPEM, 0o600)
+}
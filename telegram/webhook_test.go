@@ -0,0 +1,103 @@
+package telegram
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookHandlerVerification(t *testing.T) {
+	app, err := NewApp(Config{Token: "token", Webhook: WebhookConfig{SecretToken: "secret"}},
+		WithWebhookIPAllowlist(), WithWebhookForwardedIP("X-Forwarded-For"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := app.WebhookHandler()
+	// the request context is done, so accepted updates are dropped instead of queued
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cases := []struct {
+		name      string
+		secret    string
+		forwarded string
+		want      int
+	}{
+		{"valid", "secret", "10.0.0.1, 149.154.167.220", http.StatusOK},
+		{"wrong secret", "guess", "149.154.167.220", http.StatusForbidden},
+		{"missing secret", "", "149.154.167.220", http.StatusForbidden},
+		{"spoofed first address", "secret", "149.154.167.220, 203.0.113.7", http.StatusForbidden},
+		{"no address", "secret", "", http.StatusForbidden},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"update_id":1}`)).WithContext(ctx)
+		if c.secret != "" {
+			req.Header.Set("X-Telegram-Bot-Api-Secret-Token", c.secret)
+		}
+		req.Header.Set("X-Forwarded-For", c.forwarded)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != c.want {
+			t.Errorf("%s: got status %d, want %d", c.name, rec.Code, c.want)
+		}
+	}
+}
+
+func TestStartWebhookTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := WriteSelfSignedCertificate(certFile, keyFile, "127.0.0.1", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	uploaded := make(chan bool, 1)
+	server := newTestAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/setWebhook") {
+			_ = r.ParseMultipartForm(1 << 20)
+			_, ok := r.MultipartForm.File["certificate"]
+			uploaded <- ok
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	_ = listener.Close()
+	app, err := NewApp(Config{Token: "token", Webhook: WebhookConfig{
+		URL: "https://127.0.0.1/hook", SecretToken: "secret", Listen: addr, SelfSigned: true,
+	}}, WithAPIServer(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- app.StartWebhookTLS(ctx, certFile, keyFile) }()
+	if !<-uploaded {
+		t.Error("self-signed certificate was not uploaded")
+	}
+
+	pem, _ := os.ReadFile(certFile)
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(pem)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	resp, err := client.Post("https://"+addr+"/hook", "application/json", strings.NewReader(`{"update_id":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("request without secret token got status %d", resp.StatusCode)
+	}
+	cancel()
+	if err = <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package tmaauth
+
+// UserID returns the ID of the user who opened the Mini App.
+func UserID(claims Claims) int64 {
+	return claims.User.ID
+}
+
+// Username returns the username of the user who opened the Mini App.
+func Username(claims Claims) string {
+	return claims.User.Username
+}
+
+// IsPremium reports whether the user has Telegram Premium.
+func IsPremium(claims Claims) bool {
+	return claims.User.IsPremium
+}
+
+// StartParam returns the startapp parameter passed in the Mini App link.
+func StartParam(claims Claims) string {
+	return claims.StartParam
+}
+
+// ChatType returns the type of chat from which the Mini App was opened.
+func ChatType(claims Claims) string {
+	return string(claims.ChatType)
+}
+
+// ToAuthMap returns the claims in the same shape produced by telegram.DefaultAuthExtractor,
+// containing "uid" (user ID) and "subject" (username), so bot side and Mini App side
+// identities can be handled by one code path.
+func ToAuthMap(claims Claims) map[string]any {
+	if claims.User.ID == 0 {
+		return nil
+	}
+	return map[string]any{
+		"uid":     claims.User.ID,
+		"subject": claims.User.Username,
+	}
+}
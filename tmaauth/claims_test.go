@@ -0,0 +1,28 @@
+package tmaauth
+
+import (
+	"testing"
+
+	initdata "github.com/telegram-mini-apps/init-data-golang"
+)
+
+func TestClaimsHelpers(t *testing.T) {
+	claims := Claims{
+		User:       initdata.User{ID: 1, Username: "username", IsPremium: true},
+		StartParam: "ref",
+		ChatType:   initdata.ChatTypePrivate,
+	}
+	if UserID(claims) != 1 || Username(claims) != "username" || !IsPremium(claims) {
+		t.Errorf("unexpected user accessors: %d %q %t", UserID(claims), Username(claims), IsPremium(claims))
+	}
+	if StartParam(claims) != "ref" || ChatType(claims) != "private" {
+		t.Errorf("unexpected accessors: %q %q", StartParam(claims), ChatType(claims))
+	}
+	auth := ToAuthMap(claims)
+	if auth["uid"] != int64(1) || auth["subject"] != "username" {
+		t.Errorf("unexpected auth map: %v", auth)
+	}
+	if ToAuthMap(Claims{}) != nil {
+		t.Error("claims without user must have no auth map")
+	}
+}
@@ -0,0 +1,189 @@
+package tmaauth
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrSessionMalformed = errors.New("session token is malformed")
+	ErrSessionSignature = errors.New("session token signature is invalid")
+	ErrSessionExpired   = errors.New("session token is expired")
+)
+
+// Signer signs and verifies session tokens issued by TokenExchanger.
+type Signer interface {
+	Algorithm() string
+	Sign(data []byte) ([]byte, error)
+	Verify(data, signature []byte) error
+}
+
+// HMACSigner signs session tokens with HMAC-SHA256 (HS256).
+type HMACSigner struct {
+	key []byte
+}
+
+func NewHMACSigner(key []byte) *HMACSigner {
+	return &HMACSigner{key: key}
+}
+
+func (s *HMACSigner) Algorithm() string {
+	return "HS256"
+}
+
+func (s *HMACSigner) Sign(data []byte) ([]byte, error) {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write(data)
+	return mac.Sum(nil), nil
+}
+
+func (s *HMACSigner) Verify(data, signature []byte) error {
+	expected, err := s.Sign(data)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(expected, signature) {
+		return ErrSessionSignature
+	}
+	return nil
+}
+
+// SessionClaims are the claims carried by a session token.
+type SessionClaims struct {
+	Subject   string         `json:"sub"`
+	UserID    int64          `json:"uid"`
+	Username  string         `json:"username,omitempty"`
+	IssuedAt  int64          `json:"iat"`
+	ExpiresAt int64          `json:"exp"`
+	Extra     map[string]any `json:"ext,omitempty"`
+}
+
+// ClaimsMapper maps validated init data to extra session claims.
+type ClaimsMapper func(claims *Claims) map[string]any
+
+// TokenExchanger exchanges validated init data for a short-lived signed JWT,
+// so that backends do not need to re-validate raw init data on every request.
+type TokenExchanger struct {
+	auth   *TmaAuth
+	signer Signer
+	ttl    time.Duration
+	mapper ClaimsMapper
+}
+
+// ExchangerOption configures a TokenExchanger.
+type ExchangerOption func(*TokenExchanger)
+
+// WithSessionTTL sets the lifetime of issued session tokens. Defaults to 15 minutes.
+func WithSessionTTL(ttl time.Duration) ExchangerOption {
+	return func(e *TokenExchanger) {
+		e.ttl = ttl
+	}
+}
+
+// WithClaimsMapper sets a function that adds extra claims to issued session tokens.
+func WithClaimsMapper(mapper ClaimsMapper) ExchangerOption {
+	return func(e *TokenExchanger) {
+		e.mapper = mapper
+	}
+}
+
+func NewTokenExchanger(auth *TmaAuth, signer Signer, opts ...ExchangerOption) *TokenExchanger {
+	exchanger := &TokenExchanger{
+		auth:   auth,
+		signer: signer,
+		ttl:    15 * time.Minute,
+	}
+	for _, opt := range opts {
+		opt(exchanger)
+	}
+	return exchanger
+}
+
+// Exchange validates the init data and issues a session token for it.
+func (e *TokenExchanger) Exchange(ctx context.Context, initData string) (string, *SessionClaims, error) {
+	claims, err := e.auth.ParseToken(ctx, initData)
+	if err != nil {
+		return "", nil, err
+	}
+	now := time.Now()
+	session := &SessionClaims{
+		Subject:   strconv.FormatInt(claims.User.ID, 10),
+		UserID:    claims.User.ID,
+		Username:  claims.User.Username,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(e.ttl).Unix(),
+	}
+	if e.mapper != nil {
+		session.Extra = e.mapper(&claims)
+	}
+	token, err := e.Issue(session)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, session, nil
+}
+
+// Issue signs the session claims as a compact JWT.
+func (e *TokenExchanger) Issue(session *SessionClaims) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": e.signer.Algorithm(), "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(session)
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	sign, err := e.signer.Sign([]byte(unsigned))
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sign), nil
+}
+
+// Verify checks the signature and expiry of a session token and returns its claims.
+func (e *TokenExchanger) Verify(ctx context.Context, token string) (*SessionClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrSessionMalformed
+	}
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrSessionMalformed
+	}
+	var head struct {
+		Alg string `json:"alg"`
+	}
+	if err = json.Unmarshal(header, &head); err != nil || head.Alg != e.signer.Algorithm() {
+		return nil, ErrSessionMalformed
+	}
+	sign, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrSessionMalformed
+	}
+	if err = e.signer.Verify([]byte(parts[0]+"."+parts[1]), sign); err != nil {
+		return nil, err
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrSessionMalformed
+	}
+	var session SessionClaims
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	if err = decoder.Decode(&session); err != nil {
+		return nil, ErrSessionMalformed
+	}
+	if time.Now().Unix() >= session.ExpiresAt {
+		return nil, ErrSessionExpired
+	}
+	return &session, nil
+}
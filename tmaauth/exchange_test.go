@@ -0,0 +1,52 @@
+package tmaauth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	initdata "github.com/telegram-mini-apps/init-data-golang"
+)
+
+func TestTokenExchanger(t *testing.T) {
+	ctx := context.Background()
+	tmaAuth := NewTmaAuth("test", time.Hour)
+	initData, err := tmaAuth.GenerateToken(ctx, &Claims{
+		User:        initdata.User{ID: 7, Username: "username"},
+		AuthDateRaw: int(time.Now().Unix()),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exchanger := NewTokenExchanger(tmaAuth, NewHMACSigner([]byte("secret")), WithClaimsMapper(func(claims *Claims) map[string]any {
+		return map[string]any{"premium": claims.User.IsPremium}
+	}))
+	token, _, err := exchanger.Exchange(ctx, initData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := exchanger.Verify(ctx, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.UserID != 7 || session.Username != "username" {
+		t.Errorf("unexpected session claims: %+v", session)
+	}
+	if _, ok := session.Extra["premium"]; !ok {
+		t.Error("expected mapped claim premium")
+	}
+
+	other := NewTokenExchanger(tmaAuth, NewHMACSigner([]byte("other")))
+	if _, err = other.Verify(ctx, token); !errors.Is(err, ErrSessionSignature) {
+		t.Errorf("expected %v, got %v", ErrSessionSignature, err)
+	}
+
+	expired, err := exchanger.Issue(&SessionClaims{UserID: 7, ExpiresAt: time.Now().Add(-time.Minute).Unix()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = exchanger.Verify(ctx, expired); !errors.Is(err, ErrSessionExpired) {
+		t.Errorf("expected %v, got %v", ErrSessionExpired, err)
+	}
+}
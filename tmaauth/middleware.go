@@ -0,0 +1,67 @@
+package tmaauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+var ErrMissingInitData = errors.New("missing tma init data")
+
+type claimsContextKey struct{}
+
+// WithClaims returns a copy of ctx carrying the validated claims.
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims injected by Middleware, if any.
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey{}).(*Claims)
+	return claims, ok && claims != nil
+}
+
+// ExtractInitData reads the raw init data from an Authorization header value
+// in the form "tma <initData>".
+func ExtractInitData(authorization string) (string, error) {
+	prefix, initData, found := strings.Cut(strings.TrimSpace(authorization), " ")
+	if !found || !strings.EqualFold(prefix, AuthorizationPrefixTMA) {
+		return "", ErrMissingInitData
+	}
+	initData = strings.TrimSpace(initData)
+	if initData == "" {
+		return "", ErrMissingInitData
+	}
+	return initData, nil
+}
+
+// Authenticate validates an Authorization header value and returns the parsed claims.
+// It is framework agnostic and can be used to build middleware for any HTTP router.
+func (t *TmaAuth) Authenticate(ctx context.Context, authorization string) (*Claims, error) {
+	initData, err := ExtractInitData(authorization)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := t.ParseToken(ctx, initData)
+	if err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+// Middleware returns a net/http middleware that validates the "tma" Authorization header
+// and injects the claims into the request context. Requests that fail validation are
+// rejected with 401 Unauthorized.
+func Middleware(auth *TmaAuth) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := auth.Authenticate(r.Context(), r.Header.Get("Authorization"))
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithClaims(r.Context(), claims)))
+		})
+	}
+}
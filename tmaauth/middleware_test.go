@@ -0,0 +1,48 @@
+package tmaauth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	initdata "github.com/telegram-mini-apps/init-data-golang"
+)
+
+func TestMiddleware(t *testing.T) {
+	tmaAuth := NewTmaAuth("test", time.Hour)
+	token, err := tmaAuth.GenerateToken(context.Background(), &Claims{
+		User:        initdata.User{ID: 42, Username: "username"},
+		AuthDateRaw: int(time.Now().Unix()),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var userID int64
+	handler := Middleware(tmaAuth)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := ClaimsFromContext(r.Context())
+		if !ok {
+			t.Fatal("claims not found in context")
+		}
+		userID = claims.User.ID
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", AuthorizationPrefixTMA+" "+token)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, rec.Code)
+	}
+	if userID != 42 {
+		t.Errorf("expected %d, got %d", 42, userID)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected %d, got %d", http.StatusUnauthorized, rec.Code)
+	}
+}
@@ -0,0 +1,32 @@
+package tmaauth
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	initdata "github.com/telegram-mini-apps/init-data-golang"
+)
+
+// ErrBotIDUnresolvable is returned by signature validation without a bot ID when the token
+// does not start with one.
+var ErrBotIDUnresolvable = errors.New("bot id can not be resolved from token")
+
+// ValidateThird validates init data using the Ed25519 signature field, which allows
+// verifying data issued for the bot with the given ID without knowing its token.
+// See https://core.telegram.org/bots/webapps#validating-data-for-third-party-use
+func (t *TmaAuth) ValidateThird(initData string, botID int64) error {
+	return initdata.ValidateThirdPartyWithEnv(initData, botID, t.expIn, t.testEnv)
+}
+
+func botIDFromToken(token string) (int64, error) {
+	raw, _, found := strings.Cut(token, ":")
+	if !found {
+		return 0, ErrBotIDUnresolvable
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, ErrBotIDUnresolvable
+	}
+	return id, nil
+}
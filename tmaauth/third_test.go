@@ -0,0 +1,46 @@
+package tmaauth
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	initdata "github.com/telegram-mini-apps/init-data-golang"
+)
+
+// thirdPartyInitData is init data signed by Telegram for the bot 7342037359.
+const thirdPartyInitData = "user=%7B%22id%22%3A279058397%2C%22first_name%22%3A%22Vladislav%20%2B%20-%20%3F%20%5C%2F%22%2C%22last_name%22%3A%22Kibenko%22%2C%22username%22%3A%22vdkfrost%22%2C%22language_code%22%3A%22ru%22%2C%22is_premium%22%3Atrue%2C%22allows_write_to_pm%22%3Atrue%2C%22photo_url%22%3A%22https%3A%5C%2F%5C%2Ft.me%5C%2Fi%5C%2Fuserpic%5C%2F320%5C%2F4FPEE4tmP3ATHa57u6MqTDih13LTOiMoKoLDRG4PnSA.svg%22%7D&chat_instance=8134722200314281151&chat_type=private&auth_date=1733584787&hash=2174df5b000556d044f3f020384e879c8efcab55ddea2ced4eb752e93e7080d6&signature=zL-ucjNyREiHDE8aihFwpfR9aggP2xiAo3NSpfe-p7IbCisNlDKlo7Kb6G4D0Ao2mBrSgEk4maLSdv6MLIlADQ"
+
+func TestTmaAuth_ValidateThird(t *testing.T) {
+	ctx := context.Background()
+	tmaAuth := NewTmaAuth("7342037359:secret", 0, WithSignatureValidation(0))
+	claims, err := tmaAuth.ParseToken(ctx, thirdPartyInitData)
+	if err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+	if claims.User.ID != 279058397 {
+		t.Errorf("unexpected user %d", claims.User.ID)
+	}
+
+	tests := []struct {
+		name string
+		auth *TmaAuth
+		data string
+		want error
+	}{
+		{"other bot", NewTmaAuth("12345:secret", 0, WithSignatureValidation(0)), thirdPartyInitData, initdata.ErrSignInvalid},
+		{"test environment", NewTmaAuth("", 0, WithSignatureValidation(7342037359), WithTestEnvironment()), thirdPartyInitData, initdata.ErrSignInvalid},
+		{"expired", NewTmaAuth("7342037359:secret", time.Hour, WithSignatureValidation(0)), thirdPartyInitData, initdata.ErrExpired},
+		{"missing signature", tmaAuth, "auth_date=" + strconv.FormatInt(time.Now().Unix(), 10), initdata.ErrSignMissing},
+		{"bot id", NewTmaAuth("secret", 0, WithSignatureValidation(0)), thirdPartyInitData, ErrBotIDUnresolvable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.auth.ParseToken(ctx, tt.data); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -19,17 +19,59 @@ type Claims = initdata.InitData
 type TmaAuth struct {
 	token string
 	expIn time.Duration
+
+	signature bool
+	botID     int64
+	testEnv   bool
+}
+
+// Option configures how a TmaAuth validates init data.
+type Option func(*TmaAuth)
+
+// WithSignatureValidation validates init data with the Ed25519 signature field
+// instead of the token based hash. If botID is 0 it is resolved from the token.
+func WithSignatureValidation(botID int64) Option {
+	return func(t *TmaAuth) {
+		t.signature = true
+		t.botID = botID
+	}
+}
+
+// WithTestEnvironment uses the Telegram test environment public key for signature validation.
+func WithTestEnvironment() Option {
+	return func(t *TmaAuth) {
+		t.testEnv = true
+	}
 }
 
-func NewTmaAuth(token string, expIn time.Duration) *TmaAuth {
-	return &TmaAuth{
+func NewTmaAuth(token string, expIn time.Duration, opts ...Option) *TmaAuth {
+	auth := &TmaAuth{
 		token: token,
 		expIn: expIn,
 	}
+	for _, opt := range opts {
+		opt(auth)
+	}
+	return auth
+}
+
+func (t *TmaAuth) validate(token string) error {
+	if !t.signature {
+		return initdata.Validate(token, t.token, t.expIn)
+	}
+	botID := t.botID
+	if botID == 0 {
+		id, err := botIDFromToken(t.token)
+		if err != nil {
+			return err
+		}
+		botID = id
+	}
+	return t.ValidateThird(token, botID)
 }
 
 func (t *TmaAuth) ParseToken(ctx context.Context, token string) (Claims, error) {
-	err := initdata.Validate(token, t.token, t.expIn)
+	err := t.validate(token)
 	if err != nil {
 		return Claims{}, err
 	}
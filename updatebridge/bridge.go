@@ -0,0 +1,110 @@
+// Package updatebridge decouples the ingestion of updates from their processing through a
+// message broker such as NATS or Kafka. An ingestion process receives updates by polling or
+// webhook and publishes them, worker processes running the same router consume and process
+// them. The broker clients are adapted with thin wrappers, so no broker SDK is required.
+package updatebridge
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+)
+
+// Publisher publishes a message to a topic, e.g. a NATS subject or a Kafka topic.
+// The key is the chat or user of the update, Kafka adapters should use it as the message key
+// so the updates of a chat stay ordered within a partition.
+type Publisher interface {
+	Publish(ctx context.Context, topic string, key, value []byte) error
+}
+
+// Subscriber consumes the messages of a topic, e.g. with a NATS queue group or a Kafka
+// consumer group so every message is processed by a single worker.
+type Subscriber interface {
+	// Subscribe calls handle for every message until ctx is done. A message is acknowledged
+	// when handle returns nil, otherwise it should be redelivered.
+	Subscribe(ctx context.Context, topic string, handle func(ctx context.Context, value []byte) error) error
+}
+
+// options holds configuration for the bridge.
+type options struct {
+	topic     string
+	redeliver bool
+}
+
+// Option configures the publishing middleware and Consume.
+type Option func(*options)
+
+// WithTopic sets the topic updates are published to and consumed from. Defaults to "telegram.updates".
+func WithTopic(topic string) Option {
+	return func(o *options) {
+		o.topic = topic
+	}
+}
+
+// WithRedeliverOnError makes Consume return handler errors to the Subscriber, so the update
+// is redelivered. By default handler errors are passed to the bot's error handler only and the
+// update is acknowledged, since a failing update would otherwise be redelivered forever.
+func WithRedeliverOnError(redeliver bool) Option {
+	return func(o *options) {
+		o.redeliver = redeliver
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{topic: "telegram.updates"}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// NewPublishMiddleware creates a middleware publishing every update instead of processing it.
+// Add it to the ingestion bot with telegram.AppendUpdateMiddlewares, so it also covers updates
+// without a route. Failed publishes are passed to the bot's error handler.
+func NewPublishMiddleware(pub Publisher, opts ...Option) telegram.MiddlewareFunc {
+	o := newOptions(opts)
+	return func(next telegram.HandlerFunc) telegram.HandlerFunc {
+		return func(ctx context.Context, update *telegram.Update) error {
+			value, err := json.Marshal(update)
+			if err != nil {
+				return err
+			}
+			return pub.Publish(ctx, o.topic, updateKey(update), value)
+		}
+	}
+}
+
+// updateKey returns the chat of the update, or its user for updates without a chat.
+func updateKey(update *telegram.Update) []byte {
+	if ref, err := telegram.UpdateMessageRef(update); err == nil {
+		return strconv.AppendInt(nil, ref.ChatID, 10)
+	}
+	if user := telegram.UpdateUser(update); user != nil {
+		return strconv.AppendInt(nil, user.ID, 10)
+	}
+	return nil
+}
+
+// Consume processes the published updates with the bot until ctx is done, running each through
+// the middlewares and the router with HandleUpdate. Start and StartWebhook are not called for
+// worker bots, the returned error is the one of the Subscriber.
+func Consume(ctx context.Context, app *telegram.Bot, sub Subscriber, opts ...Option) error {
+	o := newOptions(opts)
+	return sub.Subscribe(ctx, o.topic, func(ctx context.Context, value []byte) error {
+		var update telegram.Update
+		if err := json.Unmarshal(value, &update); err != nil {
+			// a malformed message can never be processed, redelivering it is pointless
+			slog.ErrorContext(ctx, "decode consumed update", slog.Any("error", err))
+			return nil
+		}
+		err := app.HandleUpdate(ctx, &update)
+		if err != nil && !o.redeliver {
+			slog.DebugContext(ctx, "consumed update failed", slog.Any("error", err))
+			return nil
+		}
+		return err
+	})
+}
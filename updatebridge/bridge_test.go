@@ -0,0 +1,88 @@
+package updatebridge
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-sphere/telegram-bot/telegram"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+type message struct {
+	topic      string
+	key, value []byte
+}
+
+// memoryBroker delivers the published messages to Subscribe.
+type memoryBroker struct {
+	messages []message
+	acked    int
+}
+
+func (b *memoryBroker) Publish(ctx context.Context, topic string, key, value []byte) error {
+	b.messages = append(b.messages, message{topic: topic, key: key, value: value})
+	return nil
+}
+
+func (b *memoryBroker) Subscribe(ctx context.Context, topic string, handle func(ctx context.Context, value []byte) error) error {
+	for _, m := range b.messages {
+		if m.topic != topic {
+			continue
+		}
+		if err := handle(ctx, m.value); err != nil {
+			return err
+		}
+		b.acked++
+	}
+	return nil
+}
+
+func TestBridge(t *testing.T) {
+	broker := &memoryBroker{}
+	ingest, err := telegram.NewApp(telegram.Config{Token: "token"},
+		telegram.AppendBotOptions(bot.WithNotAsyncHandlers()),
+		telegram.AppendUpdateMiddlewares(NewPublishMiddleware(broker, WithTopic("updates"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ingested := false
+	ingest.BindCommand("/start", func(ctx context.Context, update *telegram.Update) error {
+		ingested = true
+		return nil
+	})
+	ctx := context.Background()
+	ingest.API().ProcessUpdate(ctx, &telegram.Update{ID: 1, Message: &models.Message{Chat: models.Chat{ID: 42}, Text: "/start"}})
+	ingest.API().ProcessUpdate(ctx, &telegram.Update{ID: 2, Message: &models.Message{Chat: models.Chat{ID: 42}, Text: "/fail"}})
+	if ingested {
+		t.Error("the ingestion bot must not process updates")
+	}
+	if len(broker.messages) != 2 || string(broker.messages[0].key) != "42" {
+		t.Fatalf("unexpected published messages: %+v", broker.messages)
+	}
+
+	worker, err := telegram.NewApp(telegram.Config{Token: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var processed []string
+	worker.BindCommand("/start", func(ctx context.Context, update *telegram.Update) error {
+		processed = append(processed, update.Message.Text)
+		return nil
+	})
+	failed := errors.New("failed")
+	worker.BindCommand("/fail", func(ctx context.Context, update *telegram.Update) error {
+		return failed
+	})
+	if err = Consume(ctx, worker, broker, WithTopic("updates")); err != nil {
+		t.Fatal(err)
+	}
+	if len(processed) != 1 || broker.acked != 2 {
+		t.Errorf("unexpected processing: %v, acked %d", processed, broker.acked)
+	}
+	broker.acked = 0
+	if err = Consume(ctx, worker, broker, WithTopic("updates"), WithRedeliverOnError(true)); !errors.Is(err, failed) {
+		t.Errorf("expected handler error for redelivery, got %v", err)
+	}
+}